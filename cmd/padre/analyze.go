@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/encoder"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/probe"
+)
+
+const analyzeUsage = "usage: padre analyze TOKEN"
+
+// runAnalyze describes structure of token: encoding layers, entropy, block length and IV,
+// and suggests options to attack it with. no requests are sent. returns exit code
+func runAnalyze(print *out.Printer, args []string) int {
+	if len(args) != 1 {
+		print.Errorf(analyzeUsage)
+		return 1
+	}
+
+	a := probe.Analyze(args[0])
+	var suggested []string
+
+	// encoding layers
+	if len(a.Layers) == 0 {
+		print.Info("encoding: none detected")
+	} else {
+		print.Info("encoding: %s", color.Green(strings.Join(a.Layers, " > ")))
+	}
+	binary := a.Layers
+	if len(binary) > 0 && binary[0] == "url" {
+		binary = binary[1:]
+	}
+	switch {
+	case len(binary) > 1:
+		print.Warning("nested encodings are not supported, decode outer layers and pass INPUT in %s", binary[len(binary)-1])
+	case len(binary) == 1:
+		switch binary[0] {
+		case probe.EncodingHex:
+			suggested = append(suggested, "-e lhex")
+		case probe.EncodingB64URL:
+			suggested = append(suggested, `-r "+-/_"`)
+		case probe.EncodingB64URLRaw:
+			print.Warning("base64url without padding can not be produced by encoders, unless target accepts padded values")
+			suggested = append(suggested, `-r "+-/_"`)
+		}
+	}
+
+	print.Info("length: %s bytes", color.Green(len(a.Data)))
+	if a.MaxEntropy > 0 {
+		print.Info("entropy: %s bits per byte (%.0f%% of maximum for that length)", color.Green(fmt.Sprintf("%.2f", a.Entropy)), 100*a.Entropy/a.MaxEntropy)
+	}
+
+	if a.Printable {
+		print.Warning("token is a text, not a ciphertext: %s", encoder.NewASCIIencoder().EncodeToString(a.Data))
+		return 0
+	}
+
+	// block length and IV
+	if len(a.BlockLens) == 0 {
+		print.Warning("length is not a multiple of any block length: not CBC, or something (e.g. MAC) is appended")
+	}
+	for _, bl := range a.BlockLens {
+		blocks := a.Blocks(bl)
+		if blocks == 1 {
+			print.Info("block length %d: single block, IV is not part of token (see -no-iv, -iv)", bl)
+		} else {
+			print.Info("block length %d: %d blocks, the first one may be IV", bl, blocks)
+		}
+	}
+	if len(a.BlockLens) == 1 {
+		suggested = append(suggested, fmt.Sprintf("-b %d", a.BlockLens[0]))
+		if a.Blocks(a.BlockLens[0]) == 1 {
+			suggested = append(suggested, "-no-iv")
+		}
+	}
+
+	if a.Encrypted {
+		print.Success("looks like CBC ciphertext")
+	} else {
+		print.Warning("entropy is too low for a ciphertext")
+	}
+	if len(suggested) > 0 {
+		print.Info("suggested options: %s", color.CyanBold(strings.Join(suggested, " ")))
+	}
+	return 0
+}
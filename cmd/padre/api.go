@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/glebarez/padre/pkg/api"
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/glebarez/padre/pkg/monitor"
+	out "github.com/glebarez/padre/pkg/output"
+)
+
+// startAPI serves REST API of headless mode at address of -api, until exit.
+// unauthenticated access is not allowed: if token is not set, it's generated
+func startAPI(print *out.Printer, args *Args, snapshot func() *monitor.Snapshot, gate *client.Gate) (*api.Server, error) {
+	token := *args.APIToken
+	if token == "" {
+		var err error
+		if token, err = randomToken(); err != nil {
+			return nil, err
+		}
+		print.Info("generated API token: %s", color.Green(token))
+	}
+
+	server := api.NewServer(token)
+	server.Snapshot = snapshot
+	server.Gate = gate
+
+	listener, err := net.Listen("tcp", *args.API)
+	if err != nil {
+		return nil, err
+	}
+	httpServer := &http.Server{Handler: server}
+	go httpServer.Serve(listener)
+	atExit(func() { httpServer.Close() })
+
+	print.Info("API is served at %s, submit inputs with %s", color.Green("http://"+listener.Addr().String()), color.CyanBold("POST /jobs"))
+	return server, nil
+}
+
+// outcome of input, as reported through API: output is encoded the same way as for sinks,
+// raw plaintext is escaped
+func reportJob(server *api.Server, id int, output []byte, enc encoder.Encoder, err error) {
+	if err != nil {
+		server.Finish(id, "", nil, err)
+		return
+	}
+	if enc == nil {
+		enc = encoder.NewASCIIencoder()
+	}
+	server.Finish(id, enc.EncodeToString(output), output, nil)
+}
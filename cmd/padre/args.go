@@ -0,0 +1,1255 @@
+package main
+
+import (
+	"crypto/rsa"
+	"crypto/tls"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/cluster"
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/glebarez/padre/pkg/exploit"
+	"github.com/glebarez/padre/pkg/forge"
+	"github.com/glebarez/padre/pkg/httplog"
+	"github.com/glebarez/padre/pkg/monitor"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/rsaoracle"
+	"github.com/glebarez/padre/pkg/session"
+	"github.com/glebarez/padre/pkg/util"
+)
+
+func init() {
+	// a custom usage message
+	flag.Usage = func() {
+		fmt.Fprint(stderr, usage)
+	}
+}
+
+const (
+	defaultConcurrency   = 30
+	defaultRetries       = 0
+	defaultIdleTimeout   = 90 * time.Second
+	defaultTerminalWidth = 80
+	maxConcurrency       = 256
+
+	// low-resource mode
+	lowResourceConcurrency = 8
+	lowResourceGCPercent   = 25
+	lowResourceBufferSize  = 1024
+	lowResourceMaxBodySize = 64 * 1024
+
+	proxyHealthCheckInterval = 30 * time.Second
+	sshTunnelTimeout         = 30 * time.Second
+
+	defaultSessionFile = "padre.session"
+)
+
+// options that describe padding error
+const matcherFlags = "-err, -err-status, -err-length, -err-location, -err-header, -oracle"
+
+// HTTP method is a token (RFC 7230)
+var httpMethodRegexp = regexp.MustCompile("^[-!#$%&'*+.^_`|~0-9A-Za-z]+$")
+
+// Args - CLI flags
+type Args struct {
+	BlockLen             *int
+	Parallel             *int
+	TargetURL            *string
+	Encoder              encoder.Encoder
+	PaddingErrorPattern  *string
+	PaddingErrorStatus   []int
+	PaddingErrorLocation *string
+	PaddingErrorHeader   *string
+	OracleExpression     *string // boolean expression over response, see probe.NewMatcherByExpression
+	PaddingErrorLength   []int   // inclusive range: [min, max]
+	MatchSuccess         *bool
+	ProxyURL             *url.URL
+	ProxyPool            []*url.URL
+	SSH                  *string
+	POSTdata             *string
+	ContentType          *string
+	Cookies              []*http.Cookie
+	Headers              http.Header
+	CookieJar            *bool
+	PlaceholderEncoding  client.PlaceholderEncoding
+	Refresher            *client.SessionRefresher
+	CSRF                 *client.CSRFFetcher
+	EncryptMode          *bool
+	Input                *string
+	Socket               *string
+	Metrics              *string // address to serve Prometheus metrics on
+	API                  *string // headless mode: address to serve REST API on, inputs are submitted through it
+	APIToken             *string // bearer token of REST API
+	Sinks                []*sinkSpec
+	NotifyURL            *string // webhook for progress milestones and results
+	NotifyFormat         *string // payload format of notifications, empty means detected by URL
+	Version              *bool
+	TUI                  *bool
+	Hexdump              *bool
+	ForceRaw             *bool
+	TraceEdu             *bool
+	Quiet                *bool
+	Pipe                 *bool          // inputs from STDIN, outputs into STDOUT, nothing else is printed but errors
+	ProgressInterval     *time.Duration // interval of progress lines in quiet mode
+	Verbosity            int
+	Padding              exploit.Padding // nil means auto-detection
+	Order                exploit.Order
+	Hints                map[int]byte    // known plaintext by offset
+	Format               *exploit.Format // nil means any
+	Retries              *int
+	Confirm              *int
+	Referer              *string
+	CacheHeaders         *bool
+	FinalBlock           *bool
+	NoIV                 *bool
+	IV                   []byte         // known IV, that is not part of inputs
+	IVKey                []byte         // IV is the key: known prefix of the first plaintext block, to recover the key with
+	Forge                []forge.Edit   // edits of decrypted plaintext, that is encrypted back
+	RSAKey               *rsa.PublicKey // RSA mode: ciphertexts are attacked with Bleichenbacher's or Manger's attack
+	RSAMode              string         // attack on RSA
+	SeparateIV           bool           // IV is sent in its own request field, see ${iv} template
+	Scan                 *bool          // placeholder is not set, parameters that look encrypted are tested
+	Sticky               *bool
+	LowResource          *bool
+	HTTP2                *bool
+	KeepAlive            *bool
+	MaxConnsPerHost      *int
+	IdleTimeout          *time.Duration
+	TLSConfig            *tls.Config
+	Delay                *time.Duration
+	MaxRuntime           *time.Duration
+	Jitter               *time.Duration
+	Cache                *int
+	BlockParallel        *int
+	IntermediaryFile     *string
+	LoadIntermediate     *string
+	SaveIntermediate     *string
+	DryRun               *bool
+	Explore              *bool
+	BlockStats           *bool
+	HeadFirst            bool               // blocks are decrypted from the first one (-block-order first)
+	ByteRange            *exploit.ByteRange // positions within block to recover (nil means all)
+	StreamFile           *string
+	Timeout              *time.Duration
+	Method               *string
+	FollowRedirects      *int
+	Workers              []*url.URL
+	WorkerToken          *string
+	OracleCmd            *client.Command
+	LogHTTP              *string
+	LogHTTPFormat        *string
+	LogHTTPValidOnly     *bool
+	SessionFile          *string          // location of session: file or s3://
+	SessionStore         session.Store    // where session is saved
+	Session              *session.Session // session to resume
+}
+
+// errorDescribed tells whether padding error is described explicitly (see matcherFlags), so it's not fingerprinted
+func (args *Args) errorDescribed() bool {
+	return *args.PaddingErrorPattern != "" || args.PaddingErrorStatus != nil || args.PaddingErrorLength != nil ||
+		*args.PaddingErrorLocation != "" || *args.PaddingErrorHeader != "" || *args.OracleExpression != ""
+}
+
+// flag that can be specified multiple times
+type multiFlag []string
+
+func (f *multiFlag) String() string {
+	return strings.Join(*f, " ")
+}
+
+func (f *multiFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func parseArgs() (*Args, *argErrors) {
+	// container for storing errors and warnings
+	argErrs := newArgErrors()
+
+	args := &Args{}
+
+	// simple flags that go in as-is
+	args.PaddingErrorPattern = flag.String("err", "", "")
+	args.BlockLen = flag.Int("b", 0, "")
+	args.Parallel = flag.Int("p", defaultConcurrency, "")
+	args.Retries = flag.Int("retries", defaultRetries, "")
+	args.Confirm = flag.Int("confirm", 1, "")
+	args.POSTdata = flag.String("post", "", "")
+	args.ContentType = flag.String("ct", "", "")
+	args.Referer = flag.String("referer", "", "")
+	args.CacheHeaders = flag.Bool("cache-headers", false, "")
+	args.EncryptMode = flag.Bool("enc", false, "")
+	encFile := flag.String("enc-file", "", "")
+	rsaKey := flag.String("rsa", "", "")
+	mode := flag.String("mode", rsaModeBleichenbacher, "")
+	args.TargetURL = flag.String("u", "", "")
+	configFile := flag.String("c", "", "")
+	preset := flag.String("preset", "", "")
+	rawRequest := flag.String("request", "", "")
+	args.Scan = flag.Bool("scan", false, "")
+	args.MatchSuccess = flag.Bool("match-success", false, "")
+	args.Version = flag.Bool("version", false, "")
+	args.TUI = flag.Bool("tui", false, "")
+	args.Hexdump = flag.Bool("hexdump", false, "")
+	args.ForceRaw = flag.Bool("force-raw", false, "")
+	args.TraceEdu = flag.Bool("trace-edu", false, "")
+	args.Quiet = flag.Bool("quiet", false, "")
+	args.Pipe = flag.Bool("pipe", false, "")
+	args.ProgressInterval = flag.Duration("progress-interval", out.DefaultProgressInterval, "")
+	verbose := flag.Bool("v", false, "")
+	debug := flag.Bool("vv", false, "")
+	args.FinalBlock = flag.Bool("final-block", false, "")
+	args.NoIV = flag.Bool("no-iv", false, "")
+	iv := flag.String("iv", "", "")
+	ivKey := flag.String("iv-key", "", "")
+	args.Sticky = flag.Bool("sticky", false, "")
+	args.LowResource = flag.Bool("low-resource", false, "")
+	args.HTTP2 = flag.Bool("http2", false, "")
+	args.KeepAlive = flag.Bool("keepalive", true, "")
+	args.MaxConnsPerHost = flag.Int("max-conns-per-host", 0, "")
+	args.IdleTimeout = flag.Duration("idle-timeout", defaultIdleTimeout, "")
+	args.Delay = flag.Duration("delay", 0, "")
+	args.MaxRuntime = flag.Duration("max-runtime", 0, "")
+	args.Jitter = flag.Duration("jitter", 0, "")
+	args.Cache = flag.Int("cache", 0, "")
+	args.BlockParallel = flag.Int("block-parallel", 1, "")
+	args.IntermediaryFile = flag.String("intermediary-file", "", "")
+	args.LoadIntermediate = flag.String("load-intermediate", "", "")
+	args.SaveIntermediate = flag.String("save-intermediate", "", "")
+	args.DryRun = flag.Bool("dry-run", false, "")
+	args.Explore = flag.Bool("explore", false, "")
+	args.BlockStats = flag.Bool("block-stats", false, "")
+	args.Timeout = flag.Duration("timeout", 0, "")
+	args.Method = flag.String("X", "", "")
+	args.FollowRedirects = flag.Int("follow-redirects", client.DefaultMaxRedirects, "")
+	args.PaddingErrorLocation = flag.String("err-location", "", "")
+	args.PaddingErrorHeader = flag.String("err-header", "", "")
+	args.OracleExpression = flag.String("oracle", "", "")
+	args.Socket = flag.String("socket", monitor.DefaultSocketPath(os.Getpid()), "")
+
+	// flags that need additional processing
+	proxyURL := flag.String("proxy", "", "")
+	proxyPool := flag.String("proxy-pool", "", "")
+	args.SSH = flag.String("ssh", "", "")
+	certFile := flag.String("cert", "", "")
+	keyFile := flag.String("key", "", "")
+	sni := flag.String("sni", "", "")
+	insecure := flag.Bool("insecure", false, "")
+	tlsMinVersion := flag.String("tls-min-version", "", "")
+	workers := flag.String("workers", "", "")
+	args.WorkerToken = flag.String("token", os.Getenv(tokenEnv), "")
+	oracleCmd := flag.String("oracle-cmd", "", "")
+	args.LogHTTP = flag.String("log-http", "", "")
+	args.LogHTTPFormat = flag.String("log-http-format", "", "")
+	args.LogHTTPValidOnly = flag.Bool("log-http-valid-only", false, "")
+	encoding := flag.String("e", "b64", "")
+	jwe := flag.Bool("jwe", false, "")
+	replacements := flag.String("r", "", "")
+	placeholderEncoding := flag.String("placeholder-encoding", "url", "")
+	cookies := flag.String("cookie", "", "")
+	var headers multiFlag
+	flag.Var(&headers, "H", "")
+	soapAction := flag.String("soap-action", "", "")
+	graphQL := flag.String("graphql", "", "")
+	graphQLVar := flag.String("graphql-var", "", "")
+	var formParts multiFlag
+	flag.Var(&formParts, "multipart", "")
+	args.CookieJar = flag.Bool("cookie-jar", false, "")
+	refreshSession := flag.String("refresh-session", "", "")
+	refreshExtract := flag.String("refresh-extract", "", "")
+	csrfURL := flag.String("csrf-url", "", "")
+	csrfRegexp := flag.String("csrf-regex", "", "")
+	csrfSelector := flag.String("csrf-selector", "", "")
+	csrfEvery := flag.Int("csrf-every", 1, "")
+	errStatus := flag.String("err-status", "", "")
+	errLength := flag.String("err-length", "", "")
+	padding := flag.String("padding", "pkcs7", "")
+	order := flag.String("order", "freq", "")
+	blockOrder := flag.String("block-order", "last", "")
+	byteRange := flag.String("byte-range", "", "")
+	var hints multiFlag
+	flag.Var(&hints, "hint", "")
+	format := flag.String("format", "", "")
+	var forgeEdits multiFlag
+	flag.Var(&forgeEdits, "forge", "")
+	sinks := multiFlag{}
+	flag.Var(&sinks, "sink", "")
+	outFile := flag.String("out", "", "")
+	args.StreamFile = flag.String("stream", "", "")
+	args.NotifyURL = flag.String("notify-url", "", "")
+	args.Metrics = flag.String("metrics", "", "")
+	args.API = flag.String("api", "", "")
+	args.APIToken = flag.String("api-token", os.Getenv(tokenEnv), "")
+	args.NotifyFormat = flag.String("notify-format", "", "")
+	args.SessionFile = flag.String("session", "", "")
+	resume := flag.String("resume", "", "")
+
+	// parse flags
+	flag.Parse()
+
+	// options from config file, those passed on command line take precedence
+	if *configFile != "" {
+		if err := applyConfig(*configFile); err != nil {
+			argErrs.flagError("-c", err)
+		}
+	}
+
+	// options from raw HTTP request
+	if *rawRequest != "" {
+		if err := applyRawRequest(*rawRequest); err != nil {
+			argErrs.flagError("-request", err)
+		}
+	}
+
+	// options of well-known stack, lowest precedence
+	if *preset != "" {
+		if err := applyPreset(*preset); err != nil {
+			argErrs.flagError("-preset", err)
+		}
+	}
+
+	// nothing else matters when version is requested
+	if *args.Version {
+		return args, argErrs
+	}
+
+	// plaintext from file implies encryption
+	if *encFile != "" {
+		*args.EncryptMode = true
+	}
+
+	// method is sent as is, it must be a valid token (e.g. PUT, PATCH, PROPFIND)
+	if *args.Method != "" && !httpMethodRegexp.MatchString(*args.Method) {
+		argErrs.flagErrorf("-X", "Invalid HTTP method: %q", *args.Method)
+	}
+
+	// SOAP action is just another header, so placeholders are replaced in it as well
+	if *soapAction != "" {
+		headers = append(headers, "SOAPAction: \""+strings.Trim(*soapAction, `"`)+"\"")
+	}
+
+	// GraphQL envelope becomes POST data, cipher goes into variable
+	if *graphQL != "" {
+		if isFlagPassed("post") {
+			argErrs.flagErrorf("-graphql, -post", "Cannot be used together")
+		} else if body, err := client.GraphQLBody(*graphQL, *graphQLVar); err != nil {
+			argErrs.flagError("-graphql", err)
+		} else {
+			*args.POSTdata = body
+		}
+	} else if *graphQLVar != "" {
+		argErrs.flagWarningf("-graphql-var", "Ignored without -graphql")
+	}
+
+	// multipart body becomes POST data, boundary goes into content type
+	if len(formParts) > 0 {
+		parts := make([]client.FormPart, 0, len(formParts))
+		for _, s := range formParts {
+			part, err := client.ParseFormPart(s)
+			if err != nil {
+				argErrs.flagError("-multipart", err)
+				continue
+			}
+			parts = append(parts, part)
+		}
+
+		switch {
+		case isFlagPassed("post") || *graphQL != "":
+			argErrs.flagErrorf("-multipart, -post, -graphql", "Cannot be used together")
+		case len(parts) == len(formParts):
+			body, contentType, err := client.MultipartBody(parts)
+			if err != nil {
+				argErrs.flagError("-multipart", err)
+				break
+			}
+			if isFlagPassed("ct") {
+				argErrs.flagWarningf("-ct", "Ignored with -multipart, boundary of body must be in Content-Type")
+			}
+			*args.POSTdata, *args.ContentType = body, contentType
+		}
+	}
+
+	var err error
+	if *oracleCmd != "" {
+		// external command replaces HTTP server
+		args.OracleCmd, err = client.ParseCommand(*oracleCmd)
+		if err != nil {
+			argErrs.flagError("-oracle-cmd", err)
+		}
+		for _, name := range []string{"u", "post", "cookie", "proxy", "proxy-pool", "ssh", "workers", "sticky", "cookie-jar", "refresh-session", "csrf-url", "H", "soap-action", "graphql", "multipart", "request", "scan", "X", "err-location", "err-header", "follow-redirects"} {
+			if isFlagPassed(name) {
+				argErrs.flagErrorf("-oracle-cmd, -"+name, "Cannot be used together")
+			}
+		}
+	} else {
+		// general check on URL, POSTdata or Cookies for having the $ placeholder
+		match1, err := regexp.MatchString(`\$`, *args.TargetURL)
+		if err != nil {
+			argErrs.flagError("-u", err)
+		}
+		match2, err := regexp.MatchString(`\$`, *args.POSTdata)
+		if err != nil {
+			argErrs.flagError("-post", err)
+		}
+		match3, err := regexp.MatchString(`\$`, *cookies)
+		if err != nil {
+			argErrs.flagError("-cookie", err)
+		}
+		match4 := strings.Contains(strings.Join(headers, "\n"), "$")
+		switch {
+		case *args.Scan && (match1 || match2 || match3 || match4):
+			argErrs.flagErrorf("-scan", "Parameters are tested automatically, remove the $ placeholder")
+		case !*args.Scan && !(match1 || match2 || match3 || match4):
+			argErrs.flagErrorf("-u, -post, -cookie, -H", "Either URL, POST data, Cookie or Header must contain the $ placeholder")
+		}
+
+		// template expressions
+		templated := false
+		for name, value := range map[string]string{"-u": *args.TargetURL, "-post": *args.POSTdata, "-cookie": *cookies, "-referer": *args.Referer, "-H": strings.Join(headers, "\n")} {
+			if err := client.ValidateTemplate(value); err != nil {
+				argErrs.flagError(name, err)
+			}
+			templated = templated || client.HasTemplate(value)
+			args.SeparateIV = args.SeparateIV || client.HasIVTemplate(value)
+		}
+		if templated && *workers != "" {
+			argErrs.flagErrorf("-workers", "Template expressions like ${cipher:hex} are not supported by workers, use plain $ placeholder")
+		}
+
+		// Target URL
+		if *args.TargetURL == "" {
+			argErrs.flagErrorf("-u", "Must be specified")
+		} else {
+			_, err = url.Parse(*args.TargetURL)
+			if err != nil {
+				argErrs.flagError("-u", fmt.Errorf("Failed to parse URL: %w", err))
+			}
+		}
+	}
+
+	// Referer derived from the target: same page, without query
+	if *args.Referer == "auto" {
+		if u, err := url.Parse(*args.TargetURL); err == nil {
+			u.RawQuery, u.Fragment = "", ""
+			*args.Referer = u.String()
+		}
+	}
+
+	// Proxy URL
+	if *proxyURL != "" {
+		args.ProxyURL, err = url.Parse(*proxyURL)
+		if err != nil {
+			argErrs.flagError("-proxy", fmt.Errorf("Failed to parse URL: %w", err))
+		}
+	}
+
+	// Proxy pool
+	if *proxyPool != "" {
+		if *proxyURL != "" {
+			argErrs.flagErrorf("-proxy, -proxy-pool", "Cannot be used together, choose one")
+		}
+
+		f, err := os.Open(*proxyPool)
+		if err != nil {
+			argErrs.flagError("-proxy-pool", err)
+		} else {
+			args.ProxyPool, err = client.ParseProxyList(f)
+			f.Close()
+			if err != nil {
+				argErrs.flagError("-proxy-pool", err)
+			} else if len(args.ProxyPool) == 0 {
+				argErrs.flagErrorf("-proxy-pool", "No proxies found in %s", *proxyPool)
+			}
+		}
+	}
+
+	// SSH tunnel replaces proxies
+	if *args.SSH != "" && (*proxyURL != "" || *proxyPool != "") {
+		argErrs.flagErrorf("-ssh, -proxy, -proxy-pool", "Cannot be used together, choose one")
+	}
+	if strings.HasPrefix(*args.SSH, "-") {
+		argErrs.flagErrorf("-ssh", "Destination can not start with '-', expected user@host[:port]")
+	}
+
+	// Encoder (With replacements)
+	if len(*replacements)%2 == 1 {
+		argErrs.flagErrorf("-r", "String must be of even length (0,2,4, etc.)")
+	} else {
+		switch strings.ToLower(*encoding) {
+		case "b64":
+			args.Encoder = encoder.NewB64encoder(*replacements)
+		case "lhex":
+			args.Encoder = encoder.NewLHEXencoder(*replacements)
+		default:
+			argErrs.flagErrorf("-e", "Unsupported encoding specified")
+		}
+	}
+
+	// JWE tokens: IV and ciphertext are attacked, the rest of token is put back into every request
+	if *jwe {
+		args.Encoder = encoder.NewJWEencoder()
+		for _, name := range []string{"e", "r", "enc", "enc-file", "iv", "no-iv", "iv-key"} {
+			if isFlagPassed(name) {
+				argErrs.flagErrorf("-jwe, -"+name, "Cannot be used together")
+			}
+		}
+		switch *args.BlockLen {
+		case 0:
+			*args.BlockLen = 16
+		case 16:
+		default:
+			argErrs.flagErrorf("-jwe", "JWE uses AES, block length must be 16")
+		}
+	}
+
+	// encoding of every scanned parameter is detected on its own
+	if *args.Scan {
+		for _, name := range []string{"e", "r", "jwe", "rsa"} {
+			if isFlagPassed(name) {
+				argErrs.flagErrorf("-scan, -"+name, "Cannot be used together")
+			}
+		}
+	}
+
+	// IV known out-of-band, its length is the block length
+	if *iv != "" {
+		args.IV, err = hex.DecodeString(*iv)
+		switch {
+		case err != nil:
+			argErrs.flagErrorf("-iv", "Must be hex-encoded: %s", err)
+		case *args.NoIV:
+			argErrs.flagErrorf("-iv, -no-iv", "Cannot be used together, choose one")
+		case len(args.IV) != 8 && len(args.IV) != 16 && len(args.IV) != 32:
+			argErrs.flagErrorf("-iv", "Must be 8, 16 or 32 bytes long")
+		case *args.BlockLen == 0:
+			*args.BlockLen = len(args.IV)
+		case *args.BlockLen != len(args.IV):
+			argErrs.flagErrorf("-iv", "Length of IV (%d) does not match block length (%d)", len(args.IV), *args.BlockLen)
+		}
+	}
+
+	// IV in its own request field is tampered just like the cipher, so the first block is recovered as well
+	if args.SeparateIV {
+		cipherSent := false
+		for _, value := range []string{*args.TargetURL, *args.POSTdata, *cookies, strings.Join(headers, "\n")} {
+			cipherSent = cipherSent || strings.Contains(client.StripIVTemplate(value), "$")
+		}
+		switch {
+		case !cipherSent:
+			argErrs.flagErrorf("-u, -post, -cookie, -H", "Besides ${iv}, the $ placeholder must be present for the rest of cipher")
+		case *args.NoIV:
+			argErrs.flagErrorf("-no-iv", "IV is sent in its own field with ${iv}, so it must be known: put it in front of input or use -iv")
+		case *args.BlockLen == 0:
+			argErrs.flagErrorf("-b", "Must be specified when IV is sent in its own field with ${iv}")
+		}
+	}
+
+	// IV is the key: cipher is decrypted as with -no-iv, then the key is recovered from the first block
+	if isFlagPassed("iv-key") {
+		args.IVKey = append([]byte{}, *ivKey...)
+		for _, name := range []string{"iv", "no-iv", "enc", "enc-file", "forge", "final-block"} {
+			if isFlagPassed(name) {
+				argErrs.flagErrorf("-iv-key, -"+name, "Cannot be used together")
+			}
+		}
+		if args.SeparateIV {
+			argErrs.flagErrorf("-iv-key", "IV is the key, so it can not be sent in its own field with ${iv}")
+		}
+		if *args.BlockLen == 32 {
+			argErrs.flagErrorf("-iv-key", "Supported for block length of 8 (DES) and 16 (AES-128) only")
+		}
+	}
+
+	if *args.NoIV && !*args.EncryptMode {
+		argErrs.flagWarningf("-no-iv", "The first block of plaintext can not be recovered without IV, it's shown XORed with the unknown IV")
+	}
+	if (*args.NoIV || args.IV != nil) && *args.EncryptMode && !args.SeparateIV {
+		argErrs.flagWarningf("-no-iv, -iv", "IV of forged cipher can not be set, so the first block of plaintext will be garbled. Start plaintext with a block of junk")
+	}
+	if args.IVKey != nil {
+		*args.NoIV = true
+	}
+
+	// block length
+	switch *args.BlockLen {
+	case 0: // = not set
+	case 8:
+	case 16:
+	case 32:
+	default:
+		argErrs.flagErrorf("-b", "Unsupported value passed. Omit, or specify one of: 8, 16, 32")
+	}
+
+	// padding scheme
+	if !strings.EqualFold(*padding, "auto") {
+		args.Padding, err = exploit.PaddingByName(*padding)
+		if err != nil {
+			argErrs.flagErrorf("-padding", "Unsupported value passed. Specify one of: pkcs7, ansix923, iso10126, iso7816, auto")
+		}
+	}
+
+	// order of candidate bytes
+	args.Order, err = exploit.OrderByName(*order)
+	if err != nil {
+		argErrs.flagErrorf("-order", "Unsupported value passed. Specify one of: freq, seq, random")
+	}
+
+	// order of blocks
+	switch strings.ToLower(*blockOrder) {
+	case "first":
+		args.HeadFirst = true
+		if *args.EncryptMode {
+			argErrs.flagWarningf("-block-order", "Ignored in encrypt mode, every block of ciphertext depends on the next one")
+		}
+	case "last":
+	default:
+		argErrs.flagErrorf("-block-order", "Unsupported value passed. Specify one of: first, last")
+	}
+
+	// positions within block to recover
+	if *byteRange != "" {
+		args.ByteRange, err = exploit.ParseByteRange(*byteRange)
+		if err != nil {
+			argErrs.flagError("-byte-range", err)
+		} else if *args.EncryptMode {
+			argErrs.flagWarningf("-byte-range", "Ignored in encrypt mode, every byte of ciphertext is needed")
+		} else if args.ByteRange.From == 0 {
+			argErrs.flagWarningf("-byte-range", "Bytes are recovered from the end of block, so the range that starts at 0 takes the whole block")
+		}
+		for _, name := range []string{"forge", "iv-key"} {
+			if isFlagPassed(name) {
+				argErrs.flagErrorf("-byte-range, -"+name, "Cannot be used together, the whole plaintext is needed")
+			}
+		}
+	}
+
+	// known plaintext
+	for _, hint := range hints {
+		known, err := exploit.ParseHint(hint)
+		if err != nil {
+			argErrs.flagError("-hint", err)
+			continue
+		}
+		if args.Hints == nil {
+			args.Hints = make(map[int]byte)
+		}
+		for offset, b := range known {
+			args.Hints[offset] = b
+		}
+	}
+	if args.Hints != nil && *args.EncryptMode {
+		argErrs.flagWarningf("-hint", "Ignored in encrypt mode, forged ciphertext can not be guessed")
+	}
+
+	// expected format of plaintext
+	if *format != "" {
+		args.Format, err = exploit.FormatByName(*format)
+		if err != nil {
+			argErrs.flagErrorf("-format", "Unsupported value passed. Specify one of: json, urlencoded, jwt")
+		} else if *args.EncryptMode {
+			argErrs.flagWarningf("-format", "Ignored in encrypt mode, forged ciphertext has no format")
+		}
+	}
+
+	// decrypt, edit and encrypt back
+	for _, e := range forgeEdits {
+		edit, err := forge.ParseEdit(e)
+		if err != nil {
+			argErrs.flagError("-forge", err)
+			continue
+		}
+		args.Forge = append(args.Forge, edit)
+	}
+	// decrypted blocks are written as soon as they are recovered
+	if *args.StreamFile != "" && *args.EncryptMode {
+		argErrs.flagErrorf("-stream", "Applies to decryption only")
+	}
+
+	if args.Forge != nil {
+		if *args.StreamFile != "" {
+			argErrs.flagErrorf("-forge, -stream", "Cannot be used together, output of forging is ciphertext")
+		}
+		if *args.EncryptMode {
+			argErrs.flagErrorf("-forge, -enc", "Cannot be used together, -forge takes ciphertext and encrypts edited plaintext on its own")
+		}
+		if *args.FinalBlock {
+			argErrs.flagErrorf("-forge, -final-block", "Cannot be used together, the whole plaintext is needed to forge")
+		}
+	}
+
+	// only one way of matching padding error can be chosen
+	matchersChosen := 0
+	for _, chosen := range []bool{*args.PaddingErrorPattern != "", *errStatus != "", *errLength != "", *args.PaddingErrorLocation != "", *args.PaddingErrorHeader != "", *args.OracleExpression != ""} {
+		if chosen {
+			matchersChosen++
+		}
+	}
+	if matchersChosen > 1 {
+		argErrs.flagErrorf(matcherFlags, "Cannot be used together, choose one")
+	}
+
+	// RSA padding oracle
+	if *rsaKey != "" {
+		data, err := ioutil.ReadFile(*rsaKey)
+		if err == nil {
+			args.RSAKey, err = rsaoracle.ParsePublicKey(data)
+		}
+		if err != nil {
+			argErrs.flagError("-rsa", err)
+		}
+		for _, name := range []string{"enc", "enc-file", "forge", "final-block", "iv", "no-iv", "iv-key", "jwe", "resume", "format", "hint", "sticky", "block-stats", "stream", "block-order", "byte-range"} {
+			if isFlagPassed(name) {
+				argErrs.flagErrorf("-rsa, -"+name, "Cannot be used together")
+			}
+		}
+		if matchersChosen == 0 {
+			argErrs.flagErrorf("-rsa", "Must be used along with one of: "+matcherFlags)
+		}
+	} else if isFlagPassed("mode") {
+		argErrs.flagErrorf("-mode", "Applies to RSA mode only, use along with -rsa")
+	}
+
+	// attack on RSA
+	switch args.RSAMode = strings.ToLower(*mode); args.RSAMode {
+	case rsaModeBleichenbacher, rsaModeManger:
+	default:
+		argErrs.flagErrorf("-mode", "Unsupported value passed. Specify one of: %s, %s", rsaModeBleichenbacher, rsaModeManger)
+	}
+
+	// padding error status codes
+	if *errStatus != "" {
+		args.PaddingErrorStatus, err = util.ParseStatusCodes(*errStatus)
+		if err != nil {
+			argErrs.flagError("-err-status", err)
+		}
+	}
+
+	// padding error content length
+	if *errLength != "" {
+		min, max, err := util.ParseIntRange(*errLength)
+		if err != nil {
+			argErrs.flagError("-err-length", err)
+		} else {
+			args.PaddingErrorLength = []int{min, max}
+		}
+	}
+
+	if *args.MatchSuccess && matchersChosen == 0 {
+		argErrs.flagErrorf("-match-success", "Must be used along with one of: "+matcherFlags)
+	}
+
+	// padding error is picked interactively from clusters of responses
+	if *args.Explore {
+		if matchersChosen > 0 {
+			argErrs.flagErrorf("-explore", "Cannot be used together with "+matcherFlags+", padding error is picked from clusters of responses")
+		}
+		for _, name := range []string{"scan", "tui"} {
+			if isFlagPassed(name) {
+				argErrs.flagErrorf("-explore, -"+name, "Cannot be used together")
+			}
+		}
+	}
+
+	// random ciphers are rejected by integrity check, so nothing can be auto-detected
+	if *args.FinalBlock {
+		if *args.EncryptMode {
+			argErrs.flagErrorf("-final-block", "Applies to decryption only")
+		}
+		if matchersChosen == 0 {
+			argErrs.flagErrorf("-final-block", "Must be used along with one of: "+matcherFlags)
+		}
+		if *args.BlockLen == 0 {
+			argErrs.flagErrorf("-final-block", "Must be used along with -b")
+		}
+		if args.Padding == nil {
+			argErrs.flagErrorf("-final-block", "Cannot be used with automatic detection of padding")
+		}
+	}
+
+	// extra headers
+	for _, h := range headers {
+		i := strings.Index(h, ":")
+		if i <= 0 {
+			argErrs.flagErrorf("-H", "Must be in form \"Name: value\": %s", h)
+			continue
+		}
+		if args.Headers == nil {
+			args.Headers = http.Header{}
+		}
+		args.Headers.Add(strings.TrimSpace(h[:i]), strings.TrimSpace(h[i+1:]))
+	}
+
+	// escaping of cipher in requests
+	args.PlaceholderEncoding, err = client.PlaceholderEncodingByName(*placeholderEncoding)
+	if err != nil {
+		argErrs.flagErrorf("-placeholder-encoding", "Unsupported value passed. Specify one of: url, none, double-url, base64url, html, xml, json")
+	} else if args.OracleCmd != nil && args.PlaceholderEncoding != client.PlaceholderURL {
+		argErrs.flagWarningf("-placeholder-encoding", "Ignored with -oracle-cmd, cipher is passed as-is")
+	}
+
+	// Cookies
+	if *cookies != "" {
+		args.Cookies, err = util.ParseCookies(*cookies)
+		if err != nil {
+			argErrs.flagError("-cookie", fmt.Errorf("Failed to parse cookies: %s", err))
+		}
+	}
+
+	// session refresh
+	if *refreshSession != "" {
+		if u, err := url.Parse(*refreshSession); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			argErrs.flagErrorf("-refresh-session", "Must be HTTP(S) URL of login page")
+		}
+		args.Refresher = &client.SessionRefresher{URL: *refreshSession}
+		*args.CookieJar = true
+	}
+	if *refreshExtract != "" {
+		if args.Refresher == nil {
+			argErrs.flagErrorf("-refresh-extract", "Requires -refresh-session")
+		} else if err := parseRefreshExtract(*refreshExtract, args.Refresher); err != nil {
+			argErrs.flagError("-refresh-extract", err)
+		}
+	}
+
+	// anti-CSRF token
+	if *csrfURL != "" {
+		args.CSRF, err = parseCSRF(*csrfURL, *csrfRegexp, *csrfSelector, *csrfEvery)
+		if err != nil {
+			argErrs.flagError("-csrf-url", err)
+		}
+		if !strings.Contains(*args.TargetURL+*args.POSTdata+*cookies+*args.Referer, client.CSRFPlaceholder) {
+			argErrs.flagWarningf("-csrf-url", "None of -u, -post, -cookie, -referer contains the %s placeholder, the token is not sent", client.CSRFPlaceholder)
+		}
+
+		// token is usually bound to session
+		*args.CookieJar = true
+	} else if *csrfRegexp != "" || *csrfSelector != "" {
+		argErrs.flagErrorf("-csrf-regex, -csrf-selector", "Requires -csrf-url")
+	}
+
+	if *args.CookieJar && *workers != "" {
+		argErrs.flagErrorf("-cookie-jar, -refresh-session, -csrf-url, -workers", "Cannot be used together, workers do not share cookies")
+	}
+
+	// distributed mode
+	if *workers != "" {
+		args.Workers, err = cluster.ParseWorkers(*workers)
+		if err != nil {
+			argErrs.flagError("-workers", err)
+		} else if *args.WorkerToken == "" {
+			argErrs.flagErrorf("-workers", "Token of workers is required, set it with -token or %s environment variable", tokenEnv)
+		}
+	}
+
+	// response cache
+	if *args.Cache < 0 {
+		argErrs.flagWarningf("-cache", "Cannot be negative, cache is disabled")
+		*args.Cache = 0
+	} else if *args.Cache > 0 && *workers != "" {
+		argErrs.flagWarningf("-cache", "Probes sent by workers are not cached, only those sent locally")
+	}
+
+	// TLS
+	if *keyFile != "" && *certFile == "" {
+		argErrs.flagErrorf("-key", "Requires -cert")
+	} else {
+		tlsOptions := &client.TLSOptions{
+			CertFile:   *certFile,
+			KeyFile:    *keyFile,
+			ServerName: *sni,
+			Insecure:   *insecure,
+		}
+
+		if *tlsMinVersion != "" {
+			tlsOptions.MinVersion, err = client.ParseTLSVersion(*tlsMinVersion)
+			if err != nil {
+				argErrs.flagError("-tls-min-version", err)
+			}
+		}
+
+		args.TLSConfig, err = client.NewTLSConfig(tlsOptions)
+		if err != nil {
+			argErrs.flagError("-cert", err)
+		}
+	}
+
+	// HTTP traffic log
+	if *args.LogHTTP != "" {
+		if *args.LogHTTPFormat == "" {
+			*args.LogHTTPFormat = httplog.DetectFormat(*args.LogHTTP)
+		}
+		*args.LogHTTPFormat = strings.ToLower(*args.LogHTTPFormat)
+		if *args.LogHTTPFormat != httplog.FormatHAR && *args.LogHTTPFormat != httplog.FormatRaw {
+			argErrs.flagErrorf("-log-http-format", "Unsupported value passed. Specify one of: %s, %s", httplog.FormatHAR, httplog.FormatRaw)
+		}
+		if args.OracleCmd != nil {
+			argErrs.flagErrorf("-log-http, -oracle-cmd", "Cannot be used together, oracle command makes no HTTP requests")
+		}
+	} else if *args.LogHTTPFormat != "" || *args.LogHTTPValidOnly {
+		argErrs.flagErrorf("-log-http-format, -log-http-valid-only", "Must be used along with -log-http")
+	}
+
+	// Concurrency
+	if *args.Parallel < 1 {
+		argErrs.flagWarningf("-p", "Cannot be less than 1, value corrected to default value (%d)", defaultConcurrency)
+		*args.Parallel = defaultConcurrency
+	} else if *args.Parallel > maxConcurrency {
+		argErrs.flagWarningf("-p", "Value reduced to maximum allowed value (%d)", maxConcurrency)
+		*args.Parallel = maxConcurrency
+	}
+
+	// blocks at once
+	if *args.BlockParallel < 1 {
+		argErrs.flagWarningf("-block-parallel", "Cannot be less than 1, value corrected to 1")
+		*args.BlockParallel = 1
+	} else if *args.BlockParallel > 1 {
+		switch {
+		case *args.TraceEdu:
+			argErrs.flagErrorf("-block-parallel, -trace-edu", "Cannot be used together, the attack is explained block by block")
+		case *args.EncryptMode:
+			argErrs.flagWarningf("-block-parallel", "Ignored in encrypt mode, every block depends on the next one")
+		case *args.FinalBlock:
+			argErrs.flagWarningf("-block-parallel", "Ignored with -final-block, there is only one block to decrypt")
+		}
+	}
+
+	// -intermediary-file is a shorthand for loading and saving the same file
+	if *args.IntermediaryFile != "" && (*args.LoadIntermediate != "" || *args.SaveIntermediate != "") {
+		argErrs.flagErrorf("-intermediary-file, -load-intermediate, -save-intermediate", "Use either the shorthand or separate files")
+	}
+
+	// nothing is sent in dry run
+	if *args.DryRun {
+		if *args.Scan {
+			argErrs.flagErrorf("-dry-run, -scan", "Cannot be used together, parameters are scanned by sending requests")
+		}
+		for _, name := range []string{"proxy-pool", "ssh", "workers", "sticky"} {
+			if isFlagPassed(name) {
+				argErrs.flagWarningf("-"+name, "Ignored in dry run, nothing is sent")
+			}
+		}
+	}
+
+	// TUI can be excluded from build
+	if *args.TUI && !out.TUIIncluded {
+		argErrs.flagErrorf("-tui", "TUI is not included in this build (see padre build-info)")
+	}
+
+	// verbosity
+	if *debug {
+		args.Verbosity = out.LevelDebug
+	} else if *verbose {
+		args.Verbosity = out.LevelVerbose
+	}
+	if args.Verbosity > 0 && *args.TUI {
+		argErrs.flagErrorf("-v, -vv, -tui", "Cannot be used together, TUI keeps its own log")
+	}
+
+	if *args.ProgressInterval <= 0 {
+		argErrs.flagWarningf("-progress-interval", "Must be positive, value corrected to default value (%s)", out.DefaultProgressInterval)
+		*args.ProgressInterval = out.DefaultProgressInterval
+	}
+
+	if *args.Quiet && *args.TUI {
+		argErrs.flagErrorf("-quiet, -tui", "Cannot be used together")
+	}
+
+	// trace is printed along with status bar
+	if *args.TraceEdu && *args.TUI {
+		argErrs.flagErrorf("-trace-edu, -tui", "Cannot be used together")
+	}
+
+	// low-resource mode lowers default concurrency
+	if *args.LowResource {
+		if !isFlagPassed("p") {
+			*args.Parallel = lowResourceConcurrency
+		}
+		if *args.TUI {
+			argErrs.flagErrorf("-low-resource, -tui", "Cannot be used together")
+		}
+	}
+
+	// connection limit defaults to concurrency
+	if *args.MaxConnsPerHost < 0 {
+		argErrs.flagWarningf("-max-conns-per-host", "Cannot be less than 0, value corrected to %d", *args.Parallel)
+		*args.MaxConnsPerHost = 0
+	}
+	if *args.MaxConnsPerHost == 0 {
+		*args.MaxConnsPerHost = *args.Parallel
+	}
+
+	if *args.FollowRedirects < 0 {
+		argErrs.flagWarningf("-follow-redirects", "Cannot be negative, value corrected to 0 (redirects are not followed)")
+		*args.FollowRedirects = 0
+	}
+
+	// padding error is told by Location of redirect, following it would cost a request per probe
+	if *args.PaddingErrorLocation != "" && !isFlagPassed("follow-redirects") {
+		*args.FollowRedirects = 0
+	}
+
+	if *args.Timeout < 0 {
+		argErrs.flagWarningf("-timeout", "Cannot be negative, timeout is set dynamically")
+		*args.Timeout = 0
+	}
+
+	if *args.IdleTimeout < 0 {
+		argErrs.flagWarningf("-idle-timeout", "Cannot be negative, value corrected to default value (%s)", defaultIdleTimeout)
+		*args.IdleTimeout = defaultIdleTimeout
+	}
+
+	if *args.MaxRuntime < 0 {
+		argErrs.flagWarningf("-max-runtime", "Cannot be negative, value corrected to 0 (no limit)")
+		*args.MaxRuntime = 0
+	}
+
+	// throttling
+	if *args.Delay < 0 {
+		argErrs.flagWarningf("-delay", "Cannot be negative, value corrected to 0")
+		*args.Delay = 0
+	}
+	if *args.Jitter < 0 {
+		argErrs.flagWarningf("-jitter", "Cannot be negative, value corrected to 0")
+		*args.Jitter = 0
+	}
+
+	// Retries
+	if *args.Retries < 0 {
+		argErrs.flagWarningf("-retries", "Cannot be less than 0, value corrected to 0")
+		*args.Retries = 0
+	}
+
+	// majority vote
+	if *args.Confirm < 1 || *args.Confirm%2 == 0 {
+		argErrs.flagErrorf("-confirm", "Must be odd positive number, so that majority always exists (1, 3, 5, etc.)")
+	}
+
+	// content-type auto-detection
+	if *args.POSTdata != "" && *args.ContentType == "" {
+		*args.ContentType = util.DetectContentType(*args.POSTdata)
+		argErrs.warningf("HTTP Content-Type detected automatically as %s", color.Yellow(*args.ContentType))
+	}
+
+	// SOAP 1.2 has no SOAPAction header, action is a parameter of content type instead
+	if *soapAction != "" && strings.HasPrefix(*args.ContentType, "application/soap+xml") && !strings.Contains(*args.ContentType, "action=") {
+		*args.ContentType += "; action=\"" + strings.Trim(*soapAction, `"`) + "\""
+	}
+
+	// URL escaping would break the cipher inside JSON or XML body, escaping follows format of the body instead.
+	// the same escaping applies to every place of cipher, so it's only changed when the body is the only one
+	if bodyEncoding, ok := client.PlaceholderEncodingFor(*args.ContentType); ok && args.OracleCmd == nil &&
+		!isFlagPassed("placeholder-encoding") && bodyEncoding != args.PlaceholderEncoding && strings.Contains(*args.POSTdata, "$") {
+		bodyType := strings.TrimSpace(strings.SplitN(*args.ContentType, ";", 2)[0])
+		if strings.Contains(*args.TargetURL+*cookies+strings.Join(headers, "\n"), "$") {
+			argErrs.flagWarningf("-placeholder-encoding", "Cipher is placed in %s body and elsewhere, use ${cipher:%s} in body to escape it properly",
+				bodyType, bodyEncoding)
+		} else {
+			args.PlaceholderEncoding = bodyEncoding
+			argErrs.flagWarningf("-placeholder-encoding", "Cipher is placed in %s body, set to %s automatically", bodyType, color.Yellow(bodyEncoding))
+		}
+	}
+
+	// output sinks
+	// output file is a shorthand for raw file sink, it does not replace the default sink
+	if *outFile != "" {
+		if len(sinks) == 0 {
+			sinks = append(sinks, "terminal")
+		}
+		sinks = append(sinks, "file:"+*outFile+",enc=raw")
+	}
+
+	for _, s := range sinks {
+		spec, err := parseSinkSpec(s)
+		if err != nil {
+			argErrs.flagError("-sink", err)
+			continue
+		}
+		args.Sinks = append(args.Sinks, spec)
+	}
+
+	// pipeline: outputs go into STDOUT as-is, whether it's a terminal or not
+	if *args.Pipe {
+		for _, name := range []string{"sink", "out", "tui", "api", "v", "vv", "explore", "enc-file"} {
+			if isFlagPassed(name) {
+				argErrs.flagErrorf("-pipe, -"+name, "Cannot be used together")
+			}
+		}
+		if flag.NArg() > 0 {
+			argErrs.flagErrorf("-pipe, [INPUT]", "Cannot be used together, inputs are read from STDIN")
+		}
+		args.Sinks = []*sinkSpec{{kind: "terminal"}}
+		*args.ForceRaw = true
+	}
+
+	// notifications
+	if *args.NotifyURL != "" {
+		if u, err := url.Parse(*args.NotifyURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			argErrs.flagErrorf("-notify-url", "Must be http:// or https:// URL")
+		}
+	}
+	switch *args.NotifyFormat {
+	case "", out.NotifyJSON, out.NotifySlack, out.NotifyDiscord:
+		if *args.NotifyFormat != "" && *args.NotifyURL == "" {
+			argErrs.flagWarningf("-notify-format", "Ignored, since -notify-url is not set")
+		}
+	default:
+		argErrs.flagErrorf("-notify-format", "Unsupported format, use one of: %s, %s, %s", out.NotifyJSON, out.NotifySlack, out.NotifyDiscord)
+	}
+
+	// decide on input source
+	switch flag.NArg() {
+	case 0:
+		// no input passed, STDIN will be used
+	case 1:
+		// input is passed
+		args.Input = &flag.Args()[0]
+	default:
+		// too many positional arguments
+		argErrs.flagErrorf("[INPUT]", "Specify exactly one input string, or pipe into STDIN")
+	}
+
+	// plaintext is taken from file as-is (arbitrary bytes, no splitting into lines), - means STDIN
+	if *encFile != "" {
+		var plaintext []byte
+		if *encFile == "-" {
+			plaintext, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			plaintext, err = ioutil.ReadFile(*encFile)
+		}
+		switch {
+		case args.Input != nil:
+			argErrs.flagErrorf("-enc-file, [INPUT]", "Cannot be used together, choose one")
+		case err != nil:
+			argErrs.flagError("-enc-file", err)
+		case len(plaintext) == 0:
+			argErrs.flagErrorf("-enc-file", "Plaintext is empty")
+		default:
+			input := string(plaintext)
+			args.Input = &input
+		}
+	}
+
+	// resume interrupted session
+	// inputs, mode and block length are taken from the session
+	if *resume != "" {
+		var store session.Store
+		store, err = session.OpenStore(*resume)
+		if err == nil {
+			args.Session, err = store.Load()
+		}
+		if err != nil {
+			argErrs.flagError("-resume", err)
+		} else {
+			if args.Input != nil {
+				argErrs.flagErrorf("-resume, [INPUT]", "Cannot be used together, inputs are taken from the session")
+			}
+			if *args.BlockLen != 0 && *args.BlockLen != args.Session.BlockLen {
+				argErrs.flagWarningf("-b", "Overridden by the session (%d)", args.Session.BlockLen)
+			}
+			*args.BlockLen = args.Session.BlockLen
+			*args.EncryptMode = args.Session.Mode == "encrypt"
+			if args.Session.Mode == "forge" && args.Forge == nil {
+				argErrs.flagWarningf("-resume", "Session was started with -forge, pass the edits again to forge, otherwise inputs are only decrypted")
+			}
+		}
+
+		// by default, session is saved back into the same file
+		if *args.SessionFile == "" {
+			*args.SessionFile = *resume
+		}
+	}
+
+	// headless mode: inputs are submitted through API, the one passed in CLI (if any) is the first
+	if *args.API != "" {
+		for _, name := range []string{"resume", "rsa", "tui"} {
+			if isFlagPassed(name) {
+				argErrs.flagErrorf("-api, -"+name, "Cannot be used together")
+			}
+		}
+	} else if isFlagPassed("api-token") {
+		argErrs.flagWarningf("-api-token", "Ignored, since -api is not set")
+	}
+
+	if *args.SessionFile == "" {
+		*args.SessionFile = defaultSessionFile
+	}
+
+	args.SessionStore, err = session.OpenStore(*args.SessionFile)
+	if err != nil {
+		argErrs.flagError("-session", err)
+	}
+
+	return args, argErrs
+}
+
+// tells whether flag was passed explicitly
+func isFlagPassed(name string) bool {
+	passed := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
+		}
+	})
+	return passed
+}
+
+// parses NAME=REGEX: cookie NAME gets the first group of REGEX, matched against login response
+func parseRefreshExtract(spec string, r *client.SessionRefresher) error {
+	i := strings.Index(spec, "=")
+	if i <= 0 {
+		return fmt.Errorf("Must be in form NAME=REGEX")
+	}
+
+	re, err := regexp.Compile(spec[i+1:])
+	if err != nil {
+		return err
+	}
+	if re.NumSubexp() < 1 {
+		return fmt.Errorf("Regular expression must have a group to capture the session value")
+	}
+
+	r.Cookie, r.Extract = spec[:i], re
+	return nil
+}
+
+// creates fetcher of anti-CSRF token, that is extracted from page with either regular expression or CSS selector
+func parseCSRF(pageURL, re, selector string, every int) (*client.CSRFFetcher, error) {
+	if u, err := url.Parse(pageURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("Must be HTTP(S) URL of page with the token")
+	}
+	if every < 1 {
+		return nil, fmt.Errorf("-csrf-every must be positive")
+	}
+
+	fetcher := &client.CSRFFetcher{URL: pageURL, Every: every}
+
+	var err error
+	switch {
+	case re != "" && selector != "":
+		return nil, fmt.Errorf("Either -csrf-regex or -csrf-selector must be specified, not both")
+	case re != "":
+		if fetcher.Regexp, err = regexp.Compile(re); err != nil {
+			return nil, err
+		}
+		if fetcher.Regexp.NumSubexp() < 1 {
+			return nil, fmt.Errorf("Regular expression must have a group to capture the token")
+		}
+	case selector != "":
+		if fetcher.Selector, err = client.ParseSelector(selector); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("Requires -csrf-regex or -csrf-selector to extract the token")
+	}
+	return fetcher, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/exploit"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/probe"
+	"github.com/glebarez/padre/pkg/util"
+	"github.com/glebarez/padre/pkg/vulnserver"
+)
+
+const benchUsage = "usage: padre bench [-p N,N,...] [-b 8|16] [-len N] [-runs N] [-block-parallel N]"
+
+// result of attack with one concurrency setting
+type benchResult struct {
+	concurrency int
+	requests    int
+	bytes       int
+	elapsed     time.Duration
+}
+
+// runBench decrypts tokens of built-in vulnerable server (see serve-vuln) with every concurrency setting,
+// and reports requests per byte, requests per second and total time. returns exit code
+func runBench(print *out.Printer, args []string) int {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	parallel := flags.String("p", "1,8,30", "")
+	blockLen := flags.Int("b", 16, "")
+	length := flags.Int("len", 64, "")
+	runs := flags.Int("runs", 1, "")
+	blockParallel := flags.Int("block-parallel", 1, "")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		print.Errorf(benchUsage)
+		return 1
+	}
+
+	var concurrencies []int
+	for _, s := range strings.Split(*parallel, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || n < 1 || n > maxConcurrency {
+			print.Errorf("-p must be comma-separated list of numbers in range [1-%d]", maxConcurrency)
+			return 1
+		}
+		concurrencies = append(concurrencies, n)
+	}
+	if *length < 1 || *runs < 1 || *blockParallel < 1 {
+		print.Errorf("-len, -runs and -block-parallel must be positive")
+		return 1
+	}
+
+	server, err := vulnserver.New(vulnserver.Config{BlockLen: *blockLen})
+	if err != nil {
+		print.Error(err)
+		return 1
+	}
+
+	// the server runs in this process, on random local port
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		print.Error(err)
+		return 1
+	}
+	defer listener.Close()
+	go http.Serve(listener, server)
+	url := fmt.Sprintf("http://%s/?%s=$", listener.Addr(), vulnserver.DefaultParam)
+
+	print.Info("benchmarking against built-in server: %s bytes of plaintext, block length %s, %s runs per setting",
+		color.Green(*length), color.Green(*blockLen), color.Green(*runs))
+
+	fmt.Fprintf(stdout, "%12s %10s %10s %10s %12s\n", "concurrency", "requests", "req/byte", "req/sec", "time")
+	for _, concurrency := range concurrencies {
+		result, err := benchAttack(url, server, concurrency, *blockLen, *length, *runs, *blockParallel)
+		if err != nil {
+			print.Error(err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "%12d %10d %10.1f %10.0f %12s\n",
+			result.concurrency,
+			result.requests,
+			float64(result.requests)/float64(result.bytes),
+			float64(result.requests)/result.elapsed.Seconds(),
+			result.elapsed.Round(time.Millisecond),
+		)
+	}
+	return 0
+}
+
+// decrypts fresh random tokens of the server with given concurrency
+func benchAttack(url string, server *vulnserver.Server, concurrency, blockLen, length, runs, blockParallel int) (*benchResult, error) {
+	matcher, err := probe.NewMatcherByStatusCode([]int{http.StatusInternalServerError})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &client.Stats{}
+	padre := &exploit.Padre{
+		Client: &client.Client{
+			HTTPclient: &http.Client{Transport: client.NewTransport(&client.TransportOptions{
+				KeepAlive:       true,
+				MaxConnsPerHost: concurrency * blockParallel,
+				IdleTimeout:     defaultIdleTimeout,
+			})},
+			URL:               url,
+			CipherPlaceholder: "$",
+			Encoder:           server.Encoder(),
+			Concurrency:       concurrency,
+			Stats:             stats,
+		},
+		Matcher:       matcher,
+		BlockLen:      blockLen,
+		Padding:       exploit.PKCS7,
+		Retries:       defaultRetries,
+		BlockParallel: blockParallel,
+	}
+
+	result := &benchResult{concurrency: concurrency}
+	for i := 0; i < runs; i++ {
+		plaintext := util.RandomSlice(length)
+		ciphertext, err := server.Encoder().DecodeString(server.Token(plaintext))
+		if err != nil {
+			return nil, err
+		}
+
+		started := time.Now()
+		decrypted, err := padre.Decrypt(context.Background(), ciphertext, nil)
+		result.elapsed += time.Since(started)
+		if err != nil {
+			return nil, err
+		}
+
+		// broken cracker must not pass unnoticed
+		if unpadded, ok := exploit.PKCS7.Unpad(decrypted, blockLen); !ok || string(unpadded) != string(plaintext) {
+			return nil, fmt.Errorf("decrypted plaintext does not match the original (concurrency %d)", concurrency)
+		}
+		result.bytes += len(decrypted)
+	}
+	result.requests = stats.Snapshot().Requests
+	return result, nil
+}
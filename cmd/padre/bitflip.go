@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/glebarez/padre/pkg/exploit"
+	out "github.com/glebarez/padre/pkg/output"
+)
+
+const bitflipUsage = "usage: padre bitflip [-b N] [-e b64|lhex] [-r REPL] [-offset N] -known TEXT -want TEXT CIPHER"
+
+// runBitflip turns known plaintext of cipher into wanted one by flipping bits of preceding block.
+// no requests are sent, the modified cipher is printed. returns exit code
+func runBitflip(print *out.Printer, args []string) int {
+	flags := flag.NewFlagSet("bitflip", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	blockLen := flags.Int("b", 16, "")
+	encoding := flags.String("e", "b64", "")
+	replacements := flags.String("r", "", "")
+	offset := flags.Int("offset", 0, "")
+	known := flags.String("known", "", "")
+	want := flags.String("want", "", "")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 || *known == "" {
+		print.Errorf(bitflipUsage)
+		return 1
+	}
+
+	if *blockLen != 8 && *blockLen != 16 && *blockLen != 32 {
+		print.Errorf("-b must be one of: 8, 16, 32")
+		return 1
+	}
+
+	if len(*replacements)%2 == 1 {
+		print.Errorf("-r must be of even length (0,2,4, etc.)")
+		return 1
+	}
+
+	var enc encoder.Encoder
+	switch strings.ToLower(*encoding) {
+	case "b64":
+		enc = encoder.NewB64encoder(*replacements)
+	case "lhex":
+		enc = encoder.NewLHEXencoder(*replacements)
+	default:
+		print.Errorf("-e: unsupported encoding specified")
+		return 1
+	}
+
+	ciphertext, err := enc.DecodeString(flags.Arg(0))
+	if err != nil {
+		print.Errorf("could not decode cipher: %s", err)
+		return 1
+	}
+
+	flipped, garbled, err := exploit.BitFlip(ciphertext, *blockLen, *offset, []byte(*known), []byte(*want))
+	if err != nil {
+		print.Error(err)
+		return 1
+	}
+
+	// edits beyond the first block are paid for with plaintext of preceding blocks
+	for _, block := range garbled {
+		print.Warning("plaintext block %d (bytes %d-%d) is garbled", block, block**blockLen, (block+1)**blockLen-1)
+	}
+
+	fmt.Fprintln(stdout, enc.EncodeToString(flipped))
+	return 0
+}
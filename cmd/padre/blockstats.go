@@ -0,0 +1,87 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/exploit"
+	out "github.com/glebarez/padre/pkg/output"
+)
+
+// collects metrics of blocks of current input (see -block-stats).
+// blocks may be reported concurrently (see -block-parallel)
+type blockStatsCollector struct {
+	mx     sync.Mutex
+	mode   string // decrypt or encrypt
+	blocks []out.BlockStats
+}
+
+// starts stage of processing: blocks reported from now on are of given mode
+func (c *blockStatsCollector) start(mode string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.mode = mode
+}
+
+func (c *blockStatsCollector) add(s *exploit.BlockStats) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.blocks = append(c.blocks, out.BlockStats{
+		Mode:      c.mode,
+		Block:     s.Block,
+		Requests:  s.Requests,
+		Retries:   int(s.Retries),
+		Seconds:   s.Elapsed.Seconds(),
+		Ambiguous: s.Ambiguous,
+		Known:     s.Known,
+	})
+}
+
+// returns stats collected so far, and starts over.
+// stages go in order they were processed, blocks of every stage are ordered by number
+func (c *blockStatsCollector) take() []out.BlockStats {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	blocks := c.blocks
+	c.blocks = nil
+
+	for start := 0; start < len(blocks); {
+		end := start + 1
+		for end < len(blocks) && blocks[end].Mode == blocks[start].Mode {
+			end++
+		}
+		stage := blocks[start:end]
+		sort.Slice(stage, func(i, j int) bool { return stage[i].Block < stage[j].Block })
+		start = end
+	}
+	return blocks
+}
+
+// prints table of block metrics
+func printBlockStats(print *out.Printer, blocks []out.BlockStats) {
+	if len(blocks) == 0 {
+		return
+	}
+
+	print.Info("block statistics:")
+	print.Printlnf("  %-8s %5s %9s %8s %9s", "mode", "block", "requests", "retries", "time")
+
+	var requests int64
+	for _, b := range blocks {
+		note := ""
+		switch {
+		case b.Known:
+			note = "known"
+		case b.Ambiguous:
+			note = color.Yellow("ambiguous last byte")
+		}
+		elapsed := time.Duration(b.Seconds * float64(time.Second)).Round(time.Millisecond)
+		print.Printlnf("  %-8s %5d %9d %8d %9s %s", b.Mode, b.Block, b.Requests, b.Retries, elapsed, note)
+		requests += b.Requests
+	}
+	print.Printlnf("  total requests: %d, %.1f per block", requests, float64(requests)/float64(len(blocks)))
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/glebarez/padre"
+	out "github.com/glebarez/padre/pkg/output"
+)
+
+// feature is an optional subsystem, that can be excluded from build with build tag
+type feature struct {
+	name     string
+	tag      string // build tag that excludes the feature
+	included bool
+}
+
+// optional subsystems of this build
+var features = []feature{
+	{name: "tui", tag: "notui", included: out.TUIIncluded},
+}
+
+// formats list of features: included ones as-is, excluded ones along with build tag
+func formatFeatures() (included, excluded string) {
+	var in, ex []string
+	for _, f := range features {
+		if f.included {
+			in = append(in, f.name)
+		} else {
+			ex = append(ex, fmt.Sprintf("%s (-tags %s)", f.name, f.tag))
+		}
+	}
+	return strings.Join(in, ", "), strings.Join(ex, ", ")
+}
+
+// runBuildInfo prints details of the build: version, platform, features and dependencies.
+// returns exit code
+func runBuildInfo(print *out.Printer, args []string) int {
+	if len(args) > 0 {
+		print.Errorf("usage: padre build-info")
+		return 1
+	}
+
+	included, excluded := formatFeatures()
+	if included == "" {
+		included = "-"
+	}
+	if excluded == "" {
+		excluded = "-"
+	}
+
+	fmt.Fprintf(stdout, "version:  %s\n", padre.Version)
+	fmt.Fprintf(stdout, "go:       %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(stdout, "features: %s\n", included)
+	fmt.Fprintf(stdout, "excluded: %s\n", excluded)
+
+	// modules linked into the binary
+	if info, ok := debug.ReadBuildInfo(); ok && len(info.Deps) > 0 {
+		fmt.Fprintln(stdout, "modules:")
+		for _, dep := range info.Deps {
+			fmt.Fprintf(stdout, "\t%s %s\n", dep.Path, dep.Version)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	out "github.com/glebarez/padre/pkg/output"
+)
+
+// subcommand: tool of its own, with its own arguments
+type command struct {
+	name    string
+	summary string
+	usage   string // flags are taken from here for completions
+	run     func(print *out.Printer, args []string) int
+}
+
+// subcommands, in order of appearance in usage
+var commands = []*command{
+	{"status", "query progress of running instances", "usage: padre status [SOCKET]", runStatus},
+	{"explain", "describe matchers, encoders, transports and presets", "usage: padre explain [KIND] [NAME]", runExplain},
+	{"serve-vuln", "run deliberately vulnerable CBC server", serveVulnUsage, runServeVuln},
+	{"bench", "measure performance against built-in vulnerable server", benchUsage, runBench},
+	{"build-info", "show version, platform and features of this build", "usage: padre build-info", runBuildInfo},
+	{"worker", "serve probes of remote coordinator", workerUsage, runWorker},
+	{"bitflip", "flip bits of cipher to turn known plaintext into wanted one", bitflipUsage, runBitflip},
+	{"gcm", "recover GCM authentication key from messages under reused nonce", gcmUsage, runGCM},
+	{"viewstate", "show structure of ASP.NET ViewState", viewstateUsage, runViewstate},
+	{"analyze", "detect encoding, block length and IV of token", analyzeUsage, runAnalyze},
+	{"crawl", "collect encrypted tokens from target", crawlUsage, runCrawl},
+}
+
+// completion is generated from the list of subcommands, so it's added last
+func init() {
+	commands = append(commands, &command{"completion", "print shell completion script", completionUsage, runCompletion})
+}
+
+// subcommands that run the attack, they take the same options (see usage).
+// each is a shorthand for options it implies
+var attackCommands = []struct {
+	name    string
+	summary string
+	implies []string
+}{
+	{"decrypt", "decrypt INPUT (the default)", nil},
+	{"encrypt", "encrypt INPUT", []string{"-enc"}},
+	{"scan", "find the vulnerable parameter and attack it", []string{"-scan"}},
+	{"probe", "send one request with cipher as is, and classify the response", nil},
+}
+
+// finds subcommand by name, nil if there is none
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+/* shell completion */
+const completionUsage = "usage: padre completion bash|zsh|fish"
+
+// runCompletion prints completion script for the shell, e.g. source <(padre completion bash)
+func runCompletion(print *out.Printer, args []string) int {
+	if len(args) != 1 {
+		print.Errorf(completionUsage)
+		return 1
+	}
+
+	switch args[0] {
+	case "bash":
+		writeBashCompletion(stdout)
+	case "zsh":
+		writeZshCompletion(stdout)
+	case "fish":
+		writeFishCompletion(stdout)
+	default:
+		print.Errorf(completionUsage)
+		return 1
+	}
+	return 0
+}
+
+var (
+	usageHeadingRe = regexp.MustCompile(`(?m)^flag\(.*$`)
+	usageFlagRe    = regexp.MustCompile(`flag\((-[\w-]+)\)`)
+	commandFlagRe  = regexp.MustCompile(`[\s\[](-[a-z][\w-]*)`)
+)
+
+// options of the attack, as documented in usage (headings of option descriptions)
+func attackFlags() []string {
+	flags := []string{"-h"}
+	for _, heading := range usageHeadingRe.FindAllString(rawUsage, -1) {
+		for _, m := range usageFlagRe.FindAllStringSubmatch(heading, -1) {
+			flags = append(flags, m[1])
+		}
+	}
+	sort.Strings(flags)
+	return flags
+}
+
+// options of subcommand, as listed in its usage line
+func (c *command) flags() []string {
+	var flags []string
+	seen := map[string]bool{}
+	for _, m := range commandFlagRe.FindAllStringSubmatch(c.usage, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			flags = append(flags, m[1])
+		}
+	}
+	return flags
+}
+
+// names of all subcommands
+func commandNames() []string {
+	var names []string
+	for _, c := range attackCommands {
+		names = append(names, c.name)
+	}
+	for _, c := range commands {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+func writeBashCompletion(w io.Writer) {
+	fmt.Fprintf(w, "# bash completion for padre, load with: source <(padre completion bash)\n")
+	fmt.Fprintf(w, "_padre() {\n")
+	fmt.Fprintf(w, "\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\" words\n")
+	fmt.Fprintf(w, "\tif [ \"$COMP_CWORD\" -eq 1 ]; then\n")
+	fmt.Fprintf(w, "\t\twords=%q\n", strings.Join(append(commandNames(), attackFlags()...), " "))
+	fmt.Fprintf(w, "\telse\n")
+	fmt.Fprintf(w, "\t\tcase \"${COMP_WORDS[1]}\" in\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, "\t\t%s) words=%q ;;\n", c.name, strings.Join(c.flags(), " "))
+	}
+	fmt.Fprintf(w, "\t\t*) words=%q ;;\n", strings.Join(attackFlags(), " "))
+	fmt.Fprintf(w, "\t\tesac\n")
+	fmt.Fprintf(w, "\tfi\n")
+	fmt.Fprintf(w, "\tCOMPREPLY=($(compgen -W \"$words\" -- \"$cur\"))\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "complete -o default -F _padre padre\n")
+}
+
+func writeZshCompletion(w io.Writer) {
+	fmt.Fprintf(w, "#compdef padre\n")
+	fmt.Fprintf(w, "# zsh completion for padre, load with: source <(padre completion zsh)\n")
+	fmt.Fprintf(w, "_padre() {\n")
+	fmt.Fprintf(w, "\tlocal -a commands\n")
+	fmt.Fprintf(w, "\tcommands=(\n")
+	for _, c := range attackCommands {
+		fmt.Fprintf(w, "\t\t%s\n", zshQuote(c.name+":"+c.summary))
+	}
+	for _, c := range commands {
+		fmt.Fprintf(w, "\t\t%s\n", zshQuote(c.name+":"+c.summary))
+	}
+	fmt.Fprintf(w, "\t)\n")
+	fmt.Fprintf(w, "\tif (( CURRENT == 2 )); then\n")
+	fmt.Fprintf(w, "\t\t_describe 'command' commands\n")
+	fmt.Fprintf(w, "\t\tcompadd -- %s\n", strings.Join(attackFlags(), " "))
+	fmt.Fprintf(w, "\t\treturn\n")
+	fmt.Fprintf(w, "\tfi\n")
+	fmt.Fprintf(w, "\tcase $words[2] in\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, "\t%s) compadd -- %s ;;\n", c.name, strings.Join(c.flags(), " "))
+	}
+	fmt.Fprintf(w, "\t*) compadd -- %s ;;\n", strings.Join(attackFlags(), " "))
+	fmt.Fprintf(w, "\tesac\n")
+	fmt.Fprintf(w, "\t_files\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "compdef _padre padre\n")
+}
+
+func writeFishCompletion(w io.Writer) {
+	fmt.Fprintf(w, "# fish completion for padre, load with: padre completion fish | source\n")
+	names := strings.Join(commandNames(), " ")
+	for _, c := range attackCommands {
+		fmt.Fprintf(w, "complete -c padre -n 'not __fish_seen_subcommand_from %s' -a %s -d %s\n", names, c.name, fishQuote(c.summary))
+	}
+	for _, c := range commands {
+		fmt.Fprintf(w, "complete -c padre -n 'not __fish_seen_subcommand_from %s' -a %s -d %s\n", names, c.name, fishQuote(c.summary))
+	}
+
+	// options of the attack are offered, unless other subcommand is chosen
+	var others []string
+	for _, c := range commands {
+		others = append(others, c.name)
+		for _, f := range c.flags() {
+			fmt.Fprintf(w, "complete -c padre -n '__fish_seen_subcommand_from %s' -o %s\n", c.name, strings.TrimPrefix(f, "-"))
+		}
+	}
+	for _, f := range attackFlags() {
+		fmt.Fprintf(w, "complete -c padre -n 'not __fish_seen_subcommand_from %s' -o %s\n", strings.Join(others, " "), strings.TrimPrefix(f, "-"))
+	}
+}
+
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func fishQuote(s string) string {
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), "'", `\'`) + "'"
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// applyConfig sets flags from YAML (or JSON) file, see -c.
+// keys are flag names, flags passed on command line take precedence over the file.
+// repeatable flags (e.g. H, hint) take lists, H and cookie take maps of names to values as well
+func applyConfig(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]interface{})
+	if err = yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("could not parse %s: %s", path, err)
+	}
+	return applyValues(path, values)
+}
+
+// applyValues sets flags that are not set yet, source is used in errors
+func applyValues(source string, values map[string]interface{}) error {
+	// sorted, so that errors are reported in stable order
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := strings.TrimLeft(key, "-")
+		if flag.Lookup(name) == nil || name == "c" {
+			return fmt.Errorf("%s: unknown option %q", source, key)
+		}
+		if isFlagPassed(name) {
+			continue
+		}
+
+		list, err := configValues(name, values[key])
+		if err != nil {
+			return fmt.Errorf("%s: %s: %s", source, key, err)
+		}
+		for _, value := range list {
+			if err := flag.Set(name, value); err != nil {
+				return fmt.Errorf("%s: %s: %s", source, key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// flag values of config entry: scalar gives one value, list gives one per item
+func configValues(name string, value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		var list []string
+		for _, item := range v {
+			if !isScalar(item) {
+				return nil, fmt.Errorf("list items must be scalar values")
+			}
+			list = append(list, fmt.Sprint(item))
+		}
+		return list, nil
+	case map[string]interface{}:
+		return configMap(name, v)
+	default:
+		return []string{fmt.Sprint(v)}, nil
+	}
+}
+
+// headers are given as name: value, cookies as name=value pairs of a single flag
+func configMap(name string, m map[string]interface{}) ([]string, error) {
+	if name != "H" && name != "cookie" {
+		return nil, fmt.Errorf("maps are supported for H and cookie only")
+	}
+
+	names := make([]string, 0, len(m))
+	for key := range m {
+		if !isScalar(m[key]) {
+			return nil, fmt.Errorf("values must be scalar")
+		}
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	var list []string
+	for _, key := range names {
+		if name == "H" {
+			list = append(list, fmt.Sprintf("%s: %v", key, m[key]))
+		} else {
+			list = append(list, fmt.Sprintf("%s=%v", key, m[key]))
+		}
+	}
+	if name == "cookie" {
+		return []string{strings.Join(list, "; ")}, nil
+	}
+	return list, nil
+}
+
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case []interface{}, map[string]interface{}:
+		return false
+	}
+	return true
+}
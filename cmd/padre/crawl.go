@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/crawl"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/probe"
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// pages that don't respond in time are skipped
+const crawlTimeout = 30 * time.Second
+
+const crawlUsage = "usage: padre crawl [-depth N] [-pages N] [-match REGEX] [-cookie COOKIES] [-o FILE] URL"
+
+// runCrawl follows links and forms of target, and collects values that look like encrypted tokens.
+// values are written one per line, so that the file can be passed as STDIN for batch decryption. returns exit code
+func runCrawl(print *out.Printer, args []string) int {
+	flags := flag.NewFlagSet("crawl", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	depth := flags.Int("depth", crawl.DefaultMaxDepth, "")
+	pages := flags.Int("pages", crawl.DefaultMaxPages, "")
+	match := flags.String("match", "", "")
+	cookies := flags.String("cookie", "", "")
+	outFile := flags.String("o", "", "")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		print.Errorf(crawlUsage)
+		return 1
+	}
+
+	start, err := url.Parse(flags.Arg(0))
+	if err != nil || (start.Scheme != "http" && start.Scheme != "https") {
+		print.Errorf("URL must be absolute, with http or https scheme")
+		return 1
+	}
+
+	// values are filtered with regex if given, by entropy and block alignment otherwise
+	isToken := func(value string) bool { return probe.Analyze(value).Encrypted }
+	if *match != "" {
+		re, err := regexp.Compile(*match)
+		if err != nil {
+			print.Errorf("-match: %s", err)
+			return 1
+		}
+		isToken = re.MatchString
+	}
+
+	// cookies are kept between pages, as session may be required to reach tokens
+	jar, _ := cookiejar.New(nil)
+	if *cookies != "" {
+		parsed, err := util.ParseCookies(*cookies)
+		if err != nil {
+			print.Errorf("-cookie: %s", err)
+			return 1
+		}
+		jar.SetCookies(start, parsed)
+	}
+
+	var w io.Writer = stdout
+	if *outFile != "" {
+		f, err := os.Create(*outFile)
+		if err != nil {
+			print.Error(err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	// findings collected so far are reported on interrupt
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listenInterrupts(print, cancel)
+
+	crawler := &crawl.Crawler{
+		Client: &http.Client{
+			Transport: client.NewTransport(&client.TransportOptions{KeepAlive: true, IdleTimeout: defaultIdleTimeout}),
+			Jar:       jar,
+			Timeout:   crawlTimeout,
+		},
+		Match:    isToken,
+		MaxDepth: *depth,
+		MaxPages: *pages,
+		Visited: func(page string, depth int) {
+			print.Action(fmt.Sprintf("[depth %d] %s", depth, page))
+		},
+	}
+
+	print.Info("crawling %s, depth: %s, pages: %s", color.Green(start), color.Green(*depth), color.Green(*pages))
+	findings, err := crawler.Crawl(ctx, start.String())
+	for _, f := range findings {
+		print.Success("%s %s in %s: %s", f.Location, color.Yellow(f.Name), f.Page, color.Green(f.Value))
+		fmt.Fprintln(w, f.Value)
+	}
+	if err != nil {
+		print.Error(err)
+		return 130
+	}
+
+	if len(findings) == 0 {
+		print.Warning("no tokens found")
+		return 2
+	}
+	print.Info("%s tokens found, decrypt them all with: %s", color.Green(len(findings)), color.CyanBold("padre [OPTIONS] < FILE"))
+	return 0
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http/httputil"
+	"strings"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/color"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// number of sample requests, printed in dry run
+const dryRunSamples = 3
+
+// dryRun prints sample requests, as they would be sent during attack, to STDOUT without sending them.
+// ciphers are tampered like probes do: random block with varying last byte, followed by the last block of INPUT
+func dryRun(print *out.Printer, args *Args, c *client.Client) int {
+	print.Warning("dry run: %s sample requests are printed, nothing is sent", color.CyanBold(dryRunSamples))
+	if c.CSRF != nil {
+		print.Info("anti-CSRF token is fetched before every request, %s is left in place", color.Green(client.CSRFPlaceholder))
+	}
+
+	// the target block is taken from INPUT, if there's one to decrypt
+	var ciphertext []byte
+	if args.Input != nil && !*args.EncryptMode {
+		var err error
+		if ciphertext, err = args.Encoder.DecodeString(*args.Input); err != nil {
+			print.Warning("INPUT is not decodable, random cipher is used instead: %s", err)
+		}
+	}
+
+	for i := 0; i < dryRunSamples; i++ {
+		cipher := dryRunCipher(args, ciphertext, byte(i))
+
+		fmt.Fprintf(stdout, "### sample %d | cipher (hex): %s\n", i+1, hex.EncodeToString(cipher))
+		if err := printSampleRequest(c, cipher); err != nil {
+			print.Error(err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// makes tampered cipher for i-th sample
+func dryRunCipher(args *Args, ciphertext []byte, i byte) []byte {
+	// RSA ciphertexts are not split into blocks
+	if args.RSAKey != nil {
+		return util.RandomSlice(args.RSAKey.Size())
+	}
+
+	bl := *args.BlockLen
+	if bl == 0 {
+		bl = 16
+	}
+
+	target := util.RandomSlice(bl)
+	if len(ciphertext) >= bl && len(ciphertext)%bl == 0 {
+		target = ciphertext[len(ciphertext)-bl:]
+	}
+
+	prefix := util.RandomSlice(bl)
+	prefix[bl-1] = i
+	return append(prefix, target...)
+}
+
+// prints request (or command line) with cipher, as it would be sent
+func printSampleRequest(c *client.Client, cipher []byte) error {
+	if c.Command != nil {
+		encoded := c.Encoder.EncodeToString(cipher)
+		fmt.Fprintf(stdout, "%s=%s %s %s\n\n", client.CipherEnv, encoded, c.Command, encoded)
+		return nil
+	}
+
+	req, body, err := c.Render(cipher)
+	if err != nil {
+		return err
+	}
+
+	// the request is dumped as it goes on the wire, body included
+	dump, err := httputil.DumpRequestOut(req, true)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s", dump)
+	if len(body) > 0 && !strings.HasSuffix(string(body), "\n") {
+		fmt.Fprintln(stdout)
+	}
+	fmt.Fprintln(stdout)
+	return nil
+}
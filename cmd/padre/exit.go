@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/exploit"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// exit codes, so that wrapping scripts can branch on outcome
+const (
+	exitOK           = 0
+	exitError        = 1   // invalid arguments, failed setup, or error that is not classified
+	exitFailed       = 2   // all inputs failed, for different reasons
+	exitNotConfirmed = 3   // padding oracle was not confirmed, or did not behave as expected during attack
+	exitNetwork      = 4   // target, proxies or workers are unreachable, requests timed out
+	exitInvalidInput = 5   // cipher can not be processed: wrong encoding or length, produces padding error itself
+	exitPartial      = 6   // some inputs were processed, others failed
+	exitDeadline     = 124 // maximum runtime exceeded (as with timeout(1))
+	exitAborted      = 130 // aborted by user (as with SIGINT in shell)
+)
+
+// exitCodeOf maps reason of error into exit code, fallback is used for errors that are not classified
+func exitCodeOf(err error, fallback int) int {
+	switch exploit.ReasonOf(err) {
+	case exploit.StopNone:
+		return exitOK
+	case exploit.StopInput:
+		return exitInvalidInput
+	case exploit.StopOracle:
+		return exitNotConfirmed
+	case exploit.StopNetwork:
+		return exitNetwork
+	case exploit.StopAborted:
+		return exitAborted
+	case exploit.StopDeadline:
+		return exitDeadline
+	}
+	return fallback
+}
+
+// outcomes of processed inputs, see exitCode
+type outcomes struct {
+	done   int
+	failed []int // exit codes of failed inputs
+}
+
+func (o *outcomes) add(err error) {
+	if err == nil {
+		o.done++
+	} else {
+		o.failed = append(o.failed, exitCodeOf(err, exitFailed))
+	}
+}
+
+// exitCode tells the outcome of all inputs: success, partial success,
+// or reason of failure, if all inputs failed for the same one
+func (o *outcomes) exitCode() int {
+	switch {
+	case len(o.failed) == 0 && o.done > 0:
+		return exitOK
+	case len(o.failed) == 0:
+		// nothing was processed
+		return exitFailed
+	case o.done > 0:
+		return exitPartial
+	}
+
+	for _, code := range o.failed[1:] {
+		if code != o.failed[0] {
+			return exitFailed
+		}
+	}
+	return o.failed[0]
+}
+
+var (
+	cleanups   []func()
+	cleanupsMx sync.Mutex
+)
+
+// atExit registers function to be called upon exit
+func atExit(f func()) {
+	cleanupsMx.Lock()
+	defer cleanupsMx.Unlock()
+
+	cleanups = append(cleanups, f)
+}
+
+// exit runs registered cleanups (in reverse order) and terminates the process
+func exit(code int) {
+	cleanupsMx.Lock()
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+	cleanups = nil
+	cleanupsMx.Unlock()
+
+	os.Exit(code)
+}
+
+// listenInterrupts stops the work gracefully on interrupt (Ctrl+C) or termination:
+// abort is called, so that results recovered so far can be reported and saved.
+// second signal forces the exit
+func listenInterrupts(print *out.Printer, abort func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		print.Warning("interrupted, stopping... (press %s again to exit immediately)", color.CyanBold("Ctrl+C"))
+		abort()
+
+		<-signals
+		print.Errorf("aborted by user")
+		exit(exitAborted)
+	}()
+}
+
+// listenHotkeys toggles pause of the gate every time space is pressed.
+// on interrupt (Ctrl+C), user is asked whether to stop (the session is saved then, see listenInterrupts),
+// second interrupt during the question forces the exit.
+// terminal mode is restored upon exit, including exit by signal
+func listenHotkeys(print *out.Printer, gate *client.Gate, abort func()) error {
+	keys, restore, err := util.ListenKeys()
+	if err != nil {
+		return err
+	}
+	atExit(restore)
+
+	// while the question is asked, the keys are delivered as answers
+	var (
+		answersMx sync.Mutex
+		answers   chan byte
+	)
+
+	go func() {
+		for key := range keys {
+			answersMx.Lock()
+			if answers != nil {
+				answers <- key
+				answers = nil
+			} else if key == ' ' {
+				gate.Toggle()
+			}
+			answersMx.Unlock()
+		}
+	}()
+
+	// signals would terminate the process without restoring the terminal
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		aborted := false
+		for sig := range signals {
+			// already stopping, or no questions are asked on termination
+			if aborted {
+				print.Errorf("aborted by user")
+				exit(exitAborted)
+			}
+			if sig != os.Interrupt {
+				aborted = true
+				abort()
+				continue
+			}
+
+			// pause the work and take over the terminal
+			paused := gate.Paused()
+			gate.Pause()
+			print.Hold()
+			fmt.Fprintf(stderr, "\n%s save session and exit? [y/N] ", color.YellowBold("?"))
+
+			answer := make(chan byte, 1)
+			answersMx.Lock()
+			answers = answer
+			answersMx.Unlock()
+
+			select {
+			case <-signals:
+				// second interrupt
+				fmt.Fprintln(stderr)
+				print.Release()
+				print.Errorf("aborted by user")
+				exit(exitAborted)
+			case key := <-answer:
+				fmt.Fprintf(stderr, "%c\n", key)
+				print.Release()
+
+				if key == 'y' || key == 'Y' {
+					aborted = true
+					abort()
+					continue
+				}
+
+				if !paused {
+					gate.Resume()
+				}
+			}
+		}
+	}()
+
+	return nil
+}
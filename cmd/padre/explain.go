@@ -0,0 +1,384 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	out "github.com/glebarez/padre/pkg/output"
+)
+
+// kinds of components
+const (
+	kindMatcher   = "matcher"
+	kindEncoder   = "encoder"
+	kindTransport = "transport"
+	kindPreset    = "preset"
+)
+
+var componentKinds = []string{kindMatcher, kindEncoder, kindTransport, kindPreset}
+
+// component is a documented building block of padre, it can be explained with cmd(padre explain)
+// texts use the same markup as usage
+type component struct {
+	kind     string
+	name     string
+	summary  string
+	options  []string
+	examples []string
+
+	// encoders only: creates the encoder (nil for raw bytes)
+	newEncoder func() encoder.Encoder
+
+	// presets only: values of options, see -preset
+	settings map[string]interface{}
+}
+
+// registered components
+var components = []*component{
+	// matchers
+	{
+		kind:    kindMatcher,
+		name:    "fingerprint",
+		summary: "Automatic detection of padding error by fingerprints of responses (status code, lines and words count). Used when no other matcher is chosen",
+		options: []string{
+			"flag(-b)	block lengths are tried one by one if not set",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/login?token=$" "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+	{
+		kind:    kindMatcher,
+		name:    "regexp",
+		summary: "Response body is matched against regular expression, match means padding error",
+		options: []string{
+			"flag(-err)	the regular expression",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/login?token=$" -err "Invalid padding" "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+			`padre -u "http://vulnerable.com/login?token=$" -err "[Pp]adding.*error" "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+	{
+		kind:    kindMatcher,
+		name:    "status",
+		summary: "Response status code is checked against list of codes, that indicate padding error",
+		options: []string{
+			"flag(-err-status)	comma-separated status codes",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/login?token=$" -err-status 500,502 "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+	{
+		kind:    kindMatcher,
+		name:    "length",
+		summary: "Response body length (in bytes) is checked against exact value or inclusive range, that indicates padding error",
+		options: []string{
+			"flag(-err-length)	exact value or range",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/login?token=$" -err-length 1200-1300 "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+	{
+		kind:    kindMatcher,
+		name:    "location",
+		summary: "Location header of redirect is matched with regular expression (e.g. redirect to error page). Redirects are not followed then, unless told otherwise",
+		options: []string{
+			"flag(-err-location)	regular expression",
+			"flag(-follow-redirects)	number of redirects to follow",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/login?token=$" -err-location "/Error\.aspx" "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+	{
+		kind:    kindMatcher,
+		name:    "header",
+		summary: "Response header is matched with regular expression (e.g. X-Error: CryptographicException), or just checked for presence, if only name is given",
+		options: []string{
+			"flag(-err-header)	NAME: REGEXP or NAME",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/login?token=$" -err-header "X-Error: Cryptographic" "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+	{
+		kind:    kindMatcher,
+		name:    "expression",
+		summary: "Boolean expression combines conditions on status, length, time, body, location and headers, for targets where no single criterion is reliable",
+		options: []string{
+			"flag(-oracle)	expression, e.g. status==500 && body~\"padding\" || time>2s",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/login?token=$" -oracle 'status==500 && body~"padding" || time>2s' "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+	{
+		kind:    kindMatcher,
+		name:    "cluster",
+		summary: "Responses to tampered ciphers are clustered by status code, length and similarity of body, user picks the cluster of padding error",
+		options: []string{
+			"flag(-explore)	explore responses interactively",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/login?token=$" -explore "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+	{
+		kind:    kindMatcher,
+		name:    "success",
+		summary: "Inverts regexp, status, length, location, header or expression matcher: the match means successful response, anything else is padding error",
+		options: []string{
+			"flag(-match-success)	invert the matcher",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/profile?token=$" -err "Welcome back" -match-success "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+
+	// encoders
+	{
+		kind:    kindEncoder,
+		name:    "b64",
+		summary: "Standard base64. Default encoding of ciphers in HTTP requests and of encryption outputs",
+		options: []string{
+			"flag(-e)	cmd(b64) encoding of ciphers in HTTP requests",
+			"flag(-r)	character replacements, applied after encoding (e.g. URL-safe base64)",
+			"flag(-sink)	cmd(enc=b64) encoding of outputs",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/login?token=$" -r "-+_/" "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+		newEncoder: func() encoder.Encoder { return encoder.NewB64encoder("") },
+	},
+	{
+		kind:    kindEncoder,
+		name:    "lhex",
+		summary: "Lowercase hex",
+		options: []string{
+			"flag(-e)	cmd(lhex) encoding of ciphers in HTTP requests",
+			"flag(-r)	character replacements, applied after encoding",
+			"flag(-sink)	cmd(enc=lhex) encoding of outputs",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/login?token=$" -e lhex "bbb6ef2de9679fa3c9ebbd0c9e3de19c"`,
+		},
+		newEncoder: func() encoder.Encoder { return encoder.NewLHEXencoder("") },
+	},
+	{
+		kind:    kindEncoder,
+		name:    "ascii",
+		summary: "Printable characters as-is, others escaped with \\x notation. Used to display decrypted plaintext (outputs only)",
+		options: []string{
+			"flag(-sink)	cmd(enc=ascii) encoding of outputs",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/login?token=$" -sink file:out.txt,enc=ascii "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+		newEncoder: encoder.NewASCIIencoder,
+	},
+	{
+		kind:    kindEncoder,
+		name:    "hexdump",
+		summary: "Canonical hexdump: offset, hex and ASCII columns (outputs only)",
+		options: []string{
+			"flag(-hexdump)	show decrypted plaintext as hexdump",
+			"flag(-sink)	cmd(enc=hexdump) encoding of outputs",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/login?token=$" -hexdump "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+		newEncoder: encoder.NewHexdumpEncoder,
+	},
+	{
+		kind:    kindEncoder,
+		name:    "raw",
+		summary: "Bytes as-is, no encoding. Default encoding of decryption outputs (outputs only)",
+		options: []string{
+			"flag(-out)	write raw outputs into a file",
+			"flag(-force-raw)	write binary outputs as-is even into terminal (hexdump is shown otherwise)",
+			"flag(-sink)	cmd(enc=raw) encoding of outputs",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/login?token=$" -out plaintext.bin "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+
+	// transports
+	{
+		kind:    kindTransport,
+		name:    "direct",
+		summary: "HTTP(S) requests are sent directly to the target. TLS certificates are verified unless flag(-insecure) is set",
+		options: []string{
+			"flag(-p)	number of parallel connections",
+			"flag(-http2), flag(-keepalive), flag(-max-conns-per-host), flag(-idle-timeout)	connection behaviors",
+			"flag(-cert), flag(-key), flag(-sni), flag(-insecure), flag(-tls-min-version)	TLS options",
+			"flag(-cookie), flag(-post), flag(-ct), flag(-referer), flag(-cache-headers)	request contents",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/login" -post "token=$" -p 10 "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+	{
+		kind:    kindTransport,
+		name:    "proxy",
+		summary: "All requests are sent via single HTTP proxy",
+		options: []string{
+			"flag(-proxy)	proxy URL",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/login?token=$" -proxy "http://localhost:8080" "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+	{
+		kind:    kindTransport,
+		name:    "proxy-pool",
+		summary: "Requests are spread across proxies from the list, every parallel connection sticks to its own proxy. Dead proxies are replaced automatically",
+		options: []string{
+			"flag(-proxy-pool)	file with list of proxies, one per line",
+		},
+		examples: []string{
+			`padre -u "http://vulnerable.com/login?token=$" -proxy-pool proxies.txt "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+	{
+		kind:    kindTransport,
+		name:    "ssh",
+		summary: "Requests are routed through SSH tunnel (SOCKS5 dynamic forwarding) to the jump host. Requires ssh client with non-interactive authentication",
+		options: []string{
+			"flag(-ssh)	jump host as cmd([user@]host[:port])",
+		},
+		examples: []string{
+			`padre -u "http://10.0.0.5/login?token=$" -ssh user@jump.example.com "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+	{
+		kind:    kindTransport,
+		name:    "workers",
+		summary: "Probes are distributed across remote padre workers, every worker gets its own range of byte values and sends requests from its own IP. Padding errors are matched centrally",
+		options: []string{
+			"flag(-workers)	comma-separated list of workers as cmd(host:port)",
+			"flag(-token)	shared secret of coordinator and workers",
+		},
+		examples: []string{
+			`PADRE_TOKEN=secret padre worker -listen :7070`,
+			`padre -u "http://vulnerable.com/login?token=$" -workers 10.0.0.1:7070,10.0.0.2:7070 -token secret "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+	{
+		kind:    kindTransport,
+		name:    "command",
+		summary: "Ciphers are checked by external program instead of HTTP server: anything that can be wrapped in a script (SOAP services, smart cards, CLI binaries). Non-zero exit code means padding error",
+		options: []string{
+			"flag(-oracle-cmd)	the program and its arguments, encoded cipher is appended as the last argument and passed in cmd(PADRE_CIPHER) environment variable",
+			"flag(-err), flag(-err-status)	match standard output or exit codes instead",
+		},
+		examples: []string{
+			`padre -oracle-cmd ./check.sh "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+}
+
+// finds component by kind and name
+func findComponent(kind, name string) *component {
+	for _, c := range append(components, presets...) {
+		if c.kind == kind && c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// normalizes kind of component, plural is accepted. returns empty string if kind is unknown
+func normalizeKind(kind string) string {
+	kind = strings.TrimSuffix(strings.ToLower(kind), "s")
+	for _, k := range componentKinds {
+		if k == kind {
+			return k
+		}
+	}
+	return ""
+}
+
+// lists components of given kind
+func listComponents(kind string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "bold(%ss:)\n", strings.Title(kind))
+	for _, c := range append(components, presets...) {
+		if c.kind == kind {
+			fmt.Fprintf(&b, "\tflag(%s)\t%s\n", c.name, c.summary)
+		}
+	}
+	return b.String()
+}
+
+// describes component in details
+func describeComponent(c *component) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "bold(%s %s)\n\t%s\n", c.kind, c.name, c.summary)
+
+	options := c.options
+	if c.settings != nil {
+		options = presetOptions(c.settings)
+	}
+	if len(options) > 0 {
+		b.WriteString("\nbold(Options:)\n")
+		for _, o := range options {
+			fmt.Fprintf(&b, "\t%s\n", o)
+		}
+	}
+
+	if len(c.examples) > 0 {
+		b.WriteString("\nbold(Examples:)\n")
+		for _, e := range c.examples {
+			fmt.Fprintf(&b, "\tcmd(%s)\n", e)
+		}
+	}
+	return b.String()
+}
+
+// runExplain prints documentation of components.
+// returns exit code
+func runExplain(print *out.Printer, args []string) int {
+	if len(args) > 2 {
+		print.Errorf("usage: padre explain [%s] [NAME]", strings.Join(componentKinds, "|"))
+		return 1
+	}
+
+	// everything
+	if len(args) == 0 {
+		for i, kind := range componentKinds {
+			if i > 0 {
+				fmt.Fprintln(stdout)
+			}
+			fmt.Fprint(stdout, markup(listComponents(kind)))
+		}
+		return 0
+	}
+
+	kind := normalizeKind(args[0])
+	if kind == "" {
+		print.Errorf("unknown kind of component: %s (choose one of: %s)", args[0], strings.Join(componentKinds, ", "))
+		return 1
+	}
+
+	// all of the kind
+	if len(args) == 1 {
+		fmt.Fprint(stdout, markup(listComponents(kind)))
+		return 0
+	}
+
+	c := findComponent(kind, strings.ToLower(args[1]))
+	if c == nil {
+		print.Errorf("unknown %s: %s, run %s to list available", kind, args[1], "padre explain "+kind)
+		return 1
+	}
+
+	fmt.Fprint(stdout, markup(describeComponent(c)))
+	return 0
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/color"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/probe"
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// length of body sample shown for cluster
+const clusterSampleLength = 80
+
+// explore sends probes for every candidate block length, clusters the responses
+// and lets user pick the cluster that represents padding error
+func explore(ctx context.Context, print *out.Printer, c *client.Client, blockLengths []int) (probe.PaddingErrorMatcher, error) {
+	print.Action("exploring responses...")
+	responses := make([]*client.Response, 0)
+	for _, bl := range blockLengths {
+		batch, err := probe.ExploreResponses(ctx, c, bl)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, batch...)
+	}
+
+	clusters := probe.ClusterResponses(responses)
+	if len(clusters) < 2 {
+		return nil, fmt.Errorf("all %d responses are alike, tampering with cipher makes no difference", len(responses))
+	}
+
+	print.Info("%d responses fall into %d clusters:", len(responses), len(clusters))
+	for i, cluster := range clusters {
+		length := strconv.Itoa(cluster.MinLength)
+		if cluster.MaxLength != cluster.MinLength {
+			length += fmt.Sprintf("-%d", cluster.MaxLength)
+		}
+		print.Printlnf("  %s %d responses, status %d, length %s: %s", color.CyanBold(fmt.Sprintf("[%d]", i+1)),
+			cluster.Count, cluster.StatusCode, length, sampleBody(cluster.Sample.Body))
+	}
+
+	choice, err := promptCluster(len(clusters))
+	if err != nil {
+		return nil, err
+	}
+	chosen := clusters[choice]
+
+	if flag := suggestMatcherFlag(clusters, chosen); flag != "" {
+		print.Hint("to skip exploration next time, use %s", color.GreenBold(flag))
+	}
+	return probe.NewMatcherByCluster(chosen), nil
+}
+
+// asks user for number of cluster, returns its index
+func promptCluster(count int) (int, error) {
+	// STDIN may be taken by inputs, then terminal is opened directly
+	var tty io.ReadCloser
+	if util.IsTerminal(os.Stdin) {
+		tty = ioutil.NopCloser(os.Stdin)
+	} else {
+		f, err := os.Open("/dev/tty")
+		if err != nil {
+			return 0, fmt.Errorf("terminal is needed to pick the cluster: %w", err)
+		}
+		tty = f
+	}
+	defer tty.Close()
+
+	reader := bufio.NewReader(tty)
+	for {
+		fmt.Fprintf(stderr, "%s which cluster is padding error? [1-%d] ", color.YellowBold("?"), count)
+		line, err := reader.ReadString('\n')
+		if n, convErr := strconv.Atoi(strings.TrimSpace(line)); convErr == nil && n >= 1 && n <= count {
+			return n - 1, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("cluster is not chosen: %w", err)
+		}
+	}
+}
+
+// suggests option that tells chosen cluster apart from the rest: by status code, or by length
+func suggestMatcherFlag(clusters []*probe.ResponseCluster, chosen *probe.ResponseCluster) string {
+	uniqueStatus, uniqueLength := true, true
+	for _, c := range clusters {
+		if c == chosen {
+			continue
+		}
+		if c.StatusCode == chosen.StatusCode {
+			uniqueStatus = false
+		}
+		if c.MaxLength >= chosen.MinLength && c.MinLength <= chosen.MaxLength {
+			uniqueLength = false
+		}
+	}
+
+	switch {
+	case uniqueStatus:
+		return fmt.Sprintf("-err-status %d", chosen.StatusCode)
+	case uniqueLength && chosen.MinLength == chosen.MaxLength:
+		return fmt.Sprintf("-err-length %d", chosen.MinLength)
+	case uniqueLength:
+		return fmt.Sprintf("-err-length %d-%d", chosen.MinLength, chosen.MaxLength)
+	}
+	return ""
+}
+
+// one-line printable sample of body
+func sampleBody(body []byte) string {
+	sample := strings.Join(strings.Fields(string(body)), " ")
+	if len(sample) > clusterSampleLength {
+		sample = sample[:clusterSampleLength] + "..."
+	}
+	return strconv.Quote(sample)
+}
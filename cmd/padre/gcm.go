@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/glebarez/padre/pkg/gcm"
+	out "github.com/glebarez/padre/pkg/output"
+)
+
+const gcmUsage = "usage: padre gcm [-e b64|lhex] [-r REPL] [-aad TEXT]... [-known TEXT -want TEXT] [-new-aad TEXT] SEALED SEALED [SEALED]..."
+
+// runGCM recovers authentication key from GCM messages sealed under the same nonce (ciphertext followed by 16-byte tag),
+// and optionally forges a new message from known plaintext of the first one. no requests are sent. returns exit code
+func runGCM(print *out.Printer, args []string) int {
+	flags := flag.NewFlagSet("gcm", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	encoding := flags.String("e", "b64", "")
+	replacements := flags.String("r", "", "")
+	var aads multiFlag
+	flags.Var(&aads, "aad", "")
+	known := flags.String("known", "", "")
+	want := flags.String("want", "", "")
+	newAAD := flags.String("new-aad", "", "")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() < 2 {
+		print.Errorf(gcmUsage)
+		return 1
+	}
+
+	if (*known == "") != (*want == "") {
+		print.Errorf("-known and -want must be used together")
+		return 1
+	}
+
+	if len(aads) > flags.NArg() {
+		print.Errorf("-aad is passed %d times, but there are only %d messages", len(aads), flags.NArg())
+		return 1
+	}
+
+	if len(*replacements)%2 == 1 {
+		print.Errorf("-r must be of even length (0,2,4, etc.)")
+		return 1
+	}
+
+	var enc encoder.Encoder
+	switch strings.ToLower(*encoding) {
+	case "b64":
+		enc = encoder.NewB64encoder(*replacements)
+	case "lhex":
+		enc = encoder.NewLHEXencoder(*replacements)
+	default:
+		print.Errorf("-e: unsupported encoding specified")
+		return 1
+	}
+
+	// AAD is given in order of messages, the rest have none
+	messages := make([]gcm.Message, flags.NArg())
+	for i, arg := range flags.Args() {
+		sealed, err := enc.DecodeString(arg)
+		if err != nil {
+			print.Errorf("could not decode message %d: %s", i+1, err)
+			return 1
+		}
+		if len(sealed) < gcm.TagSize {
+			print.Errorf("message %d is shorter than tag", i+1)
+			return 1
+		}
+
+		split := len(sealed) - gcm.TagSize
+		messages[i] = gcm.Message{Ciphertext: sealed[:split], Tag: sealed[split:]}
+		if i < len(aads) {
+			messages[i].AAD = []byte(aads[i])
+		}
+	}
+
+	keys, err := gcm.RecoverAuthKeys(messages...)
+	if err != nil {
+		print.Error(err)
+		return 1
+	}
+
+	if len(keys) > 1 {
+		print.Warning("%d candidates of authentication key fit, pass one more message to pick the right one", len(keys))
+	}
+	for _, key := range keys {
+		print.Success("H: %s, E(K,J0): %s", color.Green(hex.EncodeToString(key.H)), color.Green(hex.EncodeToString(key.Mask)))
+	}
+
+	if *known == "" {
+		return 0
+	}
+
+	// new ciphertext reuses keystream of the first message, AAD defaults to the one of the first message
+	ciphertext, err := gcm.Encrypt(messages[0], []byte(*known), []byte(*want))
+	if err != nil {
+		print.Error(err)
+		return 1
+	}
+	aad := messages[0].AAD
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "new-aad" {
+			aad = []byte(*newAAD)
+		}
+	})
+
+	// every candidate gives its own forgery
+	for _, key := range keys {
+		sealed := append(append([]byte{}, ciphertext...), key.Tag(aad, ciphertext)...)
+		fmt.Fprintln(stdout, enc.EncodeToString(sealed))
+	}
+	return 0
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/exploit"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// checks whether plaintext (padding excluded) is printable
+func isPrintablePlaintext(plaintext []byte, padding exploit.Padding, blockLen int) bool {
+	if unpadded, ok := padding.Unpad(plaintext, blockLen); ok {
+		plaintext = unpadded
+	}
+	return util.IsPrintable(plaintext)
+}
+
+// prints data as canonical hexdump (offset, hex, ASCII)
+func printHexdump(print *out.Printer, data []byte) {
+	print.AddPrefix(color.CyanBold("[hexdump]"), true)
+	defer print.RemovePrefix()
+
+	for _, line := range strings.Split(strings.TrimRight(hex.Dump(data), "\n"), "\n") {
+		print.Println(line)
+	}
+}
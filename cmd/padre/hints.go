@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/exploit"
+	"github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/probe"
+)
+
+// flag wrapper
+func _f(f string) string {
+	return `(` + color.GreenBold(`-`+f) + ` option)`
+}
+
+// options that describe padding error, joined for hints
+func _errFlags(last string) string {
+	names := []string{`err`, `err-status`, `err-length`, `err-location`, `err-header`, `oracle`}
+	flags := make([]string, len(names))
+	for i, name := range names {
+		flags[i] = _f(name)
+	}
+	return strings.Join(flags[:len(flags)-1], `, `) + last + flags[len(flags)-1]
+}
+
+// hint texts
+var (
+	omitBlockLen     = `omit ` + _f(`b`) + `  for automatic detection of block length`
+	omitErrPattern   = `omit ` + _errFlags(`, `) + ` for automatic fingerprinting of HTTP responses`
+	setErrPattern    = `specify error pattern manually with ` + _errFlags(` or `)
+	lowerConnections = `server might be overwhelmed or rate-limiting you requests. try lowering concurrency using ` + _f(`p`)
+	checkEncoding    = `check that encoding ` + _f(`e`) + ` and replacement rules ` + _f(`r`) + ` are set properly`
+	checkInput       = `check that INPUT is properly formatted`
+	tryFinalBlock    = `attack only the final block with ` + _f(`final-block`) + `, some implementations skip integrity check for it`
+	addDelay         = `slow down with ` + _f(`delay`) + ` and ` + _f(`jitter`) + `, WAF or rate-limiter might interfere`
+	raiseRetries     = `raise number of retries for ambiguous bytes with ` + _f(`retries`)
+	recalibrate      = `re-detect the padding oracle: omit ` + _errFlags(`, `) + `, server responses might have changed`
+	checkNetwork     = `check connectivity to the target (and proxies), the server might be down or blocking you`
+	trySticky        = `stick to one backend of load balancer with ` + _f(`sticky`) + `, some backends might not be vulnerable`
+)
+
+// make hints for obvious reasons
+func makeDetectionHints(args *Args) []string {
+
+	hints := make([]string, 0)
+
+	// block length
+	if *args.BlockLen != 0 {
+		hints = append(hints, omitBlockLen)
+	} else {
+		// error pattern
+		if args.errorDescribed() {
+			hints = append(hints, omitErrPattern)
+		} else {
+			hints = append(hints, setErrPattern)
+		}
+	}
+
+	// concurrency
+	if *args.Parallel > 10 {
+		hints = append(hints, lowerConnections)
+	}
+
+	return hints
+}
+
+// short reason of premature stop, to be stated in status bar
+func stopReason(err error) string {
+	if err == nil {
+		return ""
+	}
+	return exploit.ReasonOf(err).String()
+}
+
+// make hints on next steps after premature stop
+func makeStopHints(reason exploit.StopReason, args *Args) []string {
+	hints := make([]string, 0)
+
+	switch reason {
+	case exploit.StopOracle:
+		if *args.Parallel > 1 {
+			hints = append(hints, lowerConnections)
+		}
+		if *args.Delay == 0 {
+			hints = append(hints, addDelay)
+		}
+		hints = append(hints, raiseRetries)
+		if args.errorDescribed() {
+			hints = append(hints, recalibrate)
+		}
+	case exploit.StopNetwork:
+		hints = append(hints, checkNetwork)
+		if *args.Parallel > 10 {
+			hints = append(hints, lowerConnections)
+		}
+	case exploit.StopInput:
+		hints = append(hints, checkInput)
+	}
+
+	return hints
+}
+
+// tells whether padding oracle is probably hidden behind integrity check (e.g. MAC).
+// responses must be uniform with every block length tried, as wrong length misses the padding
+func diagnoseIntegrityCheck(ctx context.Context, p *output.Printer, c *client.Client, blockLengths []int) bool {
+	uniform, err := probe.DetectUniformResponses(ctx, c, blockLengths)
+	if err != nil || !uniform {
+		return false
+	}
+
+	p.Warning("every tampered cipher produced identical response: integrity of cipher (e.g. MAC) is likely checked before padding, or cipher does not reach decryption at all")
+	return true
+}
+
+// tells whether target is probably served by multiple backends, that behave differently.
+// unless block length is known, every candidate length is tried, as cipher of wrong length may be rejected early
+func diagnoseBackends(ctx context.Context, p *output.Printer, c *client.Client, blockLengths []int) bool {
+	var backends *probe.Backends
+	for _, blockLen := range blockLengths {
+		detected, err := probe.DetectBackends(ctx, c, blockLen)
+		if err != nil {
+			return false
+		}
+		if detected.Multiple() {
+			backends = detected
+			break
+		}
+	}
+	if backends == nil {
+		return false
+	}
+
+	if backends.Inconsistent {
+		p.Warning("identical requests produced different responses: target is likely load-balanced, and backends behave differently")
+	} else {
+		p.Warning("target is likely load-balanced, responses come from different backends (%s: %s)", backends.Header, strings.Join(backends.Values, ", "))
+	}
+	return true
+}
+
+func printHints(p *output.Printer, hints []string) {
+	// hints intro
+	p.AddPrefix(color.CyanBold("[hints]"), true)
+	defer p.RemovePrefix()
+
+	p.Println(`if you believe target is vulnerable, try following:`)
+
+	// list hints
+	p.AddPrefix(color.CyanBold(`> `), false)
+	defer p.RemovePrefix()
+
+	for _, h := range hints {
+		p.Println(h)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/exploit"
+	out "github.com/glebarez/padre/pkg/output"
+)
+
+// loads intermediate values from file into the store
+func loadIntermediates(path string, store *exploit.Intermediates) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, store)
+}
+
+// saves intermediate values into file. the file is replaced atomically,
+// so that it's not left truncated if the process is killed while writing
+func saveIntermediates(path string, store *exploit.Intermediates) error {
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// intermediate values are shared by all inputs, and kept in files between runs if asked to.
+// forged token reuses the blocks of decrypted one
+func setupIntermediates(print *out.Printer, args *Args, padre *exploit.Padre) {
+	load, save := *args.LoadIntermediate, *args.SaveIntermediate
+	if *args.IntermediaryFile != "" {
+		load, save = *args.IntermediaryFile, *args.IntermediaryFile
+	}
+	if args.Forge == nil && load == "" && save == "" {
+		return
+	}
+	padre.Intermediates = exploit.NewIntermediates()
+
+	if load != "" {
+		err := loadIntermediates(load, padre.Intermediates)
+		// -intermediary-file is created upon first run
+		if os.IsNotExist(err) && *args.IntermediaryFile != "" {
+			err = nil
+		}
+		if err != nil {
+			print.Errorf("could not load intermediate values from %s: %s", load, err)
+			exit(1)
+		}
+		print.Info("intermediate values of %s blocks are known from %s", color.Green(padre.Intermediates.Len()), color.Green(load))
+	}
+
+	if save == "" {
+		return
+	}
+
+	// the file is saved after every newly broken block, so that the values survive crash or kill
+	var mx sync.Mutex
+	var warned bool
+	saveFile := func() {
+		mx.Lock()
+		defer mx.Unlock()
+		if err := saveIntermediates(save, padre.Intermediates); err != nil && !warned {
+			print.Warning("could not save intermediate values: %s", err)
+			warned = true
+		}
+	}
+	padre.Intermediates.Added = saveFile
+	atExit(func() {
+		saveFile()
+		print.Info("intermediate values of %s blocks are saved into %s", color.Green(padre.Intermediates.Len()), color.Green(save))
+	})
+}
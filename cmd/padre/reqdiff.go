@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/color"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// number of requests, that are compared with request template in debug mode (-vv)
+const requestDiffCount = 3
+
+// requestDiff prints how the first requests differ from request template (placeholders left in place),
+// so that mistakes of encoding or placeholder are spotted right away. It implements client.Recorder
+type requestDiff struct {
+	print    *out.Printer
+	template []string
+	count    int32
+}
+
+func newRequestDiff(print *out.Printer, c *client.Client) (*requestDiff, error) {
+	req, body, err := c.RenderTemplate()
+	if err != nil {
+		return nil, err
+	}
+	return &requestDiff{print: print, template: requestLines(req, body)}, nil
+}
+
+func (d *requestDiff) Record(e *client.Exchange) {
+	n := atomic.AddInt32(&d.count, 1)
+	if n > requestDiffCount {
+		return
+	}
+
+	// printed at once, so that diffs of concurrent requests do not mix
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s request %d of %d compared with template", color.Bold("[vv]"), n, requestDiffCount)
+	for _, line := range util.DiffLines(d.template, requestLines(e.Request, e.RequestBody)) {
+		switch line.Op {
+		case util.DiffRemoved:
+			b.WriteString("\n" + color.Red("- "+line.Text))
+		case util.DiffAdded:
+			b.WriteString("\n" + color.Green("+ "+line.Text))
+		default:
+			b.WriteString("\n  " + line.Text)
+		}
+	}
+	d.print.Println(b.String())
+}
+
+// lines of request: request line, host, headers (sorted) and body
+func requestLines(req *http.Request, body []byte) []string {
+	lines := []string{req.Method + " " + req.URL.RequestURI(), "Host: " + req.URL.Host}
+
+	var headers []string
+	for name, values := range req.Header {
+		for _, value := range values {
+			headers = append(headers, name+": "+value)
+		}
+	}
+	sort.Strings(headers)
+	lines = append(lines, headers...)
+
+	if len(body) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, strings.Split(string(body), "\n")...)
+	}
+	return lines
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// headers of raw request, that are set by HTTP client itself or by dedicated options
+var rawRequestSkippedHeaders = map[string]bool{
+	"Host":              true,
+	"Cookie":            true,
+	"Content-Length":    true,
+	"Content-Type":      true,
+	"Referer":           true,
+	"Connection":        true,
+	"Accept-Encoding":   true,
+	"Transfer-Encoding": true,
+}
+
+// applyRawRequest sets options from raw HTTP request (e.g. saved from intercepting proxy), see -request.
+// target is http://HOST, unless -u is passed: its scheme and host are used then.
+// body is everything after headers, Content-Length is not trusted, since requests are often edited by hand
+func applyRawRequest(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	head, body := data, []byte{}
+	for _, separator := range []string{"\r\n\r\n", "\n\n"} {
+		if i := bytes.Index(data, []byte(separator)); i >= 0 {
+			head, body = data[:i], data[i+len(separator):]
+			break
+		}
+	}
+
+	req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(append(head, "\r\n\r\n"...))))
+	if err != nil {
+		return fmt.Errorf("could not parse HTTP request: %s", err)
+	}
+
+	target := &url.URL{Scheme: "http", Host: req.Host}
+	if isFlagPassed("u") {
+		if target, err = url.Parse(flag.Lookup("u").Value.String()); err != nil {
+			return err
+		}
+	}
+	if err = flag.Set("u", target.Scheme+"://"+target.Host+req.RequestURI); err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{}
+	if len(body) > 0 {
+		values["post"] = string(body)
+	}
+
+	// method is implied by body, unless it's not GET or POST
+	if (req.Method != http.MethodGet || len(body) > 0) && (req.Method != http.MethodPost || len(body) == 0) {
+		values["X"] = req.Method
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		values["ct"] = ct
+	}
+	if referer := req.Header.Get("Referer"); referer != "" {
+		values["referer"] = referer
+	}
+	if cookie := req.Header.Get("Cookie"); cookie != "" {
+		values["cookie"] = cookie
+	}
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		if !rawRequestSkippedHeaders[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var headers []interface{}
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			headers = append(headers, name+": "+value)
+		}
+	}
+	if headers != nil {
+		values["H"] = headers
+	}
+
+	return applyValues("request "+path, values)
+}
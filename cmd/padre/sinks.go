@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// sinkSpec - parsed specification of output sink
+// format: <type>[:<target>][,enc=<encoding>][,redact]
+// only known options are split off the end, so target may contain commas (e.g. query string of webhook URL)
+type sinkSpec struct {
+	kind     string
+	target   string
+	encoding string // empty means default for the mode
+	redact   bool
+}
+
+func parseSinkSpec(s string) (*sinkSpec, error) {
+	spec := &sinkSpec{}
+
+	// options are split off the end, the rest is type and target
+	for {
+		i := strings.LastIndex(s, ",")
+		if i < 0 {
+			break
+		}
+
+		opt := s[i+1:]
+		if opt == "redact" {
+			spec.redact = true
+		} else if strings.HasPrefix(opt, "enc=") {
+			spec.encoding = strings.ToLower(strings.TrimPrefix(opt, "enc="))
+			if _, err := sinkEncoder(spec.encoding, nil); err != nil {
+				return nil, err
+			}
+		} else {
+			break // part of target
+		}
+		s = s[:i]
+	}
+
+	kindTarget := strings.SplitN(s, ":", 2)
+	spec.kind = strings.ToLower(kindTarget[0])
+	if len(kindTarget) == 2 {
+		spec.target = kindTarget[1]
+	}
+
+	// check type and target
+	switch spec.kind {
+	case "terminal":
+		if spec.target != "" {
+			return nil, fmt.Errorf("terminal sink does not accept target")
+		}
+	case "file", "json", "webhook", "socket":
+		if spec.target == "" {
+			return nil, fmt.Errorf("%s sink requires target, e.g. %s:<target>", spec.kind, spec.kind)
+		}
+	default:
+		if i := strings.Index(spec.kind, ","); i >= 0 {
+			return nil, fmt.Errorf("unsupported sink option: %q", spec.kind[i+1:])
+		}
+		return nil, fmt.Errorf("unsupported sink type: %q", spec.kind)
+	}
+
+	return spec, nil
+}
+
+// resolves encoder for sink by name, empty name resolves to default encoder
+func sinkEncoder(name string, defaultEncoder encoder.Encoder) (encoder.Encoder, error) {
+	if name == "" {
+		return defaultEncoder, nil
+	}
+
+	c := findComponent(kindEncoder, name)
+	if c == nil {
+		return nil, fmt.Errorf("unsupported sink encoding: %q", name)
+	}
+
+	// raw bytes
+	if c.newEncoder == nil {
+		return nil, nil
+	}
+	return c.newEncoder(), nil
+}
+
+// makeRouter creates output router from sink specifications.
+// if no sinks are specified, outputs are written to STDOUT, but only when it is redirected or piped.
+// this is because outputs are already shown in status bar.
+// binary outputs are not written raw into terminal, unless forceRaw is set
+func makeRouter(specs []*sinkSpec, defaultEncoder encoder.Encoder, forceRaw bool) (*out.Router, error) {
+	router := &out.Router{}
+
+	implicit := len(specs) == 0
+	if implicit {
+		specs = []*sinkSpec{{kind: "terminal"}}
+	}
+
+	for _, spec := range specs {
+		enc, err := sinkEncoder(spec.encoding, defaultEncoder)
+		if err != nil {
+			return nil, err
+		}
+		opts := out.SinkOptions{Encoder: enc, Redact: spec.redact, ForceRaw: forceRaw}
+
+		var sink out.Sink
+		switch spec.kind {
+		case "terminal":
+			if implicit && util.IsTerminal(stdout) {
+				continue
+			}
+			sink = out.NewStreamSink(stdout, opts)
+		case "file":
+			sink, err = out.NewFileSink(spec.target, opts)
+		case "json":
+			sink, err = out.NewJSONFileSink(spec.target, opts)
+		case "webhook":
+			sink = out.NewWebhookSink(spec.target, opts)
+		case "socket":
+			sink, err = out.NewSocketSink(spec.target, opts)
+		}
+
+		if err != nil {
+			router.Close()
+			return nil, fmt.Errorf("failed to create %s sink: %w", spec.kind, err)
+		}
+		router.Add(sink)
+	}
+
+	return router, nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/exploit"
+	"github.com/glebarez/padre/pkg/monitor"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/session"
+)
+
+// statusReporter tracks currently processed input, so that it can be reported on status query
+// or saved into session
+type statusReporter struct {
+	mx        sync.Mutex
+	mode      string
+	blockLen  int
+	inputList []string
+	input     int
+	inputs    int
+	bar       *out.HackyBar
+	failure   *session.Failure
+}
+
+// setInputs sets the list of inputs, that is saved into session
+func (r *statusReporter) setInputs(inputs []string) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	r.inputList = inputs
+}
+
+// track sets currently processed input along with its status bar
+func (r *statusReporter) track(input, inputs int, bar *out.HackyBar) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	r.input, r.inputs, r.bar = input, inputs, bar
+}
+
+// fail records the point where current input was stopped by error, it's saved into session
+func (r *statusReporter) fail(err error) {
+	f := &session.Failure{Reason: exploit.ReasonOf(err).String(), Error: err.Error()}
+	var blockErr *exploit.BlockError
+	if errors.As(err, &blockErr) {
+		f.Block = blockErr.Block
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	f.Input = r.input - 1
+	r.failure = f
+}
+
+func (r *statusReporter) snapshot() *monitor.Snapshot {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	s := &monitor.Snapshot{
+		PID:      os.Getpid(),
+		Mode:     r.mode,
+		BlockLen: r.blockLen,
+		Input:    r.input,
+		Inputs:   r.inputs,
+	}
+
+	if r.bar != nil {
+		p := r.bar.Progress()
+		s.Done, s.Total, s.Output, s.Requests, s.RPS = p.Done, p.Total, p.Output, p.Requests, p.RPS
+		s.ETA = int(p.ETA / time.Second)
+	}
+	return s
+}
+
+// session returns the session which allows to resume from the current point
+func (r *statusReporter) session() *session.Session {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	s := &session.Session{
+		Mode:     r.mode,
+		BlockLen: r.blockLen,
+		Inputs:   r.inputList,
+		Failure:  r.failure,
+	}
+
+	// nothing is processed yet
+	if r.input == 0 {
+		return s
+	}
+
+	s.Current = r.input - 1
+	if r.bar != nil {
+		s.Output = r.bar.Progress().Data
+	}
+	return s
+}
+
+// runStatus queries running padre instances and prints their progress.
+// returns exit code
+func runStatus(print *out.Printer, sockets []string) int {
+	// discover sockets if not specified explicitly
+	if len(sockets) == 0 {
+		var err error
+		sockets, err = monitor.FindSockets()
+		if err != nil {
+			print.Error(err)
+			return 1
+		}
+	}
+
+	if len(sockets) == 0 {
+		print.Errorf("no running instances of padre found")
+		return 1
+	}
+
+	var errCount int
+	for _, socket := range sockets {
+		s, err := monitor.Query(socket)
+		if err != nil {
+			print.Errorf("%s: %s", socket, err)
+			errCount++
+			continue
+		}
+
+		var eta string
+		if s.ETA > 0 {
+			eta = fmt.Sprintf(" | ETA: %s", time.Duration(s.ETA)*time.Second)
+		}
+
+		print.Info("pid %s: %s [%d/%d] %d/%d bytes | reqs: %d (%d/sec)%s",
+			color.Green(s.PID), color.CyanBold(s.Mode), s.Input, s.Inputs, s.Done, s.Total, s.Requests, s.RPS, eta)
+
+		if s.Output != "" {
+			print.AddPrefix(color.CyanBold(`> `), false)
+			print.Println(color.HiGreenBold(s.Output))
+			print.RemovePrefix()
+		}
+	}
+
+	if errCount == len(sockets) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/encoder"
+	out "github.com/glebarez/padre/pkg/output"
+)
+
+// inputs of more blocks than this are shown in progress bar by blocks, not bytes
+const largeInputBlocks = 64
+
+// plaintextStream writes blocks of plaintext into file as soon as they are decrypted, each at its place.
+// plaintexts of inputs follow one after another (like with -out), blocks that failed to decrypt are left zeroed
+type plaintextStream struct {
+	file   *os.File
+	base   int64 // where plaintext of current input starts
+	length int   // length of plaintext of current input
+	err    error // the first write error, nothing is written after it
+}
+
+func openPlaintextStream(path string) (*plaintextStream, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &plaintextStream{file: file}, nil
+}
+
+// writes block at offset within plaintext of current input
+func (s *plaintextStream) write(offset int, block []byte) {
+	if s.err == nil {
+		_, s.err = s.file.WriteAt(block, s.base+int64(offset))
+	}
+}
+
+// starts plaintext of the next input, of given length. the file is extended to fit it right away
+func (s *plaintextStream) start(length int) {
+	s.base += int64(s.length)
+	s.length = length
+	if s.err == nil {
+		s.err = s.file.Truncate(s.base + int64(length))
+	}
+}
+
+func (s *plaintextStream) Close() error {
+	if err := s.file.Close(); err != nil && s.err == nil {
+		s.err = err
+	}
+	return s.err
+}
+
+// headPreview prints the head of plaintext as it grows, when blocks are decrypted from the first one (see -block-order)
+type headPreview struct {
+	print  *out.Printer
+	blocks map[int][]byte // decrypted blocks by offset, not adjacent to the head yet
+	head   int            // length of the head
+}
+
+func newHeadPreview(print *out.Printer) *headPreview {
+	return &headPreview{print: print, blocks: make(map[int][]byte)}
+}
+
+// adds decrypted block, newly known part of the head is printed
+func (h *headPreview) add(offset int, block []byte) {
+	h.blocks[offset] = append([]byte{}, block...)
+
+	var grown []byte
+	for b, ok := h.blocks[h.head]; ok; b, ok = h.blocks[h.head] {
+		delete(h.blocks, h.head)
+		grown = append(grown, b...)
+		h.head += len(b)
+	}
+	if grown != nil {
+		h.print.Printlnf("%s %s", color.CyanBold(fmt.Sprintf("[head %d-%d]", h.head-len(grown), h.head-1)),
+			color.HiGreenBold(encoder.NewASCIIencoder().EncodeToString(grown)))
+	}
+}
+
+// returns callback for decrypted blocks, that delivers them into stream and preview (either may be nil)
+func deliverBlocks(stream *plaintextStream, head *headPreview) func(offset int, block []byte) {
+	if stream == nil && head == nil {
+		return nil
+	}
+	return func(offset int, block []byte) {
+		if stream != nil {
+			stream.write(offset, block)
+		}
+		if head != nil {
+			head.add(offset, block)
+		}
+	}
+}
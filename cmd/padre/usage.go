@@ -0,0 +1,575 @@
+package main
+
+import (
+	"regexp"
+
+	"github.com/glebarez/padre/pkg/color"
+)
+
+var usage = `
+Usage: cmd(padre [decrypt|encrypt] [OPTIONS] [INPUT])	decrypt INPUT (the default), or encrypt it (same as flag(-enc))
+       cmd(padre scan [OPTIONS] [INPUT])	find the vulnerable parameter and attack it (same as flag(-scan))
+       cmd(padre probe [OPTIONS] CIPHER)	send one request with cipher as is, print the response and whether it's classified as padding error (see flag(-err))
+       cmd(padre status [SOCKET])	query progress of running instances
+       cmd(padre explain [KIND] [NAME])	describe matchers, encoders, transports and presets
+       cmd(padre serve-vuln [-listen ADDR] [-b 8|16] [-e ENC] [-style status|body|redirect] [-key HEX])	run deliberately vulnerable CBC server (local target for self-validation and training), prints token and command to break it
+       cmd(padre bench [-p N,N,...] [-b 8|16] [-len N] [-runs N] [-block-parallel N])	decrypt random tokens of built-in vulnerable server with every concurrency setting, report requests per byte, requests per second and time
+       cmd(padre build-info)	show version, platform and features of this build
+       cmd(padre worker [-listen ADDR] [-token TOKEN] [-p N])	serve probes of remote coordinator (see flag(-workers))
+       cmd(padre bitflip [-b N] [-e ENC] [-offset N] -known TEXT -want TEXT CIPHER)	turn known plaintext at offset into wanted one by flipping bits of cipher (no requests are sent)
+       cmd(padre gcm [-e ENC] [-aad TEXT]... [-known TEXT -want TEXT] SEALED SEALED...)	recover GCM authentication key from messages sealed under reused nonce, forge new message (no requests are sent)
+       cmd(padre viewstate VIEWSTATE)	detect encoding, MAC and encryption of ASP.NET ViewState, show its structure (no requests are sent)
+       cmd(padre analyze TOKEN)	detect encoding layers, entropy, block length and IV of token, suggest options (no requests are sent)
+       cmd(padre crawl [-depth N] [-pages N] [-match REGEX] [-cookie COOKIES] [-o FILE] URL)	follow links and forms of target, collect values that look like encrypted tokens, one per line (pass them as bold(STDIN) to decrypt)
+       cmd(padre completion bash|zsh|fish)	print shell completion script, e.g. cmd(source <(padre completion bash)) or cmd(padre completion fish | source)
+
+INPUT: 
+	In bold(decrypt) mode: encrypted data
+	In bold(encrypt) mode: the plaintext to be encrypted
+	If not passed, will read from bold(STDIN)
+
+	NOTE: binary data is always encoded in HTTP. Tweak encoding rules if needed (see options: flag(-e), flag(-r))
+
+OPTIONS:
+
+flag(-u) *required*
+	target URL, use dollar($) character to define token placeholder (if present in URL)
+
+flag(-oracle-cmd)
+	External program to use as padding oracle instead of HTTP server (replaces flag(-u)). Encoded cipher is passed as
+	the last argument and in cmd(PADRE_CIPHER) environment variable. Non-zero exit code means padding error, unless
+	flag(-err) (matched against standard output) or flag(-err-status) (exit codes) is set
+	Example: cmd(-oracle-cmd "./check.sh --host 10.0.0.5")
+
+flag(-c) FILE
+	Load options from YAML (or JSON) file, so that complex setups are reproducible and shareable. Keys are names of options without dash,
+	options passed on command line take precedence over the file. Repeatable options take lists, flag(-H) and flag(-cookie) take maps as well:
+	  u: https://target.site/profile?token=$
+	  err: Invalid padding
+	  H: {Authorization: Bearer xyz, X-Forwarded-For: 127.0.0.1}
+	  cookie: {session: abc, lang: en}
+
+flag(-preset) NAME
+	Options of well-known vulnerable stack: cmd(aspnet-viewstate), cmd(jsf-viewstate), cmd(telerik), cmd(rails-cookie). Sets encoding, block length,
+	position of placeholder (unless it's already set) and padding error matcher. Any option can be overridden, see cmd(padre explain presets)
+
+flag(-request) FILE
+	Load URL, headers, cookies and body from raw HTTP request (e.g. saved from intercepting proxy). Target is cmd(http://) + Host header,
+	pass flag(-u) with scheme and host only to override, e.g. cmd(-u https://10.0.0.5). Other options take precedence over the request
+
+flag(-scan)
+	Find the vulnerable parameter automatically: query, POST and cookie parameters that look like encrypted blobs (decodable as base64,
+	base64url or hex, aligned to block, high entropy) are tested for padding oracle one by one. The first vulnerable one is attacked,
+	its value is the INPUT unless passed. The dollar($) placeholder must not be set
+	Example: cmd(padre -scan -request login.txt)
+
+flag(-enc)
+	Encrypt mode
+
+flag(-enc-file)
+	Encrypt mode, plaintext is read from file as-is: arbitrary bytes (serialized objects, null bytes), no splitting into lines. Use cmd(-) to read STDIN
+
+flag(-forge)
+	Forge mode: INPUT is decrypted, the field is set in plaintext, and the result is encrypted back, e.g. cmd(-forge role=admin).
+	Format is cmd(FIELD=VALUE), repeat the flag to edit several fields. JSON objects and key=value pairs (separated by cmd(&), cmd(;), cmd(|) or cmd(,)) are supported.
+	Every occurrence of the field is changed, missing field is added. In JSON, value is inserted as-is if it's a number, boolean or null, otherwise it's quoted as string
+
+flag(-intermediary-file)
+	File to keep intermediate values of broken cipher blocks in: loaded at start (if exists) and saved as blocks are broken. Shorthand for flag(-load-intermediate) and flag(-save-intermediate) with the same file
+
+flag(-load-intermediate)
+	Load intermediate values, learned in previous runs, from file. Encryption reuses them: it starts from the last block of the decrypted token,
+	and blocks that match the original plaintext cost no requests, so forging a modified token costs only the changed blocks.
+	Decryption does not break known blocks again. The values are only valid for the key of the target they were learned from
+
+flag(-save-intermediate)
+	Save intermediate values of broken cipher blocks (and loaded ones) into JSON file. The file is rewritten after every broken block, so it survives a crash or kill
+
+flag(-rsa)
+	RSA mode: INPUT is RSA ciphertext, decrypted with Bleichenbacher's or Manger's attack (see flag(-mode)). The value is a file with PEM-encoded public key or certificate.
+	Padding error (not conforming plaintext) must be described with one of flag(-err), flag(-err-status), flag(-err-length). Expect thousands to millions of requests, depending on strictness of the oracle
+
+flag(-mode)
+	Attack on RSA (with flag(-rsa)):
+		bleichenbacher - PKCS#1 v1.5 padding, oracle tells whether decrypted message starts with 00 02 *default*
+		manger - OAEP padding, oracle tells whether decrypted message starts with zero byte (e.g. "integer too large" vs other decoding errors).
+		  Takes about as many requests as there are bits in the key. Decrypted message is decoded with SHA-1 or SHA-256 and empty label
+
+flag(-err)
+	Regex pattern, HTTP response bodies will be matched against this to detect padding oracle. Omit to perform automatic fingerprinting
+	Full regular expression syntax (RE2) is supported, e.g. cmd((?i)) for case-insensitive match, cmd((?s)) to let dot match newlines. Dynamic parts of message
+	(request IDs, timestamps) are matched with classes like cmd(\d+). The first capture group (or the whole match) is shown by cmd(padre probe) and logged with flag(-vv)
+
+flag(-err-status)
+	HTTP status code(s) that indicate padding error, comma-separated (e.g. 500,502). Alternative to flag(-err)
+
+flag(-err-length)
+	Response body length (in bytes) that indicates padding error. Exact value (e.g. 1234) or inclusive range (e.g. 1200-1300). Alternative to flag(-err)
+
+flag(-err-location)
+	Regular expression, matched against Location header of redirect, that indicates padding error (e.g. redirect to error page). Alternative to flag(-err).
+	Redirects are not followed then (see flag(-follow-redirects)), so that the redirect response itself is checked
+
+flag(-err-header)
+	Response header that indicates padding error, as cmd(NAME: REGEXP) (e.g. cmd(X-Error: CryptographicException)), or just cmd(NAME) to check its presence.
+	Name is case-insensitive. Alternative to flag(-err)
+
+flag(-oracle)
+	Boolean expression that indicates padding error, for targets where no single criterion is reliable, e.g. cmd(status==500 && body~"padding" || time>2s)
+	Fields: status, length (of body), time (of response, e.g. 2s, 300ms), body, location (of redirects), header.NAME (e.g. header.X-Error).
+	Numbers and time are compared with == != < <= > >=, text is matched with regular expression by ~ and !~, or compared exactly with == and != (quoted strings).
+	Conditions are combined with && (binds tighter), || and !, grouped with parentheses. Alternative to flag(-err)
+
+flag(-explore)
+	For unknown error signature: send a batch of tampered ciphers, cluster responses by status code, length and similarity of body,
+	and pick the cluster of padding error interactively (terminal is needed). Option that tells the cluster apart is suggested for next runs
+
+flag(-follow-redirects)
+	Maximum number of redirects to follow, e.g. cmd(0): the redirect response itself is the response of oracle, its status and body are matched then.
+	Location headers of followed redirects are checked by flag(-err-location) as well
+		10 *default*
+
+flag(-match-success)
+	Invert matching: responses matched by flag(-err), flag(-err-status), flag(-err-length), flag(-err-location), flag(-err-header) or flag(-oracle) are considered successful (NOT padding errors). Use when application only signals success distinctively
+
+flag(-e)
+	Encoding to apply to binary data. Supported values:
+		b64 (standard base64) *default*
+		lhex (lowercase hex)
+
+flag(-r)
+	Additional replacements to apply after encoding binary data. Use odd-length strings, consiting of pairs of characters <OLD><NEW>.
+	Example:
+		If server uses base64, but replaces '/' with '!', '+' with '-', '=' with '~', then use cmd(-r "/!+-=~")
+
+flag(-jwe)
+	INPUT is JWE compact token with AES-CBC content encryption, e.g. A128CBC-HS256 (replaces flag(-e), flag(-r)). IV and ciphertext are attacked,
+	header, encrypted key and tag of the token are put back into every request and into forged tokens (see flag(-forge)).
+	The tag is kept as-is, so the target is an oracle only if it reports padding errors apart from tag mismatches
+
+flag(-placeholder-encoding)
+	Escaping of encoded cipher, when it replaces the placeholder in request. One of:
+		url - query escaping, e.g. + becomes %2B *default*
+		none - as-is, for targets that expect raw values (chosen automatically with flag(-multipart))
+		double-url - query escaping applied twice
+		base64url - URL-safe base64 of encoded cipher (without padding)
+		html - HTML entities
+		xml - XML entities, e.g. for SOAP envelopes
+		json - JSON string escaping, e.g. for JSON bodies and flag(-graphql)
+	When this option is omitted, and cipher is in POST body only, escaping follows Content-Type of the body (see flag(-ct)):
+	json for JSON, xml for XML, url for forms
+	Besides plain $, template expressions can be placed anywhere in request, e.g. to send the cipher twice with different encodings:
+		cmd(${cipher}) - the same as $
+		cmd(${cipher:b64url}), cmd(${cipher:hex:none}) - cipher in another encoding (b64, b64url, hex) and/or escaping (as above)
+		cmd(${len}) - length of encoded cipher, e.g. cmd(-H "X-Token-Length: ${len}")
+		cmd(${iv}) - IV, when target takes it from its own field, e.g. cmd(-u "http://site.com/?iv=${iv}&data=$"). The first block of INPUT goes there, the rest replaces $.
+		  The IV is tampered just like the cipher, so the first block of plaintext is recovered too. Transforms are the same as of cipher. Requires flag(-b) or flag(-iv)
+
+flag(-cookie)
+	Cookie value to be set in HTTP requests. Use dollar($) character to mark token placeholder.
+
+flag(-H)
+	Header to be set in HTTP requests, as cmd("Name: value"). Placeholders are replaced in value. Can be specified multiple times
+
+flag(-cookie-jar)
+	Keep cookies set by server, and send them back with further requests (like browser does)
+
+flag(-refresh-session)
+	URL of login page, fetched when session expires mid-attack (server responds with redirect to another page, or 401).
+	Fresh session cookies are collected into cookie jar, and the request is sent once again. Implies cmd(-cookie-jar).
+	Do not pass session cookie with cmd(-cookie), as it would not be refreshed
+
+flag(-refresh-extract)
+	Session value to capture from login page as cmd(NAME=REGEX), e.g. cmd(-refresh-extract) 'sid=name="sid" value="(\w+)"'.
+	The first group of REGEX is sent in cookie NAME
+
+flag(-csrf-url)
+	Page with anti-CSRF token, fetched before oracle requests. The token replaces {csrf} placeholder in cmd(-u), cmd(-post), cmd(-cookie) or cmd(-referer).
+	Implies cmd(-cookie-jar), as tokens are usually bound to session
+
+flag(-csrf-regex)
+	Regular expression to extract the token from the page, the first group is the token, e.g. cmd(-csrf-regex) 'name="csrf" value="([^"]+)"'
+
+flag(-csrf-selector)
+	CSS selector of element with the token (its value or content attribute), e.g. cmd(input[name=csrf]) or cmd(meta[name="csrf-token"]).
+	Supports tag, #id, .class and [attr=value]
+
+flag(-csrf-every)
+	Number of requests, that single token is used for
+		1 *default*
+
+flag(-post)
+	String data to perform POST requests. Use dollar($) character to mark token placeholder. 
+
+flag(-X)
+	HTTP method, e.g. cmd(PUT), cmd(PATCH), cmd(DELETE) or any custom one. Sent as is, with body if flag(-post) is set.
+	By default it's GET, or POST when there's body
+
+flag(-ct)
+	Content-Type for POST requests. If not specified, Content-Type will be determined automatically.
+	XML bodies are sent as text/xml, or as application/soap+xml when they are SOAP 1.2 envelopes
+	
+flag(-soap-action)
+	SOAPAction header of SOAP 1.1 request, e.g. cmd(-soap-action urn:ProcessToken). Placeholders are replaced in it, as in flag(-H).
+	For SOAP 1.2 (Content-Type application/soap+xml) the action is added to Content-Type as well
+
+flag(-graphql)
+	GraphQL query, sent as JSON envelope in POST body (replaces flag(-post)). The cipher is the value of variable, e.g.
+		cmd(-graphql 'query ($t: String!) { profile(token: $t) { name } }')
+	Dollar signs of query are escaped in JSON, so they are not taken for placeholder
+
+flag(-graphql-var)
+	Variable of flag(-graphql) query to put the cipher in *default* the first declared variable
+
+flag(-multipart)
+	Part of multipart/form-data POST body (replaces flag(-post)), as cmd(NAME=VALUE). Repeat the flag to add more parts.
+	Append cmd(;filename=FILE) to send the part as file upload, and cmd(;type=TYPE) to set its Content-Type, e.g. cmd(-multipart 'import=$;filename=backup.dat').
+	Boundary is generated and put into Content-Type, cipher is placed in parts as-is
+
+flag(-referer)
+	Referer header to send with every request, e.g. when oracle is reachable only via navigation from certain page.
+	Use dollar($) character to mark token placeholder. Use cmd(auto) to derive from the target URL (query is stripped)
+
+flag(-cache-headers)
+	Emulate browser cache: send ETag and Last-Modified, received from the target, back in If-None-Match and If-Modified-Since headers
+
+flag(-b)
+	Block length used in cipher (use 16 for AES). Omit to perform automatic detection. Supported values:
+		8
+		16 *default*
+		32
+
+flag(-padding)
+	Padding scheme, used by the target. In encrypt mode, forged plaintext is padded accordingly. One of:
+		pkcs7 *default*
+		ansix923 - zero bytes followed by padding length, zero bytes must be verified by the target
+		iso10126 - random bytes followed by padding length (only last byte is verified, so cipher can not be broken)
+		iso7816 - ISO/IEC 7816-4, 0x80 byte followed by zero bytes
+		auto - detect by behavior of the oracle (costs few hundred extra requests)
+
+flag(-order)
+	Order in which candidate values of every byte are probed. Probing stops as soon as valid byte is found. One of:
+		freq (values that reveal likely plaintext go first: letters, digits, separators like = & ;) *default*
+		seq - 0x00 to 0xff
+		random - shuffled for every byte
+	In encrypt mode, output bytes can not be guessed, so cmd(freq) is the same as cmd(seq)
+
+flag(-block-order)
+	Which block of ciphertext is decrypted first. One of:
+		last - from the end of plaintext *default*
+		first - from the head of plaintext, which is printed as soon as known (e.g. to see username field early)
+	With cmd(first), the progress bar fills in only when decryption is over. Ignored in encrypt mode.
+	Not to be confused with cmd(-order), which is the order of byte values probed within a block
+
+flag(-byte-range)
+	Positions within every block of plaintext to recover, as cmd(FROM-TO) or a single position, e.g. cmd(-byte-range 12-15) for the role byte of known token layout.
+	Bytes are recovered from the end of block, so those after the range are recovered as well, while those in front of it are not probed and come out as zeroes.
+	Saves requests, when only a part of plaintext matters. Ignored in encrypt mode
+
+flag(-hint)
+	Known fragment of plaintext as cmd([OFFSET:]TEXT), e.g. cmd(-hint "user=") or cmd(-hint "16:role="). OFFSET is position in plaintext (0 if omitted).
+	Hinted bytes are probed before anything else, and verified by the oracle like any other byte. Can be specified multiple times
+
+flag(-format)
+	Expected format of plaintext, one of:
+		json - JSON document
+		urlencoded - query string or form (key=value pairs separated with &)
+		jwt - JSON Web Token (base64url parts separated with dots)
+	Characters typical for the format are probed first (with cmd(-order freq)). Decrypted bytes that do not fit the format
+	are verified by the oracle once more, and the block is decrypted again if verification fails
+
+flag(-final-block)
+	Decrypt only the final block of INPUT, sending all preceding blocks intact with every request.
+	Useful against implementations that skip integrity check (MAC) for the final block.
+	Oracle can not be detected automatically in this mode, so cmd(-b) and one of cmd(-err), cmd(-err-status), cmd(-err-length) are required (cmd(-padding auto) is not supported)
+
+flag(-no-iv)
+	INPUT does not start with IV (target uses fixed or secret IV). All blocks but the first are recovered,
+	the first one is shown XORed with the unknown IV. In encrypt mode, IV is not included into output, and the first block of plaintext gets garbled
+
+flag(-iv)
+	IV known out-of-band (hex-encoded), when INPUT does not start with IV. The first block is recovered as well. Sets block length, if flag(-b) is omitted
+
+flag(-iv-key) PREFIX
+	Target uses the key as IV (AES-128 or DES), INPUT does not start with IV. After decryption, the key is recovered from the first block,
+	given PREFIX of its plaintext: the rest of the block (up to 3 bytes) is brute-forced offline. The first block is recovered with the key as well
+	Example: cmd(-iv-key '{"user":"bob",')
+
+flag(-sticky)
+	Stick to one backend of load-balanced target. Before calibration, identical requests are sent to detect multiple backends,
+	then sticky cookie set by load balancer (e.g. AWSALB, SERVERID, BIGipServer*) is sent with every request.
+	If there is no such cookie, response header that names the backend (e.g. X-Served-By) is sent back as request header.
+	Useful when some backends are not vulnerable, or behave differently
+
+flag(-low-resource)
+	Run comfortably on tiny machines and in restricted containers: lower default concurrency (8),
+	smaller network buffers, bounded memory for large responses and static progress bar (no animation).
+	Can not be used with cmd(-tui)
+
+flag(-p)
+	Number of parallel HTTP connections established to target server [1-256]
+		30 *default*
+		
+flag(-http2)
+	Attempt HTTP/2 over TLS, with fallback to HTTP/1.1. Requests are multiplexed over fewer connections
+
+flag(-block-parallel)
+	Number of blocks decrypted at once *default* 1. Every block gets its own flag(-p) connections, so up to N×P requests are in flight.
+	Identical probes of different blocks (e.g. repeated cipher blocks) are sent once and share the response. Decryption only:
+	in encrypt mode every block depends on the next one
+
+flag(-keepalive)
+	Reuse connections across requests, use cmd(-keepalive=false) to open new connection for every request
+		true *default*
+
+flag(-max-conns-per-host)
+	Maximum number of connections to the target (or proxy), requests beyond the limit wait for free connection
+		value of cmd(-p) *default*
+
+flag(-idle-timeout)
+	How long idle connection is kept open for reuse, e.g. cmd(30s), cmd(2m). Zero means no limit
+		90s *default*
+
+flag(-timeout)
+	How long response to a single request is awaited, e.g. cmd(10s). By default it's set dynamically: latency is measured since calibration,
+	and timeout follows 99th percentile of recent latencies times 3 (at least 1s, 30s until measured). Timed out request stops the input like other network errors
+
+flag(-cert), flag(-key)
+	Client certificate and its private key (PEM files) for endpoints protected with mutual TLS.
+	If cmd(-key) is not set, the key is read from cmd(-cert) file
+
+flag(-sni)
+	Server name to send in TLS handshake (SNI), instead of the host from URL
+
+flag(-insecure)
+	Skip verification of server certificate, e.g. for targets with self-signed certificates. By default the certificate is verified
+
+flag(-tls-min-version)
+	Minimum TLS version: cmd(1.0), cmd(1.1), cmd(1.2), cmd(1.3)
+
+flag(-delay), flag(-jitter)
+	Pause before every request (per connection), randomly shifted by up to ±jitter, e.g. cmd(-delay 100ms -jitter 50ms).
+	Mimics human traffic and helps to stay below anomaly detection. Effective RPS is shown in status bar
+
+flag(-cache)
+	Cache up to N responses in memory, keyed by the exact probed cipher, e.g. cmd(-cache 100000). Repeated probes are answered from cache:
+	probes of the same cipher block are the same every time, so blocks shared by several inputs are broken without requests.
+	Votes of flag(-confirm) and verification of found bytes (see flag(-retries)) are always sent. Disabled by default
+
+flag(-workers)
+	Distribute probes across remote workers (comma-separated list of cmd(host:port)), e.g. nodes with different egress IPs.
+	Workers are started with cmd(padre worker), every worker gets its own range of byte values, padding errors are matched centrally.
+	If worker fails, its probes are sent locally
+
+flag(-token)
+	Shared secret of coordinator and workers, defaults to cmd(PADRE_TOKEN) environment variable.
+	Worker started without token generates and prints one
+
+flag(-retries)
+	Number of times to retry the byte, when oracle gives ambiguous results (e.g. unstable server or WAF interference),
+	before giving up on the input. When set, every found byte is also verified with extra request, so use it against unreliable oracles only
+	If oracle was unstable (retries or network errors) while a block was broken, all bytes of the block are verified once more
+		0 *default*
+
+flag(-confirm)
+	Number of requests, that every verdict about padding error is made of (majority vote). Must be odd.
+	Helps against flaky oracles, e.g. load balancer in front of vulnerable and not vulnerable backends. Multiplies the number of requests
+		1 *default*
+
+flag(-max-runtime)
+	Stop after given time, e.g. cmd(-max-runtime 30m). Bytes recovered so far are printed and the session is saved,
+	so the work can be resumed later. Exit code is 124 when time is over
+
+flag(-proxy)
+	HTTP proxy. e.g. use cmd(-proxy "http://localhost:8080") for Burp or ZAP
+
+flag(-proxy-pool)
+	File with list of proxies (one per line, e.g. cmd(http://10.0.0.1:8080) or cmd(socks5://10.0.0.2:1080)).
+	Every parallel connection sticks to its own proxy, dead proxies are replaced automatically and revived by periodic health checks.
+	Proxy is considered dead when connection through it fails (dial, CONNECT or SOCKS handshake), errors of target do not count
+
+flag(-ssh)
+	Route all requests through SSH tunnel to jump host, e.g. cmd(-ssh user@jumphost) or cmd(-ssh user@jumphost:2222).
+	System's ssh client is used, keys from local agent and ssh config apply. Useful for targets in internal networks
+
+flag(-v), flag(-vv)
+	Verbose output as key=value lines, printed along with status bar. flag(-v): calibration decisions, every found byte
+	with its intermediate value, retries. flag(-vv): also every response and its classification (padding error or not),
+	and colored diff of the first 3 requests against request template, to spot mistakes of encoding or placeholder
+
+flag(-dry-run)
+	Print a few sample requests (or oracle command lines) with tampered cipher to STDOUT, as they would be sent, and exit. Nothing is sent,
+	so placeholder placement, encoding and headers can be verified before the attack. Anti-CSRF token is left as cmd({csrf})
+
+flag(-trace-edu)
+	Annotated trace: explain every step of the attack in human terms (which byte is guessed, what padding is targeted,
+	the XOR math). A teaching aid, best used with short demo ciphers (1-2 blocks)
+
+flag(-log-http)
+	Record every HTTP request/response pair into a file, for evidence, debugging or WAF analysis.
+	Format is chosen by extension: .har is HAR 1.2 (open in browser dev tools), anything else is raw HTTP.
+	Requests made by remote workers (see flag(-workers)) are not recorded
+
+flag(-log-http-format)
+	Format of HTTP log: cmd(har) or cmd(raw), overrides detection by extension
+
+flag(-log-http-valid-only)
+	Record only responses that are NOT padding errors (recording starts once padding error is recognizable)
+
+flag(-quiet)
+	No status bar animation: progress is printed as a separate line every 10 seconds (see flag(-progress-interval)), readable in files, CI
+	and container logs. Every line starts with the time it was printed at. Enabled automatically when STDERR is not a terminal
+
+flag(-pipe)
+	Pipeline mode: inputs are read from STDIN (one per line), outputs are written into STDOUT (one per line, decrypted plaintext without padding),
+	and nothing else is printed but errors. Exit code tells how it went. Example: cmd(cut -d= -f2 tokens.txt | padre -pipe -u ... | jq .role)
+
+flag(-progress-interval)
+	Interval of progress lines in quiet mode, e.g. 30s, 1m *default* 10s
+
+flag(-tui)
+	Full-screen terminal UI: per-block progress map, live RPS and latency graphs, recent errors.
+	Keys: space (pause/resume), +/- (adjust concurrency), q (quit)
+
+flag(-hexdump)
+	Show decrypted plaintext as hexdump (offset, hex, ASCII). Enabled automatically when plaintext contains non-printable bytes
+
+flag(-sink)
+	Output sink, can be specified multiple times. Format: <TYPE>[:<TARGET>][,enc=<ENCODING>][,redact]
+	Supported types:
+		terminal (STDOUT; by default, only when redirected or piped) *default*
+		file:<FILE> (outputs written verbatim, one after another)
+		json:<FILE> (JSON lines)
+		webhook:<URL> (JSON POST per result)
+		socket:<tcp://HOST:PORT|unix:///PATH> (JSON lines)
+	Encodings: b64, lhex, ascii, hexdump, raw (default: raw for decryption, same as flag(-e) for encryption)
+	In JSON records, raw output that is not valid UTF-8 is base64-encoded and marked with "encoding": "b64"
+	Option redact hides inputs and outputs, only their lengths are reported. Commas in TARGET are kept, only known options are split off the end
+	Example: cmd(-sink terminal -sink json:results.json,enc=b64 -sink webhook:https://hooks.local/padre,redact)
+
+flag(-out)
+	Write outputs into a file verbatim (raw bytes, no encoding), shorthand for cmd(-sink file:<FILE>,enc=raw).
+	Useful when plaintext is binary (serialized objects, gzip, etc.). With multiple inputs, outputs are written one after another
+
+flag(-stream) FILE
+	Write every block of plaintext into FILE as soon as it's decrypted, at its place (raw bytes, with padding), so that
+	long ciphertexts can be watched and used before the end. Blocks not decrypted yet (or failed) are zeroed.
+	With multiple inputs, plaintexts are written one after another. Progress bar shows blocks instead of bytes
+	(it does so for ciphertexts over 64 blocks anyway). Inputs completed before cmd(-resume) are not written
+
+flag(-force-raw)
+	Write binary outputs as-is even when destination is a terminal. By default, binary outputs (raw encoding)
+	are shown as hexdump in terminal to prevent its corruption, while pipes and files always get raw bytes
+
+flag(-notify-url)
+	Webhook to POST progress of long attacks to: start, every 25% of each input, result of each input and the finish.
+	Slack (hooks.slack.com) and Discord (discord.com/api/webhooks) webhooks are recognized and get chat messages,
+	other URLs get JSON documents: {"event", "message", "mode", "input", "inputs", "percent", "output", "error"}.
+	NOTE: outputs are sent as well, use flag(-sink) with redact option instead if they must not leave the machine
+	Example: cmd(-notify-url https://hooks.slack.com/services/T000/B000/XXXX)
+
+flag(-notify-format)
+	Payload of notifications, one of: json, slack, discord (default: detected by flag(-notify-url))
+
+flag(-session)
+	Where to save the session, when interrupted with Ctrl+C (see Hotkeys) or stopped by error. One of:
+		cmd(path/to/file)	local file
+		cmd(s3://bucket/key?endpoint=URL&region=REGION)	S3-compatible storage, credentials are taken from cmd(AWS_ACCESS_KEY_ID), cmd(AWS_SECRET_ACCESS_KEY)
+		padre.session *default*, or the location passed in cmd(-resume)
+	When stopped by error, the session records the failed input and block along with the reason.
+	Blocks decrypted before the error are printed (and written to structured sinks as cmd(partial)), unknown blocks are marked as cmd([block N unknown])
+
+flag(-resume)
+	Resume the session, saved previously. Inputs, mode and block length are taken from the session,
+	target options (URL, padding error, encoding, etc.) must be passed again
+
+flag(-socket)
+	Path to local unix socket, where progress of running instance is served. Query it with cmd(padre status [SOCKET]) from another terminal.
+	The socket serves JSON snapshot: mode, inputs, bytes done and total, output so far, requests, RPS and ETA (seconds)
+		$TMPDIR/padre-<PID>.sock *default*
+
+flag(-metrics)
+	Serve metrics for Prometheus over HTTP at given address (any path, e.g. /metrics), so that long attacks can be dashboarded:
+	requests sent, requests per second, error rate, bytes recovered, inputs done and failed, histogram of time per block.
+	NOTE: bind to loopback (cmd(127.0.0.1:9090)) unless metrics must be reachable from other hosts
+	Example: cmd(-metrics 127.0.0.1:9090)
+
+flag(-block-stats)
+	At the end of every input, print metrics of its blocks: requests used, retried byte positions, time taken, and whether
+	the last byte was ambiguous (more than one valid value). Many retries or ambiguities mean unreliable oracle.
+	Metrics are included into JSON records of output sinks as well (cmd(blocks) field)
+
+flag(-api)
+	Headless mode: serve REST API at given address, inputs are submitted through it instead of bold(STDIN) and processed one by one,
+	until interrupted. Input passed in CLI (if any) becomes the first job. Endpoints (JSON):
+		GET  /status	progress of current job, pause state, number of queued jobs
+		GET  /jobs	all jobs: input, state (queued, running, done, failed), output, raw output (base64), error
+		POST /jobs	submit cmd({"input": "..."}) or cmd({"inputs": ["...", "..."]}), add cmd("mode": "encrypt") or "decrypt" to override the mode of instance
+		GET  /jobs/ID	single job
+		POST /pause	pause sending requests
+		POST /resume	resume sending requests
+		GET  /	web UI: live progress block by block, queue of jobs, the form to submit new ones
+	Every request must carry the token: cmd(Authorization: Bearer TOKEN)
+	Example: cmd(curl -H "Authorization: Bearer $PADRE_TOKEN" -d '{"input": "..."}' http://127.0.0.1:8080/jobs)
+
+flag(-api-token)
+	Bearer token of REST API (default: PADRE_TOKEN environment variable, generated and printed if not set)
+
+flag(-version)
+	Print version and exit
+
+bold(Hotkeys:)
+	space	pause/resume sending of requests (e.g. when WAF starts rate-limiting). Available when INPUT is passed as argument
+	Ctrl+C	asks whether to save the session and exit (second Ctrl+C exits immediately). Available along with space.
+		Otherwise, Ctrl+C (or SIGTERM) stops without asking: in-flight requests are canceled, bytes recovered so far
+		(plaintext and intermediate) are printed and the session is saved
+
+bold(Exit codes:)
+	0	all inputs processed
+	1	invalid arguments, failed setup, or error that is not classified
+	2	all inputs failed, for different reasons
+	3	padding oracle was not confirmed, or did not behave as expected during attack
+	4	network failure: target, proxies or workers are unreachable, requests timed out
+	5	invalid cipher: wrong encoding or length, or it produces padding error itself
+	6	partial success: some inputs were processed, others failed
+	124	maximum runtime exceeded (see flag(-max-runtime))
+	130	aborted by user
+
+bold(Examples:)
+	Decrypt token in GET parameter:	cmd(padre -u "http://vulnerable.com/login?token=$" "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6")
+	POST data: cmd(padre -u "http://vulnerable.com/login" -post "token=$" "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6")
+	Cookies: cmd(padre -u "http://vulnerable.com/login$" -cookie "auth=$" "u7bvLewln6PJ670Gnj3hnE40L0SqG8e6")
+	Encrypt token in GET parameter:	cmd(padre -u "http://vulnerable.com/login?token=$" -enc "EncryptMe")
+`
+
+// usage as it's written (see markup)
+var rawUsage string
+
+func init() {
+	rawUsage, usage = usage, markup(usage)
+}
+
+// markup adds some color to text, marked up as usage text
+func markup(text string) string {
+	re := regexp.MustCompile(`\*required\*`)
+	text = string(re.ReplaceAll([]byte(text), []byte(color.Yellow(`(required)`))))
+
+	re = regexp.MustCompile(`\*default\*`)
+	text = string(re.ReplaceAll([]byte(text), []byte(color.Green(`(default)`))))
+
+	re = regexp.MustCompile(`cmd\(([^\)]*?)\)`)
+	text = string(re.ReplaceAll([]byte(text), []byte(color.Cyan("$1"))))
+
+	re = regexp.MustCompile(`dollar\(([^\)]*?)\)`)
+	text = string(re.ReplaceAll([]byte(text), []byte(color.CyanBold("$1"))))
+
+	re = regexp.MustCompile(`flag\(([^\)]*?)\)`)
+	text = string(re.ReplaceAll([]byte(text), []byte(color.GreenBold("$1"))))
+
+	re = regexp.MustCompile(`link\(([^\)]*?)\)`)
+	text = string(re.ReplaceAll([]byte(text), []byte(color.Underline("$1"))))
+
+	re = regexp.MustCompile(`bold\(([^\)]*?)\)`)
+	text = string(re.ReplaceAll([]byte(text), []byte(color.Bold("$1"))))
+	return text
+}
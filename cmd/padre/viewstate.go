@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/glebarez/padre/pkg/exploit"
+	out "github.com/glebarez/padre/pkg/output"
+	"github.com/glebarez/padre/pkg/viewstate"
+)
+
+const viewstateUsage = "usage: padre viewstate VIEWSTATE"
+
+// runViewstate inspects ASP.NET ViewState: encoding, MAC and structure of unencrypted state.
+// ciphertext of encrypted state is printed, ready to be passed to padre. returns exit code
+func runViewstate(print *out.Printer, args []string) int {
+	if len(args) != 1 {
+		print.Errorf(viewstateUsage)
+		return 1
+	}
+
+	p, err := viewstate.Decode(args[0])
+	if err != nil {
+		print.Error(err)
+		return 1
+	}
+
+	if p.URLEncoded {
+		print.Info("encoding: URL-encoded base64")
+	} else {
+		print.Info("encoding: base64")
+	}
+	if p.MAC != nil {
+		print.Info("MAC: %s bytes", color.Green(len(p.MAC)))
+	} else {
+		print.Info("MAC: not detected")
+	}
+
+	if !p.Encrypted {
+		print.Success("state is not encrypted")
+		printViewstate(print, p.Data)
+		return 0
+	}
+
+	// MAC is checked before decryption, padding oracle is likely not there
+	print.Success("state is encrypted: %s bytes", color.Green(len(p.Data)))
+	if p.MAC != nil {
+		print.Warning("MAC is verified before decryption, unless validation is disabled the target is not a padding oracle")
+	}
+	fmt.Fprintln(stdout, base64.StdEncoding.EncodeToString(p.Data))
+	return 0
+}
+
+// prints structure of serialized ViewState, if plaintext is one
+func printViewstate(print *out.Printer, plaintext []byte) {
+	root, _, err := viewstate.Parse(plaintext)
+	if err != nil {
+		print.Warning("could not parse ViewState: %s", err)
+		return
+	}
+
+	print.AddPrefix(color.CyanBold("[viewstate]"), true)
+	defer print.RemovePrefix()
+	for _, line := range strings.Split(strings.TrimRight(root.Format(), "\n"), "\n") {
+		print.Println(line)
+	}
+}
+
+// decrypted ViewState is shown as structure
+func printDecryptedViewstate(print *out.Printer, plaintext []byte, padding exploit.Padding, blockLen int) {
+	if unpadded, ok := padding.Unpad(plaintext, blockLen); ok {
+		plaintext = unpadded
+	}
+	if viewstate.IsSerialized(plaintext) {
+		printViewstate(print, plaintext)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/cluster"
+	"github.com/glebarez/padre/pkg/color"
+	out "github.com/glebarez/padre/pkg/output"
+)
+
+const (
+	defaultWorkerAddr = ":7070"
+
+	// environment variable with shared token of coordinator and workers
+	tokenEnv = "PADRE_TOKEN"
+)
+
+// generates random token
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+const workerUsage = "usage: padre worker [-listen ADDR] [-token TOKEN] [-p N]"
+
+// runWorker serves probes of remote coordinator until killed.
+// returns exit code
+func runWorker(print *out.Printer, args []string) int {
+	flags := flag.NewFlagSet("worker", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	addr := flags.String("listen", defaultWorkerAddr, "")
+	token := flags.String("token", os.Getenv(tokenEnv), "")
+	parallel := flags.Int("p", defaultConcurrency, "")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		print.Errorf(workerUsage)
+		return 1
+	}
+
+	if *parallel < 1 || *parallel > maxConcurrency {
+		print.Errorf("-p must be in range [1-%d]", maxConcurrency)
+		return 1
+	}
+
+	// unauthenticated workers are not allowed
+	if *token == "" {
+		var err error
+		if *token, err = randomToken(); err != nil {
+			print.Error(err)
+			return 1
+		}
+		print.Info("generated token: %s", color.Green(*token))
+	}
+
+	worker := &cluster.Worker{
+		HTTPclient: &http.Client{Transport: client.NewTransport(&client.TransportOptions{
+			KeepAlive:       true,
+			MaxConnsPerHost: *parallel,
+			IdleTimeout:     defaultIdleTimeout,
+		})},
+		Concurrency: *parallel,
+		Token:       *token,
+	}
+
+	print.Info("worker is listening on %s, concurrency: %s", color.Green(*addr), color.Green(*parallel))
+	if err := http.ListenAndServe(*addr, worker); err != nil {
+		print.Error(fmt.Errorf("worker stopped: %w", err))
+		return 1
+	}
+	return 0
+}
@@ -8,4 +8,6 @@ require (
 	github.com/mattn/go-runewidth v0.0.9 // indirect
 	github.com/nsf/termbox-go v0.0.0-20200418040025-38ba6e5628f1
 	github.com/stretchr/testify v1.6.1
+	golang.org/x/sys v0.0.0-20200116001909-b77594299b42
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,19 @@
+/*
+Package padre is a toolkit for exploitation of padding oracles in CBC mode encryption.
+
+The command line tool lives in cmd/padre, while the building blocks are
+importable on their own, without pulling the CLI:
+
+	pkg/exploit  - the padding oracle algorithm: decryption and encryption (crack)
+	pkg/probe    - padding error matchers and oracle detection (oracle, matcher)
+	pkg/client   - HTTP transport that delivers tampered ciphers to the target (transport)
+	pkg/encoder  - encoding of binary data as it travels in HTTP
+	pkg/output   - terminal printing, status bar and output sinks
+
+Packages not listed above are internal details of the CLI.
+*/
+package padre
+
+// Version of padre, as reported by -version and build-info.
+// overridden at build time for releases, e.g. -ldflags "-X github.com/glebarez/padre.Version=1.2.3"
+var Version = "1.0.0"
@@ -0,0 +1,3 @@
+// Package api implements REST API for remote control of headless padre instance:
+// ciphers are submitted as jobs, progress and results are queried as JSON, the work is paused and resumed.
+package api
@@ -0,0 +1,285 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/monitor"
+)
+
+// states of job
+const (
+	StateQueued  = "queued"
+	StateRunning = "running"
+	StateDone    = "done"
+	StateFailed  = "failed"
+)
+
+// modes of job, empty mode means the mode of instance
+const (
+	ModeDecrypt = "decrypt"
+	ModeEncrypt = "encrypt"
+)
+
+// Job - single input, submitted for processing
+type Job struct {
+	ID        int        `json:"id"`
+	Input     string     `json:"input"`
+	Mode      string     `json:"mode,omitempty"`
+	State     string     `json:"state"`
+	Output    string     `json:"output,omitempty"` // encoded output
+	Raw       []byte     `json:"raw,omitempty"`    // output bytes (base64 in JSON)
+	Error     string     `json:"error,omitempty"`
+	Submitted time.Time  `json:"submitted"`
+	Finished  *time.Time `json:"finished,omitempty"`
+}
+
+// Status - progress of current job, along with the state of queue
+type Status struct {
+	*monitor.Snapshot
+	Paused bool `json:"paused"`
+	Queued int  `json:"queued"` // jobs waiting for processing
+}
+
+// request body of job submission, either single input or a list
+type submission struct {
+	Input  string   `json:"input"`
+	Inputs []string `json:"inputs"`
+	Mode   string   `json:"mode"`
+}
+
+// Server serves REST API and holds queue of jobs. Jobs are processed one by one, in order of submission:
+// the processing side takes them with Next and reports outcome with Finish.
+// endpoints:
+//
+//	GET  /status     progress of current job
+//	GET  /jobs       all jobs
+//	POST /jobs       submit {"input": "..."} or {"inputs": [...]}, optionally with {"mode": "encrypt"}
+//	GET  /jobs/ID    single job
+//	POST /pause      pause sending requests
+//	POST /resume     resume sending requests
+//	GET  /           web UI (served without token, the token is asked for by the page)
+type Server struct {
+	// every request must carry it as bearer token (Authorization: Bearer <token>)
+	Token string
+
+	// progress of current job
+	Snapshot func() *monitor.Snapshot
+
+	// pauses and resumes the work, nil means pause is not supported
+	Gate *client.Gate
+
+	mx   sync.Mutex
+	jobs []*Job
+	next int           // index of job to be processed next
+	wake chan struct{} // signals new submissions
+}
+
+// ErrNotFound is returned for unknown job ID
+var ErrNotFound = errors.New("job not found")
+
+// NewServer creates API server, that accepts given bearer token
+func NewServer(token string) *Server {
+	return &Server{Token: token, wake: make(chan struct{}, 1)}
+}
+
+// Submit queues inputs to be processed in given mode (empty means the mode of instance), the created jobs are returned
+func (s *Server) Submit(mode string, inputs ...string) []*Job {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var jobs []*Job
+	for _, input := range inputs {
+		job := &Job{ID: len(s.jobs) + 1, Input: input, Mode: mode, State: StateQueued, Submitted: time.Now()}
+		s.jobs = append(s.jobs, job)
+		jobs = append(jobs, job.copy())
+	}
+
+	// wake up the waiting side, if any
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return jobs
+}
+
+// Next waits for the next queued job and marks it as running, copy of job is returned.
+// false is returned when ctx is done
+func (s *Server) Next(ctx context.Context) (*Job, bool) {
+	for {
+		s.mx.Lock()
+		if s.next < len(s.jobs) {
+			job := s.jobs[s.next]
+			s.next++
+			job.State = StateRunning
+			s.mx.Unlock()
+			return job.copy(), true
+		}
+		s.mx.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-s.wake:
+		}
+	}
+}
+
+// Finish records outcome of job
+func (s *Server) Finish(id int, output string, raw []byte, err error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if id < 1 || id > len(s.jobs) {
+		return
+	}
+	job := s.jobs[id-1]
+	now := time.Now()
+	job.Finished = &now
+	if err != nil {
+		job.State, job.Error = StateFailed, err.Error()
+	} else {
+		job.State, job.Output, job.Raw = StateDone, output, raw
+	}
+}
+
+// Job returns copy of job by ID
+func (s *Server) Job(id int) (*Job, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if id < 1 || id > len(s.jobs) {
+		return nil, ErrNotFound
+	}
+	return s.jobs[id-1].copy(), nil
+}
+
+// Jobs returns copies of all jobs
+func (s *Server) Jobs() []*Job {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	jobs := make([]*Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job.copy())
+	}
+	return jobs
+}
+
+func (j *Job) copy() *Job {
+	c := *j
+	return &c
+}
+
+// ServeHTTP handles API requests
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// the page holds no data, it asks for the token itself
+	if r.URL.Path == "/" && r.Method == http.MethodGet {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, indexHTML)
+		return
+	}
+
+	given := r.Header.Get("Authorization")
+	if s.Token == "" || subtle.ConstantTimeCompare([]byte(given), []byte("Bearer "+s.Token)) != 1 {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case path == "/status" && r.Method == http.MethodGet:
+		s.serveStatus(w)
+	case path == "/jobs" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.Jobs())
+	case path == "/jobs" && r.Method == http.MethodPost:
+		s.serveSubmit(w, r)
+	case strings.HasPrefix(path, "/jobs/") && r.Method == http.MethodGet:
+		id, _ := strconv.Atoi(strings.TrimPrefix(path, "/jobs/"))
+		job, err := s.Job(id)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, job)
+	case (path == "/pause" || path == "/resume") && r.Method == http.MethodPost:
+		if s.Gate == nil {
+			writeError(w, http.StatusNotImplemented, "pause is not supported")
+			return
+		}
+		if path == "/pause" {
+			s.Gate.Pause()
+		} else {
+			s.Gate.Resume()
+		}
+		s.serveStatus(w)
+	case path == "/status" || path == "/jobs" || strings.HasPrefix(path, "/jobs/") || path == "/pause" || path == "/resume":
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) serveStatus(w http.ResponseWriter) {
+	status := &Status{Snapshot: &monitor.Snapshot{}}
+	if s.Snapshot != nil {
+		status.Snapshot = s.Snapshot()
+	}
+	if s.Gate != nil {
+		status.Paused = s.Gate.Paused()
+	}
+
+	s.mx.Lock()
+	status.Queued = len(s.jobs) - s.next
+	s.mx.Unlock()
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) serveSubmit(w http.ResponseWriter, r *http.Request) {
+	var sub submission
+	if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+
+	inputs := sub.Inputs
+	if sub.Input != "" {
+		inputs = append([]string{sub.Input}, inputs...)
+	}
+	for _, input := range inputs {
+		if input == "" {
+			writeError(w, http.StatusBadRequest, "empty input")
+			return
+		}
+	}
+	if len(inputs) == 0 {
+		writeError(w, http.StatusBadRequest, `no inputs: pass {"input": "..."} or {"inputs": [...]}`)
+		return
+	}
+	if sub.Mode != "" && sub.Mode != ModeDecrypt && sub.Mode != ModeEncrypt {
+		writeError(w, http.StatusBadRequest, "mode must be one of: decrypt, encrypt")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, s.Submit(sub.Mode, inputs...))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
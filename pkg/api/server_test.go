@@ -0,0 +1,109 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/monitor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func call(t *testing.T, s *Server, method, path, token, body string, v interface{}) int {
+	r := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if v != nil {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), v))
+	}
+	return w.Code
+}
+
+func TestServer(t *testing.T) {
+	s := NewServer("secret")
+	s.Gate = client.NewGate(1)
+	s.Snapshot = func() *monitor.Snapshot { return &monitor.Snapshot{Mode: "decrypt", Done: 3, Total: 16} }
+
+	// web UI is public, data is not
+	assert.Equal(t, http.StatusOK, call(t, s, "GET", "/", "", "", nil))
+
+	// token is required
+	assert.Equal(t, http.StatusUnauthorized, call(t, s, "GET", "/jobs", "", "", nil))
+	assert.Equal(t, http.StatusUnauthorized, call(t, s, "GET", "/jobs", "wrong", "", nil))
+
+	// submit
+	var jobs []*Job
+	require.Equal(t, http.StatusCreated, call(t, s, "POST", "/jobs", "secret", `{"input": "c1", "inputs": ["c2"]}`, &jobs))
+	require.Len(t, jobs, 2)
+	assert.Equal(t, 1, jobs[0].ID)
+	assert.Equal(t, "c2", jobs[1].Input)
+	assert.Equal(t, StateQueued, jobs[1].State)
+
+	assert.Equal(t, http.StatusBadRequest, call(t, s, "POST", "/jobs", "secret", `{}`, nil))
+	assert.Equal(t, http.StatusBadRequest, call(t, s, "POST", "/jobs", "secret", `{"input": "c3", "mode": "forge"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, call(t, s, "POST", "/jobs", "secret", `not json`, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, call(t, s, "DELETE", "/jobs", "secret", "", nil))
+
+	// processing side takes jobs in order
+	job, ok := s.Next(context.Background())
+	require.True(t, ok)
+	assert.Equal(t, 1, job.ID)
+	assert.Equal(t, "c1", job.Input)
+	assert.Equal(t, StateRunning, job.State)
+	s.Finish(job.ID, "plain", []byte("plain"), nil)
+
+	job, _ = s.Next(context.Background())
+	s.Finish(job.ID, "", nil, errors.New("broken"))
+
+	done, failed := &Job{}, &Job{}
+	require.Equal(t, http.StatusOK, call(t, s, "GET", "/jobs/1", "secret", "", done))
+	assert.Equal(t, StateDone, done.State)
+	assert.Equal(t, []byte("plain"), done.Raw)
+	assert.NotNil(t, done.Finished)
+
+	require.Equal(t, http.StatusOK, call(t, s, "GET", "/jobs/2", "secret", "", failed))
+	assert.Equal(t, StateFailed, failed.State)
+	assert.Equal(t, "broken", failed.Error)
+
+	assert.Equal(t, http.StatusNotFound, call(t, s, "GET", "/jobs/3", "secret", "", nil))
+
+	// pause and resume
+	var status Status
+	require.Equal(t, http.StatusOK, call(t, s, "POST", "/pause", "secret", "", &status))
+	assert.True(t, status.Paused)
+	assert.True(t, s.Gate.Paused())
+	require.Equal(t, http.StatusOK, call(t, s, "POST", "/resume", "secret", "", &status))
+	assert.False(t, status.Paused)
+	assert.Equal(t, 3, status.Done)
+	assert.Equal(t, 0, status.Queued)
+}
+
+func TestServerNext(t *testing.T) {
+	s := NewServer("secret")
+
+	// waits for submission
+	got := make(chan string)
+	go func() {
+		job, _ := s.Next(context.Background())
+		got <- job.Input
+	}()
+	time.Sleep(10 * time.Millisecond)
+	s.Submit("", "late")
+	assert.Equal(t, "late", <-got)
+
+	// gives up when context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, ok := s.Next(ctx)
+	assert.False(t, ok)
+}
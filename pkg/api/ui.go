@@ -0,0 +1,172 @@
+package api
+
+// web UI: live progress of current job block by block, queue of jobs and the form to submit new ones.
+// the page polls the API with the token, that is asked for once and kept in local storage of browser
+const indexHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>padre</title>
+<style>
+	body { font-family: monospace; background: #111; color: #ddd; margin: 2em; }
+	h1 { color: #e5c07b; margin: 0 0 1em 0; }
+	h2 { color: #61afef; font-size: 1em; margin: 1.5em 0 0.5em 0; }
+	button, select, input, textarea { font-family: monospace; background: #222; color: #ddd; border: 1px solid #555; padding: 0.3em; }
+	textarea { width: 100%; height: 5em; box-sizing: border-box; }
+	table { border-collapse: collapse; width: 100%; }
+	td, th { border-bottom: 1px solid #333; padding: 0.3em; text-align: left; vertical-align: top; word-break: break-all; }
+	.blocks { display: flex; flex-wrap: wrap; gap: 4px; }
+	.block { width: 6em; height: 1.6em; background: #333; position: relative; }
+	.block .fill { position: absolute; top: 0; bottom: 0; right: 0; background: #98c379; }
+	.block .label { position: absolute; width: 100%; text-align: center; line-height: 1.6em; color: #111; mix-blend-mode: difference; }
+	.queued { color: #888; } .running { color: #e5c07b; } .done { color: #98c379; } .failed { color: #e06c75; }
+	#error { color: #e06c75; }
+	#output { white-space: pre-wrap; word-break: break-all; color: #98c379; }
+</style>
+</head>
+<body>
+<h1>padre</h1>
+<div id="error"></div>
+
+<h2>progress</h2>
+<div id="summary">connecting...</div>
+<p><button id="pause">pause</button> <button id="resume">resume</button></p>
+<div class="blocks" id="blocks"></div>
+<p>output so far (trailing part): <span id="output"></span></p>
+
+<h2>queue</h2>
+<textarea id="inputs" placeholder="one input per line"></textarea>
+<p>
+	<select id="mode">
+		<option value="">mode of instance</option>
+		<option value="decrypt">decrypt</option>
+		<option value="encrypt">encrypt</option>
+	</select>
+	<button id="submit">submit</button>
+	<button id="token">change token</button>
+</p>
+<table>
+	<thead><tr><th>#</th><th>state</th><th>mode</th><th>input</th><th>output / error</th></tr></thead>
+	<tbody id="jobs"></tbody>
+</table>
+
+<script>
+var token = localStorage.getItem("padre-token") || "";
+
+function askToken() {
+	token = prompt("API token (see -api-token):", token) || "";
+	localStorage.setItem("padre-token", token);
+}
+
+function call(method, path, body) {
+	var opts = {method: method, headers: {"Authorization": "Bearer " + token}};
+	if (body !== undefined) {
+		opts.body = JSON.stringify(body);
+		opts.headers["Content-Type"] = "application/json";
+	}
+	return fetch(path, opts).then(function (resp) {
+		return resp.json().then(function (data) {
+			if (resp.status === 401) {
+				askToken();
+			}
+			if (!resp.ok) {
+				throw new Error(data.error || resp.statusText);
+			}
+			return data;
+		});
+	});
+}
+
+function text(tag, value, cls) {
+	var el = document.createElement(tag);
+	el.textContent = value;
+	if (cls) {
+		el.className = cls;
+	}
+	return el;
+}
+
+// bytes are recovered from the end, so blocks are filled from the last one
+function renderBlocks(s) {
+	var container = document.getElementById("blocks");
+	container.innerHTML = "";
+	if (!s.block_len || !s.total) {
+		return;
+	}
+	var count = Math.ceil(s.total / s.block_len);
+	for (var i = 0; i < count; i++) {
+		var done = Math.min(Math.max(s.done - (count - 1 - i) * s.block_len, 0), s.block_len);
+		var block = document.createElement("div");
+		block.className = "block";
+		var fill = document.createElement("div");
+		fill.className = "fill";
+		fill.style.width = (100 * done / s.block_len) + "%";
+		block.appendChild(fill);
+		block.appendChild(text("div", (i + 1) + ": " + done + "/" + s.block_len, "label"));
+		container.appendChild(block);
+	}
+}
+
+function renderStatus(s) {
+	var summary = s.mode + " | job " + s.input + "/" + s.inputs + " | " + s.done + "/" + s.total + " bytes | " +
+		s.requests + " requests (" + s.rps + "/sec)";
+	if (s.eta) {
+		summary += " | ETA " + s.eta + "s";
+	}
+	if (s.queued) {
+		summary += " | queued: " + s.queued;
+	}
+	if (s.paused) {
+		summary += " | PAUSED";
+	}
+	document.getElementById("summary").textContent = summary;
+	document.getElementById("output").textContent = s.output || "";
+	renderBlocks(s);
+}
+
+function renderJobs(jobs) {
+	var body = document.getElementById("jobs");
+	body.innerHTML = "";
+	jobs.slice().reverse().forEach(function (job) {
+		var row = document.createElement("tr");
+		row.appendChild(text("td", job.id));
+		row.appendChild(text("td", job.state, job.state));
+		row.appendChild(text("td", job.mode || "-"));
+		row.appendChild(text("td", job.input));
+		row.appendChild(text("td", job.error || job.output || "", job.error ? "failed" : ""));
+		body.appendChild(row);
+	});
+}
+
+function refresh() {
+	Promise.all([call("GET", "/status"), call("GET", "/jobs")]).then(function (results) {
+		document.getElementById("error").textContent = "";
+		renderStatus(results[0]);
+		renderJobs(results[1]);
+	}).catch(function (err) {
+		document.getElementById("error").textContent = err.message;
+	}).then(function () {
+		setTimeout(refresh, 1000);
+	});
+}
+
+document.getElementById("pause").onclick = function () { call("POST", "/pause").then(renderStatus); };
+document.getElementById("resume").onclick = function () { call("POST", "/resume").then(renderStatus); };
+document.getElementById("token").onclick = askToken;
+document.getElementById("submit").onclick = function () {
+	var inputs = document.getElementById("inputs").value.split("\n").map(function (s) { return s.trim(); }).filter(Boolean);
+	call("POST", "/jobs", {inputs: inputs, mode: document.getElementById("mode").value}).then(function () {
+		document.getElementById("inputs").value = "";
+	}).catch(function (err) {
+		document.getElementById("error").textContent = err.message;
+	});
+};
+
+if (!token) {
+	askToken();
+}
+refresh();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// Cache keeps responses to recently sent ciphers, keyed by the exact cipher bytes,
+// so that repeated identical probes are answered without another request.
+// the least recently used responses are evicted, when size is exceeded. safe for concurrent use
+type Cache struct {
+	mx    sync.Mutex
+	size  int
+	items map[string]*list.Element
+	order *list.List // most recently used in front
+	hits  int
+}
+
+// cached response along with its key, so that evicted element can be removed from map
+type cacheEntry struct {
+	key  string
+	resp *Response
+}
+
+// NewCache creates cache, that holds up to size responses
+func NewCache(size int) *Cache {
+	if size < 1 {
+		size = 1
+	}
+	return &Cache{
+		size:  size,
+		items: make(map[string]*list.Element),
+		order: list.New(),
+	}
+}
+
+// returns cached response to cipher
+func (c *Cache) get(cipher []byte) (*Response, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	e, ok := c.items[string(cipher)]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	c.hits++
+	return e.Value.(*cacheEntry).resp, true
+}
+
+// stores response to cipher, evicting the least recently used one if cache is full
+func (c *Cache) put(cipher []byte, resp *Response) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	key := string(cipher)
+	if e, ok := c.items[key]; ok {
+		e.Value.(*cacheEntry).resp = resp
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, resp: resp})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Len returns number of cached responses
+func (c *Cache) Len() int {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.order.Len()
+}
+
+// Hits returns number of probes answered from cache
+func (c *Cache) Hits() int {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.hits
+}
+
+type noCacheKey struct{}
+
+// WithoutCache returns context, whose requests are always sent, even if response is cached (the fresh one is cached then),
+// or identical request is in flight (see Deduplicator).
+// meant for probes, that are repeated on purpose to get another answer of oracle (e.g. majority vote)
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// tells whether cache is bypassed for requests of context
+func cacheBypassed(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	bypassed, _ := ctx.Value(noCacheKey{}).(bool)
+	return bypassed
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Evict(t *testing.T) {
+	c := NewCache(2)
+	c.put([]byte("a"), &Response{StatusCode: 1})
+	c.put([]byte("b"), &Response{StatusCode: 2})
+
+	// a is used, so b is the least recent one
+	resp, ok := c.get([]byte("a"))
+	require.True(t, ok)
+	assert.Equal(t, 1, resp.StatusCode)
+
+	c.put([]byte("c"), &Response{StatusCode: 3})
+	assert.Equal(t, 2, c.Len())
+
+	_, ok = c.get([]byte("b"))
+	assert.False(t, ok)
+	_, ok = c.get([]byte("c"))
+	assert.True(t, ok)
+	assert.Equal(t, 2, c.Hits())
+}
+
+func TestClient_Cache(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(r.URL.Query().Get("c")))
+	}))
+	defer ts.Close()
+
+	c := &Client{
+		HTTPclient:        ts.Client(),
+		URL:               ts.URL + "/?c=$",
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewB64encoder(""),
+		Concurrency:       1,
+		Cache:             NewCache(10),
+	}
+
+	for i := 0; i < 3; i++ {
+		resp, err := c.DoRequest(context.Background(), []byte("same"))
+		require.NoError(t, err)
+		assert.Equal(t, "c2FtZQ==", string(resp.Body))
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests))
+	assert.Equal(t, 2, c.Cache.Hits())
+
+	// fresh answer is asked for
+	_, err := c.DoRequest(WithoutCache(context.Background()), []byte("same"))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&requests))
+
+	_, err = c.DoRequest(context.Background(), []byte("other"))
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&requests))
+}
@@ -2,10 +2,14 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/glebarez/padre/pkg/encoder"
 )
@@ -24,9 +28,23 @@ type Client struct {
 	POSTdata string
 	Cookies  []*http.Cookie
 
-	// placeholder to replace with encoded ciphertext
+	// HTTP method, if empty: GET, or POST if POSTdata is set
+	Method string
+
+	// extra headers, sent as-is with every request (e.g. to stick to one backend of load balancer)
+	Headers http.Header
+
+	// placeholder to replace with encoded ciphertext.
+	// template expressions (e.g. ${cipher:b64url}) are replaced as well, see template.go
 	CipherPlaceholder string
 
+	// if positive, the first IVLength bytes of cipher are the IV, that target takes from its own request field:
+	// IV replaces ${iv} template expressions, the rest of cipher replaces placeholder
+	IVLength int
+
+	// escaping of encoded ciphertext in place of placeholder (query escaping by default)
+	PlaceholderEncoding PlaceholderEncoding
+
 	// encoder that is used to transform binary ciphertext
 	// into plaintext representation. this must comply with
 	//  what remote server uses (e.g. Base64, Hex, etc)
@@ -38,73 +56,410 @@ type Client struct {
 	// the content type of to be sent HTTP requests
 	ContentType string
 
+	// if not empty, sent as Referer header. placeholder is replaced as well
+	Referer string
+
+	// if not nil, conditional headers are managed like browser does, see Validators
+	Validators *Validators
+
+	// if positive, only that many bytes of response body are kept in memory, the rest is discarded
+	MaxBodySize int64
+
+	// pause before every request, randomly shifted by up to ±Jitter (mimics human traffic)
+	Delay  time.Duration
+	Jitter time.Duration
+
 	// if this channel is not nil, it will be provided with byte value every time
 	// the new HTTP request is made, so that RPS stats can be collected from
 	// outside parties
 	RequestEventChan chan byte
+
+	// if not nil, every request must pass through the gate, so that requests
+	// can be paused or limited at runtime
+	Gate *Gate
+
+	// if not nil, metrics of every request are recorded
+	Stats *Stats
+
+	// if not nil, requests are spread across proxies from the pool.
+	// underlying HTTP transport must use ProxyPool.Proxy as its proxy function
+	ProxyPool *ProxyPool
+
+	// if not nil, probes are sent by dispatcher (e.g. remote workers) instead of this client
+	Dispatcher Dispatcher
+
+	// if not nil, ciphers are checked by running the command instead of sending HTTP requests
+	Command *Command
+
+	// if not nil, every HTTP exchange is passed to recorder (e.g. for logging)
+	Recorder Recorder
+
+	// if not nil, fresh anti-CSRF token is fetched for requests, and replaces CSRFPlaceholder
+	CSRF *CSRFFetcher
+
+	// if not nil, session is refreshed when server redirects requests to login page.
+	// HTTPclient must have cookie jar
+	Refresher *SessionRefresher
+
+	// if not nil, responses are cached, and repeated ciphers are not sent again (see WithoutCache).
+	// probes sent by Dispatcher are not cached
+	Cache *Cache
+
+	// if not nil, identical requests in flight are sent once, and share the response (see WithoutCache)
+	Dedup *Deduplicator
+
+	// if not nil, response to HTTP request is awaited no longer than the timeout (oracle commands are not limited)
+	Timeout *Timeout
+}
+
+// Exchange - HTTP request made by client, along with received response
+type Exchange struct {
+	Request      *http.Request
+	RequestBody  []byte
+	Response     *http.Response // body is already consumed, see ResponseBody
+	ResponseBody []byte         // may be truncated, see Client.MaxBodySize
+	Started      time.Time
+	Elapsed      time.Duration
+}
+
+// Recorder receives every HTTP exchange made by client. must be safe for concurrent use
+type Recorder interface {
+	Record(*Exchange)
+}
+
+// Recorders passes every exchange to each of recorders in turn
+type Recorders []Recorder
+
+// Record passes exchange to every recorder
+func (rs Recorders) Record(e *Exchange) {
+	for _, r := range rs {
+		r.Record(e)
+	}
+}
+
+// AddRecorder adds recorder to the one already set (if any).
+// not safe to call while requests are made
+func (c *Client) AddRecorder(r Recorder) {
+	if c.Recorder == nil {
+		c.Recorder = r
+		return
+	}
+	c.Recorder = Recorders{c.Recorder, r}
 }
 
 // DoRequest - send HTTP request with cipher, encoded according to config
 func (c *Client) DoRequest(ctx context.Context, cipher []byte) (*Response, error) {
+	fresh := cacheBypassed(ctx)
+	if c.Cache != nil && !fresh {
+		if resp, ok := c.Cache.get(cipher); ok {
+			return resp, nil
+		}
+	}
+
+	var (
+		resp *Response
+		err  error
+	)
+	if c.Dedup != nil && !fresh {
+		resp, err = c.Dedup.do(ctx, cipher, func() (*Response, error) { return c.do(ctx, cipher) })
+	} else {
+		resp, err = c.do(ctx, cipher)
+	}
+
+	if err == nil && c.Cache != nil {
+		c.Cache.put(cipher, resp)
+	}
+	return resp, err
+}
+
+// sends cipher to the oracle: runs command, or makes HTTP request
+func (c *Client) do(ctx context.Context, cipher []byte) (*Response, error) {
+	if c.Command != nil {
+		return c.runCommand(ctx, cipher)
+	}
+	if c.Refresher != nil {
+		return c.doRequestRefreshing(ctx, cipher)
+	}
+	return c.send(ctx, cipher)
+}
+
+// sends HTTP request directly or via proxy pool
+func (c *Client) send(ctx context.Context, cipher []byte) (*Response, error) {
+	if c.ProxyPool != nil {
+		return c.doRequestViaProxyPool(ctx, cipher)
+	}
+	return c.doRequest(ctx, cipher)
+}
+
+// sends request via proxy assigned to context, the dead proxy is replaced transparently
+func (c *Client) doRequestViaProxyPool(ctx context.Context, cipher []byte) (*Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// requests made outside of worker get their own slot
+	slot := proxySlotFromContext(ctx)
+	if slot == nil {
+		ctx = withProxySlot(ctx)
+		slot = proxySlotFromContext(ctx)
+	}
+
+	var lastErr error
+	for {
+		if err := c.ProxyPool.assign(slot); err != nil {
+			if lastErr != nil {
+				return nil, fmt.Errorf("%w, last error: %s", err, lastErr)
+			}
+			return nil, err
+		}
+		proxy := slot.proxy
+
+		trace := &proxyTrace{}
+		resp, err := c.doRequest(httptrace.WithClientTrace(ctx, trace.clientTrace()), cipher)
+		if ctx.Err() != nil {
+			return resp, err
+		}
+		if err == nil || !proxyFailed(err, proxy.url, trace) {
+			c.ProxyPool.report(proxy, true)
+			return resp, err
+		}
+
+		// request failed on the way through proxy, it's retried until proxy is considered dead
+		c.ProxyPool.report(proxy, false)
+		lastErr = err
+	}
+}
+
+// Render builds HTTP request with cipher exactly as it would be sent, but does not send it (e.g. to preview requests).
+// anti-CSRF token is not fetched, CSRFPlaceholder is left in place
+func (c *Client) Render(cipher []byte) (*http.Request, []byte, error) {
+	req, data, err := c.newRequest(cipher, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	return req, []byte(data), nil
+}
+
+// builds HTTP request with cipher, returns POST data along with it.
+// empty CSRF token leaves its placeholder as is
+func (c *Client) newRequest(cipher []byte, csrfToken string) (*http.Request, string, error) {
+	// IV goes into its own field
+	var iv []byte
+	if c.IVLength > 0 && len(cipher) > c.IVLength {
+		iv, cipher = cipher[:c.IVLength], cipher[c.IVLength:]
+	}
+
 	// encode the cipher
 	cipherEncoded := c.Encoder.EncodeToString(cipher)
 
+	return c.buildRequest(func(s string) string {
+		s = c.expandTemplate(s, iv, cipher, cipherEncoded)
+		s = strings.Replace(s, c.CipherPlaceholder, c.PlaceholderEncoding.escape(cipherEncoded), -1)
+		if csrfToken != "" {
+			s = replacePlaceholder(s, CSRFPlaceholder, csrfToken)
+		}
+		return s
+	})
+}
+
+// RenderTemplate builds HTTP request as it's configured, with placeholders left in place (e.g. to compare probes with)
+func (c *Client) RenderTemplate() (*http.Request, []byte, error) {
+	req, data, err := c.buildRequest(func(s string) string { return s })
+	if err != nil {
+		return nil, nil, err
+	}
+	return req, []byte(data), nil
+}
+
+// builds HTTP request, every configured value is passed through fill.
+// returns POST data along with it
+func (c *Client) buildRequest(fill func(string) string) (*http.Request, string, error) {
 	// build URL
-	url, err := url.Parse(replacePlaceholder(c.URL, c.CipherPlaceholder, cipherEncoded))
+	url, err := url.Parse(fill(c.URL))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// create request
 	req := &http.Request{
+		Method: http.MethodGet,
 		URL:    url,
 		Header: http.Header{},
 	}
 
 	// upgrade to POST if data is provided
+	var data string
 	if c.POSTdata != "" {
 		// perform data for POST body
-		req.Method = "POST"
-		data := replacePlaceholder(c.POSTdata, c.CipherPlaceholder, cipherEncoded)
+		req.Method = http.MethodPost
+		data = fill(c.POSTdata)
 		req.Body = ioutil.NopCloser(strings.NewReader(data))
 
+		// length is known, so that body is not chunked (legacy servers, e.g. SOAP ones, often reject chunked requests)
+		req.ContentLength = int64(len(data))
+
 		// set content type
 		req.Header["Content-Type"] = []string{c.ContentType}
 	}
 
+	// explicit method goes with or without body
+	if c.Method != "" {
+		req.Method = c.Method
+	}
+
 	// add cookies if any
 	if c.Cookies != nil {
 		for _, cookie := range c.Cookies {
 			// add cookies
 			req.AddCookie(&http.Cookie{
 				Name:  cookie.Name,
-				Value: replacePlaceholder(cookie.Value, c.CipherPlaceholder, cipherEncoded),
+				Value: fill(cookie.Value),
 			})
 		}
 	}
 
+	// add extra headers
+	for name, values := range c.Headers {
+		for _, value := range values {
+			req.Header.Add(name, fill(value))
+		}
+	}
+
+	// set referer
+	if c.Referer != "" {
+		req.Header.Set("Referer", fill(c.Referer))
+	}
+
+	// set conditional headers
+	if c.Validators != nil {
+		c.Validators.apply(req)
+	}
+
+	return req, data, nil
+}
+
+func (c *Client) doRequest(ctx context.Context, cipher []byte) (*Response, error) {
+	// fresh anti-CSRF token goes along with the cipher
+	var csrfToken string
+	if c.CSRF != nil {
+		var err error
+		if csrfToken, err = c.CSRF.next(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+
+	req, data, err := c.newRequest(cipher, csrfToken)
+	if err != nil {
+		return nil, err
+	}
+
 	// add context if passed
 	if ctx != nil {
 		req = req.WithContext(ctx)
 	}
 
+	// pass through the gate
+	if c.Gate != nil {
+		release, err := c.Gate.Acquire(req.Context())
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	// throttle
+	if err := c.sleep(req.Context()); err != nil {
+		return nil, err
+	}
+
+	// response is awaited no longer than timeout (body included)
+	var timeout time.Duration
+	parent := req.Context()
+	if c.Timeout != nil {
+		timeout = c.Timeout.Current()
+		timeoutCtx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+		req = req.WithContext(timeoutCtx)
+	}
+	timedOut := func(err error) error {
+		if c.Timeout != nil && req.Context().Err() == context.DeadlineExceeded && parent.Err() == nil {
+			return fmt.Errorf("%w: no response within %s", ErrTimeout, timeout)
+		}
+		return err
+	}
+
 	// send request
+	start := time.Now()
 	resp, err := c.HTTPclient.Do(req)
 	if err != nil {
+		err = timedOut(err)
+		if c.Stats != nil {
+			c.Stats.record(time.Since(start), err)
+		}
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	// remember validators
+	if c.Validators != nil {
+		c.Validators.update(req, resp)
+	}
+
 	// report about made request to status
 	if c.RequestEventChan != nil {
 		c.RequestEventChan <- 1
 	}
+	CountRequest(ctx)
 
 	// read body
-	body, err := ioutil.ReadAll(resp.Body)
+	body, length, err := readBody(resp.Body, c.MaxBodySize)
+	if err != nil {
+		err = timedOut(err)
+	}
+	if c.Stats != nil {
+		c.Stats.record(time.Since(start), err)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if c.Timeout != nil {
+		c.Timeout.observe(time.Since(start))
+	}
+
+	if c.Recorder != nil {
+		c.Recorder.Record(&Exchange{
+			Request:      req,
+			RequestBody:  []byte(data),
+			Response:     resp,
+			ResponseBody: body,
+			Started:      start,
+			Elapsed:      time.Since(start),
+		})
+	}
+
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		Length:     length,
+		Elapsed:    time.Since(start),
+		Redirects:  redirectLocations(resp),
+		redirected: resp.Request.URL.Path != req.URL.Path,
+	}, nil
+}
+
+// reads body, keeping at most maxSize bytes (unless maxSize is zero). returns full length of body as well
+func readBody(r io.Reader, maxSize int64) ([]byte, int, error) {
+	if maxSize <= 0 {
+		body, err := ioutil.ReadAll(r)
+		return body, len(body), err
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r, maxSize))
+	if err != nil {
+		return nil, 0, err
+	}
 
-	return &Response{StatusCode: resp.StatusCode, Body: body}, nil
+	rest, err := io.Copy(ioutil.Discard, r)
+	return body, len(body) + int(rest), err
 }
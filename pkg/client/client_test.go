@@ -91,6 +91,8 @@ func TestClient_DoRequest(t *testing.T) {
 		// check content type
 		assert.Equal(request.Header.Get("Content-Type"), "cont/type")
 
+		// check body is not chunked
+		assert.Equal(int64(len(response.Body)), request.ContentLength)
 	}
 
 	// check total requests reported
@@ -112,3 +114,168 @@ func TestClient_NotRespondingServer(t *testing.T) {
 	_, err := client.DoRequest(context.Background(), []byte{})
 	assert.Error(t, err)
 }
+
+func TestClient_RefererAndValidators(t *testing.T) {
+	// propagate received headers
+	headersChan := make(chan http.Header, 1)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headersChan <- r.Header
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+	}))
+	defer ts.Close()
+
+	client := &Client{
+		HTTPclient:        ts.Client(),
+		URL:               ts.URL + "/page?data=$",
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewLHEXencoder(""),
+		Concurrency:       1,
+		Referer:           ts.URL + "/from?data=$",
+		Validators:        NewValidators(),
+	}
+
+	// first request: no validators known yet
+	_, err := client.DoRequest(context.Background(), []byte{1})
+	assert.NoError(t, err)
+
+	headers := <-headersChan
+	assert.Equal(t, ts.URL+"/from?data=01", headers.Get("Referer"))
+	assert.Empty(t, headers.Get("If-None-Match"))
+
+	// second request to the same resource carries validators
+	_, err = client.DoRequest(context.Background(), []byte{2})
+	assert.NoError(t, err)
+
+	headers = <-headersChan
+	assert.Equal(t, ts.URL+"/from?data=02", headers.Get("Referer"))
+	assert.Equal(t, `"v1"`, headers.Get("If-None-Match"))
+	assert.Equal(t, "Mon, 02 Jan 2006 15:04:05 GMT", headers.Get("If-Modified-Since"))
+}
+
+func TestClient_MaxBodySize(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, 1000))
+	}))
+	defer ts.Close()
+
+	client := &Client{
+		HTTPclient:        ts.Client(),
+		URL:               ts.URL + "/?data=$",
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewB64encoder(""),
+		Concurrency:       1,
+		MaxBodySize:       10,
+	}
+
+	resp, err := client.DoRequest(context.Background(), []byte{1})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Body, 10)
+	assert.Equal(t, 1000, resp.Length)
+}
+
+func TestClient_Render(t *testing.T) {
+	client := &Client{
+		URL:               "http://target/?data=$&token={csrf}",
+		POSTdata:          "data=$",
+		Cookies:           []*http.Cookie{{Name: "key", Value: "$"}},
+		Headers:           http.Header{"X-Cipher": {"$"}},
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewB64encoder(""),
+		ContentType:       "application/x-www-form-urlencoded",
+		CSRF:              &CSRFFetcher{},
+	}
+
+	req, body, err := client.Render([]byte{0xfb, 0xff})
+	assert.NoError(t, err)
+	assert.Equal(t, "POST", req.Method)
+	assert.Equal(t, "data=%2B%2F8%3D&token={csrf}", req.URL.RawQuery)
+	assert.Equal(t, "data=%2B%2F8%3D", string(body))
+	assert.Equal(t, "%2B%2F8%3D", req.Header.Get("X-Cipher"))
+	assert.Equal(t, "key=%2B%2F8%3D", req.Header.Get("Cookie"))
+	assert.Equal(t, "application/x-www-form-urlencoded", req.Header.Get("Content-Type"))
+}
+
+func TestClient_Method(t *testing.T) {
+	client := &Client{
+		URL:               "http://target/?data=$",
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewLHEXencoder(""),
+	}
+
+	req, _, err := client.Render([]byte{1})
+	assert.NoError(t, err)
+	assert.Equal(t, "GET", req.Method)
+
+	client.Method = "PURGE"
+	req, _, err = client.Render([]byte{1})
+	assert.NoError(t, err)
+	assert.Equal(t, "PURGE", req.Method)
+
+	// explicit method is kept when body is sent
+	client.Method = "PATCH"
+	client.POSTdata = "data=$"
+	req, body, err := client.Render([]byte{1})
+	assert.NoError(t, err)
+	assert.Equal(t, "PATCH", req.Method)
+	assert.Equal(t, "data=01", string(body))
+}
+
+func TestClient_Redirects(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			http.Redirect(w, r, "/step", http.StatusFound)
+		case "/step":
+			http.Redirect(w, r, "/error?code=padding", http.StatusFound)
+		default:
+			w.Write([]byte("error page"))
+		}
+	}))
+	defer ts.Close()
+
+	httpClient := ts.Client()
+	client := &Client{
+		HTTPclient:        httpClient,
+		URL:               ts.URL + "/?data=$",
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewLHEXencoder(""),
+		Concurrency:       1,
+	}
+
+	// followed redirects are remembered
+	resp, err := client.DoRequest(context.Background(), []byte{1})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, []string{"/step", "/error?code=padding"}, resp.Redirects)
+
+	// redirect that is not followed is the response
+	httpClient.CheckRedirect = RedirectPolicy(1)
+	resp, err = client.DoRequest(context.Background(), []byte{1})
+	assert.NoError(t, err)
+	assert.Equal(t, 302, resp.StatusCode)
+	assert.Equal(t, []string{"/step", "/error?code=padding"}, resp.Redirects)
+
+	httpClient.CheckRedirect = RedirectPolicy(0)
+	resp, err = client.DoRequest(context.Background(), []byte{1})
+	assert.NoError(t, err)
+	assert.Equal(t, 302, resp.StatusCode)
+	assert.Equal(t, []string{"/step"}, resp.Redirects)
+}
+
+func TestRenderTemplate(t *testing.T) {
+	c := &Client{
+		URL:               "http://example.com/?token=$",
+		POSTdata:          "data=${cipher:hex}",
+		Cookies:           []*http.Cookie{{Name: "c", Value: "$"}},
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewB64encoder(""),
+	}
+
+	req, body, err := c.RenderTemplate()
+	assert.NoError(t, err)
+	assert.Equal(t, "token=$", req.URL.RawQuery)
+	assert.Equal(t, "data=${cipher:hex}", string(body))
+	assert.Equal(t, "c=$", req.Header.Get("Cookie"))
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// CipherEnv - environment variable, in which encoded cipher is passed to oracle command
+const CipherEnv = "PADRE_CIPHER"
+
+// Command - external program that plays the role of padding oracle instead of HTTP server.
+// encoded cipher is passed as the last argument and in CipherEnv environment variable.
+// exit code of the program is reported as status code of response, its standard output as body
+type Command struct {
+	Path string
+	Args []string
+}
+
+// ParseCommand splits command line into program and its arguments by whitespace (no shell is involved)
+func ParseCommand(line string) (*Command, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return &Command{Path: fields[0], Args: fields[1:]}, nil
+}
+
+func (c *Command) String() string {
+	return strings.Join(append([]string{c.Path}, c.Args...), " ")
+}
+
+// runs oracle command with cipher
+func (c *Client) runCommand(ctx context.Context, cipher []byte) (*Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// encode the cipher
+	cipherEncoded := c.Encoder.EncodeToString(cipher)
+
+	args := make([]string, 0, len(c.Command.Args)+1)
+	args = append(args, c.Command.Args...)
+	args = append(args, cipherEncoded)
+
+	cmd := exec.CommandContext(ctx, c.Command.Path, args...)
+	cmd.Env = append(os.Environ(), CipherEnv+"="+cipherEncoded)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	// pass through the gate
+	if c.Gate != nil {
+		release, err := c.Gate.Acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	// throttle
+	if err := c.sleep(ctx); err != nil {
+		return nil, err
+	}
+
+	// run command
+	start := time.Now()
+	if err = cmd.Start(); err == nil {
+		body, length, readErr := readBody(stdout, c.MaxBodySize)
+		if err = cmd.Wait(); err == nil {
+			err = readErr
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+			err = nil
+		}
+
+		if err == nil {
+			if c.Stats != nil {
+				c.Stats.record(time.Since(start), nil)
+			}
+
+			// report about made request to status
+			if c.RequestEventChan != nil {
+				c.RequestEventChan <- 1
+			}
+			CountRequest(ctx)
+
+			return &Response{StatusCode: cmd.ProcessState.ExitCode(), Body: body, Length: length, Elapsed: time.Since(start)}, nil
+		}
+	}
+
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	if c.Stats != nil {
+		c.Stats.record(time.Since(start), err)
+	}
+	return nil, fmt.Errorf("oracle command failed: %w", err)
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommand(t *testing.T) {
+	cmd, err := ParseCommand("  ./check.sh  --host  10.0.0.1 ")
+	require.NoError(t, err)
+	assert.Equal(t, &Command{Path: "./check.sh", Args: []string{"--host", "10.0.0.1"}}, cmd)
+	assert.Equal(t, "./check.sh --host 10.0.0.1", cmd.String())
+
+	_, err = ParseCommand(" ")
+	assert.Error(t, err)
+}
+
+func TestClient_RunCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	// the script echoes cipher from argument and environment, exits with code 3 when cipher is "AQI="
+	script := `echo "$1 $` + CipherEnv + `"; [ "$1" = "AQI=" ] && exit 3; exit 0`
+	client := &Client{
+		Command: &Command{Path: "sh", Args: []string{"-c", script, "oracle"}},
+		Encoder: encoder.NewB64encoder(""),
+	}
+
+	resp, err := client.DoRequest(nil, []byte{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, 3, resp.StatusCode)
+	assert.Equal(t, "AQI= AQI=\n", string(resp.Body))
+
+	resp, err = client.DoRequest(nil, []byte{1, 3})
+	require.NoError(t, err)
+	assert.Equal(t, 0, resp.StatusCode)
+
+	// body is truncated, full length is reported
+	client.MaxBodySize = 2
+	resp, err = client.DoRequest(nil, []byte{1, 3})
+	require.NoError(t, err)
+	assert.Equal(t, "AQ", string(resp.Body))
+	assert.Equal(t, 10, resp.Length)
+
+	// program that can't be run is an error
+	client.Command = &Command{Path: "./no-such-oracle"}
+	_, err = client.DoRequest(nil, []byte{1, 2})
+	assert.Error(t, err)
+}
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// CSRFPlaceholder marks the place of anti-CSRF token in request (URL, POST data, cookies, referer)
+const CSRFPlaceholder = "{csrf}"
+
+// CSRFFetcher fetches page before oracle requests, and extracts fresh anti-CSRF token from it.
+// the page is fetched with HTTP client of Client, so cookie jar is needed, if token is bound to session
+type CSRFFetcher struct {
+	// page with the token
+	URL string
+
+	// the token is extracted either with the first group of regular expression, or with CSS selector
+	Regexp   *regexp.Regexp
+	Selector *Selector
+
+	// number of requests, that single token is used for (0 or 1 means every request gets fresh token)
+	Every int
+
+	mx    sync.Mutex
+	token string
+	uses  int
+}
+
+// returns token for the next request, the page is fetched when the token is used up
+func (f *CSRFFetcher) next(ctx context.Context, c *Client) (string, error) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	if f.uses > 0 && f.uses < f.Every {
+		f.uses++
+		return f.token, nil
+	}
+
+	token, err := f.fetch(ctx, c)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch anti-CSRF token: %w", err)
+	}
+	f.token, f.uses = token, 1
+	return token, nil
+}
+
+// fetches the page and extracts the token
+func (f *CSRFFetcher) fetch(ctx context.Context, c *Client) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, f.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	resp, err := c.HTTPclient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, _, err := readBody(resp.Body, c.MaxBodySize)
+	if err != nil {
+		return "", err
+	}
+
+	if f.Selector != nil {
+		if token, ok := f.Selector.Extract(body); ok {
+			return token, nil
+		}
+		return "", fmt.Errorf("no element matches %s at %s", f.Selector, f.URL)
+	}
+
+	match := f.Regexp.FindSubmatch(body)
+	if len(match) < 2 {
+		return "", fmt.Errorf("%s does not match at %s", f.Regexp, f.URL)
+	}
+	return string(match[1]), nil
+}
+
+// Selector is a simple CSS selector of HTML element: tag name, #id, .class and [attr=value] (all optional), e.g.
+// input[name=csrf_token] or meta[name="csrf-token"].
+// the value of matched element is its value attribute (inputs), or content attribute (meta tags)
+type Selector struct {
+	source string
+	tag    string
+	attrs  map[string]string // required values of attributes
+	class  string
+}
+
+var (
+	selectorPattern = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9]*)?((?:#[\w-]+|\.[\w-]+|\[[\w-]+=(?:"[^"]*"|'[^']*'|[^\]]*)\])*)$`)
+	selectorPart    = regexp.MustCompile(`#([\w-]+)|\.([\w-]+)|\[([\w-]+)=(?:"([^"]*)"|'([^']*)'|([^\]]*))\]`)
+	htmlTagPattern  = regexp.MustCompile(`<([a-zA-Z][a-zA-Z0-9]*)(\s[^>]*)?>`)
+	htmlAttrPattern = regexp.MustCompile(`([\w-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	htmlEntities    = strings.NewReplacer("&amp;", "&", "&quot;", `"`, "&#39;", "'", "&lt;", "<", "&gt;", ">")
+)
+
+// ParseSelector parses simple CSS selector, see Selector
+func ParseSelector(s string) (*Selector, error) {
+	m := selectorPattern.FindStringSubmatch(s)
+	if m == nil || s == "" {
+		return nil, fmt.Errorf("unsupported selector: %s (use tag, #id, .class and [attr=value])", s)
+	}
+
+	sel := &Selector{source: s, tag: strings.ToLower(m[1]), attrs: map[string]string{}}
+	for _, part := range selectorPart.FindAllStringSubmatch(m[2], -1) {
+		switch {
+		case part[1] != "":
+			sel.attrs["id"] = part[1]
+		case part[2] != "":
+			sel.class = part[2]
+		default:
+			sel.attrs[strings.ToLower(part[3])] = part[4] + part[5] + part[6]
+		}
+	}
+	return sel, nil
+}
+
+func (s *Selector) String() string {
+	return s.source
+}
+
+// Extract finds the first matching element in HTML, and returns its value
+func (s *Selector) Extract(html []byte) (string, bool) {
+	for _, tag := range htmlTagPattern.FindAllSubmatch(html, -1) {
+		if s.tag != "" && !strings.EqualFold(string(tag[1]), s.tag) {
+			continue
+		}
+
+		attrs := map[string]string{}
+		for _, a := range htmlAttrPattern.FindAllSubmatch(tag[2], -1) {
+			attrs[strings.ToLower(string(a[1]))] = htmlEntities.Replace(string(a[2]) + string(a[3]) + string(a[4]))
+		}
+
+		if s.matches(attrs) {
+			if value, ok := attrs["value"]; ok {
+				return value, true
+			}
+			if content, ok := attrs["content"]; ok {
+				return content, true
+			}
+		}
+	}
+	return "", false
+}
+
+// tells whether attributes of element satisfy the selector
+func (s *Selector) matches(attrs map[string]string) bool {
+	for name, value := range s.attrs {
+		if attrs[name] != value {
+			return false
+		}
+	}
+	if s.class != "" {
+		for _, class := range strings.Fields(attrs["class"]) {
+			if class == s.class {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync/atomic"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelector(t *testing.T) {
+	html := []byte(`<html><head><meta name="csrf-token" content="m1"></head>
+<body><form><input type="hidden" name='csrf' value="a&amp;b"><input id=tok class="x hidden" value=v2></form></body></html>`)
+
+	for selector, expected := range map[string]string{
+		`meta[name="csrf-token"]`:  "m1",
+		`input[name=csrf]`:         "a&b",
+		`#tok`:                     "v2",
+		`input.hidden`:             "v2",
+		`[type=hidden][name=csrf]`: "a&b",
+	} {
+		sel, err := ParseSelector(selector)
+		require.NoError(t, err, selector)
+		value, ok := sel.Extract(html)
+		assert.True(t, ok, selector)
+		assert.Equal(t, expected, value, selector)
+	}
+
+	sel, err := ParseSelector("input[name=missing]")
+	require.NoError(t, err)
+	_, ok := sel.Extract(html)
+	assert.False(t, ok)
+
+	for _, selector := range []string{"", "div > input", "input:first-child"} {
+		_, err := ParseSelector(selector)
+		assert.Error(t, err, selector)
+	}
+}
+
+func TestClient_CSRF(t *testing.T) {
+	// every token is valid once
+	var issued, fetched int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/form", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetched, 1)
+		fmt.Fprintf(w, `<input name="csrf" value="t%d">`, atomic.AddInt32(&issued, 1))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("csrf") != fmt.Sprintf("t%d", atomic.LoadInt32(&issued)) {
+			w.WriteHeader(403)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := &Client{
+		HTTPclient:        server.Client(),
+		URL:               server.URL + "/?c=$&csrf=" + CSRFPlaceholder,
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewB64encoder(""),
+		CSRF: &CSRFFetcher{
+			URL:    server.URL + "/form",
+			Regexp: regexp.MustCompile(`name="csrf" value="(\w+)"`),
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		resp, err := c.DoRequest(context.Background(), []byte("cipher"))
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+	}
+	assert.Equal(t, int32(3), fetched)
+
+	// token is reused
+	c.CSRF.Every = 3
+	for i := 0; i < 3; i++ {
+		_, err := c.DoRequest(context.Background(), []byte("cipher"))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(4), fetched)
+}
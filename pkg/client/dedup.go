@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Deduplicator shares responses among identical requests in flight: while the cipher is being sent,
+// other requests with the same cipher wait for its response instead of sending their own.
+// useful when several blocks are broken at once (see exploit.Padre.BlockParallel). safe for concurrent use
+type Deduplicator struct {
+	mx       sync.Mutex
+	inflight map[string]*dedupCall
+	shared   int
+}
+
+// request in flight, its outcome is available once done is closed
+type dedupCall struct {
+	done chan struct{}
+	resp *Response
+	err  error
+}
+
+// NewDeduplicator creates deduplicator of requests
+func NewDeduplicator() *Deduplicator {
+	return &Deduplicator{inflight: make(map[string]*dedupCall)}
+}
+
+// Shared returns number of requests, that were answered with response to identical request
+func (d *Deduplicator) Shared() int {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	return d.shared
+}
+
+// sends cipher with send, unless identical one is in flight already
+func (d *Deduplicator) do(ctx context.Context, cipher []byte, send func() (*Response, error)) (*Response, error) {
+	key := string(cipher)
+	for {
+		d.mx.Lock()
+		call, ok := d.inflight[key]
+		if !ok {
+			call = &dedupCall{done: make(chan struct{})}
+			d.inflight[key] = call
+			d.mx.Unlock()
+
+			call.resp, call.err = send()
+
+			d.mx.Lock()
+			delete(d.inflight, key)
+			d.mx.Unlock()
+			close(call.done)
+			return call.resp, call.err
+		}
+		d.mx.Unlock()
+
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		// request was cancelled by its sender, not by this one: send it once again
+		if errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded) {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+
+		d.mx.Lock()
+		d.shared++
+		d.mx.Unlock()
+		return call.resp, call.err
+	}
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDedupClient(t *testing.T, requests *int32) (*Client, func()) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte(r.URL.Query().Get("c")))
+	}))
+
+	return &Client{
+		HTTPclient:        ts.Client(),
+		URL:               ts.URL + "/?c=$",
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewB64encoder(""),
+		Concurrency:       1,
+		Dedup:             NewDeduplicator(),
+	}, ts.Close
+}
+
+func TestDeduplicator(t *testing.T) {
+	var requests int32
+	c, stop := newDedupClient(t, &requests)
+	defer stop()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := c.DoRequest(context.Background(), []byte("same"))
+			assert.NoError(t, err)
+			assert.Equal(t, "c2FtZQ==", string(resp.Body))
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests))
+	assert.Equal(t, 4, c.Dedup.Shared())
+}
+
+func TestDeduplicator_SenderCancelled(t *testing.T) {
+	var requests int32
+	c, stop := newDedupClient(t, &requests)
+	defer stop()
+
+	// the first sender gives up, the waiting one sends the request on its own
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		_, _ = c.DoRequest(ctx, []byte("same"))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	resp, err := c.DoRequest(context.Background(), []byte("same"))
+	require.NoError(t, err)
+	assert.Equal(t, "c2FtZQ==", string(resp.Body))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&requests))
+	assert.Equal(t, 0, c.Dedup.Shared())
+}
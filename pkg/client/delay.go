@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// delay returns pause to make before request: Delay, randomly shifted by up to ±Jitter
+func (c *Client) delay() time.Duration {
+	d := c.Delay
+	if c.Jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(2*c.Jitter)+1)) - c.Jitter
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
+
+// sleeps before request, returns early with error if context is done
+func (c *Client) sleep(ctx context.Context) error {
+	d := c.delay()
+	if d == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
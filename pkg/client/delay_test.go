@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_delay(t *testing.T) {
+	c := &Client{Delay: 100 * time.Millisecond, Jitter: 50 * time.Millisecond}
+	for i := 0; i < 1000; i++ {
+		d := c.delay()
+		assert.True(t, d >= 50*time.Millisecond && d <= 150*time.Millisecond, d)
+	}
+
+	// never negative
+	c = &Client{Delay: 10 * time.Millisecond, Jitter: 50 * time.Millisecond}
+	for i := 0; i < 1000; i++ {
+		assert.True(t, c.delay() >= 0)
+	}
+}
+
+func TestClient_DelayedRequest(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	client := &Client{
+		HTTPclient:        ts.Client(),
+		URL:               ts.URL + "/?data=$",
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewB64encoder(""),
+		Concurrency:       1,
+		Delay:             50 * time.Millisecond,
+	}
+
+	start := time.Now()
+	_, err := client.DoRequest(context.Background(), []byte{1})
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+
+	// cancellation interrupts the delay
+	client.Delay = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.DoRequest(ctx, []byte{1})
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
@@ -0,0 +1,5 @@
+// Package client is the HTTP transport for padding oracle queries.
+// Client places encoded ciphertext into request template (URL, POST data, cookies)
+// and sends probes concurrently.
+// Oracle abstracts the question asked (is there padding error?), with HTTP and in-memory implementations.
+package client
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// Gate controls the flow of outgoing HTTP requests at runtime:
+// requests can be paused/resumed, and the number of simultaneous requests can be limited
+type Gate struct {
+	mx      sync.Mutex
+	paused  bool
+	limit   int           // maximum number of requests in flight
+	active  int           // number of requests in flight
+	changed chan struct{} // closed (and re-created) on every change of state
+}
+
+// NewGate creates gate that lets limit simultaneous requests through
+func NewGate(limit int) *Gate {
+	if limit < 1 {
+		limit = 1
+	}
+	return &Gate{
+		limit:   limit,
+		changed: make(chan struct{}),
+	}
+}
+
+// notify waiters about state change, must be called with mutex held
+func (g *Gate) notify() {
+	close(g.changed)
+	g.changed = make(chan struct{})
+}
+
+// Acquire blocks until request is allowed to go through (or context is done).
+// returned function must be called when request is complete
+func (g *Gate) Acquire(ctx context.Context) (release func(), err error) {
+	for {
+		g.mx.Lock()
+		if !g.paused && g.active < g.limit {
+			g.active++
+			g.mx.Unlock()
+			return g.release, nil
+		}
+		changed := g.changed
+		g.mx.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (g *Gate) release() {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	g.active--
+	g.notify()
+}
+
+// Pause stops new requests from going through, requests in flight are not affected
+func (g *Gate) Pause() {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	g.paused = true
+	g.notify()
+}
+
+// Resume lets requests go through again
+func (g *Gate) Resume() {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	g.paused = false
+	g.notify()
+}
+
+// Toggle pauses running gate, or resumes paused one.
+// returns true if gate became paused
+func (g *Gate) Toggle() bool {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	g.paused = !g.paused
+	g.notify()
+	return g.paused
+}
+
+// Paused tells whether gate is paused
+func (g *Gate) Paused() bool {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	return g.paused
+}
+
+// Limit returns maximum number of simultaneous requests
+func (g *Gate) Limit() int {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	return g.limit
+}
+
+// SetLimit changes maximum number of simultaneous requests (at least 1)
+func (g *Gate) SetLimit(limit int) {
+	if limit < 1 {
+		limit = 1
+	}
+
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	g.limit = limit
+	g.notify()
+}
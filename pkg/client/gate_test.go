@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tries to acquire gate within short period of time
+func tryAcquire(g *Gate) (func(), bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	release, err := g.Acquire(ctx)
+	return release, err == nil
+}
+
+func TestGate_Limit(t *testing.T) {
+	g := NewGate(2)
+
+	release1, ok := tryAcquire(g)
+	require.True(t, ok)
+	_, ok = tryAcquire(g)
+	require.True(t, ok)
+
+	// limit reached
+	_, ok = tryAcquire(g)
+	assert.False(t, ok)
+
+	// release one slot
+	release1()
+	_, ok = tryAcquire(g)
+	assert.True(t, ok)
+
+	// raise the limit
+	g.SetLimit(3)
+	assert.Equal(t, 3, g.Limit())
+	_, ok = tryAcquire(g)
+	assert.True(t, ok)
+}
+
+func TestGate_Pause(t *testing.T) {
+	g := NewGate(1)
+
+	g.Pause()
+	assert.True(t, g.Paused())
+	_, ok := tryAcquire(g)
+	assert.False(t, ok)
+
+	// waiter must be released upon resume
+	acquired := make(chan struct{})
+	go func() {
+		release, err := g.Acquire(context.Background())
+		assert.NoError(t, err)
+		release()
+		close(acquired)
+	}()
+
+	assert.False(t, g.Toggle())
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("waiter was not released upon resume")
+	}
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// declarations of variables in GraphQL operation, e.g. query ($token: String!)
+var graphQLVariablePattern = regexp.MustCompile(`\$([_A-Za-z][_0-9A-Za-z]*)\s*:`)
+
+// GraphQLBody builds JSON envelope of GraphQL request: {"query": ..., "variables": {NAME: "$"}}.
+// the cipher placeholder is the value of variable, if name is empty the first declared variable is used.
+// dollar signs of query are escaped as \u0024, so that they are not taken for placeholder
+func GraphQLBody(query, variable string) (string, error) {
+	declared := graphQLVariablePattern.FindAllStringSubmatch(query, -1)
+	if len(declared) == 0 {
+		return "", fmt.Errorf("query declares no variables, declare one for the cipher, e.g. query ($token: String!)")
+	}
+
+	if variable == "" {
+		variable = declared[0][1]
+	}
+	variable = strings.TrimPrefix(variable, "$")
+
+	found := false
+	for _, d := range declared {
+		found = found || d[1] == variable
+	}
+	if !found {
+		return "", fmt.Errorf("variable $%s is not declared in query", variable)
+	}
+
+	q, err := json.Marshal(query)
+	if err != nil {
+		return "", err
+	}
+	name, _ := json.Marshal(variable)
+
+	return fmt.Sprintf(`{"query":%s,"variables":{%s:"$"}}`, strings.Replace(string(q), "$", `\u0024`, -1), name), nil
+}
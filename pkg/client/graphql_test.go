@@ -0,0 +1,36 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLBody(t *testing.T) {
+	query := `query Check($id: Int, $token: String!) { check(id: $id, token: $token) { "ok" } }`
+
+	body, err := GraphQLBody(query, "$token")
+	require.NoError(t, err)
+	assert.NotContains(t, body[:len(body)-len(`"$"}}`)], "$", "only placeholder is left")
+
+	var envelope struct {
+		Query     string
+		Variables map[string]string
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &envelope))
+	assert.Equal(t, query, envelope.Query)
+	assert.Equal(t, map[string]string{"token": "$"}, envelope.Variables)
+
+	// the first declared variable by default
+	body, err = GraphQLBody(query, "")
+	require.NoError(t, err)
+	assert.Contains(t, body, `"variables":{"id":"$"}`)
+
+	_, err = GraphQLBody(query, "missing")
+	assert.Error(t, err)
+
+	_, err = GraphQLBody(`{ check }`, "")
+	assert.Error(t, err)
+}
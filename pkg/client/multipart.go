@@ -0,0 +1,88 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+// FormPart - part of multipart/form-data body: plain form field, or file upload if Filename is set
+type FormPart struct {
+	Name        string
+	Value       string
+	Filename    string
+	ContentType string
+}
+
+// ParseFormPart parses part in form of NAME=VALUE, optionally followed by ;filename=FILE and ;type=CONTENT-TYPE (like in curl -F)
+func ParseFormPart(s string) (FormPart, error) {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return FormPart{}, fmt.Errorf("invalid form part: %q (use NAME=VALUE[;filename=FILE][;type=CONTENT-TYPE])", s)
+	}
+	part := FormPart{Name: parts[0], Value: parts[1]}
+
+	// attributes are taken from the end, so that value itself may contain semicolons
+	for {
+		i := strings.LastIndex(part.Value, ";")
+		if i < 0 {
+			break
+		}
+		attr := strings.SplitN(part.Value[i+1:], "=", 2)
+		if len(attr) != 2 {
+			break
+		}
+		switch strings.ToLower(strings.TrimSpace(attr[0])) {
+		case "filename":
+			part.Filename = attr[1]
+		case "type":
+			part.ContentType = attr[1]
+		default:
+			return part, nil
+		}
+		part.Value = part.Value[:i]
+	}
+
+	// file parts are binary by default, as browsers send them
+	if part.Filename != "" && part.ContentType == "" {
+		part.ContentType = "application/octet-stream"
+	}
+	return part, nil
+}
+
+// quotes of names in Content-Disposition, the same as mime/multipart does
+var dispositionEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// MultipartBody builds multipart/form-data body from parts, placeholders in values are kept as-is.
+// content type with random boundary is returned along with the body
+func MultipartBody(parts []FormPart) (body string, contentType string, err error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		disposition := fmt.Sprintf(`form-data; name="%s"`, dispositionEscaper.Replace(part.Name))
+		if part.Filename != "" {
+			disposition += fmt.Sprintf(`; filename="%s"`, dispositionEscaper.Replace(part.Filename))
+		}
+		header.Set("Content-Disposition", disposition)
+		if part.ContentType != "" {
+			header.Set("Content-Type", part.ContentType)
+		}
+
+		pw, err := w.CreatePart(header)
+		if err != nil {
+			return "", "", err
+		}
+		if _, err = pw.Write([]byte(part.Value)); err != nil {
+			return "", "", err
+		}
+	}
+
+	if err = w.Close(); err != nil {
+		return "", "", err
+	}
+	return buf.String(), w.FormDataContentType(), nil
+}
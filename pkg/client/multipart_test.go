@@ -0,0 +1,65 @@
+package client
+
+import (
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormPart(t *testing.T) {
+	for s, expected := range map[string]FormPart{
+		"token=$":                          {Name: "token", Value: "$"},
+		"a=b=c;d":                          {Name: "a", Value: "b=c;d"},
+		"upload=$;filename=token.bin":      {Name: "upload", Value: "$", Filename: "token.bin", ContentType: "application/octet-stream"},
+		"doc=$;type=text/plain":            {Name: "doc", Value: "$", ContentType: "text/plain"},
+		"f=$;filename=x.xml;type=text/xml": {Name: "f", Value: "$", Filename: "x.xml", ContentType: "text/xml"},
+		"q=x;charset=utf-8":                {Name: "q", Value: "x;charset=utf-8"},
+	} {
+		part, err := ParseFormPart(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, part, s)
+	}
+
+	for _, s := range []string{"token", "=value"} {
+		_, err := ParseFormPart(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestMultipartBody(t *testing.T) {
+	body, contentType, err := MultipartBody([]FormPart{
+		{Name: "action", Value: "import"},
+		{Name: "upload", Value: "$", Filename: `a"b.bin`, ContentType: "application/octet-stream"},
+	})
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/form-data", mediaType)
+
+	// cipher is put in place of placeholder, just like client does
+	body = strings.Replace(body, "$", "c2VjcmV0+/==", -1)
+
+	r := multipart.NewReader(strings.NewReader(body), params["boundary"])
+	part, err := r.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "action", part.FormName())
+	value, _ := ioutil.ReadAll(part)
+	assert.Equal(t, "import", string(value))
+
+	part, err = r.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "upload", part.FormName())
+	assert.Equal(t, `a"b.bin`, part.FileName())
+	assert.Equal(t, "application/octet-stream", part.Header.Get("Content-Type"))
+	value, _ = ioutil.ReadAll(part)
+	assert.Equal(t, "c2VjcmV0+/==", string(value))
+
+	_, err = r.NextPart()
+	assert.Error(t, err)
+}
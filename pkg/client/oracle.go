@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"crypto/cipher"
+	"fmt"
+	"sync/atomic"
+)
+
+// Oracle tells whether ciphertext produces padding error
+type Oracle interface {
+	IsPaddingError(ctx context.Context, ciphertext []byte) (bool, error)
+}
+
+// ResponseMatcher recognizes padding error in HTTP response (see package probe for implementations)
+type ResponseMatcher interface {
+	IsPaddingError(*Response) (bool, error)
+}
+
+// HTTPOracle - oracle reachable over HTTP: ciphertext is sent with Client, response is recognized with Matcher
+type HTTPOracle struct {
+	Client  *Client
+	Matcher ResponseMatcher
+}
+
+// IsPaddingError sends ciphertext and matches the response
+func (o *HTTPOracle) IsPaddingError(ctx context.Context, ciphertext []byte) (bool, error) {
+	resp, err := o.Client.DoRequest(ctx, ciphertext)
+	if err != nil {
+		return false, err
+	}
+	return o.Matcher.IsPaddingError(resp)
+}
+
+// MockOracle - in-memory oracle, meant for tests: ciphertext is decrypted in CBC mode with Block (first block is IV),
+// and Valid tells whether padding of plaintext is correct
+type MockOracle struct {
+	Block cipher.Block
+	Valid func(plaintext []byte) bool
+
+	queries int64
+}
+
+// IsPaddingError decrypts ciphertext and checks the padding
+func (o *MockOracle) IsPaddingError(ctx context.Context, ciphertext []byte) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	atomic.AddInt64(&o.queries, 1)
+
+	blockLen := o.Block.BlockSize()
+	if len(ciphertext) < 2*blockLen || len(ciphertext)%blockLen != 0 {
+		return false, fmt.Errorf("ciphertext length %d is not a multiple of block length %d", len(ciphertext), blockLen)
+	}
+
+	plaintext := make([]byte, len(ciphertext)-blockLen)
+	cipher.NewCBCDecrypter(o.Block, ciphertext[:blockLen]).CryptBlocks(plaintext, ciphertext[blockLen:])
+	return !o.Valid(plaintext), nil
+}
+
+// Queries returns number of ciphertexts checked so far
+func (o *MockOracle) Queries() int {
+	return int(atomic.LoadInt64(&o.queries))
+}
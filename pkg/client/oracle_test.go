@@ -0,0 +1,78 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/glebarez/padre/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// padding is valid when plaintext ends with \x01
+func endsWithOne(plaintext []byte) bool {
+	return plaintext[len(plaintext)-1] == 1
+}
+
+type statusMatcher int
+
+func (m statusMatcher) IsPaddingError(resp *Response) (bool, error) {
+	return resp.StatusCode == int(m), nil
+}
+
+func TestMockOracle(t *testing.T) {
+	block, err := aes.NewCipher(util.RandomSlice(16))
+	require.NoError(t, err)
+	oracle := &MockOracle{Block: block, Valid: endsWithOne}
+
+	plaintext := append(bytes.Repeat([]byte{'a'}, 31), 1)
+	ciphertext := util.RandomSlice(16 + len(plaintext))
+	cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+	isErr, err := oracle.IsPaddingError(context.Background(), ciphertext)
+	require.NoError(t, err)
+	assert.False(t, isErr)
+
+	ciphertext[len(ciphertext)-17] ^= 1
+	isErr, err = oracle.IsPaddingError(context.Background(), ciphertext)
+	require.NoError(t, err)
+	assert.True(t, isErr)
+
+	_, err = oracle.IsPaddingError(context.Background(), ciphertext[1:])
+	assert.Error(t, err)
+	assert.Equal(t, 3, oracle.Queries())
+}
+
+func TestHTTPOracle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("c") != "00ff" {
+			w.WriteHeader(500)
+		}
+	}))
+	defer server.Close()
+
+	oracle := &HTTPOracle{
+		Client: &Client{
+			HTTPclient:        server.Client(),
+			URL:               server.URL + "/?c=$",
+			CipherPlaceholder: "$",
+			Encoder:           encoder.NewLHEXencoder(""),
+			Concurrency:       1,
+		},
+		Matcher: statusMatcher(500),
+	}
+
+	isErr, err := oracle.IsPaddingError(context.Background(), []byte{0, 0xff})
+	require.NoError(t, err)
+	assert.False(t, isErr)
+
+	isErr, err = oracle.IsPaddingError(context.Background(), []byte{0, 0xfe})
+	require.NoError(t, err)
+	assert.True(t, isErr)
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// PlaceholderEncoding - escaping of encoded cipher, when it replaces the placeholder in request
+type PlaceholderEncoding int
+
+// supported placeholder encodings
+const (
+	PlaceholderURL       PlaceholderEncoding = iota // query escaping (e.g. + becomes %2B)
+	PlaceholderNone                                 // as-is
+	PlaceholderDoubleURL                            // query escaping, applied twice
+	PlaceholderBase64URL                            // URL-safe base64 of encoded cipher, without padding
+	PlaceholderHTML                                 // HTML entities
+	PlaceholderXML                                  // XML entities, e.g. for SOAP envelopes
+	PlaceholderJSON                                 // JSON string escaping, e.g. for GraphQL variables
+)
+
+var placeholderEncodingNames = map[PlaceholderEncoding]string{
+	PlaceholderURL:       "url",
+	PlaceholderNone:      "none",
+	PlaceholderDoubleURL: "double-url",
+	PlaceholderBase64URL: "base64url",
+	PlaceholderHTML:      "html",
+	PlaceholderXML:       "xml",
+	PlaceholderJSON:      "json",
+}
+
+// the five predefined XML entities, understood by any XML parser
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+
+func (e PlaceholderEncoding) String() string {
+	return placeholderEncodingNames[e]
+}
+
+// PlaceholderEncodingByName returns placeholder encoding by its name (case-insensitive)
+func PlaceholderEncodingByName(name string) (PlaceholderEncoding, error) {
+	for e, n := range placeholderEncodingNames {
+		if strings.EqualFold(n, name) {
+			return e, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported placeholder encoding: %s", name)
+}
+
+// PlaceholderEncodingFor returns escaping that matches format of request body with given content type:
+// JSON string escaping, XML entities, query escaping for forms, or none for multipart bodies (values are sent raw).
+// false is returned for other formats
+func PlaceholderEncodingFor(contentType string) (PlaceholderEncoding, bool) {
+	switch {
+	case util.IsJSONContentType(contentType):
+		return PlaceholderJSON, true
+	case util.IsXMLContentType(contentType):
+		return PlaceholderXML, true
+	case util.IsFormContentType(contentType):
+		return PlaceholderURL, true
+	case util.IsMultipartContentType(contentType):
+		return PlaceholderNone, true
+	}
+	return PlaceholderURL, false
+}
+
+// escapes value for the placeholder
+func (e PlaceholderEncoding) escape(s string) string {
+	switch e {
+	case PlaceholderNone:
+		return s
+	case PlaceholderDoubleURL:
+		return url.QueryEscape(url.QueryEscape(s))
+	case PlaceholderBase64URL:
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	case PlaceholderHTML:
+		return html.EscapeString(s)
+	case PlaceholderXML:
+		return xmlEscaper.Replace(s)
+	case PlaceholderJSON:
+		// contents of JSON string, without quotes
+		quoted, _ := json.Marshal(s)
+		return string(quoted[1 : len(quoted)-1])
+	default:
+		return url.QueryEscape(s)
+	}
+}
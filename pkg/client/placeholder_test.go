@@ -0,0 +1,50 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceholderEncoding(t *testing.T) {
+	for e, expected := range map[PlaceholderEncoding]string{
+		PlaceholderURL:       "a%2Bb%2F%3D",
+		PlaceholderNone:      "a+b/=",
+		PlaceholderDoubleURL: "a%252Bb%252F%253D",
+		PlaceholderBase64URL: "YStiLz0",
+		PlaceholderHTML:      "a+b/=",
+		PlaceholderXML:       "a+b/=",
+		PlaceholderJSON:      "a+b/=",
+	} {
+		assert.Equal(t, expected, e.escape("a+b/="), e.String())
+
+		parsed, err := PlaceholderEncodingByName(e.String())
+		require.NoError(t, err)
+		assert.Equal(t, e, parsed)
+	}
+	assert.Equal(t, "&lt;&amp;&#34;", PlaceholderHTML.escape(`<&"`))
+	assert.Equal(t, "&lt;&amp;&quot;&apos;&gt;", PlaceholderXML.escape(`<&"'>`))
+	assert.Equal(t, `\"\\\n`, PlaceholderJSON.escape("\"\\\n"))
+
+	_, err := PlaceholderEncodingByName("rot13")
+	assert.Error(t, err)
+}
+
+func TestPlaceholderEncodingFor(t *testing.T) {
+	for contentType, expected := range map[string]PlaceholderEncoding{
+		"application/json":                  PlaceholderJSON,
+		"application/graphql+json":          PlaceholderJSON,
+		"text/xml; charset=utf-8":           PlaceholderXML,
+		"application/soap+xml":              PlaceholderXML,
+		"application/x-www-form-urlencoded": PlaceholderURL,
+		"multipart/form-data; boundary=xyz": PlaceholderNone,
+	} {
+		e, ok := PlaceholderEncodingFor(contentType)
+		assert.True(t, ok, contentType)
+		assert.Equal(t, expected, e, contentType)
+	}
+
+	_, ok := PlaceholderEncodingFor("text/plain")
+	assert.False(t, ok)
+}
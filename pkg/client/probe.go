@@ -15,12 +15,50 @@ type ProbeResult struct {
 	Err      error
 }
 
-// SendProbes -  given a chunk of bytes, place every possible byte-value at specified position.
+// Dispatcher sends probes on behalf of client, e.g. via remote workers.
+// it must follow the contract of SendProbes: results are written into chanResult, which is closed when done
+type Dispatcher interface {
+	SendProbes(ctx context.Context, client *Client, chunk []byte, pos int, values []byte, chanResult chan *ProbeResult)
+}
+
+// SendProbes -  given a chunk of bytes, place every given byte-value at specified position.
+// values are probed in given order, nil means every possible value (in sequence).
 // These probes are sent concurrently over HTTP.
 // The results will be written into chanResult channel
-func (client *Client) SendProbes(ctx context.Context, chunk []byte, pos int, chanResult chan *ProbeResult) {
-	// send byte values into this
-	chanIn := make(chan byte, probeCount)
+func (client *Client) SendProbes(ctx context.Context, chunk []byte, pos int, values []byte, chanResult chan *ProbeResult) {
+	if values == nil {
+		values = allByteValues()
+	}
+
+	if client.Dispatcher != nil {
+		client.Dispatcher.SendProbes(ctx, client, chunk, pos, values, chanResult)
+		return
+	}
+
+	client.sendProbes(ctx, chunk, pos, values, chanResult)
+}
+
+// SendProbesLocally is like SendProbes, but only given byte values are probed, and dispatcher is not used
+func (client *Client) SendProbesLocally(ctx context.Context, chunk []byte, pos int, values []byte, chanResult chan *ProbeResult) {
+	client.sendProbes(ctx, chunk, pos, values, chanResult)
+}
+
+// all possible values of a byte
+func allByteValues() []byte {
+	values := make([]byte, probeCount)
+	for i := range values {
+		values[i] = byte(i)
+	}
+	return values
+}
+
+func (client *Client) sendProbes(ctx context.Context, chunk []byte, pos int, values []byte, chanResult chan *ProbeResult) {
+	// byte values are handed to workers one by one, so that the queue drains as soon as probing is cancelled
+	// (e.g. when wanted byte is found, the rest of values is not probed)
+	chanIn := make(chan byte)
+
+	// caller is free to modify chunk once probing is over, while late workers might be just starting
+	chunk = copySlice(chunk)
 
 	/* run workers */
 	wg := sync.WaitGroup{}
@@ -32,6 +70,12 @@ func (client *Client) SendProbes(ctx context.Context, chunk []byte, pos int, cha
 			// copy chunk to produce local concurrent-safe copy
 			chunkCopy := copySlice(chunk)
 
+			// every worker sticks to its own proxy
+			ctx := ctx
+			if client.ProxyPool != nil {
+				ctx = withProxySlot(ctx)
+			}
+
 			// do the work
 			for {
 				select {
@@ -39,8 +83,8 @@ func (client *Client) SendProbes(ctx context.Context, chunk []byte, pos int, cha
 					// early exit if context is cancelled
 					return
 				case b, ok := <-chanIn:
-					// exit when input channel exhausted
-					if !ok {
+					// exit when input channel exhausted, or when cancelled meanwhile
+					if !ok || ctx.Err() != nil {
 						return
 					}
 
@@ -77,11 +121,15 @@ func (client *Client) SendProbes(ctx context.Context, chunk []byte, pos int, cha
 		close(chanResult)
 	}()
 
-	/* input generator: every possible byte value */
+	/* input generator: byte values to probe */
 	go func() {
-		for i := 0; i <= 0xff; i++ {
-			chanIn <- byte(i)
+		defer close(chanIn)
+		for _, b := range values {
+			select {
+			case chanIn <- b:
+			case <-ctx.Done():
+				return
+			}
 		}
-		close(chanIn)
 	}()
 }
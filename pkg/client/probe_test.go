@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync/atomic"
 	"testing"
 
 	"github.com/glebarez/padre/pkg/encoder"
@@ -55,7 +56,7 @@ func TestClient_SendProbes(t *testing.T) {
 		chanProbeResult := make(chan *ProbeResult, 1)
 
 		// send probes
-		go client.SendProbes(context.Background(), data, pos, chanProbeResult)
+		go client.SendProbes(context.Background(), data, pos, nil, chanProbeResult)
 
 		// get probe result
 		for probeResult := range chanProbeResult {
@@ -78,3 +79,51 @@ func TestClient_SendProbes(t *testing.T) {
 		}
 	}
 }
+
+func TestClient_SendProbes_Cancel(t *testing.T) {
+	const (
+		hit         = 0x10
+		concurrency = 4
+	)
+
+	enc := encoder.NewLHEXencoder("")
+
+	// only one byte value is accepted
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		probe, err := enc.DecodeString(r.URL.Query().Get("c"))
+		if err != nil || probe[0] != hit {
+			w.WriteHeader(500)
+		}
+	}))
+	defer ts.Close()
+
+	client := &Client{
+		HTTPclient:        ts.Client(),
+		URL:               ts.URL + "/?c=$",
+		CipherPlaceholder: "$",
+		Encoder:           enc,
+		Concurrency:       concurrency,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chanResult := make(chan *ProbeResult, probeCount)
+	go client.SendProbes(ctx, []byte{0}, 0, nil, chanResult)
+
+	// stop as soon as the hit is found
+	found := false
+	for result := range chanResult {
+		require.NoError(t, result.Err)
+		if result.Response.StatusCode == 200 {
+			found = true
+			cancel()
+		}
+	}
+
+	// values are probed in order, only those in flight are probed after the hit
+	assert.True(t, found)
+	assert.LessOrEqual(t, int(atomic.LoadInt32(&requests)), hit+1+2*concurrency)
+}
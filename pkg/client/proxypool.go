@@ -0,0 +1,272 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// proxy is considered dead after this many consecutive failures
+	proxyMaxFailures = 3
+
+	// timeout for proxy health check
+	proxyCheckTimeout = 5 * time.Second
+)
+
+// ErrNoAliveProxies is returned when every proxy in pool is dead
+var ErrNoAliveProxies = errors.New("no alive proxies left in pool")
+
+type proxyState struct {
+	url      *url.URL
+	failures int // consecutive failures
+	dead     bool
+}
+
+// ProxyPool spreads requests across many proxies.
+// Every worker sticks to a single proxy, until the proxy dies, then it is replaced transparently.
+// Dead proxies are revived by periodic health checks
+type ProxyPool struct {
+	mx      sync.Mutex
+	proxies []*proxyState
+	next    int // round-robin position
+}
+
+// context key for proxy slot
+type proxySlotKey struct{}
+
+// proxySlot holds the proxy assigned to a worker, replaced when proxy dies
+type proxySlot struct {
+	proxy *proxyState
+}
+
+// NewProxyPool creates pool from list of proxy URLs
+func NewProxyPool(proxies []*url.URL) (*ProxyPool, error) {
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("proxy pool is empty")
+	}
+
+	pool := &ProxyPool{}
+	for _, u := range proxies {
+		pool.proxies = append(pool.proxies, &proxyState{url: u})
+	}
+	return pool, nil
+}
+
+// ParseProxyList reads proxy URLs, one per line. Empty lines and lines starting with # are ignored.
+// proxies without scheme are considered HTTP proxies
+func ParseProxyList(r io.Reader) ([]*url.URL, error) {
+	proxies := make([]*url.URL, 0)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if !strings.Contains(line, "://") {
+			line = "http://" + line
+		}
+
+		u, err := url.Parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %q: %w", line, err)
+		}
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy %q: no host", line)
+		}
+		proxies = append(proxies, u)
+	}
+
+	return proxies, scanner.Err()
+}
+
+// withProxySlot creates context with empty proxy slot, so that all requests made within
+// this context stick to the same proxy
+func withProxySlot(ctx context.Context) context.Context {
+	return context.WithValue(ctx, proxySlotKey{}, &proxySlot{})
+}
+
+func proxySlotFromContext(ctx context.Context) *proxySlot {
+	slot, _ := ctx.Value(proxySlotKey{}).(*proxySlot)
+	return slot
+}
+
+// Proxy is a function for http.Transport.Proxy
+// it returns the proxy assigned to request's context
+func (p *ProxyPool) Proxy(req *http.Request) (*url.URL, error) {
+	slot := proxySlotFromContext(req.Context())
+	if slot == nil || slot.proxy == nil {
+		return nil, ErrNoAliveProxies
+	}
+	return slot.proxy.url, nil
+}
+
+// assign puts alive proxy into the slot (unless slot already holds alive one)
+func (p *ProxyPool) assign(slot *proxySlot) error {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if slot.proxy != nil && !slot.proxy.dead {
+		return nil
+	}
+
+	for i := 0; i < len(p.proxies); i++ {
+		candidate := p.proxies[p.next]
+		p.next = (p.next + 1) % len(p.proxies)
+
+		if !candidate.dead {
+			slot.proxy = candidate
+			return nil
+		}
+	}
+	return ErrNoAliveProxies
+}
+
+// report outcome of request made via proxy
+func (p *ProxyPool) report(proxy *proxyState, ok bool) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if ok {
+		proxy.failures = 0
+		return
+	}
+
+	proxy.failures++
+	if proxy.failures >= proxyMaxFailures {
+		proxy.dead = true
+	}
+}
+
+// tracks how far request went, so that failures of proxy are told apart from failures of target
+type proxyTrace struct {
+	mx         sync.Mutex
+	connected  bool // connection to target is ready, the proxy did its job
+	handshakes int  // TLS handshakes started
+}
+
+func (t *proxyTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GotConn: func(httptrace.GotConnInfo) {
+			t.mx.Lock()
+			defer t.mx.Unlock()
+			t.connected = true
+		},
+		TLSHandshakeStart: func() {
+			t.mx.Lock()
+			defer t.mx.Unlock()
+			t.handshakes++
+		},
+	}
+}
+
+// tells whether request failed because of proxy: dial to proxy, CONNECT or SOCKS handshake.
+// errors that come later (TLS with target, HTTP exchange) are not counted against the proxy
+func proxyFailed(err error, proxy *url.URL, trace *proxyTrace) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && (opErr.Op == "proxyconnect" || strings.HasPrefix(opErr.Op, "socks")) {
+		return true
+	}
+
+	trace.mx.Lock()
+	defer trace.mx.Unlock()
+	if trace.connected {
+		return false
+	}
+
+	// failed CONNECT is not reported with typed error,
+	// but it happens before TLS handshake with target is started
+	proxyHandshakes := 0
+	if proxy.Scheme == "https" {
+		proxyHandshakes = 1
+	}
+	return trace.handshakes <= proxyHandshakes
+}
+
+// Alive returns number of alive proxies
+func (p *ProxyPool) Alive() int {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	alive := 0
+	for _, proxy := range p.proxies {
+		if !proxy.dead {
+			alive++
+		}
+	}
+	return alive
+}
+
+// Size returns total number of proxies in pool
+func (p *ProxyPool) Size() int {
+	return len(p.proxies)
+}
+
+// Check performs health check of every proxy, by establishing TCP connection to it.
+// returns number of alive proxies
+func (p *ProxyPool) Check() int {
+	wg := sync.WaitGroup{}
+	for _, proxy := range p.proxies {
+		wg.Add(1)
+		go func(proxy *proxyState) {
+			defer wg.Done()
+
+			conn, err := net.DialTimeout("tcp", proxyAddress(proxy.url), proxyCheckTimeout)
+			if err == nil {
+				conn.Close()
+			}
+
+			p.mx.Lock()
+			defer p.mx.Unlock()
+			proxy.dead = err != nil
+			proxy.failures = 0
+		}(proxy)
+	}
+	wg.Wait()
+
+	return p.Alive()
+}
+
+// StartHealthChecks periodically checks the proxies, until context is done
+func (p *ProxyPool) StartHealthChecks(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.Check()
+			}
+		}
+	}()
+}
+
+// host:port of proxy, with default port for scheme
+func proxyAddress(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+
+	port := "80"
+	switch u.Scheme {
+	case "https":
+		port = "443"
+	case "socks5", "socks5h":
+		port = "1080"
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
@@ -0,0 +1,32 @@
+package client
+
+import (
+	"net/http"
+)
+
+// DefaultMaxRedirects is how many redirects are followed by default (same as net/http does)
+const DefaultMaxRedirects = 10
+
+// RedirectPolicy makes HTTP client follow at most max redirects (zero means none).
+// the last response is returned as is then, so that redirect itself can tell padding error
+func RedirectPolicy(max int) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > max {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}
+}
+
+// collects Location headers of redirects that led to response (in order they were followed),
+// and of the response itself, if it's a redirect that was not followed
+func redirectLocations(resp *http.Response) []string {
+	var locations []string
+	for r := resp.Request; r != nil && r.Response != nil; r = r.Response.Request {
+		locations = append([]string{r.Response.Header.Get("Location")}, locations...)
+	}
+	if location := resp.Header.Get("Location"); location != "" && resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		locations = append(locations, location)
+	}
+	return locations
+}
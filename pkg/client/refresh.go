@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// SessionRefresher re-authenticates, when server starts redirecting requests to login page (session has expired).
+// login URL is fetched with HTTP client of Client, so that fresh session cookies are collected by its cookie jar
+type SessionRefresher struct {
+	// login URL, fetched to get fresh session
+	URL string
+
+	// if set, the first group of regular expression is captured from body of login response,
+	// and stored in cookie jar as Cookie
+	Extract *regexp.Regexp
+	Cookie  string
+
+	mx         sync.Mutex
+	generation int       // number of refreshes made so far
+	last       time.Time // time of the last refresh
+}
+
+// sessions are not refreshed more often than that.
+// some servers redirect on padding error too, and refreshing would not help then
+const minRefreshInterval = 5 * time.Second
+
+// Refreshes returns number of refreshes made so far
+func (r *SessionRefresher) Refreshes() int {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.generation
+}
+
+// tells whether response means that session has expired:
+// redirect to another page (followed or not), or authentication required
+func sessionExpired(resp *Response) bool {
+	if resp.redirected || resp.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+	return resp.StatusCode >= 300 && resp.StatusCode < 400 && resp.Header.Get("Location") != ""
+}
+
+// sends request, refreshing the session and resending if it has expired
+func (c *Client) doRequestRefreshing(ctx context.Context, cipher []byte) (*Response, error) {
+	generation := c.Refresher.Refreshes()
+
+	resp, err := c.send(ctx, cipher)
+	if err != nil || !sessionExpired(resp) {
+		return resp, err
+	}
+
+	refreshed, err := c.Refresher.refresh(ctx, c, generation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh session: %w", err)
+	}
+	if !refreshed {
+		return resp, nil
+	}
+	return c.send(ctx, cipher)
+}
+
+// fetches login URL, returns whether session is fresh since the request with given generation was sent.
+// concurrent requests that found session expired cause single refresh:
+// the one is skipped, if session was refreshed after the request was sent (generation has changed)
+func (r *SessionRefresher) refresh(ctx context.Context, c *Client, generation int) (bool, error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if r.generation != generation {
+		return true, nil
+	}
+	if time.Since(r.last) < minRefreshInterval {
+		return false, nil
+	}
+
+	jar := c.HTTPclient.Jar
+	if jar == nil {
+		return false, fmt.Errorf("HTTP client has no cookie jar")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, r.URL, nil)
+	if err != nil {
+		return false, err
+	}
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	resp, err := c.HTTPclient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	body, _, err := readBody(resp.Body, c.MaxBodySize)
+	if err != nil {
+		return false, err
+	}
+
+	// session value from body
+	if r.Extract != nil {
+		match := r.Extract.FindSubmatch(body)
+		if len(match) < 2 {
+			return false, fmt.Errorf("session was not found in response of %s with %s", r.URL, r.Extract)
+		}
+
+		target, err := url.Parse(replacePlaceholder(c.URL, c.CipherPlaceholder, ""))
+		if err != nil {
+			return false, err
+		}
+		jar.SetCookies(target, []*http.Cookie{{Name: r.Cookie, Value: string(match[1])}})
+	}
+
+	r.generation++
+	r.last = time.Now()
+	return true, nil
+}
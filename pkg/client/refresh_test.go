@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RefreshSession(t *testing.T) {
+	// session is valid until the first request
+	session := "expired"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "please log in")
+	})
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		session = "fresh"
+		fmt.Fprintf(w, `<input name="sid" value="%s">`, session)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie("sid"); err != nil || cookie.Value != session {
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		fmt.Fprint(w, "welcome")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	c := &Client{
+		HTTPclient:        &http.Client{Jar: jar},
+		URL:               server.URL + "/?c=$",
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewB64encoder(""),
+		Refresher: &SessionRefresher{
+			URL:     server.URL + "/auth",
+			Extract: regexp.MustCompile(`name="sid" value="(\w+)"`),
+			Cookie:  "sid",
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		resp, err := c.DoRequest(context.Background(), []byte("cipher"))
+		require.NoError(t, err)
+		assert.Equal(t, "welcome", string(resp.Body))
+	}
+	assert.Equal(t, 1, c.Refresher.Refreshes())
+
+	// session is not refreshed again right away, response is passed as-is
+	session = "expired again"
+	resp, err := c.DoRequest(context.Background(), []byte("cipher"))
+	require.NoError(t, err)
+	assert.Equal(t, "please log in", string(resp.Body))
+	assert.Equal(t, 1, c.Refresher.Refreshes())
+}
@@ -1,7 +1,23 @@
 package client
 
+import (
+	"net/http"
+	"time"
+)
+
 // Response - HTTP Response data
 type Response struct {
 	StatusCode int
-	Body       []byte
+	Header     http.Header // nil for oracle command
+	Body       []byte      // may be truncated, see Client.MaxBodySize
+	Length     int         // full length of body
+
+	// time from sending request to reading response
+	Elapsed time.Duration
+
+	// Location headers of redirects: followed ones, and of the response itself, if it's not followed redirect
+	Redirects []string
+
+	// redirect to another path was followed
+	redirected bool
 }
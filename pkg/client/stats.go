@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// how many recent errors are kept
+const recentErrorsCount = 10
+
+// Stats collects metrics of HTTP requests made by client
+type Stats struct {
+	mx           sync.Mutex
+	requests     int
+	errors       int
+	latency      time.Duration // total latency of all requests
+	recentErrors []string
+}
+
+// StatsSnapshot is a point-in-time copy of stats
+type StatsSnapshot struct {
+	Requests     int
+	Errors       int
+	Latency      time.Duration // total latency of all requests
+	RecentErrors []string      // most recent errors, oldest first
+}
+
+// record single request
+func (s *Stats) record(latency time.Duration, err error) {
+	// requests cancelled on purpose are not counted
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.requests++
+	s.latency += latency
+
+	if err != nil {
+		s.errors++
+		s.recentErrors = append(s.recentErrors, err.Error())
+		if len(s.recentErrors) > recentErrorsCount {
+			s.recentErrors = s.recentErrors[1:]
+		}
+	}
+}
+
+// Errors returns number of failed requests
+func (s *Stats) Errors() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.errors
+}
+
+// Snapshot returns current state of stats
+func (s *Stats) Snapshot() StatsSnapshot {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	return StatsSnapshot{
+		Requests:     s.requests,
+		Errors:       s.errors,
+		Latency:      s.latency,
+		RecentErrors: append([]string{}, s.recentErrors...),
+	}
+}
+
+type requestCounterKey struct{}
+
+// WithRequestCounter returns context, whose requests are counted in counter (atomically).
+// only requests actually sent are counted, not the ones answered from cache
+func WithRequestCounter(ctx context.Context, counter *int64) context.Context {
+	return context.WithValue(ctx, requestCounterKey{}, counter)
+}
+
+// CountRequest counts request of context, if it has counter (see WithRequestCounter).
+// meant for requests sent on behalf of client elsewhere (e.g. by remote workers)
+func CountRequest(ctx context.Context) {
+	if ctx == nil {
+		return
+	}
+	if counter, ok := ctx.Value(requestCounterKey{}).(*int64); ok {
+		atomic.AddInt64(counter, 1)
+	}
+}
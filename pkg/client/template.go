@@ -0,0 +1,137 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/* template expressions: ${NAME[:TRANSFORM]...}, placed in request along with (or instead of) the plain placeholder.
+names:
+	cipher - the cipher, encoded and escaped just like the plain placeholder, unless transforms say otherwise
+	iv - the IV, when target takes it from its own request field (see Client.IVLength). transforms are the same as of cipher
+	len - length of the encoded cipher (before escaping)
+transforms of cipher and iv:
+	encodings of cipher bytes: b64, b64url (URL-safe, without padding), hex
+	escapings: see PlaceholderEncoding names (url, none, double-url, base64url, html, xml, json) */
+
+var templatePattern = regexp.MustCompile(`\$\{([a-zA-Z]+)((?::[a-zA-Z0-9-]+)*)\}`)
+
+// encodings of cipher bytes, that override encoder of client
+var templateEncodings = map[string]func([]byte) string{
+	"b64":    base64.StdEncoding.EncodeToString,
+	"b64url": base64.RawURLEncoding.EncodeToString,
+	"hex":    hex.EncodeToString,
+}
+
+// template expression
+type templateExpr struct {
+	name     string
+	encode   func([]byte) string  // nil means encoder of client
+	escaping *PlaceholderEncoding // nil means escaping of client
+}
+
+// parses template expression from submatches of templatePattern
+func parseTemplateExpr(name, transforms string) (*templateExpr, error) {
+	expr := &templateExpr{name: strings.ToLower(name)}
+
+	switch expr.name {
+	case "cipher", "iv":
+	case "len":
+		if transforms != "" {
+			return nil, fmt.Errorf("${len} does not support transforms")
+		}
+		return expr, nil
+	default:
+		return nil, fmt.Errorf("unknown template variable: %s (use cipher, iv or len)", name)
+	}
+
+	for _, t := range strings.Split(strings.TrimPrefix(transforms, ":"), ":") {
+		if t == "" {
+			continue
+		}
+		if encode, ok := templateEncodings[strings.ToLower(t)]; ok && expr.encode == nil {
+			expr.encode = encode
+			continue
+		}
+		if escaping, err := PlaceholderEncodingByName(t); err == nil && expr.escaping == nil {
+			expr.escaping = &escaping
+			continue
+		}
+		return nil, fmt.Errorf("unsupported or repeated transform of %s: %s (use one of b64, b64url, hex, optionally followed by one of url, none, double-url, base64url, html, xml, json)", expr.name, t)
+	}
+	return expr, nil
+}
+
+// ValidateTemplate checks template expressions in s
+func ValidateTemplate(s string) error {
+	for _, m := range templatePattern.FindAllStringSubmatch(s, -1) {
+		if _, err := parseTemplateExpr(m[1], m[2]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasTemplate tells whether s contains template expressions
+func HasTemplate(s string) bool {
+	return templatePattern.MatchString(s)
+}
+
+// HasIVTemplate tells whether s contains ${iv} template expressions
+func HasIVTemplate(s string) bool {
+	for _, m := range templatePattern.FindAllStringSubmatch(s, -1) {
+		if strings.ToLower(m[1]) == "iv" {
+			return true
+		}
+	}
+	return false
+}
+
+// StripIVTemplate removes ${iv} template expressions from s
+func StripIVTemplate(s string) string {
+	return templatePattern.ReplaceAllStringFunc(s, func(match string) string {
+		if strings.ToLower(templatePattern.FindStringSubmatch(match)[1]) == "iv" {
+			return ""
+		}
+		return match
+	})
+}
+
+// replaces template expressions in s, cipherEncoded is the cipher encoded by encoder of client.
+// iv is nil, unless IV is sent separately
+func (c *Client) expandTemplate(s string, iv, cipher []byte, cipherEncoded string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+
+	return templatePattern.ReplaceAllStringFunc(s, func(match string) string {
+		m := templatePattern.FindStringSubmatch(match)
+		expr, err := parseTemplateExpr(m[1], m[2])
+		if err != nil {
+			// invalid expressions are left as-is
+			return match
+		}
+
+		if expr.name == "len" {
+			return strconv.Itoa(len(cipherEncoded))
+		}
+
+		value, raw := cipherEncoded, cipher
+		if expr.name == "iv" {
+			value, raw = c.Encoder.EncodeToString(iv), iv
+		}
+		if expr.encode != nil {
+			value = expr.encode(raw)
+		}
+
+		escaping := c.PlaceholderEncoding
+		if expr.escaping != nil {
+			escaping = *expr.escaping
+		}
+		return escaping.escape(value)
+	})
+}
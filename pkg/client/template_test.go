@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	c := &Client{}
+	cipher := []byte{0xfb, 0xff, 0x01}
+
+	for template, expected := range map[string]string{
+		"${cipher}":              "%2B%2F8B",
+		"${cipher:none}":         "+/8B",
+		"${cipher:hex}":          "fbff01",
+		"${cipher:b64url}":       "-_8B",
+		"${cipher:b64:none}":     "+/8B",
+		"${len}":                 "4",
+		"a=${cipher:hex}&b=$":    "a=fbff01&b=$",
+		"${unknown} ${cipher:x}": "${unknown} ${cipher:x}",
+	} {
+		assert.Equal(t, expected, c.expandTemplate(template, nil, cipher, "+/8B"), template)
+	}
+
+	assert.NoError(t, ValidateTemplate("${cipher:b64url:double-url} and ${len}"))
+	for _, template := range []string{"${cipher:rot13}", "${cipher:hex:b64}", "${len:hex}", "${iv:rot13}"} {
+		assert.Error(t, ValidateTemplate(template), template)
+	}
+	assert.True(t, HasTemplate("x=${cipher}"))
+	assert.False(t, HasTemplate("x=$"))
+	assert.True(t, HasIVTemplate("x=${iv:hex}"))
+	assert.False(t, HasIVTemplate("x=${cipher}"))
+	assert.Equal(t, "x=&y=$", StripIVTemplate("x=${iv:hex}&y=$"))
+}
+
+func TestClient_DoRequest_Template(t *testing.T) {
+	requestChan := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestChan <- r
+	}))
+	defer server.Close()
+
+	c := &Client{
+		HTTPclient:        server.Client(),
+		URL:               server.URL + "/?a=$&b=${cipher:hex}",
+		Headers:           http.Header{"X-Length": []string{"${len}"}},
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewB64encoder(""),
+	}
+
+	_, err := c.DoRequest(context.Background(), []byte("cipher"))
+	require.NoError(t, err)
+
+	request := <-requestChan
+	assert.Equal(t, "Y2lwaGVy", request.URL.Query().Get("a"))
+	assert.Equal(t, "636970686572", request.URL.Query().Get("b"))
+	assert.Equal(t, "8", request.Header.Get("X-Length"))
+}
+
+func TestClient_DoRequest_IV(t *testing.T) {
+	requestChan := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestChan <- r
+	}))
+	defer server.Close()
+
+	c := &Client{
+		HTTPclient:        server.Client(),
+		URL:               server.URL + "/?iv=${iv:hex}&c=$",
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewLHEXencoder(""),
+		IVLength:          2,
+	}
+
+	_, err := c.DoRequest(context.Background(), []byte{1, 2, 3, 4})
+	require.NoError(t, err)
+
+	request := <-requestChan
+	assert.Equal(t, "0102", request.URL.Query().Get("iv"))
+	assert.Equal(t, "0304", request.URL.Query().Get("c"))
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrTimeout is reported when response does not arrive within timeout
+var ErrTimeout = errors.New("request timed out")
+
+const (
+	// dynamic timeout is a multiple of 99th percentile of recent latencies
+	DefaultTimeoutFactor = 3
+
+	// latencies of that many recent requests are kept
+	timeoutWindow = 500
+
+	// until that many latencies are measured, initial timeout applies
+	timeoutMinSamples = 20
+
+	// percentile is recalculated after every that many requests
+	timeoutRecalcEvery = 20
+
+	// timeout before latency is known
+	initialTimeout = 30 * time.Second
+
+	// dynamic timeout is never shorter than this (fast local targets have jittery latencies)
+	minTimeout = time.Second
+)
+
+// Timeout limits how long response to a single HTTP request is awaited.
+// fixed timeout stays the same, dynamic one follows latency of target: it's DefaultTimeoutFactor times
+// 99th percentile of latencies of recent successful requests, so that slow targets are not given up on,
+// and dead ones do not hang the attack. safe for concurrent use
+type Timeout struct {
+	mx      sync.Mutex
+	fixed   bool
+	current time.Duration
+	p99     time.Duration
+
+	window  []time.Duration // ring buffer of recent latencies
+	next    int
+	samples int
+}
+
+// NewFixedTimeout creates timeout that does not change
+func NewFixedTimeout(d time.Duration) *Timeout {
+	return &Timeout{fixed: true, current: d}
+}
+
+// NewDynamicTimeout creates timeout that follows latency of target
+func NewDynamicTimeout() *Timeout {
+	return &Timeout{current: initialTimeout, window: make([]time.Duration, 0, timeoutWindow)}
+}
+
+// Current returns timeout for the next request
+func (t *Timeout) Current() time.Duration {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	return t.current
+}
+
+// Latency returns 99th percentile of recent latencies, ok is false until enough requests are made (or if timeout is fixed)
+func (t *Timeout) Latency() (p99 time.Duration, ok bool) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	return t.p99, !t.fixed && t.samples >= timeoutMinSamples
+}
+
+func (t *Timeout) String() string {
+	if p99, ok := t.Latency(); ok {
+		if p99*DefaultTimeoutFactor < minTimeout {
+			return fmt.Sprintf("%s (minimum, latency p99 %s)", t.Current(), p99.Round(time.Millisecond))
+		}
+		return fmt.Sprintf("%s (latency p99 %s × %d)", t.Current(), p99.Round(time.Millisecond), DefaultTimeoutFactor)
+	}
+	return t.Current().String()
+}
+
+// records latency of successful request
+func (t *Timeout) observe(latency time.Duration) {
+	if t.fixed {
+		return
+	}
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	if len(t.window) < timeoutWindow {
+		t.window = append(t.window, latency)
+	} else {
+		t.window[t.next] = latency
+		t.next = (t.next + 1) % timeoutWindow
+	}
+	t.samples++
+
+	if t.samples < timeoutMinSamples || (t.samples-timeoutMinSamples)%timeoutRecalcEvery != 0 {
+		return
+	}
+
+	sorted := append([]time.Duration{}, t.window...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	t.p99 = sorted[(len(sorted)*99)/100]
+
+	t.current = t.p99 * DefaultTimeoutFactor
+	if t.current < minTimeout {
+		t.current = minTimeout
+	}
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout_Dynamic(t *testing.T) {
+	timeout := NewDynamicTimeout()
+	assert.Equal(t, initialTimeout, timeout.Current())
+
+	// too few samples
+	for i := 0; i < timeoutMinSamples-1; i++ {
+		timeout.observe(time.Second)
+	}
+	_, ok := timeout.Latency()
+	assert.False(t, ok)
+	assert.Equal(t, initialTimeout, timeout.Current())
+
+	timeout.observe(time.Second)
+	p99, ok := timeout.Latency()
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, p99)
+	assert.Equal(t, DefaultTimeoutFactor*time.Second, timeout.Current())
+
+	// fast target: timeout is not shorter than minimum
+	for i := 0; i < timeoutWindow; i++ {
+		timeout.observe(time.Millisecond)
+	}
+	assert.Equal(t, minTimeout, timeout.Current())
+}
+
+func TestTimeout_Fixed(t *testing.T) {
+	timeout := NewFixedTimeout(5 * time.Second)
+	for i := 0; i < 100; i++ {
+		timeout.observe(time.Minute)
+	}
+	assert.Equal(t, 5*time.Second, timeout.Current())
+	_, ok := timeout.Latency()
+	assert.False(t, ok)
+}
+
+func TestClient_Timeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("data") == "AQ==" {
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer ts.Close()
+
+	client := &Client{
+		HTTPclient:        ts.Client(),
+		URL:               ts.URL + "/?data=$",
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewB64encoder(""),
+		Concurrency:       1,
+		Timeout:           NewFixedTimeout(50 * time.Millisecond),
+	}
+
+	_, err := client.DoRequest(context.Background(), []byte{2})
+	assert.NoError(t, err)
+
+	_, err = client.DoRequest(context.Background(), []byte{1})
+	assert.True(t, errors.Is(err, ErrTimeout), err)
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// TLSOptions describe TLS configuration of connections to the target
+type TLSOptions struct {
+	// client certificate and its private key (PEM) for mutual TLS.
+	// if KeyFile is empty, the key is read from CertFile
+	CertFile string
+	KeyFile  string
+
+	// server name sent in SNI (and verified against certificate), overrides the host from URL
+	ServerName string
+
+	// skip verification of server certificate
+	Insecure bool
+
+	// minimum TLS version, zero means defaults of crypto/tls
+	MinVersion uint16
+}
+
+// TLS versions by name
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseTLSVersion parses TLS version as 1.0, 1.1, 1.2 or 1.3
+func ParseTLSVersion(s string) (uint16, error) {
+	version, ok := tlsVersions[s]
+	if !ok {
+		return 0, fmt.Errorf("unsupported TLS version: %s (choose one of: 1.0, 1.1, 1.2, 1.3)", s)
+	}
+	return version, nil
+}
+
+// NewTLSConfig creates TLS configuration from options, client certificate is loaded from disk
+func NewTLSConfig(opts *TLSOptions) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         opts.ServerName,
+		InsecureSkipVerify: opts.Insecure,
+		MinVersion:         opts.MinVersion,
+	}
+
+	if opts.CertFile != "" {
+		keyFile := opts.KeyFile
+		if keyFile == "" {
+			keyFile = opts.CertFile
+		}
+
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
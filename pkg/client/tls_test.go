@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writes self-signed certificate and its key into temporary directory
+func writeTestCertificate(t *testing.T) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "padre-tls")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	v, err := ParseTLSVersion("1.2")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), v)
+
+	_, err = ParseTLSVersion("2.0")
+	assert.Error(t, err)
+}
+
+func TestNewTLSConfig_MutualTLS(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	ts.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	ts.StartTLS()
+	defer ts.Close()
+
+	newClient := func(opts *TLSOptions) *Client {
+		config, err := NewTLSConfig(opts)
+		require.NoError(t, err)
+		return &Client{
+			HTTPclient:        &http.Client{Transport: NewTransport(&TransportOptions{TLSConfig: config})},
+			URL:               ts.URL + "/?data=$",
+			CipherPlaceholder: "$",
+			Encoder:           encoder.NewB64encoder(""),
+			Concurrency:       1,
+		}
+	}
+
+	// without client certificate
+	_, err := newClient(&TLSOptions{Insecure: true}).DoRequest(context.Background(), []byte{1})
+	assert.Error(t, err)
+
+	// with client certificate
+	resp, err := newClient(&TLSOptions{CertFile: certFile, KeyFile: keyFile, Insecure: true}).DoRequest(context.Background(), []byte{1})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(resp.Body))
+
+	// server certificate is verified unless insecure
+	_, err = newClient(&TLSOptions{CertFile: certFile, KeyFile: keyFile}).DoRequest(context.Background(), []byte{1})
+	assert.Error(t, err)
+
+	// missing key
+	_, err = NewTLSConfig(&TLSOptions{CertFile: certFile})
+	assert.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// limit of idle connections kept per host, when number of connections is not limited
+const maxIdleConnsPerHost = 256
+
+// TransportOptions pin connection behaviors of HTTP transport
+type TransportOptions struct {
+	// attempt HTTP/2 over TLS (with fallback to HTTP/1.1 if server does not support it)
+	HTTP2 bool
+
+	// if false, every request is sent over new connection
+	KeepAlive bool
+
+	// maximum number of connections per host (in any state), zero means no limit
+	MaxConnsPerHost int
+
+	// how long idle connection is kept open before it is closed, zero means no limit
+	IdleTimeout time.Duration
+
+	// TLS configuration, nil means TLS certificates are not verified
+	TLSConfig *tls.Config
+
+	// proxy function, nil means no proxy
+	Proxy func(*http.Request) (*url.URL, error)
+
+	// sizes of per-connection buffers, zero means defaults of net/http
+	ReadBufferSize  int
+	WriteBufferSize int
+}
+
+// NewTransport creates HTTP transport with given options
+func NewTransport(opts *TransportOptions) *http.Transport {
+	tlsConfig := opts.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{InsecureSkipVerify: true} // skip TLS verification
+	}
+
+	transport := &http.Transport{
+		Proxy:             opts.Proxy,
+		TLSClientConfig:   tlsConfig,
+		ForceAttemptHTTP2: opts.HTTP2,
+		DisableKeepAlives: !opts.KeepAlive,
+		MaxConnsPerHost:   opts.MaxConnsPerHost,
+		IdleConnTimeout:   opts.IdleTimeout,
+		ReadBufferSize:    opts.ReadBufferSize,
+		WriteBufferSize:   opts.WriteBufferSize,
+	}
+
+	// keep every connection for reuse in next sweep, net/http keeps only 2 idle connections per host by default
+	if opts.KeepAlive {
+		transport.MaxIdleConnsPerHost = opts.MaxConnsPerHost
+		if transport.MaxIdleConnsPerHost == 0 {
+			transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+		}
+		transport.MaxIdleConns = transport.MaxIdleConnsPerHost
+	}
+
+	// HTTP/1.1 only: non-nil empty map disables HTTP/2 upgrade
+	if !opts.HTTP2 {
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return transport
+}
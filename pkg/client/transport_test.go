@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTransport(t *testing.T) {
+	transport := NewTransport(&TransportOptions{
+		KeepAlive:       true,
+		MaxConnsPerHost: 30,
+		IdleTimeout:     time.Minute,
+	})
+	assert.False(t, transport.DisableKeepAlives)
+	assert.Equal(t, 30, transport.MaxConnsPerHost)
+	assert.Equal(t, 30, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+	assert.False(t, transport.ForceAttemptHTTP2)
+	assert.NotNil(t, transport.TLSNextProto)
+
+	transport = NewTransport(&TransportOptions{HTTP2: true})
+	assert.True(t, transport.DisableKeepAlives)
+	assert.True(t, transport.ForceAttemptHTTP2)
+	assert.Nil(t, transport.TLSNextProto)
+}
+
+func TestNewTransport_ConnectionReuse(t *testing.T) {
+	for _, keepAlive := range []bool{true, false} {
+		var conns int32
+		ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		}))
+		ts.Config.ConnState = func(c net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				atomic.AddInt32(&conns, 1)
+			}
+		}
+		ts.Start()
+
+		client := &Client{
+			HTTPclient:        &http.Client{Transport: NewTransport(&TransportOptions{KeepAlive: keepAlive, MaxConnsPerHost: 4})},
+			URL:               ts.URL + "/?data=$",
+			CipherPlaceholder: "$",
+			Encoder:           encoder.NewB64encoder(""),
+			Concurrency:       4,
+		}
+
+		// two sequential sweeps of parallel requests
+		for i := 0; i < 2; i++ {
+			wg := sync.WaitGroup{}
+			for j := 0; j < 8; j++ {
+				wg.Add(1)
+				go func(b byte) {
+					defer wg.Done()
+					_, err := client.DoRequest(context.Background(), []byte{b})
+					assert.NoError(t, err)
+				}(byte(j))
+			}
+			wg.Wait()
+		}
+		ts.Close()
+
+		if keepAlive {
+			assert.LessOrEqual(t, int(conns), 4)
+		} else {
+			assert.Equal(t, int32(16), conns)
+		}
+	}
+}
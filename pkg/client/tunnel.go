@@ -0,0 +1,110 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// how often tunnel readiness is checked
+const tunnelPollInterval = 100 * time.Millisecond
+
+// SSHTunnel is a dynamic port forwarding (SOCKS5 proxy) through SSH jump host.
+// It is established by system's ssh client, so that keys from local agent and ssh config are used
+type SSHTunnel struct {
+	// SOCKS5 proxy to route requests through
+	ProxyURL *url.URL
+
+	cmd    *exec.Cmd
+	exited chan struct{}
+	stderr bytes.Buffer
+}
+
+// OpenSSHTunnel establishes tunnel to destination (user@host[:port]).
+// blocks until tunnel is ready or timeout expires
+func OpenSSHTunnel(destination string, timeout time.Duration) (*SSHTunnel, error) {
+	sshPath, err := exec.LookPath("ssh")
+	if err != nil {
+		return nil, fmt.Errorf("ssh client not found: %w", err)
+	}
+
+	// pick free local port for SOCKS proxy
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	localAddr := listener.Addr().String()
+	listener.Close()
+
+	// port may be specified in destination
+	sshArgs := []string{
+		"-N",            // no remote command
+		"-D", localAddr, // dynamic forwarding
+		"-o", "BatchMode=yes", // no password prompts, keys only
+		"-o", "ExitOnForwardFailure=yes", // fail if forwarding is not possible
+		"-o", "ServerAliveInterval=15", // detect dead connection
+	}
+	if host, port, err := net.SplitHostPort(destination); err == nil {
+		if _, err := strconv.Atoi(port); err == nil {
+			sshArgs = append(sshArgs, "-p", port)
+			destination = host
+		}
+	}
+	// destination is never taken for an option of ssh
+	sshArgs = append(sshArgs, "--", destination)
+
+	t := &SSHTunnel{
+		ProxyURL: &url.URL{Scheme: "socks5", Host: localAddr},
+		cmd:      exec.Command(sshPath, sshArgs...),
+		exited:   make(chan struct{}),
+	}
+	t.cmd.Stderr = &t.stderr
+
+	if err := t.cmd.Start(); err != nil {
+		return nil, err
+	}
+	go func() {
+		t.cmd.Wait()
+		close(t.exited)
+	}()
+
+	// wait until local port is listening
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-t.exited:
+			return nil, fmt.Errorf("ssh exited: %s", strings.TrimSpace(t.stderr.String()))
+		default:
+		}
+
+		conn, err := net.DialTimeout("tcp", localAddr, tunnelPollInterval)
+		if err == nil {
+			conn.Close()
+			return t, nil
+		}
+		time.Sleep(tunnelPollInterval)
+	}
+
+	t.Close()
+	return nil, fmt.Errorf("ssh tunnel was not established within %s", timeout)
+}
+
+// Close tears the tunnel down
+func (t *SSHTunnel) Close() error {
+	select {
+	case <-t.exited:
+		return nil
+	default:
+	}
+
+	if err := t.cmd.Process.Kill(); err != nil {
+		return err
+	}
+	<-t.exited
+	return nil
+}
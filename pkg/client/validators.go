@@ -0,0 +1,61 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// Validators emulates browser cache: validators (ETag, Last-Modified) received from server
+// are sent back in conditional headers (If-None-Match, If-Modified-Since) of subsequent requests to the same resource.
+// Resource is identified by URL without query, since query changes from probe to probe
+type Validators struct {
+	mx        sync.Mutex
+	resources map[string]*validator
+}
+
+type validator struct {
+	etag         string
+	lastModified string
+}
+
+// NewValidators creates empty cache of validators
+func NewValidators() *Validators {
+	return &Validators{resources: map[string]*validator{}}
+}
+
+// key of the resource
+func resourceKey(u *url.URL) string {
+	return u.Scheme + "://" + u.Host + u.Path
+}
+
+// apply sets conditional headers on request, if validators for the resource are known
+func (v *Validators) apply(req *http.Request) {
+	v.mx.Lock()
+	defer v.mx.Unlock()
+
+	val, ok := v.resources[resourceKey(req.URL)]
+	if !ok {
+		return
+	}
+
+	if val.etag != "" {
+		req.Header.Set("If-None-Match", val.etag)
+	}
+	if val.lastModified != "" {
+		req.Header.Set("If-Modified-Since", val.lastModified)
+	}
+}
+
+// update stores validators from response
+func (v *Validators) update(req *http.Request, resp *http.Response) {
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return
+	}
+
+	v.mx.Lock()
+	defer v.mx.Unlock()
+
+	v.resources[resourceKey(req.URL)] = &validator{etag: etag, lastModified: lastModified}
+}
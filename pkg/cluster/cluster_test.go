@@ -0,0 +1,189 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "secret"
+
+// target echoes the cipher back
+func newTarget(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Query().Get("c")))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// worker counts requests it has served
+func newWorker(t *testing.T, served *int32) *httptest.Server {
+	worker := &Worker{HTTPclient: http.DefaultClient, Concurrency: 4, Token: testToken}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == probesPath {
+			atomic.AddInt32(served, 1)
+		}
+		worker.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func mustParseURL(t *testing.T, s string) *url.URL {
+	u, err := url.Parse(s)
+	require.NoError(t, err)
+	return u
+}
+
+func TestCoordinator_SendProbes(t *testing.T) {
+	target := newTarget(t)
+
+	var served1, served2 int32
+	worker1, worker2 := newWorker(t, &served1), newWorker(t, &served2)
+
+	// the dead worker
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	coordinator := &Coordinator{
+		Workers:    []*url.URL{mustParseURL(t, worker1.URL), mustParseURL(t, worker2.URL), mustParseURL(t, dead.URL)},
+		Token:      testToken,
+		HTTPclient: http.DefaultClient,
+	}
+
+	c := &client.Client{
+		HTTPclient:        http.DefaultClient,
+		URL:               target.URL + "/?c=$",
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewLHEXencoder(""),
+		Concurrency:       4,
+		Dispatcher:        coordinator,
+	}
+
+	chanResult := make(chan *client.ProbeResult, 256)
+	c.SendProbes(context.Background(), []byte{0xaa, 0xbb}, 1, nil, chanResult)
+
+	seen := map[byte]bool{}
+	for result := range chanResult {
+		require.NoError(t, result.Err)
+		assert.False(t, seen[result.Byte], "duplicate result for %x", result.Byte)
+		seen[result.Byte] = true
+
+		// every response carries its own probe
+		assert.Equal(t, encoder.NewLHEXencoder("").EncodeToString([]byte{0xaa, result.Byte}), string(result.Response.Body))
+	}
+	assert.Len(t, seen, 256)
+
+	// both alive workers took part
+	assert.Equal(t, int32(1), served1)
+	assert.Equal(t, int32(1), served2)
+}
+
+func TestCoordinator_SendProbes_WorkerDrops(t *testing.T) {
+	target := newTarget(t)
+
+	// worker answers part of payloads, then drops the connection
+	const answers = 300
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ProbeRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		enc := json.NewEncoder(w)
+		for _, p := range req.Payloads[:answers] {
+			require.NoError(t, enc.Encode(&ProbeResponse{Byte: p.Byte, StatusCode: http.StatusOK, Body: []byte(p.Value)}))
+		}
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}))
+	t.Cleanup(worker.Close)
+
+	coordinator := &Coordinator{
+		Workers:    []*url.URL{mustParseURL(t, worker.URL)},
+		Token:      testToken,
+		HTTPclient: http.DefaultClient,
+	}
+
+	c := &client.Client{
+		HTTPclient:        http.DefaultClient,
+		URL:               target.URL + "/?c=$",
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewLHEXencoder(""),
+		Concurrency:       4,
+	}
+
+	// every value is probed 3 times, as with -confirm 3
+	values := make([]byte, 0, 3*256)
+	for i := 0; i < 3; i++ {
+		for b := 0; b < 256; b++ {
+			values = append(values, byte(b))
+		}
+	}
+
+	chanResult := make(chan *client.ProbeResult, 256)
+	coordinator.SendProbes(context.Background(), c, []byte{0xaa, 0xbb}, 1, values, chanResult)
+
+	// unanswered repeats are probed locally, every value gets all of its votes
+	votes := map[byte]int{}
+	for result := range chanResult {
+		require.NoError(t, result.Err)
+		votes[result.Byte]++
+	}
+	require.Len(t, votes, 256)
+	for b, n := range votes {
+		assert.Equal(t, 3, n, "votes for %x", b)
+	}
+}
+
+func TestCoordinator_Check(t *testing.T) {
+	var served int32
+	worker := newWorker(t, &served)
+
+	coordinator := &Coordinator{
+		Workers:    []*url.URL{mustParseURL(t, worker.URL)},
+		Token:      testToken,
+		HTTPclient: http.DefaultClient,
+	}
+	assert.NoError(t, coordinator.Check())
+
+	coordinator.Token = "wrong"
+	assert.Error(t, coordinator.Check())
+}
+
+func TestParseWorkers(t *testing.T) {
+	workers, err := ParseWorkers("10.0.0.1:7000, https://10.0.0.2:7000")
+	require.NoError(t, err)
+	require.Len(t, workers, 2)
+	assert.Equal(t, "http://10.0.0.1:7000", workers[0].String())
+	assert.Equal(t, "https://10.0.0.2:7000", workers[1].String())
+
+	_, err = ParseWorkers(" , ")
+	assert.Error(t, err)
+}
+
+func TestSplitByteValues(t *testing.T) {
+	values := make([]byte, 256)
+	for i := range values {
+		values[i] = byte(i)
+	}
+
+	ranges := splitByteValues(values, 3)
+	require.Len(t, ranges, 3)
+
+	total := 0
+	for _, r := range ranges {
+		total += len(r)
+	}
+	assert.Equal(t, 256, total)
+	assert.Equal(t, byte(0), ranges[0][0])
+	assert.Equal(t, byte(255), ranges[2][len(ranges[2])-1])
+}
@@ -0,0 +1,236 @@
+package cluster
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glebarez/padre/pkg/client"
+)
+
+// timeout for health check of worker
+const healthCheckTimeout = 5 * time.Second
+
+// maximum size of single response line of worker
+const maxResponseLine = 16 * 1024 * 1024
+
+// Coordinator distributes probes across remote workers, every worker gets its own range of byte values.
+// if worker fails, its remaining values are probed locally.
+// Coordinator implements client.Dispatcher
+type Coordinator struct {
+	// base URLs of workers
+	Workers []*url.URL
+
+	// shared secret of coordinator and workers
+	Token string
+
+	// client used to reach workers
+	HTTPclient *http.Client
+}
+
+// ParseWorkers parses comma-separated list of worker addresses, scheme defaults to http
+func ParseWorkers(list string) ([]*url.URL, error) {
+	var workers []*url.URL
+	for _, addr := range strings.Split(list, ",") {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		if !strings.Contains(addr, "://") {
+			addr = "http://" + addr
+		}
+
+		u, err := url.Parse(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid worker %q: %w", addr, err)
+		}
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid worker %q: no host", addr)
+		}
+		workers = append(workers, u)
+	}
+
+	if len(workers) == 0 {
+		return nil, fmt.Errorf("no workers specified")
+	}
+	return workers, nil
+}
+
+// Check verifies that every worker is reachable and accepts the token
+func (co *Coordinator) Check() error {
+	for _, w := range co.Workers {
+		ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+		err := co.checkWorker(ctx, w)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("worker %s: %w", w.Host, err)
+		}
+	}
+	return nil
+}
+
+func (co *Coordinator) checkWorker(ctx context.Context, worker *url.URL) error {
+	req, err := co.newRequest(ctx, http.MethodGet, worker, healthPath, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := co.HTTPclient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	return nil
+}
+
+// creates request to worker
+func (co *Coordinator) newRequest(ctx context.Context, method string, worker *url.URL, path string, body []byte) (*http.Request, error) {
+	u := *worker
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+co.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req.WithContext(ctx), nil
+}
+
+// SendProbes splits byte values into ranges, one per worker, and collects results into chanResult
+func (co *Coordinator) SendProbes(ctx context.Context, c *client.Client, chunk []byte, pos int, values []byte, chanResult chan *client.ProbeResult) {
+	template := newTemplate(c)
+	ranges := splitByteValues(values, len(co.Workers))
+
+	wg := sync.WaitGroup{}
+	for i, worker := range co.Workers {
+		wg.Add(1)
+		go func(worker *url.URL, values []byte) {
+			defer wg.Done()
+
+			// the values which worker failed to probe are probed locally
+			remaining := co.probeRemotely(ctx, c, worker, template, chunk, pos, values, chanResult)
+			if len(remaining) > 0 && ctx.Err() == nil {
+				local := make(chan *client.ProbeResult, len(remaining))
+				c.SendProbesLocally(ctx, chunk, pos, remaining, local)
+				for result := range local {
+					select {
+					case chanResult <- result:
+					case <-ctx.Done():
+						// early exit if context is cancelled
+						return
+					}
+				}
+			}
+		}(worker, ranges[i])
+	}
+
+	go func() {
+		wg.Wait()
+		close(chanResult)
+	}()
+}
+
+// sends values to worker, results are delivered into chanResult. returns values that were not probed,
+// nil when context is cancelled
+func (co *Coordinator) probeRemotely(ctx context.Context, c *client.Client, worker *url.URL, template Template,
+	chunk []byte, pos int, values []byte, chanResult chan *client.ProbeResult) []byte {
+	// encode payloads
+	probe := make([]byte, len(chunk))
+	copy(probe, chunk)
+
+	payloads := make([]Payload, len(values))
+	for i, b := range values {
+		probe[pos] = b
+		payloads[i] = Payload{Byte: b, Value: c.Encoder.EncodeToString(probe)}
+	}
+
+	body, err := json.Marshal(&ProbeRequest{Template: template, Payloads: payloads})
+	if err != nil {
+		return values
+	}
+
+	req, err := co.newRequest(ctx, http.MethodPost, worker, probesPath, body)
+	if err != nil {
+		return values
+	}
+
+	resp, err := co.HTTPclient.Do(req)
+	if err != nil {
+		return values
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return values
+	}
+
+	// stream of results.
+	// the same value may be probed more than once (e.g. for majority vote), so answers are counted
+	answered := make(map[byte]int, len(values))
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, maxResponseLine)
+	for scanner.Scan() {
+		var r ProbeResponse
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			break
+		}
+		answered[r.Byte]++
+
+		result := &client.ProbeResult{Byte: r.Byte}
+		if r.Err != "" {
+			result.Err = fmt.Errorf("worker %s: %s", worker.Host, r.Err)
+		} else {
+			result.Response = &client.Response{StatusCode: r.StatusCode, Body: r.Body, Length: r.Length, Elapsed: r.Elapsed}
+		}
+
+		// report about made request to status
+		if c.RequestEventChan != nil {
+			c.RequestEventChan <- 1
+		}
+		client.CountRequest(ctx)
+		select {
+		case chanResult <- result:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	// values left unanswered
+	remaining := make([]byte, 0)
+	for _, b := range values {
+		if answered[b] > 0 {
+			answered[b]--
+			continue
+		}
+		remaining = append(remaining, b)
+	}
+	return remaining
+}
+
+// splits byte values into n contiguous ranges of nearly equal size
+func splitByteValues(values []byte, n int) [][]byte {
+	ranges := make([][]byte, n)
+	for i := range ranges {
+		from, to := len(values)*i/n, len(values)*(i+1)/n
+		ranges[i] = values[from:to]
+	}
+	return ranges
+}
@@ -0,0 +1,8 @@
+// Package cluster distributes padding oracle probes across remote padre workers,
+// so that the attack is parallelized beyond rate limits of a single egress IP.
+//
+// Coordinator splits the 256 candidate values of a byte into ranges, one per worker,
+// and sends every worker the request template along with encoded payloads.
+// Workers send HTTP requests to the target and stream responses back,
+// while padding errors are matched centrally by the coordinator.
+package cluster
@@ -0,0 +1,93 @@
+package cluster
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/glebarez/padre/pkg/client"
+)
+
+// HTTP endpoints of worker
+const (
+	probesPath = "/probes"
+	healthPath = "/health"
+)
+
+// ProbeRequest is sent by coordinator to worker: every payload is placed into the template and sent to target
+type ProbeRequest struct {
+	Template Template  `json:"template"`
+	Payloads []Payload `json:"payloads"`
+}
+
+// Template of HTTP request to target, payloads replace the placeholder
+type Template struct {
+	URL                 string                     `json:"url"`
+	Method              string                     `json:"method,omitempty"`
+	POSTdata            string                     `json:"post,omitempty"`
+	ContentType         string                     `json:"content_type,omitempty"`
+	Referer             string                     `json:"referer,omitempty"`
+	Cookies             []Cookie                   `json:"cookies,omitempty"`
+	Headers             http.Header                `json:"headers,omitempty"`
+	Placeholder         string                     `json:"placeholder"`
+	PlaceholderEncoding client.PlaceholderEncoding `json:"placeholder_encoding,omitempty"`
+}
+
+// Cookie sent to target
+type Cookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Payload is encoded cipher of a probe
+type Payload struct {
+	Byte  byte   `json:"byte"`  // probed byte value
+	Value string `json:"value"` // encoded cipher
+}
+
+// ProbeResponse is streamed by worker as JSON line, for every payload
+type ProbeResponse struct {
+	Byte       byte          `json:"byte"`
+	StatusCode int           `json:"status,omitempty"`
+	Body       []byte        `json:"body,omitempty"`
+	Length     int           `json:"length,omitempty"`
+	Elapsed    time.Duration `json:"elapsed,omitempty"`
+	Err        string        `json:"error,omitempty"`
+}
+
+// builds template from client
+func newTemplate(c *client.Client) Template {
+	t := Template{
+		URL:                 c.URL,
+		Method:              c.Method,
+		POSTdata:            c.POSTdata,
+		ContentType:         c.ContentType,
+		Referer:             c.Referer,
+		Headers:             c.Headers,
+		Placeholder:         c.CipherPlaceholder,
+		PlaceholderEncoding: c.PlaceholderEncoding,
+	}
+	for _, cookie := range c.Cookies {
+		t.Cookies = append(t.Cookies, Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	return t
+}
+
+// restores cookies of template
+func (t *Template) cookies() []*http.Cookie {
+	cookies := make([]*http.Cookie, 0, len(t.Cookies))
+	for _, c := range t.Cookies {
+		cookies = append(cookies, &http.Cookie{Name: c.Name, Value: c.Value})
+	}
+	return cookies
+}
+
+// passes payloads as-is, since they are encoded by coordinator already
+type verbatimEncoder struct{}
+
+func (verbatimEncoder) EncodeToString(b []byte) string {
+	return string(b)
+}
+
+func (verbatimEncoder) DecodeString(s string) ([]byte, error) {
+	return []byte(s), nil
+}
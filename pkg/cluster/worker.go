@@ -0,0 +1,130 @@
+package cluster
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/glebarez/padre/pkg/client"
+)
+
+// Worker sends probes to target on behalf of coordinator.
+// it is an HTTP handler, requests must carry the shared token
+type Worker struct {
+	// client used to reach the target
+	HTTPclient *http.Client
+
+	// number of simultaneous requests to target
+	Concurrency int
+
+	// if positive, only that many bytes of response body are sent back to coordinator
+	MaxBodySize int64
+
+	// shared secret of coordinator and workers
+	Token string
+}
+
+// ServeHTTP handles requests of coordinator
+func (w *Worker) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if !authorized(r, w.Token) {
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case healthPath:
+		rw.WriteHeader(http.StatusOK)
+	case probesPath:
+		if r.Method != http.MethodPost {
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.serveProbes(rw, r)
+	default:
+		http.NotFound(rw, r)
+	}
+}
+
+// sends probes to target, responses are streamed back as JSON lines in order of arrival
+func (w *Worker) serveProbes(rw http.ResponseWriter, r *http.Request) {
+	var req ProbeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c := &client.Client{
+		HTTPclient:          w.HTTPclient,
+		URL:                 req.Template.URL,
+		Method:              req.Template.Method,
+		POSTdata:            req.Template.POSTdata,
+		ContentType:         req.Template.ContentType,
+		Referer:             req.Template.Referer,
+		Cookies:             req.Template.cookies(),
+		Headers:             req.Template.Headers,
+		CipherPlaceholder:   req.Template.Placeholder,
+		PlaceholderEncoding: req.Template.PlaceholderEncoding,
+		Encoder:             verbatimEncoder{},
+		MaxBodySize:         w.MaxBodySize,
+	}
+
+	rw.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := rw.(http.Flusher)
+
+	var (
+		mx  sync.Mutex
+		enc = json.NewEncoder(rw)
+	)
+
+	// every response is written as soon as it arrives
+	respond := func(resp *ProbeResponse) {
+		mx.Lock()
+		defer mx.Unlock()
+
+		enc.Encode(resp)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	payloads := make(chan Payload, len(req.Payloads))
+	for _, p := range req.Payloads {
+		payloads <- p
+	}
+	close(payloads)
+
+	concurrency := w.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for p := range payloads {
+				// coordinator is not interested anymore
+				if r.Context().Err() != nil {
+					return
+				}
+
+				resp, err := c.DoRequest(r.Context(), []byte(p.Value))
+				if err != nil {
+					respond(&ProbeResponse{Byte: p.Byte, Err: err.Error()})
+					continue
+				}
+				respond(&ProbeResponse{Byte: p.Byte, StatusCode: resp.StatusCode, Body: resp.Body, Length: resp.Length, Elapsed: resp.Elapsed})
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// checks the shared token
+func authorized(r *http.Request, token string) bool {
+	given := r.Header.Get("Authorization")
+	return token != "" && subtle.ConstantTimeCompare([]byte(given), []byte("Bearer "+token)) == 1
+}
@@ -0,0 +1,2 @@
+// Package color provides terminal coloring helpers.
+package color
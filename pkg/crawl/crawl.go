@@ -0,0 +1,201 @@
+package crawl
+
+import (
+	"context"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// limits of crawling, used when not set
+const (
+	DefaultMaxDepth = 3
+	DefaultMaxPages = 100
+)
+
+// only that many bytes of page are parsed
+const maxPageSize = 4 << 20
+
+// where value is found
+const (
+	LocationLink   = "link"
+	LocationForm   = "form"
+	LocationCookie = "cookie"
+)
+
+// Finding - value, that looks like encrypted token
+type Finding struct {
+	Value    string
+	Name     string // name of parameter, field or cookie
+	Location string // link, form or cookie
+	Page     string // URL of page, where it was found
+}
+
+// Crawler follows links and forms of target
+type Crawler struct {
+	Client *http.Client
+
+	// tells whether value is a token, see probe.LooksLikeCipher
+	Match func(value string) bool
+
+	// limits, defaults are used if not set
+	MaxDepth int
+	MaxPages int
+
+	// if not nil, called for every page visited
+	Visited func(page string, depth int)
+
+	findings []*Finding
+	seen     map[string]bool // values already found
+}
+
+var (
+	linkPattern  = regexp.MustCompile(`(?is)<(?:a|link|area|iframe|frame)\b[^>]*?\s(?:href|src)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	formPattern  = regexp.MustCompile(`(?is)<form\b([^>]*)>(.*?)</form>`)
+	inputPattern = regexp.MustCompile(`(?is)<(?:input|button|textarea|select)\b([^>]*)>`)
+	attrPattern  = regexp.MustCompile(`(?is)\b([a-z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+)
+
+// Crawl visits pages breadth-first, starting from the URL. only pages of the same host are visited.
+// findings are returned in order of discovery, each value only once
+func (c *Crawler) Crawl(ctx context.Context, start string) ([]*Finding, error) {
+	startURL, err := url.Parse(start)
+	if err != nil {
+		return nil, err
+	}
+
+	maxDepth, maxPages := c.MaxDepth, c.MaxPages
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	if maxPages <= 0 {
+		maxPages = DefaultMaxPages
+	}
+
+	c.findings, c.seen = nil, make(map[string]bool)
+	queued := map[string]bool{startURL.String(): true}
+	queue := []*url.URL{startURL}
+
+	for depth := 0; depth <= maxDepth && len(queue) > 0; depth++ {
+		var next []*url.URL
+		for _, page := range queue {
+			if ctx.Err() != nil {
+				return c.findings, ctx.Err()
+			}
+			if c.Visited != nil {
+				c.Visited(page.String(), depth)
+			}
+
+			links, err := c.visit(ctx, page)
+			if err != nil {
+				// broken pages don't stop crawling
+				continue
+			}
+
+			for _, link := range links {
+				if link.Host != startURL.Host || queued[link.String()] || len(queued) >= maxPages {
+					continue
+				}
+				queued[link.String()] = true
+				next = append(next, link)
+			}
+		}
+		queue = next
+	}
+	return c.findings, nil
+}
+
+// fetches page, collects its findings and returns links to follow
+func (c *Crawler) visit(ctx context.Context, page *url.URL) ([]*url.URL, error) {
+	c.collectQuery(page, LocationLink, page.String())
+
+	req, err := http.NewRequest(http.MethodGet, page.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	for _, cookie := range resp.Cookies() {
+		c.add(cookie.Value, cookie.Name, LocationCookie, page.String())
+	}
+
+	if !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var links []*url.URL
+	for _, m := range linkPattern.FindAllStringSubmatch(string(body), -1) {
+		if link := resolve(page, m[1]+m[2]+m[3]); link != nil {
+			c.collectQuery(link, LocationLink, page.String())
+			links = append(links, link)
+		}
+	}
+
+	for _, m := range formPattern.FindAllStringSubmatch(string(body), -1) {
+		formAttrs := attributes(m[1])
+		for _, input := range inputPattern.FindAllStringSubmatch(m[2], -1) {
+			attrs := attributes(input[1])
+			c.add(attrs["value"], attrs["name"], LocationForm, page.String())
+		}
+
+		// forms sent with GET are pages as well
+		action := resolve(page, formAttrs["action"])
+		if action != nil {
+			c.collectQuery(action, LocationForm, page.String())
+			if method := strings.ToUpper(formAttrs["method"]); method == "" || method == http.MethodGet {
+				links = append(links, action)
+			}
+		}
+	}
+	return links, nil
+}
+
+func (c *Crawler) collectQuery(u *url.URL, location, page string) {
+	for name, values := range u.Query() {
+		for _, value := range values {
+			c.add(value, name, location, page)
+		}
+	}
+}
+
+func (c *Crawler) add(value, name, location, page string) {
+	if value == "" || c.seen[value] || !c.Match(value) {
+		return
+	}
+	c.seen[value] = true
+	c.findings = append(c.findings, &Finding{Value: value, Name: name, Location: location, Page: page})
+}
+
+// resolves link relative to page, fragment is dropped. nil for non-HTTP links (e.g. mailto:, javascript:)
+func resolve(page *url.URL, link string) *url.URL {
+	ref, err := url.Parse(strings.TrimSpace(html.UnescapeString(link)))
+	if err != nil {
+		return nil
+	}
+	u := page.ResolveReference(ref)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil
+	}
+	u.Fragment = ""
+	return u
+}
+
+func attributes(tag string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attrPattern.FindAllStringSubmatch(tag, -1) {
+		attrs[strings.ToLower(m[1])] = html.UnescapeString(m[2] + m[3] + m[4])
+	}
+	return attrs
+}
@@ -0,0 +1,88 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func isToken(value string) bool {
+	return strings.HasPrefix(value, "tok")
+}
+
+func newSite() *httptest.Server {
+	pages := map[string]string{
+		"/": `<a href="/a?id=tok1&page=2">a</a> <a href='b#top'>b</a> <a href="mailto:x@y">mail</a>
+			<a href="http://elsewhere.invalid/?t=tok9">other host</a>`,
+		"/a": `<form action="/search" method="get"><input type="hidden" name="state" value="tok2"><input name="q"></form>
+			<form action="/login?next=tok3" method="POST"><input name="csrf" value="tok1"></form>`,
+		"/b":      `<a href="/deep">deep</a>`,
+		"/deep":   `<a href="/deeper?x=tok8">deeper</a>`,
+		"/search": `nothing`,
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Path == "/b" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "tok4"})
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, page)
+	}))
+}
+
+func TestCrawl(t *testing.T) {
+	site := newSite()
+	defer site.Close()
+
+	var visited []string
+	c := &Crawler{
+		Client:   site.Client(),
+		Match:    isToken,
+		MaxDepth: 1,
+		Visited:  func(page string, depth int) { visited = append(visited, strings.TrimPrefix(page, site.URL)) },
+	}
+	findings, err := c.Crawl(context.Background(), site.URL+"/")
+	require.NoError(t, err)
+
+	// the same value is reported once, other hosts are not visited, depth is limited
+	assert.Equal(t, []string{"/", "/a?id=tok1&page=2", "/b"}, visited)
+
+	values := make(map[string]string)
+	for _, f := range findings {
+		values[f.Value] = f.Location + ":" + f.Name
+	}
+	assert.Equal(t, map[string]string{
+		"tok1": "link:id",
+		"tok2": "form:state",
+		"tok3": "form:next",
+		"tok4": "cookie:session",
+		"tok9": "link:t",
+	}, values)
+	assert.Equal(t, "tok1", findings[0].Value)
+}
+
+func TestCrawlMaxPages(t *testing.T) {
+	site := newSite()
+	defer site.Close()
+
+	pages := 0
+	c := &Crawler{
+		Client:   site.Client(),
+		Match:    isToken,
+		MaxPages: 2,
+		Visited:  func(string, int) { pages++ },
+	}
+	_, err := c.Crawl(context.Background(), site.URL+"/")
+	require.NoError(t, err)
+	assert.Equal(t, 2, pages)
+}
@@ -0,0 +1,3 @@
+// Package crawl harvests ciphers from target: links and forms of pages are followed within the same host,
+// values of query parameters, form fields and cookies that look like encrypted tokens are collected.
+package crawl
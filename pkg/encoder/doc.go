@@ -0,0 +1,3 @@
+// Package encoder transforms binary data into text representation used by target (base64, hex, etc.)
+// and back, with optional character replacements.
+package encoder
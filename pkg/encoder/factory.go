@@ -2,14 +2,28 @@ package encoder
 
 import "encoding/base64"
 
+// NewB64encoder creates standard base64 encoder with replacements applied after encoding
 func NewB64encoder(replacements string) Encoder {
 	return newEncoderWithReplacer(base64.StdEncoding, replacements)
 }
 
+// NewLHEXencoder creates lowercase hex encoder with replacements applied after encoding
 func NewLHEXencoder(replacements string) Encoder {
 	return newEncoderWithReplacer(&lhexEncoder{}, replacements)
 }
 
+// NewASCIIencoder creates encoder that escapes non-printable bytes with \x notation (encoding only)
 func NewASCIIencoder() Encoder {
 	return &asciiEncoder{}
 }
+
+// NewHexdumpEncoder creates encoder that renders data as canonical hexdump (encoding only)
+func NewHexdumpEncoder() Encoder {
+	return &hexdumpEncoder{}
+}
+
+// NewJWEencoder creates encoder of JWE compact tokens with AES-CBC content encryption:
+// decoding gives IV followed by ciphertext, encoding puts them back into the last decoded token
+func NewJWEencoder() Encoder {
+	return &jweEncoder{}
+}
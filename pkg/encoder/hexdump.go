@@ -0,0 +1,15 @@
+package encoder
+
+import "encoding/hex"
+
+// hexdump encoder, produces canonical hexdump (offset, hex, ASCII), as in `hexdump -C`
+type hexdumpEncoder struct{}
+
+func (e hexdumpEncoder) EncodeToString(input []byte) string {
+	return hex.Dump(input)
+}
+
+// ... just to comply with interface
+func (e hexdumpEncoder) DecodeString(input string) ([]byte, error) {
+	panic("Not implemented")
+}
@@ -0,0 +1,76 @@
+package encoder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// length of IV in JWE with AES-CBC content encryption (A128CBC-HS256, A192CBC-HS384, A256CBC-HS512)
+const jweIVLen = 16
+
+// JWE compact serialization: HEADER.ENCRYPTED_KEY.IV.CIPHERTEXT.TAG, all parts are base64url-encoded without padding.
+// decoding gives IV followed by ciphertext, the rest of the token is remembered and put back when encoding,
+// so that tokens of the same structure are produced. Tag is kept as-is, it can not be recomputed without the key
+type jweEncoder struct {
+	mu          sync.RWMutex
+	header, key string
+	tag         string
+}
+
+type jweHeader struct {
+	Alg string `json:"alg"`
+	Enc string `json:"enc"`
+}
+
+func (j *jweEncoder) DecodeString(token string) ([]byte, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 5 {
+		return nil, DecodeError("not a JWE compact token: 5 parts separated by dots expected")
+	}
+
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, DecodeError("JWE header is not base64url-encoded: " + err.Error())
+	}
+	var header jweHeader
+	if err = json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, DecodeError("JWE header is not a JSON: " + err.Error())
+	}
+	if !strings.Contains(header.Enc, "CBC") {
+		return nil, DecodeError("JWE content encryption is not CBC: " + header.Enc)
+	}
+
+	iv, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(iv) != jweIVLen {
+		return nil, DecodeError("JWE IV must be 16 bytes, base64url-encoded")
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, DecodeError("JWE ciphertext is not base64url-encoded: " + err.Error())
+	}
+
+	j.mu.Lock()
+	j.header, j.key, j.tag = parts[0], parts[1], parts[4]
+	j.mu.Unlock()
+
+	return append(iv, ciphertext...), nil
+}
+
+func (j *jweEncoder) EncodeToString(input []byte) string {
+	split := jweIVLen
+	if len(input) < split {
+		split = len(input)
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return strings.Join([]string{
+		j.header,
+		j.key,
+		base64.RawURLEncoding.EncodeToString(input[:split]),
+		base64.RawURLEncoding.EncodeToString(input[split:]),
+		j.tag,
+	}, ".")
+}
@@ -0,0 +1,46 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jweToken(header string, iv, ciphertext []byte) string {
+	b64 := base64.RawURLEncoding.EncodeToString
+	return b64([]byte(header)) + ".a2V5." + b64(iv) + "." + b64(ciphertext) + ".dGFn"
+}
+
+func Test_jweEncoder(t *testing.T) {
+	e := NewJWEencoder()
+	iv := bytes.Repeat([]byte{1}, 16)
+	ciphertext := bytes.Repeat([]byte{2}, 32)
+	token := jweToken(`{"alg":"RSA-OAEP","enc":"A128CBC-HS256"}`, iv, ciphertext)
+
+	decoded, err := e.DecodeString(token)
+	require.NoError(t, err)
+	assert.Equal(t, append(iv, ciphertext...), decoded)
+	assert.Equal(t, token, e.EncodeToString(decoded))
+
+	// the rest of token is kept
+	forged := bytes.Repeat([]byte{3}, 48)
+	assert.Equal(t, jweToken(`{"alg":"RSA-OAEP","enc":"A128CBC-HS256"}`, forged[:16], forged[16:]), e.EncodeToString(forged))
+}
+
+func Test_jweEncoder_Invalid(t *testing.T) {
+	e := NewJWEencoder()
+	iv := bytes.Repeat([]byte{1}, 16)
+
+	for _, token := range []string{
+		"a.b.c",
+		"!!!.a.b.c.d",
+		jweToken(`{"alg":"dir","enc":"A256GCM"}`, iv, iv),
+		jweToken(`{"alg":"dir","enc":"A128CBC-HS256"}`, iv[:12], iv),
+	} {
+		_, err := e.DecodeString(token)
+		assert.Error(t, err, token)
+	}
+}
@@ -0,0 +1,36 @@
+package exploit
+
+import "fmt"
+
+// BitFlip modifies ciphertext (IV included), so that known plaintext at offset turns into want.
+// this is plain CBC malleability, no oracle is involved: bytes of the preceding cipher block are XORed with known XOR want.
+// returns modified ciphertext along with numbers of plaintext blocks that get garbled (those that precede the edited ones)
+func BitFlip(ciphertext []byte, blockLen, offset int, known, want []byte) ([]byte, []int, error) {
+	if len(ciphertext)%blockLen != 0 || len(ciphertext) < 2*blockLen {
+		return nil, nil, fmt.Errorf("ciphertext length (%d) must be a multiple of block length (%d), at least 2 blocks with IV", len(ciphertext), blockLen)
+	}
+	if len(known) != len(want) {
+		return nil, nil, fmt.Errorf("known and wanted plaintexts must be of the same length (%d != %d)", len(known), len(want))
+	}
+	if offset < 0 || offset+len(known) > len(ciphertext)-blockLen {
+		return nil, nil, fmt.Errorf("plaintext at offset %d does not fit into %d bytes of ciphertext", offset, len(ciphertext)-blockLen)
+	}
+
+	flipped := append([]byte{}, ciphertext...)
+	var garbled []int
+	for i := range known {
+		diff := known[i] ^ want[i]
+		if diff == 0 {
+			continue
+		}
+
+		// byte of plaintext is flipped via the same byte of preceding cipher block,
+		// which in turn garbles its own plaintext (unless it's IV)
+		pos := offset + i
+		flipped[pos] ^= diff
+		if block := pos/blockLen - 1; block >= 0 && (len(garbled) == 0 || garbled[len(garbled)-1] != block) {
+			garbled = append(garbled, block)
+		}
+	}
+	return flipped, garbled, nil
+}
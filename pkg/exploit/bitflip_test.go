@@ -0,0 +1,52 @@
+package exploit
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitFlip(t *testing.T) {
+	block, err := aes.NewCipher(util.RandomSlice(16))
+	require.NoError(t, err)
+
+	plaintext := PKCS7.Pad([]byte("user=bob;role=user;uid=1000;name=x"), 16)
+	ciphertext := util.RandomSlice(16 + len(plaintext))
+	cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+	decrypt := func(c []byte) []byte {
+		decrypted := make([]byte, len(c)-16)
+		cipher.NewCBCDecrypter(block, c[:16]).CryptBlocks(decrypted, c[16:])
+		return decrypted
+	}
+
+	// first block is flipped via IV, nothing is garbled
+	flipped, garbled, err := BitFlip(ciphertext, 16, 5, []byte("bob"), []byte("eve"))
+	require.NoError(t, err)
+	assert.Empty(t, garbled)
+	assert.Equal(t, "user=eve;role=user;uid=1000;name=x", string(decrypt(flipped)[:34]))
+
+	// second block is flipped via the first one, which gets garbled
+	flipped, garbled, err = BitFlip(ciphertext, 16, 23, []byte("1000"), []byte("0000"))
+	require.NoError(t, err)
+	assert.Equal(t, []int{0}, garbled)
+	assert.Equal(t, "0000;name=x", string(decrypt(flipped)[23:34]))
+
+	for _, tc := range []struct {
+		offset      int
+		known, want string
+	}{
+		{0, "user", "root1"},
+		{-1, "u", "r"},
+		{40, "xxxxxxxxxx", "yyyyyyyyyy"},
+	} {
+		_, _, err := BitFlip(ciphertext, 16, tc.offset, []byte(tc.known), []byte(tc.want))
+		assert.Error(t, err, tc)
+	}
+	_, _, err = BitFlip(ciphertext[:20], 16, 0, []byte("u"), []byte("r"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package exploit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/glebarez/padre/pkg/client"
+)
+
+// BlockStats - metrics of one block, see Padre.BlockStats
+type BlockStats struct {
+	// number of block, as in error messages:
+	// block of ciphertext when decrypting (IV is the 1st), block of plaintext when encrypting
+	Block int
+
+	Requests  int64         // requests sent to the oracle, responses from cache are not counted
+	Retries   int32         // byte positions retried, see Padre.Retries
+	Elapsed   time.Duration // time it took to break the block
+	Ambiguous bool          // last byte had more than one valid value, and was disambiguated
+	Known     bool          // intermediate was known (see Padre.Intermediates), block took no requests
+}
+
+type blockStatsKey struct{}
+
+// returns context, where stats of the block are collected
+func withBlockStats(ctx context.Context, stats *BlockStats) context.Context {
+	ctx = client.WithRequestCounter(ctx, &stats.Requests)
+	return context.WithValue(ctx, blockStatsKey{}, stats)
+}
+
+// stats of the block that is broken within context, nil if not collected
+func blockStatsOf(ctx context.Context) *BlockStats {
+	stats, _ := ctx.Value(blockStatsKey{}).(*BlockStats)
+	return stats
+}
+
+// copy of stats, safe to pass on while probes of the block (cancelled ones) are still being counted
+func (s *BlockStats) snapshot() *BlockStats {
+	return &BlockStats{
+		Block:     s.Block,
+		Requests:  atomic.LoadInt64(&s.Requests),
+		Retries:   atomic.LoadInt32(&s.Retries),
+		Elapsed:   s.Elapsed,
+		Ambiguous: s.Ambiguous,
+		Known:     s.Known,
+	}
+}
+
+// counts retry of byte position
+func countRetry(ctx context.Context) {
+	if stats := blockStatsOf(ctx); stats != nil {
+		atomic.AddInt32(&stats.Retries, 1)
+	}
+}
+
+// marks the block as having ambiguous last byte
+func markAmbiguous(ctx context.Context) {
+	if stats := blockStatsOf(ctx); stats != nil {
+		stats.Ambiguous = true
+	}
+}
@@ -0,0 +1,44 @@
+package exploit
+
+import (
+	"fmt"
+
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// ByteRange - positions within every block of plaintext, that are to be recovered (both ends included).
+// bytes are recovered from the end of block, so those after the range are recovered along the way,
+// while those in front of it are not probed at all, and come out as zeroes
+type ByteRange struct {
+	From, To int
+}
+
+// ParseByteRange parses range of byte positions in form FROM-TO (or a single position), e.g. "0-7" or "12"
+func ParseByteRange(s string) (*ByteRange, error) {
+	from, to, err := util.ParseIntRange(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid byte range: %w", err)
+	}
+	return &ByteRange{From: from, To: to}, nil
+}
+
+func (r *ByteRange) String() string {
+	return fmt.Sprintf("%d-%d", r.From, r.To)
+}
+
+// number of leading bytes of block, that are not recovered.
+// applies to decryption only, i.e. when plaintext is guessed
+func (p *Padre) skipped(guess *plainGuess) int {
+	if p.ByteRange == nil || guess == nil {
+		return 0
+	}
+	return p.ByteRange.From
+}
+
+// checks ByteRange against block length
+func (p *Padre) checkByteRange() error {
+	if p.ByteRange != nil && p.ByteRange.To >= p.BlockLen {
+		return inputError{fmt.Errorf("Byte range %s is out of block (block length is %d)", p.ByteRange, p.BlockLen)}
+	}
+	return nil
+}
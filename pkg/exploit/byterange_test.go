@@ -0,0 +1,59 @@
+package exploit
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteRange(t *testing.T) {
+	r, err := ParseByteRange("0-7")
+	require.NoError(t, err)
+	assert.Equal(t, &ByteRange{From: 0, To: 7}, r)
+
+	r, err = ParseByteRange("12")
+	require.NoError(t, err)
+	assert.Equal(t, &ByteRange{From: 12, To: 12}, r)
+	assert.Equal(t, "12-12", r.String())
+
+	for _, s := range []string{"", "a-b", "7-0", "-1-3", "3-"} {
+		_, err = ParseByteRange(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestDecrypt_ByteRange(t *testing.T) {
+	s := newSimulation(1, PKCS7)
+	plaintext := []byte("user=alice;role=admin;expires=1700000000")
+	ciphertext := s.encrypt(plaintext)
+	padded := PKCS7.Pad(plaintext, 16)
+
+	for _, parallel := range []int{1, 3} {
+		p := &Padre{Oracle: s.oracle, BlockLen: 16, BlockParallel: parallel, ByteRange: &ByteRange{From: 10, To: 15}}
+		queries := s.oracle.Queries()
+
+		decrypted, err := p.Decrypt(context.Background(), ciphertext, nil)
+		require.NoError(t, err)
+		require.Len(t, decrypted, len(padded))
+		for i := 0; i < len(padded); i += 16 {
+			assert.Equal(t, make([]byte, 10), decrypted[i:i+10], "block at %d", i)
+			assert.True(t, bytes.Equal(padded[i+10:i+16], decrypted[i+10:i+16]), "block at %d", i)
+		}
+
+		// bytes in front of the range are not probed
+		full := &Padre{Oracle: s.oracle, BlockLen: 16}
+		spent := s.oracle.Queries() - queries
+		queries = s.oracle.Queries()
+		_, err = full.Decrypt(context.Background(), ciphertext, nil)
+		require.NoError(t, err)
+		assert.Less(t, spent, s.oracle.Queries()-queries)
+	}
+
+	// range must fit the block
+	p := &Padre{Oracle: s.oracle, BlockLen: 16, ByteRange: &ByteRange{From: 8, To: 16}}
+	_, err := p.Decrypt(context.Background(), ciphertext, nil)
+	assert.Equal(t, StopInput, ReasonOf(err), err)
+}
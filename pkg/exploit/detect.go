@@ -0,0 +1,54 @@
+package exploit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// number of bytes to break when testing padding scheme
+const paddingProbeLen = 3
+
+// DetectPadding detects padding scheme used by the oracle.
+// Schemes are distinguished by behavior: when only the last byte of padding is verified,
+// blockLen values of the last byte are valid (ISO10126 is returned).
+// otherwise, every scheme is tried on few trailing bytes of random block, the one that works is returned
+func (p *Padre) DetectPadding(ctx context.Context) (Padding, error) {
+	blockLen := p.BlockLen
+	block := util.RandomSlice(blockLen)
+
+	// count valid values of the last byte
+	chunk := append(util.RandomSlice(blockLen), block...)
+	found, err := p.getErrorlessByteValues(ctx, chunk, blockLen-1, nil, 256)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(found) == 0:
+		return nil, fmt.Errorf("no valid padding was produced by any value of the last byte")
+	case len(found) == blockLen:
+		return ISO10126, nil
+	case len(found) > 2:
+		return nil, fmt.Errorf("unexpected count of valid values of the last byte: %d", len(found))
+	}
+
+	// try schemes which verify every byte of padding
+	for _, padding := range []Padding{PKCS7, ANSIX923, ISO7816} {
+		probe := *p
+		probe.Padding = padding
+		probe.Retries = 0 // wrong scheme fails for sure, no need to retry
+
+		_, err := probe.breakBytes(ctx, block, paddingProbeLen, nil, nil)
+		if err == nil {
+			return padding, nil
+		}
+		if !errors.Is(err, errNoValidByte) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("padding scheme was not recognized")
+}
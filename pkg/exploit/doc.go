@@ -0,0 +1,5 @@
+// Package exploit implements Padding Oracle attack against CBC mode encryption.
+// Padre is the entry point: it decrypts ciphertexts and encrypts arbitrary plaintexts
+// using a padding oracle, reachable via client and recognized with matcher.
+// Everything an attack depends on is held by Padre, so independent attacks may run concurrently in one process.
+package exploit
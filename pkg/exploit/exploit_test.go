@@ -0,0 +1,131 @@
+package exploit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// block cipher, that XORs with the key: intermediate of any block is known in advance
+type xorBlock []byte
+
+func (k xorBlock) BlockSize() int { return len(k) }
+
+func (k xorBlock) Encrypt(dst, src []byte) {
+	for i := range k {
+		dst[i] = src[i] ^ k[i]
+	}
+}
+
+func (k xorBlock) Decrypt(dst, src []byte) { k.Encrypt(dst, src) }
+
+func pkcs7Valid(plaintext []byte) bool {
+	_, ok := PKCS7.Unpad(plaintext, 16)
+	return ok
+}
+
+func TestBreakCipher_AmbiguousLastByte(t *testing.T) {
+	tests := []struct {
+		name string
+		// plaintext bytes at given positions, produced by IV of the first probed chunk,
+		// those make a longer padding valid along with \x01
+		accidental map[int]byte
+		ambiguous  bool
+	}{
+		{"no accidental padding", nil, false},
+		{"\\x02\\x02", map[int]byte{14: 2}, true},
+		{"\\x03\\x03\\x03", map[int]byte{13: 3, 14: 3}, true},
+		{"full block of padding", map[int]byte{0: 16, 1: 16, 2: 16, 3: 16, 4: 16, 5: 16, 6: 16, 7: 16,
+			8: 16, 9: 16, 10: 16, 11: 16, 12: 16, 13: 16, 14: 16}, true},
+		{"incomplete padding", map[int]byte{14: 3}, false},
+	}
+
+	for _, tt := range tests {
+		for _, order := range []Order{OrderSequential, OrderFrequency, OrderRandom} {
+			cipherBlock := util.RandomSlice(16)
+			prefix := blockPrefix(cipherBlock)
+
+			// intermediate is chosen, so that the first chunk decrypts into the accidental bytes (zeroes elsewhere)
+			intermediate := append([]byte{}, prefix...)
+			for pos, b := range tt.accidental {
+				intermediate[pos] ^= b
+			}
+			intermediate[15] = util.RandomSlice(1)[0]
+			key := make(xorBlock, 16)
+			for i := range key {
+				key[i] = cipherBlock[i] ^ intermediate[i]
+			}
+
+			oracle := &client.MockOracle{Block: key, Valid: pkcs7Valid}
+			p := &Padre{Oracle: oracle, BlockLen: 16, Order: order}
+
+			stats := &BlockStats{}
+			broken, err := p.breakCipher(withBlockStats(context.Background(), stats), cipherBlock, nil, nil)
+			require.NoError(t, err, tt.name)
+			assert.Equal(t, intermediate, broken, tt.name)
+			assert.Equal(t, tt.ambiguous, stats.Ambiguous, "%s, order %s", tt.name, order)
+			assert.EqualValues(t, oracle.Queries(), stats.Requests, tt.name)
+
+			// sequential probing is deterministic
+			if order == OrderSequential {
+				again := &client.MockOracle{Block: key, Valid: pkcs7Valid}
+				p.Oracle = again
+				_, err := p.breakCipher(context.Background(), cipherBlock, nil, nil)
+				require.NoError(t, err)
+				assert.Equal(t, oracle.Queries(), again.Queries(), tt.name)
+			}
+		}
+	}
+}
+
+func TestBreakCipher_AmbiguityUnresolved(t *testing.T) {
+	cipherBlock := util.RandomSlice(16)
+	intermediate := util.RandomSlice(16)
+	intermediate[14] = blockPrefix(cipherBlock)[14] ^ 2
+	key := make(xorBlock, 16)
+	for i := range key {
+		key[i] = cipherBlock[i] ^ intermediate[i]
+	}
+
+	// server rejects anything but \x02 before the last byte, so that neither of ambiguous values
+	// survives modification of preceding byte
+	oracle := &client.MockOracle{Block: key, Valid: func(plaintext []byte) bool {
+		return pkcs7Valid(plaintext) && plaintext[14] == 2
+	}}
+	p := &Padre{Oracle: oracle, BlockLen: 16, Order: OrderSequential}
+
+	_, err := p.breakCipher(context.Background(), cipherBlock, nil, nil)
+	assert.True(t, errors.Is(err, errNoValidByte), err)
+	assert.Contains(t, err.Error(), "unexpected server behavior")
+
+	// retries start over with fresh bytes in front of the position, and give up all the same
+	p.Retries = 2
+	_, err = p.breakCipher(context.Background(), cipherBlock, nil, nil)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, errNoValidByte), err)
+	assert.Contains(t, err.Error(), "gave up after 2 retries")
+}
+
+func TestDecrypt_MockOracle(t *testing.T) {
+	key := xorBlock(util.RandomSlice(16))
+	plaintext := PKCS7.Pad([]byte("decrypted without any HTTP"), 16)
+	ciphertext := util.RandomSlice(16 + len(plaintext))
+	for i := 16; i < len(ciphertext); i += 16 {
+		block := make([]byte, 16)
+		for j := range block {
+			block[j] = plaintext[i-16+j] ^ ciphertext[i-16+j]
+		}
+		key.Encrypt(ciphertext[i:i+16], block)
+	}
+
+	p := &Padre{Oracle: &client.MockOracle{Block: key, Valid: pkcs7Valid}, BlockLen: 16}
+	decrypted, err := p.Decrypt(context.Background(), ciphertext, nil)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(plaintext, decrypted))
+}
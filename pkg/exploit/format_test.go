@@ -0,0 +1,74 @@
+package exploit
+
+import (
+	"context"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatByName(t *testing.T) {
+	for _, format := range formats {
+		parsed, err := FormatByName(format.String())
+		require.NoError(t, err)
+		assert.Equal(t, format, parsed)
+		assert.Len(t, format.likely, 256)
+	}
+
+	_, err := FormatByName("xml")
+	assert.Error(t, err)
+}
+
+func TestFormat_Suspicious(t *testing.T) {
+	assert.Empty(t, FormatJSON.suspicious([]byte("{\"a\": [1, 2]}\n"), false, PKCS7))
+	assert.Equal(t, []int{2}, FormatURLEncoded.suspicious([]byte("a=\x00&b"), false, PKCS7))
+	assert.Equal(t, []int{3, 4}, FormatJWT.suspicious([]byte("eyJ/+"), false, PKCS7))
+
+	// padding is not suspicious, but bytes in front of it are
+	assert.Empty(t, FormatJWT.suspicious(PKCS7.Pad([]byte("eyJ"), 16), true, PKCS7))
+	assert.Equal(t, []int{0}, FormatJWT.suspicious(PKCS7.Pad([]byte("\x01yJ"), 16), true, PKCS7))
+}
+
+func TestVerifyFormat(t *testing.T) {
+	server, block := newOracleServer(t, PKCS7, 0)
+	defer server.Close()
+
+	plaintext := []byte(`{"role":"admin"}`)
+	ciphertext := util.RandomSlice(32)
+	cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+	p := newTestPadre(t, server.URL)
+	p.Format = FormatJSON
+	guess := p.guess(ciphertext[:16], 0)
+
+	// correct intermediate passes as is
+	intermediate := xorSlices(plaintext, ciphertext[:16])
+	verified, err := p.verifyFormat(context.Background(), ciphertext[16:], intermediate, guess, false)
+	require.NoError(t, err)
+	assert.Equal(t, intermediate, verified)
+
+	// wrong byte that does not fit the format is caught and corrected
+	wrong := append([]byte{}, intermediate...)
+	wrong[0] ^= '{' ^ 0x01
+	verified, err = p.verifyFormat(context.Background(), ciphertext[16:], wrong, guess, false)
+	require.NoError(t, err)
+	assert.Equal(t, intermediate, verified)
+}
+
+func TestDecrypt_Format(t *testing.T) {
+	server, block := newOracleServer(t, PKCS7, 0)
+	defer server.Close()
+
+	plaintext := PKCS7.Pad([]byte(`{"id":1,"admin":true}`), 16)
+	ciphertext := util.RandomSlice(16 + len(plaintext))
+	cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+	p := newTestPadre(t, server.URL)
+	p.Format = FormatJSON
+	decrypted, err := p.Decrypt(context.Background(), ciphertext, nil)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
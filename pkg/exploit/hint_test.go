@@ -0,0 +1,60 @@
+package exploit
+
+import (
+	"context"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHint(t *testing.T) {
+	known, err := ParseHint("user=")
+	require.NoError(t, err)
+	assert.Equal(t, map[int]byte{0: 'u', 1: 's', 2: 'e', 3: 'r', 4: '='}, known)
+
+	known, err = ParseHint("16:a:b")
+	require.NoError(t, err)
+	assert.Equal(t, map[int]byte{16: 'a', 17: ':', 18: 'b'}, known)
+
+	// not an offset
+	known, err = ParseHint("id:1")
+	require.NoError(t, err)
+	assert.Equal(t, byte('i'), known[0])
+
+	for _, hint := range []string{"", "5:", "-1:abc"} {
+		_, err = ParseHint(hint)
+		assert.Error(t, err, hint)
+	}
+}
+
+func TestDecrypt_Hints(t *testing.T) {
+	server, block := newOracleServer(t, PKCS7, 0)
+	defer server.Close()
+
+	plaintext := PKCS7.Pad([]byte("user=admin"), 16)
+	ciphertext := util.RandomSlice(32)
+	cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+	decrypt := func(hint string) int {
+		hints, err := ParseHint(hint)
+		require.NoError(t, err)
+
+		p := newTestPadre(t, server.URL)
+		p.Hints = hints
+		p.Client.RequestEventChan = make(chan byte, 10000)
+
+		decrypted, err := p.Decrypt(context.Background(), ciphertext, nil)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+		return len(p.Client.RequestEventChan)
+	}
+
+	// validation of input, then single request per byte (and one to disambiguate the last byte)
+	assert.Equal(t, 18, decrypt("user=admin\x06\x06\x06\x06\x06\x06"))
+
+	// wrong hints do not break decryption
+	decrypt("root=")
+}
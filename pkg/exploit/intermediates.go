@@ -0,0 +1,132 @@
+package exploit
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Intermediates holds intermediate values of cipher blocks (the block decrypted with the key, before XOR with preceding block),
+// learned while breaking blocks. they depend only on the key and the block, so they are valid for every ciphertext
+// of the same target: known blocks are not broken again (see Padre.Intermediates). safe for concurrent use
+type Intermediates struct {
+	mx     sync.Mutex
+	values map[string][]byte
+	final  [][]byte // blocks that ended decrypted ciphertexts, in order of decryption
+
+	// Added is called (if set) after intermediate value of new block is stored, e.g. to save the store as it grows
+	Added func()
+}
+
+// NewIntermediates creates empty store of intermediate values
+func NewIntermediates() *Intermediates {
+	return &Intermediates{values: make(map[string][]byte)}
+}
+
+// Get returns intermediate value of cipher block, if known
+func (s *Intermediates) Get(block []byte) ([]byte, bool) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	intermediate, ok := s.values[string(block)]
+	return intermediate, ok
+}
+
+// Put stores intermediate value of cipher block. final tells whether the block ended decrypted ciphertext
+func (s *Intermediates) Put(block, intermediate []byte, final bool) {
+	s.mx.Lock()
+	_, known := s.values[string(block)]
+	if !known && final {
+		s.final = append(s.final, append([]byte{}, block...))
+	}
+	s.values[string(block)] = append([]byte{}, intermediate...)
+	s.mx.Unlock()
+
+	if !known && s.Added != nil {
+		s.Added()
+	}
+}
+
+// LastFinal returns the last block of the most recently decrypted ciphertext (nil if none).
+// encryption starts with it, so that plaintext, which ends like the decrypted one, reuses its blocks
+func (s *Intermediates) LastFinal(blockLen int) []byte {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	for i := len(s.final) - 1; i >= 0; i-- {
+		if len(s.final[i]) == blockLen {
+			return s.final[i]
+		}
+	}
+	return nil
+}
+
+// Len returns number of known blocks
+func (s *Intermediates) Len() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return len(s.values)
+}
+
+// serialized form: blocks and their intermediates in hex
+type intermediatesJSON struct {
+	Blocks map[string]string `json:"blocks"`
+	Final  []string          `json:"final,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (s *Intermediates) MarshalJSON() ([]byte, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	data := intermediatesJSON{Blocks: make(map[string]string, len(s.values))}
+	for block, intermediate := range s.values {
+		data.Blocks[hex.EncodeToString([]byte(block))] = hex.EncodeToString(intermediate)
+	}
+	for _, block := range s.final {
+		data.Final = append(data.Final, hex.EncodeToString(block))
+	}
+	return json.Marshal(&data)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, loaded values are added to those already known
+func (s *Intermediates) UnmarshalJSON(b []byte) error {
+	var data intermediatesJSON
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+
+	values := make(map[string][]byte, len(data.Blocks))
+	for block, intermediate := range data.Blocks {
+		b, err := hex.DecodeString(block)
+		if err != nil {
+			return fmt.Errorf("invalid cipher block %q: %w", block, err)
+		}
+		i, err := hex.DecodeString(intermediate)
+		if err != nil || len(i) != len(b) {
+			return fmt.Errorf("invalid intermediate of cipher block %s", block)
+		}
+		values[string(b)] = i
+	}
+
+	var final [][]byte
+	for _, block := range data.Final {
+		b, err := hex.DecodeString(block)
+		if err != nil {
+			return fmt.Errorf("invalid final cipher block %q: %w", block, err)
+		}
+		if _, ok := values[string(b)]; ok {
+			final = append(final, b)
+		}
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if s.values == nil {
+		s.values = make(map[string][]byte)
+	}
+	for block, intermediate := range values {
+		s.values[block] = intermediate
+	}
+	s.final = append(s.final, final...)
+	return nil
+}
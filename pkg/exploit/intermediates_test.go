@@ -0,0 +1,80 @@
+package exploit
+
+import (
+	"context"
+	"crypto/cipher"
+	"encoding/json"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntermediates_JSON(t *testing.T) {
+	s := NewIntermediates()
+	s.Put([]byte{1, 2}, []byte{3, 4}, false)
+	s.Put([]byte{5, 6}, []byte{7, 8}, true)
+
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	loaded := NewIntermediates()
+	require.NoError(t, json.Unmarshal(data, loaded))
+	assert.Equal(t, 2, loaded.Len())
+	intermediate, ok := loaded.Get([]byte{1, 2})
+	assert.True(t, ok)
+	assert.Equal(t, []byte{3, 4}, intermediate)
+	assert.Equal(t, []byte{5, 6}, loaded.LastFinal(2))
+	assert.Nil(t, loaded.LastFinal(16))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"blocks":{"0102":"03"}}`), loaded))
+}
+
+func TestIntermediates_Added(t *testing.T) {
+	s := NewIntermediates()
+	added := 0
+	s.Added = func() { added++ }
+
+	s.Put([]byte{1, 2}, []byte{3, 4}, false)
+	s.Put([]byte{1, 2}, []byte{3, 4}, true)
+	s.Put([]byte{5, 6}, []byte{7, 8}, false)
+	assert.Equal(t, 2, added)
+}
+
+func TestEncrypt_Intermediates(t *testing.T) {
+	server, block := newOracleServer(t, PKCS7, 0)
+	defer server.Close()
+
+	plaintext := PKCS7.Pad([]byte("user=alice;role=guest;team=blue!"), 16)
+	ciphertext := util.RandomSlice(16 + len(plaintext))
+	cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+	p := newTestPadre(t, server.URL)
+	p.Client.Stats = &client.Stats{}
+	p.Intermediates = NewIntermediates()
+
+	_, err := p.Decrypt(context.Background(), ciphertext, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, p.Intermediates.Len())
+	requests := p.Client.Stats.Snapshot().Requests
+
+	// only the first block is changed, every block of decrypted ciphertext is reused
+	forged, err := p.Encrypt(context.Background(), "user=admin;role=guest;team=blue!", nil)
+	require.NoError(t, err)
+	assert.Equal(t, requests, p.Client.Stats.Snapshot().Requests)
+	assert.Equal(t, ciphertext[16:], forged[16:])
+
+	decrypted := make([]byte, len(forged)-16)
+	cipher.NewCBCDecrypter(block, forged[:16]).CryptBlocks(decrypted, forged[16:])
+	assert.Equal(t, PKCS7.Pad([]byte("user=admin;role=guest;team=blue!"), 16), decrypted)
+
+	// changed last block costs the blocks before it
+	forged, err = p.Encrypt(context.Background(), "user=alice;role=guest;team=red!!", nil)
+	require.NoError(t, err)
+	assert.Greater(t, p.Client.Stats.Snapshot().Requests, requests)
+	decrypted = make([]byte, len(forged)-16)
+	cipher.NewCBCDecrypter(block, forged[:16]).CryptBlocks(decrypted, forged[16:])
+	assert.Equal(t, PKCS7.Pad([]byte("user=alice;role=guest;team=red!!"), 16), decrypted)
+}
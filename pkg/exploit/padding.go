@@ -0,0 +1,173 @@
+package exploit
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// Padding is a block cipher padding scheme
+type Padding interface {
+	// Name of the scheme
+	Name() string
+
+	// Pad pads input to the multiple of blockLen
+	Pad(input []byte, blockLen int) []byte
+
+	// Unpad removes padding. returns false if padding is not valid
+	Unpad(input []byte, blockLen int) ([]byte, bool)
+
+	// Tail returns plaintext bytes that form a valid padding of length n.
+	// nil is returned if the scheme verifies only the last byte of padding,
+	// in which case bytes beyond the last one cannot be recovered via padding oracle
+	Tail(n int) []byte
+}
+
+// supported padding schemes
+var (
+	PKCS7    Padding = pkcs7Padding{}
+	ANSIX923 Padding = ansix923Padding{}
+	ISO10126 Padding = iso10126Padding{}
+	ISO7816  Padding = iso7816Padding{}
+)
+
+// PaddingByName returns padding scheme by its name (case-insensitive)
+func PaddingByName(name string) (Padding, error) {
+	for _, padding := range []Padding{PKCS7, ANSIX923, ISO10126, ISO7816} {
+		if strings.EqualFold(padding.Name(), name) {
+			return padding, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported padding scheme: %s", name)
+}
+
+// whether padding length is not stored explicitly, but derived from contents.
+// such paddings may be valid with different lengths at the same time
+func hasImplicitLength(padding Padding) bool {
+	_, ok := padding.(iso7816Padding)
+	return ok
+}
+
+// padding length for input
+func padLen(input []byte, blockLen int) int {
+	return blockLen - len(input)%blockLen
+}
+
+// padding length, stored in last byte of input. returns 0 if it is out of valid range
+func storedPadLen(input []byte, blockLen int) int {
+	if len(input) == 0 {
+		return 0
+	}
+
+	n := int(input[len(input)-1])
+	if n == 0 || n > blockLen || n > len(input) {
+		return 0
+	}
+	return n
+}
+
+// PKCS#7: every byte of padding is the length of padding
+type pkcs7Padding struct{}
+
+func (pkcs7Padding) Name() string {
+	return "pkcs7"
+}
+
+func (pkcs7Padding) Pad(input []byte, blockLen int) []byte {
+	return []byte(Pkcs7Pad(string(input), blockLen))
+}
+
+func (pkcs7Padding) Unpad(input []byte, blockLen int) ([]byte, bool) {
+	return Pkcs7Unpad(input, blockLen)
+}
+
+func (pkcs7Padding) Tail(n int) []byte {
+	return bytes.Repeat([]byte{byte(n)}, n)
+}
+
+// ANSI X9.23: zero bytes, followed by the length of padding
+type ansix923Padding struct{}
+
+func (ansix923Padding) Name() string {
+	return "ansix923"
+}
+
+func (p ansix923Padding) Pad(input []byte, blockLen int) []byte {
+	return append(append([]byte{}, input...), p.Tail(padLen(input, blockLen))...)
+}
+
+func (p ansix923Padding) Unpad(input []byte, blockLen int) ([]byte, bool) {
+	n := storedPadLen(input, blockLen)
+	if n == 0 || !bytes.HasSuffix(input, p.Tail(n)) {
+		return nil, false
+	}
+	return input[:len(input)-n], true
+}
+
+func (ansix923Padding) Tail(n int) []byte {
+	tail := make([]byte, n)
+	tail[n-1] = byte(n)
+	return tail
+}
+
+// ISO 10126: random bytes, followed by the length of padding
+type iso10126Padding struct{}
+
+func (iso10126Padding) Name() string {
+	return "iso10126"
+}
+
+func (iso10126Padding) Pad(input []byte, blockLen int) []byte {
+	n := padLen(input, blockLen)
+	tail := util.RandomSlice(n)
+	tail[n-1] = byte(n)
+	return append(append([]byte{}, input...), tail...)
+}
+
+func (iso10126Padding) Unpad(input []byte, blockLen int) ([]byte, bool) {
+	n := storedPadLen(input, blockLen)
+	if n == 0 {
+		return nil, false
+	}
+	return input[:len(input)-n], true
+}
+
+func (iso10126Padding) Tail(n int) []byte {
+	// random bytes are not verified
+	if n > 1 {
+		return nil
+	}
+	return []byte{1}
+}
+
+// ISO/IEC 7816-4: 0x80 byte, followed by zero bytes
+type iso7816Padding struct{}
+
+func (iso7816Padding) Name() string {
+	return "iso7816"
+}
+
+func (p iso7816Padding) Pad(input []byte, blockLen int) []byte {
+	return append(append([]byte{}, input...), p.Tail(padLen(input, blockLen))...)
+}
+
+func (iso7816Padding) Unpad(input []byte, blockLen int) ([]byte, bool) {
+	for i := len(input) - 1; i >= 0 && i >= len(input)-blockLen; i-- {
+		switch input[i] {
+		case 0:
+			continue
+		case 0x80:
+			return input[:i], true
+		}
+		break
+	}
+	return nil, false
+}
+
+func (iso7816Padding) Tail(n int) []byte {
+	tail := make([]byte, n)
+	tail[0] = 0x80
+	return tail
+}
@@ -0,0 +1,151 @@
+package exploit
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/glebarez/padre/pkg/probe"
+	"github.com/glebarez/padre/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaddings(t *testing.T) {
+	for _, padding := range []Padding{PKCS7, ANSIX923, ISO10126, ISO7816} {
+		for _, input := range []string{"", "a", "exactly 16 bytes", "some longer input, spanning several blocks"} {
+			padded := padding.Pad([]byte(input), 16)
+			assert.Zero(t, len(padded)%16, padding.Name())
+
+			unpadded, ok := padding.Unpad(padded, 16)
+			assert.True(t, ok, padding.Name())
+			assert.Equal(t, input, string(unpadded), padding.Name())
+		}
+
+		found, err := PaddingByName(padding.Name())
+		require.NoError(t, err)
+		assert.Equal(t, padding, found)
+	}
+
+	_, ok := ANSIX923.Unpad([]byte{1, 1, 2}, 16)
+	assert.False(t, ok)
+
+	assert.Equal(t, []byte{3, 3, 3}, PKCS7.Tail(3))
+	assert.Equal(t, []byte{0, 0, 3}, ANSIX923.Tail(3))
+	assert.Nil(t, ISO10126.Tail(3))
+	assert.Equal(t, []byte{0x80, 0, 0}, ISO7816.Tail(3))
+
+	_, ok = ISO7816.Unpad([]byte{1, 0x80, 0, 1}, 16)
+	assert.False(t, ok)
+
+	_, err := PaddingByName("zero")
+	assert.Error(t, err)
+}
+
+// creates server that decrypts AES-CBC ciphertext from query, and responds with 500 on invalid padding.
+// to simulate unstable oracle, first falseErrors of valid paddings are responded with 500 as well
+func newOracleServer(t *testing.T, padding Padding, falseErrors int32) (*httptest.Server, cipher.Block) {
+	block, err := aes.NewCipher(util.RandomSlice(16))
+	require.NoError(t, err)
+
+	enc := encoder.NewLHEXencoder("")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ciphertext, err := enc.DecodeString(r.URL.Query().Get("c"))
+		if err != nil || len(ciphertext) < 32 || len(ciphertext)%16 != 0 {
+			w.WriteHeader(400)
+			return
+		}
+
+		plaintext := make([]byte, len(ciphertext)-16)
+		cipher.NewCBCDecrypter(block, ciphertext[:16]).CryptBlocks(plaintext, ciphertext[16:])
+		if _, ok := padding.Unpad(plaintext, 16); !ok || atomic.AddInt32(&falseErrors, -1) >= 0 {
+			w.WriteHeader(500)
+		}
+	}))
+	return server, block
+}
+
+func newTestPadre(t *testing.T, url string) *Padre {
+	matcher, err := probe.NewMatcherByStatusCode([]int{500})
+	require.NoError(t, err)
+
+	return &Padre{
+		Client: &client.Client{
+			HTTPclient:        http.DefaultClient,
+			URL:               url + "/?c=$",
+			CipherPlaceholder: "$",
+			Encoder:           encoder.NewLHEXencoder(""),
+			Concurrency:       16,
+		},
+		Matcher:  matcher,
+		BlockLen: 16,
+	}
+}
+
+func TestPaddingSchemes(t *testing.T) {
+	for _, padding := range []Padding{PKCS7, ANSIX923, ISO7816} {
+		server, block := newOracleServer(t, padding, 0)
+		defer server.Close()
+
+		p := newTestPadre(t, server.URL)
+
+		detected, err := p.DetectPadding(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, padding, detected)
+		p.Padding = detected
+
+		// encrypt, then decrypt back
+		plaintext := "forged plaintext, more than one block"
+		ciphertext, err := p.Encrypt(context.Background(), plaintext, nil)
+		require.NoError(t, err)
+
+		decrypted := make([]byte, len(ciphertext)-16)
+		cipher.NewCBCDecrypter(block, ciphertext[:16]).CryptBlocks(decrypted, ciphertext[16:])
+		unpadded, ok := padding.Unpad(decrypted, 16)
+		require.True(t, ok, padding.Name())
+		assert.Equal(t, plaintext, string(unpadded))
+
+		decrypted, err = p.Decrypt(context.Background(), ciphertext, nil)
+		require.NoError(t, err)
+		unpadded, _ = padding.Unpad(decrypted, 16)
+		assert.Equal(t, plaintext, string(unpadded))
+	}
+
+	// only the last byte is verified
+	server, _ := newOracleServer(t, ISO10126, 0)
+	defer server.Close()
+
+	p := newTestPadre(t, server.URL)
+	detected, err := p.DetectPadding(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ISO10126, detected)
+}
+
+func TestRetries(t *testing.T) {
+	// first valid paddings are reported as errors
+	server, _ := newOracleServer(t, PKCS7, 3)
+	defer server.Close()
+
+	p := newTestPadre(t, server.URL)
+	_, err := p.Encrypt(context.Background(), "a", nil)
+	assert.Error(t, err)
+
+	// every attempt consumes at least one false error
+	server, block := newOracleServer(t, PKCS7, 3)
+	defer server.Close()
+
+	p = newTestPadre(t, server.URL)
+	p.Retries = 3
+	ciphertext, err := p.Encrypt(context.Background(), "a", nil)
+	require.NoError(t, err)
+
+	decrypted := make([]byte, len(ciphertext)-16)
+	cipher.NewCBCDecrypter(block, ciphertext[:16]).CryptBlocks(decrypted, ciphertext[16:])
+	assert.Equal(t, PKCS7.Pad([]byte("a"), 16), decrypted)
+}
@@ -1,12 +1,112 @@
 package exploit
 
 import (
+	"time"
+
 	"github.com/glebarez/padre/pkg/client"
 	"github.com/glebarez/padre/pkg/probe"
 )
 
+// Padre - padding oracle exploiter.
+// Client delivers ciphers to the oracle, Matcher recognizes padding errors in responses
 type Padre struct {
 	Client   *client.Client
 	Matcher  probe.PaddingErrorMatcher
 	BlockLen int
+	Padding  Padding // PKCS7 if not set
+
+	// if not nil, asked instead of Client and Matcher (e.g. client.MockOracle in tests).
+	// values are probed one by one
+	Oracle client.Oracle
+
+	// number of times to retry the byte position, when none or more than expected valid bytes were found
+	// (which is impossible on a stable oracle), before giving up on block.
+	// if set, every found byte is additionally verified with a fresh probe
+	Retries int
+
+	// number of requests, that every verdict about padding error is made of (majority vote).
+	// helps against flaky oracles, e.g. load balancer in front of vulnerable and not vulnerable backends.
+	// 0 or 1 means single request
+	Confirm int
+
+	// order in which candidate byte values are probed (frequency of plaintext characters by default)
+	Order Order
+
+	// expected structure of plaintext, if known: typical characters are probed first,
+	// and decrypted bytes that do not fit are verified once more (nil means any)
+	Format *Format
+
+	// number of blocks decrypted at once (1 if not set), every block is probed with Client.Concurrency connections.
+	// blocks are independent in decryption, while encryption is always done block by block
+	BlockParallel int
+
+	// if set, blocks are decrypted from the first one, so that the head of plaintext is known early (see BlockDecrypted).
+	// plaintext is still delivered into byteStream from the end, i.e. once the last block is decrypted.
+	// encryption always goes from the last block
+	HeadFirst bool
+
+	// if not nil, only bytes in this range (and after it) are recovered in every block of plaintext, the rest is zeroed.
+	// saves requests, when only a part of plaintext matters. applies to decryption only
+	ByteRange *ByteRange
+
+	// known bytes of plaintext by offset (see ParseHint), they are tried before anything else when decrypting
+	Hints map[int]byte
+
+	// if not nil, every step of the algorithm is explained in human terms (teaching aid).
+	// meant for short demo ciphers, as it produces dozens of lines per byte
+	Trace func(format string, a ...interface{})
+
+	// if not nil, internal events are logged: found bytes, retries (level 1),
+	// every response and its classification (level 2). fields are key-value pairs
+	Log func(level int, msg string, fields ...interface{})
+
+	// if not nil, intermediate values of known blocks are taken from there instead of breaking them,
+	// and values of broken blocks are added. decrypted token can then be forged cheaply (see EncryptWithKnown)
+	Intermediates *Intermediates
+
+	// if not nil, called every time a block is broken (and verified), with time it took
+	BlockDone func(elapsed time.Duration)
+
+	// if not nil, called with every block of plaintext as soon as it's decrypted (or known), offset is the block's place in plaintext.
+	// with BlockParallel, blocks are reported in order of completion
+	BlockDecrypted func(offset int, plaintext []byte)
+
+	// if not nil, called with metrics of every block, once it's broken (or taken from Intermediates).
+	// with BlockParallel, blocks are reported in order of completion
+	BlockStats func(stats *BlockStats)
+
+	// ciphertext blocks, sent in front of every probed chunk (see DecryptFinalBlock)
+	prefix []byte
+
+	// number of retried positions, see instability
+	retried int32
+}
+
+// padding scheme in use
+func (p *Padre) padding() Padding {
+	if p.Padding == nil {
+		return PKCS7
+	}
+	return p.Padding
+}
+
+// levels of logged events, see Padre.Log
+const (
+	logVerbose = 1
+	logDebug   = 2
+)
+
+// logs event, if logging is enabled
+func (p *Padre) log(level int, msg string, fields ...interface{}) {
+	if p.Log != nil {
+		p.Log(level, msg, fields...)
+	}
+}
+
+// prepends prefix to the chunk
+func (p *Padre) withPrefix(chunk []byte) []byte {
+	if len(p.prefix) == 0 {
+		return chunk
+	}
+	return append(append([]byte{}, p.prefix...), chunk...)
 }
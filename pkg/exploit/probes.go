@@ -4,22 +4,48 @@ import (
 	"context"
 
 	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/probe"
 )
 
-// detect byte values that do not produce padding error
+// detect byte values that do not produce padding error, values are probed in given order (nil means sequence).
+// every value is probed as many times as needed for verdict (see Padre.Confirm).
 // early-stop when maxCount of such bytes reached
-func (p *Padre) getErrorlessByteValues(chunk []byte, pos int, maxCount int) ([]byte, error) {
-	// the context 	will be cancelled upon returning from function
-	ctx, cancel := context.WithCancel(context.Background())
+func (p *Padre) getErrorlessByteValues(ctx context.Context, chunk []byte, pos int, values []byte, maxCount int) ([]byte, error) {
+	// remaining probes are cancelled upon returning from function
+	probeCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	// container for bytes that do not produce padding error
 	goodBytes := make([]byte, 0, maxCount)
 
-	chanResult := make(chan *client.ProbeResult, 256)
+	// every value gets the votes of its own, those must be independent answers of oracle
+	votes := newTally(p.confirmCount())
+	if p.confirmCount() > 1 {
+		values = repeatValues(values, p.confirmCount())
+		probeCtx = client.WithoutCache(probeCtx)
+	}
+
+	// collects the right bytes, tells when maxCount is reached
+	collect := func(b byte, isErr bool) bool {
+		// wait for majority
+		decided, isErr := votes.add(b, isErr)
+		if decided && !isErr {
+			goodBytes = append(goodBytes, b)
+		}
+		return len(goodBytes) == maxCount
+	}
+
+	// in-memory oracle
+	if p.Oracle != nil {
+		if err := p.askOracle(probeCtx, chunk, pos, values, collect); err != nil {
+			return nil, err
+		}
+		return goodBytes, nil
+	}
 
 	// do probing
-	go p.Client.SendProbes(ctx, chunk, pos, chanResult)
+	chanResult := make(chan *client.ProbeResult, 256)
+	go p.Client.SendProbes(probeCtx, p.withPrefix(chunk), len(p.prefix)+pos, values, chanResult)
 
 	// process result
 	for result := range chanResult {
@@ -28,32 +54,108 @@ func (p *Padre) getErrorlessByteValues(chunk []byte, pos int, maxCount int) ([]b
 		}
 
 		// test for padding error
-		isErr, err := p.Matcher.IsPaddingError(result.Response)
+		isErr, err := p.isPaddingError(result.Response, "pos", pos, "byte", hexByte(result.Byte))
 		if err != nil {
 			return nil, err
 		}
 
-		// collect the right bytes
-		if !isErr {
-			goodBytes = append(goodBytes, result.Byte)
-			// early exit of maxCount reached
-			if len(goodBytes) == maxCount {
-				break
-			}
+		// early exit of maxCount reached
+		if collect(result.Byte, isErr) {
+			break
 		}
 	}
 
+	// probing was stopped from outside, results are incomplete
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return goodBytes, nil
 }
 
-// test concrete chunk for padding oracle
-func (p *Padre) IsPaddingErrorInChunk(chunk []byte) (bool, error) {
-	// send
-	resp, err := p.Client.DoRequest(context.Background(), chunk)
+// probes byte values with Padre.Oracle one at a time (nil means sequence), verdicts are passed into collect until it says enough.
+// unlike probing over HTTP, it's sequential, so the same oracle is asked the same questions every time
+func (p *Padre) askOracle(ctx context.Context, chunk []byte, pos int, values []byte, collect func(b byte, isErr bool) bool) error {
+	if values == nil {
+		values = make([]byte, 256)
+		for i := range values {
+			values[i] = byte(i)
+		}
+	}
+
+	chunk = append([]byte{}, p.withPrefix(chunk)...)
+	pos += len(p.prefix)
+
+	for _, b := range values {
+		chunk[pos] = b
+		isErr, err := p.Oracle.IsPaddingError(ctx, chunk)
+		if err != nil {
+			return err
+		}
+		client.CountRequest(ctx)
+
+		if collect(b, isErr) {
+			break
+		}
+	}
+	return nil
+}
+
+// IsPaddingErrorInChunk tests concrete chunk for padding error.
+// the chunk is sent as many times as needed for verdict (see Padre.Confirm)
+func (p *Padre) IsPaddingErrorInChunk(ctx context.Context, chunk []byte) (bool, error) {
+	votes := newTally(p.confirmCount())
+	if p.confirmCount() > 1 {
+		ctx = client.WithoutCache(ctx)
+	}
+
+	oracle := p.Oracle
+	if oracle == nil {
+		oracle = &client.HTTPOracle{Client: p.Client, Matcher: loggingMatcher{p}}
+	}
+
+	for {
+		isErr, err := oracle.IsPaddingError(ctx, p.withPrefix(chunk))
+		if err != nil {
+			return false, err
+		}
+		if p.Oracle != nil {
+			// HTTP requests are counted by client
+			client.CountRequest(ctx)
+		}
+
+		if decided, verdict := votes.add(0, isErr); decided {
+			return verdict, nil
+		}
+	}
+}
+
+// recognizes padding error in response with Matcher, the verdict is logged along with given fields
+func (p *Padre) isPaddingError(resp *client.Response, fields ...interface{}) (bool, error) {
+	isErr, err := p.Matcher.IsPaddingError(resp)
 	if err != nil {
 		return false, err
 	}
+	if p.Log != nil {
+		p.logResponse(resp, isErr, fields...)
+	}
+	return isErr, nil
+}
 
-	// test for padding oracle
-	return p.Matcher.IsPaddingError(resp)
+// Matcher of Padre, that logs verdicts
+type loggingMatcher struct {
+	p *Padre
+}
+
+func (m loggingMatcher) IsPaddingError(resp *client.Response) (bool, error) {
+	return m.p.isPaddingError(resp)
+}
+
+// logs classification of response
+func (p *Padre) logResponse(resp *client.Response, isErr bool, fields ...interface{}) {
+	fields = append(fields, "status", resp.StatusCode, "length", resp.Length, "padding_error", isErr)
+	if capture := probe.Capture(p.Matcher, resp); capture != "" {
+		fields = append(fields, "capture", capture)
+	}
+	p.Log(logDebug, "response", fields...)
 }
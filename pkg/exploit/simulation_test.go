@@ -0,0 +1,129 @@
+package exploit
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"flag"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	simulationRounds = flag.Int("sim-rounds", 100, "number of random cases checked by TestSimulation")
+	simulationSeed   = flag.Int64("sim-seed", 0, "seed of random cases of TestSimulation (0 means current time)")
+)
+
+// simulation - padding oracle in memory: AES-CBC with a random key, the padding is verified with given scheme.
+// everything random is derived from seed, so that the failed case can be reproduced
+type simulation struct {
+	rnd     *rand.Rand
+	block   cipher.Block
+	padding Padding
+	oracle  *client.MockOracle
+}
+
+func newSimulation(seed int64, padding Padding) *simulation {
+	rnd := rand.New(rand.NewSource(seed))
+
+	key := make([]byte, 16)
+	rnd.Read(key)
+	block, _ := aes.NewCipher(key)
+
+	return &simulation{
+		rnd:     rnd,
+		block:   block,
+		padding: padding,
+		oracle: &client.MockOracle{Block: block, Valid: func(plaintext []byte) bool {
+			_, ok := padding.Unpad(plaintext, aes.BlockSize)
+			return ok
+		}},
+	}
+}
+
+// pads and encrypts plaintext, random IV is prepended
+func (s *simulation) encrypt(plaintext []byte) []byte {
+	padded := s.padding.Pad(plaintext, aes.BlockSize)
+	ciphertext := make([]byte, aes.BlockSize+len(padded))
+	s.rnd.Read(ciphertext[:aes.BlockSize])
+	cipher.NewCBCEncrypter(s.block, ciphertext[:aes.BlockSize]).CryptBlocks(ciphertext[aes.BlockSize:], padded)
+	return ciphertext
+}
+
+// decrypts and unpads ciphertext, false is returned if padding is not valid
+func (s *simulation) decrypt(ciphertext []byte) ([]byte, bool) {
+	plaintext := make([]byte, len(ciphertext)-aes.BlockSize)
+	cipher.NewCBCDecrypter(s.block, ciphertext[:aes.BlockSize]).CryptBlocks(plaintext, ciphertext[aes.BlockSize:])
+	return s.padding.Unpad(plaintext, aes.BlockSize)
+}
+
+// random plaintext, biased towards bytes that make accidental paddings
+// (the tricky part of the algorithm is to tell those from the expected one)
+func (s *simulation) plaintext() []byte {
+	plaintext := make([]byte, s.rnd.Intn(5*aes.BlockSize))
+	for i := range plaintext {
+		switch s.rnd.Intn(4) {
+		case 0:
+			plaintext[i] = byte(1 + s.rnd.Intn(aes.BlockSize))
+		case 1:
+			plaintext[i] = []byte{0x00, 0x80}[s.rnd.Intn(2)]
+		default:
+			plaintext[i] = byte(s.rnd.Intn(256))
+		}
+	}
+	return plaintext
+}
+
+// padre with random options, that affect the algorithm but not the result
+func (s *simulation) padre() *Padre {
+	return &Padre{
+		Oracle:        s.oracle,
+		BlockLen:      aes.BlockSize,
+		Padding:       s.padding,
+		Order:         []Order{OrderFrequency, OrderSequential, OrderRandom}[s.rnd.Intn(3)],
+		Retries:       s.rnd.Intn(2),
+		Confirm:       []int{1, 1, 1, 3}[s.rnd.Intn(4)],
+		BlockParallel: 1 + s.rnd.Intn(3),
+		HeadFirst:     s.rnd.Intn(2) == 0,
+	}
+}
+
+func TestSimulation(t *testing.T) {
+	seed := *simulationSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rounds := *simulationRounds
+	if testing.Short() {
+		rounds /= 10
+	}
+
+	paddings := []Padding{PKCS7, ANSIX923, ISO7816}
+	for round := 0; round < rounds; round++ {
+		caseSeed := seed + int64(round)
+		s := newSimulation(caseSeed, paddings[round%len(paddings)])
+		plaintext := s.plaintext()
+		p := s.padre()
+		failed := fmt.Sprintf("reproduce with -sim-seed %d -sim-rounds 1 (padding %s, plaintext %x, order %s, retries %d, confirm %d, parallel %d, head first %t)",
+			caseSeed, s.padding.Name(), plaintext, p.Order, p.Retries, p.Confirm, p.BlockParallel, p.HeadFirst)
+
+		// decrypt
+		decrypted, err := p.Decrypt(context.Background(), s.encrypt(plaintext), nil)
+		require.NoError(t, err, failed)
+		require.True(t, bytes.Equal(s.padding.Pad(plaintext, aes.BlockSize), decrypted), failed)
+
+		// encrypt
+		encrypted, err := p.Encrypt(context.Background(), string(plaintext), nil)
+		require.NoError(t, err, failed)
+		roundtrip, ok := s.decrypt(encrypted)
+		require.True(t, ok, failed)
+		assert.True(t, bytes.Equal(plaintext, roundtrip), failed)
+	}
+}
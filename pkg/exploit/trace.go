@@ -0,0 +1,51 @@
+package exploit
+
+import "strconv"
+
+/* explanation of the attack in human terms, see Padre.Trace.
+notation: C is the attacked cipher block, C' is the forged block sent in front of it,
+I = D(C) is the intermediate block (decrypted, but not yet XORed), P' is plaintext produced with forged block */
+
+// explains the setup before bytes of cipher block are broken
+func (p *Padre) traceBlock(cipherBlock []byte) {
+	p.Trace("attacking block C = % x", cipherBlock)
+	p.Trace("server decrypts C into unknown intermediate I = D(C), then XORs it with the preceding block: P' = I XOR C'")
+	p.Trace("we forge the preceding block C' (sent in front of C), and the server tells us whether P' ends with a valid padding")
+}
+
+// explains how byte at pos is guessed
+func (p *Padre) traceGuess(pos int, tail, intermediate []byte) {
+	blockLen := len(intermediate)
+	p.Trace("byte %d of %d (position %d): target padding is % x", blockLen-pos, blockLen, pos, tail)
+
+	if pos < blockLen-1 {
+		for i := pos + 1; i < blockLen; i++ {
+			p.Trace("  C'[%d] = I[%d] XOR 0x%02x = 0x%02x XOR 0x%02x = 0x%02x, so that P'[%d] = 0x%02x",
+				i, i, tail[i-pos], intermediate[i], tail[i-pos], intermediate[i]^tail[i-pos], i, tail[i-pos])
+		}
+	}
+	p.Trace("  trying all 256 values of C'[%d], until the server stops complaining about padding", pos)
+}
+
+// explains what was learned from the found byte
+func (p *Padre) traceFound(pos int, found, target byte) {
+	p.Trace("  C'[%d] = 0x%02x gives valid padding, so P'[%d] = 0x%02x and I[%d] = C'[%d] XOR P'[%d] = 0x%02x XOR 0x%02x = 0x%02x",
+		pos, found, pos, target, pos, pos, pos, found, target, found^target)
+}
+
+// explains how plaintext block is revealed from intermediate block
+func (p *Padre) traceDecrypted(blockNum int, intermediate, prev []byte) {
+	plain := xorSlices(intermediate, prev)
+	p.Trace("block C%d is decrypted with the real preceding block: P%d = I XOR C%d", blockNum, blockNum, blockNum-1)
+	p.Trace("  % x", intermediate)
+	p.Trace("  XOR % x", prev)
+	p.Trace("  = % x %s", plain, strconv.QuoteToASCII(string(plain)))
+}
+
+// explains how cipher block is forged from intermediate block
+func (p *Padre) traceEncrypted(blockNum int, intermediate, plain []byte) {
+	p.Trace("block C%d is forged so that C%d decrypts into desired plaintext: C%d = I XOR P%d", blockNum-1, blockNum, blockNum-1, blockNum)
+	p.Trace("  % x", intermediate)
+	p.Trace("  XOR % x %s", plain, strconv.QuoteToASCII(string(plain)))
+	p.Trace("  = % x", xorSlices(intermediate, plain))
+}
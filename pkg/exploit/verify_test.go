@@ -0,0 +1,65 @@
+package exploit
+
+import (
+	"context"
+	"crypto/cipher"
+	"sync"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverify(t *testing.T) {
+	server, block := newOracleServer(t, PKCS7, 0)
+	defer server.Close()
+
+	ciphertext := util.RandomSlice(32)
+	cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], util.RandomSlice(16))
+
+	// intermediate of the block, as it's decrypted with zero IV
+	intermediate := make([]byte, 16)
+	cipher.NewCBCDecrypter(block, make([]byte, 16)).CryptBlocks(intermediate, ciphertext[16:])
+
+	p := newTestPadre(t, server.URL)
+	verified, err := p.reverify(context.Background(), ciphertext[16:], intermediate, nil)
+	require.NoError(t, err)
+	assert.Equal(t, intermediate, verified)
+
+	// corrupted byte is caught, and the block is broken again
+	for _, pos := range []int{0, 7, 15} {
+		wrong := append([]byte{}, intermediate...)
+		wrong[pos]++
+		verified, err = p.reverify(context.Background(), ciphertext[16:], wrong, nil)
+		require.NoError(t, err)
+		assert.Equal(t, intermediate, verified, pos)
+	}
+}
+
+func TestBreakVerified_Unstable(t *testing.T) {
+	// first valid paddings are reported as errors
+	server, block := newOracleServer(t, PKCS7, 2)
+	defer server.Close()
+
+	plaintext := PKCS7.Pad([]byte("unstable"), 16)
+	ciphertext := util.RandomSlice(32)
+	cipher.NewCBCEncrypter(block, ciphertext[:16]).CryptBlocks(ciphertext[16:], plaintext)
+
+	var (
+		mx       sync.Mutex
+		messages []string
+	)
+	p := newTestPadre(t, server.URL)
+	p.Retries = 3
+	p.Log = func(level int, msg string, fields ...interface{}) {
+		mx.Lock()
+		defer mx.Unlock()
+		messages = append(messages, msg)
+	}
+
+	intermediate, err := p.breakVerified(context.Background(), 2, ciphertext[16:], p.guess(ciphertext[:16], 0), true, nil)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, xorSlices(intermediate, ciphertext[:16]))
+	assert.Contains(t, messages, "oracle was unstable while breaking the block, verifying every byte")
+}
@@ -0,0 +1,3 @@
+// Package forge edits fields of decrypted plaintext, so that it can be encrypted back as a forged token.
+// JSON objects and key=value formats (query strings, cookies, semicolon-separated lists) are supported.
+package forge
@@ -0,0 +1,115 @@
+package forge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Edit - new value of plaintext field
+type Edit struct {
+	Field string
+	Value string
+}
+
+func (e Edit) String() string {
+	return e.Field + "=" + e.Value
+}
+
+// ParseEdit parses edit in form of FIELD=VALUE
+func ParseEdit(s string) (Edit, error) {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return Edit{}, fmt.Errorf("invalid edit: %q (use FIELD=VALUE)", s)
+	}
+	return Edit{Field: parts[0], Value: parts[1]}, nil
+}
+
+// Apply applies edits to plaintext. Every occurrence of the field is changed, missing fields are added
+func Apply(plain []byte, edits []Edit) ([]byte, error) {
+	plain = append([]byte{}, plain...)
+
+	isJSON := isJSONObject(plain)
+	for _, e := range edits {
+		if isJSON {
+			plain = applyJSON(plain, e)
+		} else {
+			plain = applyKeyValue(plain, e)
+		}
+	}
+
+	// edited JSON must stay valid, otherwise target would reject it anyway
+	if isJSON && !json.Valid(plain) {
+		return nil, fmt.Errorf("edited plaintext is not a valid JSON: %q", plain)
+	}
+	return plain, nil
+}
+
+func isJSONObject(plain []byte) bool {
+	trimmed := bytes.TrimSpace(plain)
+	return len(trimmed) > 0 && trimmed[0] == '{' && json.Valid(trimmed)
+}
+
+// "field": value, where value is a string, number or literal
+const jsonValuePattern = `("(?:[^"\\]|\\.)*"|-?[0-9][0-9.eE+-]*|true|false|null)`
+
+// value is inserted as-is if it's valid JSON scalar (e.g. true, 1, "x"), otherwise it's quoted as string
+func jsonValue(value string) []byte {
+	var v interface{}
+	if err := json.Unmarshal([]byte(value), &v); err == nil {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+		default:
+			return []byte(value)
+		}
+	}
+	quoted, _ := json.Marshal(value)
+	return quoted
+}
+
+func applyJSON(plain []byte, e Edit) []byte {
+	key, _ := json.Marshal(e.Field)
+	re := regexp.MustCompile(`(` + regexp.QuoteMeta(string(key)) + `\s*:\s*)` + jsonValuePattern)
+	value := jsonValue(e.Value)
+
+	if re.Match(plain) {
+		return re.ReplaceAllFunc(plain, func(match []byte) []byte {
+			m := re.FindSubmatch(match)
+			return append(append([]byte{}, m[1]...), value...)
+		})
+	}
+
+	// missing field is added at the end of the object
+	end := bytes.LastIndexByte(plain, '}')
+	field := append(append(key, ':'), value...)
+	if len(bytes.TrimSpace(plain[bytes.IndexByte(plain, '{')+1:end])) > 0 {
+		field = append([]byte{','}, field...)
+	}
+	return append(append(append([]byte{}, plain[:end]...), field...), plain[end:]...)
+}
+
+// separators of key=value pairs, in order of preference
+const separators = "&;|,"
+
+func applyKeyValue(plain []byte, e Edit) []byte {
+	re := regexp.MustCompile(`(^|[^\w.-])(` + regexp.QuoteMeta(e.Field) + `=)[^` + separators + `\s]*`)
+
+	if re.Match(plain) {
+		return re.ReplaceAllFunc(plain, func(match []byte) []byte {
+			m := re.FindSubmatch(match)
+			return append(append(append([]byte{}, m[1]...), m[2]...), e.Value...)
+		})
+	}
+
+	// missing field is appended, with the same separator as other fields
+	if len(plain) == 0 {
+		return []byte(e.String())
+	}
+	sep := byte('&')
+	if i := bytes.IndexAny(plain, separators); i >= 0 {
+		sep = plain[i]
+	}
+	return append(append(plain, sep), e.String()...)
+}
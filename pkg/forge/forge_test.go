@@ -0,0 +1,47 @@
+package forge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEdit(t *testing.T) {
+	e, err := ParseEdit("role=admin=1")
+	require.NoError(t, err)
+	assert.Equal(t, Edit{"role", "admin=1"}, e)
+
+	for _, s := range []string{"role", "=admin"} {
+		_, err := ParseEdit(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		plain    string
+		edits    []Edit
+		expected string
+	}{
+		// key=value
+		{"user=bob&role=user", []Edit{{"role", "admin"}}, "user=bob&role=admin"},
+		{"user=bob;role=user;exp=1", []Edit{{"role", "admin"}, {"exp", "9999"}}, "user=bob;role=admin;exp=9999"},
+		{"user=bob&subrole=user", []Edit{{"role", "admin"}}, "user=bob&subrole=user&role=admin"},
+		{"user=bob", []Edit{{"uid", "0"}}, "user=bob&uid=0"},
+		{"", []Edit{{"uid", "0"}}, "uid=0"},
+
+		// JSON
+		{`{"user":"bob","role":"user"}`, []Edit{{"role", "admin"}}, `{"user":"bob","role":"admin"}`},
+		{`{"user": "bob", "admin": false}`, []Edit{{"admin", "true"}}, `{"user": "bob", "admin": true}`},
+		{`{"user":"bob","uid":12}`, []Edit{{"uid", "0"}, {"user", `a"b`}}, `{"user":"a\"b","uid":0}`},
+		{`{"user":"bob"}`, []Edit{{"admin", "true"}}, `{"user":"bob","admin":true}`},
+		{`{}`, []Edit{{"admin", "true"}}, `{"admin":true}`},
+	}
+
+	for _, tc := range tests {
+		forged, err := Apply([]byte(tc.plain), tc.edits)
+		require.NoError(t, err, tc.plain)
+		assert.Equal(t, tc.expected, string(forged), tc.plain)
+	}
+}
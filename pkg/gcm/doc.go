@@ -0,0 +1,4 @@
+// Package gcm implements the "forbidden attack" on AES-GCM with reused nonce.
+// Two messages encrypted under the same key and nonce reveal the authentication key H (as a root of polynomial over GF(2^128)),
+// after which tags of arbitrary ciphertexts under that nonce can be forged.
+package gcm
@@ -0,0 +1,235 @@
+package gcm
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+)
+
+// element of GF(2^128) in GCM bit order: the first bit of block is the coefficient of x^0
+type element struct {
+	hi, lo uint64
+}
+
+var (
+	zero = element{}
+	one  = element{hi: 1 << 63}
+)
+
+func elementFromBytes(b []byte) element {
+	return element{binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:16])}
+}
+
+func (x element) bytes() []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b[:8], x.hi)
+	binary.BigEndian.PutUint64(b[8:], x.lo)
+	return b
+}
+
+func (x element) add(y element) element {
+	return element{x.hi ^ y.hi, x.lo ^ y.lo}
+}
+
+// multiplication as defined by GCM specification (NIST SP 800-38D, algorithm 1)
+func (x element) mul(y element) element {
+	var z element
+	v := y
+	for i := 0; i < 128; i++ {
+		var bit uint64
+		if i < 64 {
+			bit = x.hi >> (63 - i) & 1
+		} else {
+			bit = x.lo >> (127 - i) & 1
+		}
+		if bit == 1 {
+			z = z.add(v)
+		}
+
+		lsb := v.lo & 1
+		v.lo = v.lo>>1 | v.hi<<63
+		v.hi >>= 1
+		if lsb == 1 {
+			v.hi ^= 0xe1 << 56
+		}
+	}
+	return z
+}
+
+// multiplicative inverse: x^(2^128 - 2)
+func (x element) inverse() element {
+	r := one
+	for i := 0; i < 127; i++ {
+		r = r.mul(r).mul(x)
+	}
+	return r.mul(r)
+}
+
+func randomElement() (element, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return zero, err
+	}
+	return elementFromBytes(b), nil
+}
+
+// polynomial over GF(2^128), coefficients from the lowest degree.
+// normalized polynomials have no leading zero coefficients
+type poly []element
+
+func (p poly) normalize() poly {
+	for len(p) > 0 && p[len(p)-1] == zero {
+		p = p[:len(p)-1]
+	}
+	return p
+}
+
+func (p poly) degree() int {
+	return len(p.normalize()) - 1
+}
+
+func (p poly) add(q poly) poly {
+	if len(p) < len(q) {
+		p, q = q, p
+	}
+	r := append(poly{}, p...)
+	for i := range q {
+		r[i] = r[i].add(q[i])
+	}
+	return r.normalize()
+}
+
+func (p poly) mul(q poly) poly {
+	if len(p) == 0 || len(q) == 0 {
+		return nil
+	}
+	r := make(poly, len(p)+len(q)-1)
+	for i := range p {
+		for j := range q {
+			r[i+j] = r[i+j].add(p[i].mul(q[j]))
+		}
+	}
+	return r.normalize()
+}
+
+// remainder of division by q (q is not zero)
+func (p poly) mod(q poly) poly {
+	q = q.normalize()
+	r := append(poly{}, p.normalize()...)
+	inv := q[len(q)-1].inverse()
+	for len(r) >= len(q) {
+		factor := r[len(r)-1].mul(inv)
+		shift := len(r) - len(q)
+		for i := range q {
+			r[shift+i] = r[shift+i].add(factor.mul(q[i]))
+		}
+		r = r.normalize()
+	}
+	return r
+}
+
+// divides p by q exactly (q divides p)
+func (p poly) div(q poly) poly {
+	q = q.normalize()
+	r := append(poly{}, p.normalize()...)
+	quotient := make(poly, len(r)-len(q)+1)
+	inv := q[len(q)-1].inverse()
+	for len(r) >= len(q) {
+		factor := r[len(r)-1].mul(inv)
+		shift := len(r) - len(q)
+		quotient[shift] = factor
+		for i := range q {
+			r[shift+i] = r[shift+i].add(factor.mul(q[i]))
+		}
+		r = r.normalize()
+	}
+	return quotient.normalize()
+}
+
+// makes leading coefficient one
+func (p poly) monic() poly {
+	p = p.normalize()
+	inv := p[len(p)-1].inverse()
+	r := make(poly, len(p))
+	for i := range p {
+		r[i] = p[i].mul(inv)
+	}
+	return r
+}
+
+func gcd(p, q poly) poly {
+	p, q = p.normalize(), q.normalize()
+	for len(q) > 0 {
+		p, q = q, p.mod(q)
+	}
+	if len(p) == 0 {
+		return p
+	}
+	return p.monic()
+}
+
+// squares p modulo m. squaring is linear in characteristic 2: (sum c_i x^i)^2 = sum c_i^2 x^2i
+func (p poly) squareMod(m poly) poly {
+	r := make(poly, 2*len(p))
+	for i, c := range p {
+		r[2*i] = c.mul(c)
+	}
+	return r.normalize().mod(m)
+}
+
+// roots finds all distinct roots of p in GF(2^128)
+func (p poly) roots() ([]element, error) {
+	p = p.normalize()
+	if len(p) < 2 {
+		return nil, nil
+	}
+	p = p.monic()
+
+	// product of distinct linear factors of p: gcd(p, x^(2^128) - x)
+	x := poly{zero, one}
+	xq := x.mod(p)
+	for i := 0; i < 128; i++ {
+		xq = xq.squareMod(p)
+	}
+	g := gcd(p, xq.add(x))
+
+	return splitLinear(g)
+}
+
+// splits product of distinct linear factors with random trace maps (equal-degree factorization)
+func splitLinear(g poly) ([]element, error) {
+	switch g.degree() {
+	case -1, 0:
+		return nil, nil
+	case 1:
+		// monic x + c has root c
+		return []element{g[0]}, nil
+	}
+
+	for {
+		a, err := randomElement()
+		if err != nil {
+			return nil, err
+		}
+
+		// trace of a*x: sum of (a*x)^(2^i) for i < 128
+		y := poly{zero, a}.mod(g)
+		trace := y
+		for i := 1; i < 128; i++ {
+			y = y.squareMod(g)
+			trace = trace.add(y)
+		}
+
+		d := gcd(g, trace)
+		if deg := d.degree(); deg > 0 && deg < g.degree() {
+			left, err := splitLinear(d)
+			if err != nil {
+				return nil, err
+			}
+			right, err := splitLinear(g.div(d))
+			if err != nil {
+				return nil, err
+			}
+			return append(left, right...), nil
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package gcm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// TagSize - only full 16-byte tags are supported
+const TagSize = 16
+
+// Message - GCM ciphertext with its additional authenticated data and tag
+type Message struct {
+	AAD        []byte
+	Ciphertext []byte
+	Tag        []byte
+}
+
+// AuthKey - authentication key H, together with encrypted initial counter block E(K, J0) of the reused nonce.
+// Both are needed to compute a valid tag of any ciphertext under that nonce
+type AuthKey struct {
+	H    []byte
+	Mask []byte
+}
+
+// ErrNoAuthKey is returned when no authentication key is consistent with given messages,
+// i.e. they were not encrypted under the same key and nonce
+var ErrNoAuthKey = errors.New("no authentication key fits the messages: nonce was not reused")
+
+// RecoverAuthKeys finds candidates of authentication key from messages encrypted under the same nonce.
+// With two messages several candidates may be returned, every additional message filters them out
+func RecoverAuthKeys(messages ...Message) ([]AuthKey, error) {
+	if len(messages) < 2 {
+		return nil, fmt.Errorf("at least two messages are needed, got %d", len(messages))
+	}
+	for i, m := range messages {
+		if len(m.Tag) != TagSize {
+			return nil, fmt.Errorf("message %d: tag must be %d bytes long, got %d", i+1, TagSize, len(m.Tag))
+		}
+	}
+
+	// tags share the mask, so T1 + T2 = GHASH(H, m1) + GHASH(H, m2): H is a root of that polynomial
+	m1, m2 := messages[0], messages[1]
+	p := ghashPoly(m1).add(ghashPoly(m2))
+	if p.degree() < 1 {
+		return nil, fmt.Errorf("first two messages are equal up to tag")
+	}
+
+	roots, err := p.roots()
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []AuthKey
+	for _, h := range roots {
+		key := AuthKey{H: h.bytes()}
+		key.Mask = xor(m1.Tag, ghash(h, m1.AAD, m1.Ciphertext).bytes())
+
+		if key.fits(messages) {
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrNoAuthKey
+	}
+	return keys, nil
+}
+
+func (k AuthKey) fits(messages []Message) bool {
+	for _, m := range messages {
+		if !bytes.Equal(k.Tag(m.AAD, m.Ciphertext), m.Tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// Tag computes authentication tag of ciphertext and additional data under the reused nonce
+func (k AuthKey) Tag(aad, ciphertext []byte) []byte {
+	return xor(k.Mask, ghash(elementFromBytes(k.H), aad, ciphertext).bytes())
+}
+
+// Encrypt produces ciphertext of plaintext under the reused nonce, using known plaintext of other message.
+// The keystream is known only as far as the known plaintext goes, so the plaintext can't be longer than it
+func Encrypt(known Message, knownPlaintext, plaintext []byte) ([]byte, error) {
+	if len(knownPlaintext) > len(known.Ciphertext) {
+		return nil, fmt.Errorf("known plaintext is longer than its ciphertext")
+	}
+	if len(plaintext) > len(knownPlaintext) {
+		return nil, fmt.Errorf("plaintext is longer than known one: %d > %d bytes", len(plaintext), len(knownPlaintext))
+	}
+	keystream := xor(known.Ciphertext, knownPlaintext)
+	return xor(keystream[:len(plaintext)], plaintext), nil
+}
+
+// blocks of GHASH input: padded AAD, padded ciphertext and lengths in bits
+func ghashBlocks(aad, ciphertext []byte) []element {
+	var blocks []element
+	for _, data := range [][]byte{aad, ciphertext} {
+		for i := 0; i < len(data); i += 16 {
+			block := make([]byte, 16)
+			copy(block, data[i:])
+			blocks = append(blocks, elementFromBytes(block))
+		}
+	}
+
+	lengths := make([]byte, 16)
+	binary.BigEndian.PutUint64(lengths[:8], uint64(len(aad))*8)
+	binary.BigEndian.PutUint64(lengths[8:], uint64(len(ciphertext))*8)
+	return append(blocks, elementFromBytes(lengths))
+}
+
+func ghash(h element, aad, ciphertext []byte) element {
+	var y element
+	for _, x := range ghashBlocks(aad, ciphertext) {
+		y = y.add(x).mul(h)
+	}
+	return y
+}
+
+// tag of message as polynomial of H: T + X1*H^m + ... + Xm*H
+func ghashPoly(m Message) poly {
+	blocks := ghashBlocks(m.AAD, m.Ciphertext)
+	p := make(poly, len(blocks)+1)
+	p[0] = elementFromBytes(m.Tag)
+	for i, x := range blocks {
+		p[len(blocks)-i] = x
+	}
+	return p
+}
+
+func xor(a, b []byte) []byte {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	r := make([]byte, n)
+	for i := range r {
+		r[i] = a[i] ^ b[i]
+	}
+	return r
+}
@@ -0,0 +1,90 @@
+package gcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seal(t *testing.T, aead cipher.AEAD, nonce []byte, plaintext, aad string) Message {
+	sealed := aead.Seal(nil, nonce, []byte(plaintext), []byte(aad))
+	split := len(sealed) - TagSize
+	return Message{AAD: []byte(aad), Ciphertext: sealed[:split], Tag: sealed[split:]}
+}
+
+func TestElement_Mul(t *testing.T) {
+	a := elementFromBytes([]byte("0123456789abcdef"))
+	b := elementFromBytes([]byte("fedcba9876543210"))
+
+	assert.Equal(t, a, a.mul(one))
+	assert.Equal(t, zero, a.mul(zero))
+	assert.Equal(t, a.mul(b), b.mul(a))
+	assert.Equal(t, one, a.mul(a.inverse()))
+}
+
+func TestGHASH(t *testing.T) {
+	// test case 2 of GCM specification: zero key, single zero block
+	block, _ := aes.NewCipher(make([]byte, 16))
+	h := make([]byte, 16)
+	block.Encrypt(h, h)
+
+	ciphertext := []byte{0x03, 0x88, 0xda, 0xce, 0x60, 0xb6, 0xa3, 0x92, 0xf3, 0x28, 0xc2, 0xb9, 0x71, 0xb2, 0xfe, 0x78}
+	expected := []byte{0xf3, 0x8c, 0xbb, 0x1a, 0xd6, 0x92, 0x23, 0xdc, 0xc3, 0x45, 0x7a, 0xe5, 0xb6, 0xb0, 0xf8, 0x85}
+	assert.Equal(t, expected, ghash(elementFromBytes(h), nil, ciphertext).bytes())
+}
+
+func TestRecoverAuthKeys(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	nonce := []byte("reused nonce")
+
+	m1 := seal(t, aead, nonce, "transfer 100 USD to alice", "header")
+	m2 := seal(t, aead, nonce, "hello, this is the second message, a bit longer", "")
+	m3 := seal(t, aead, nonce, "third", "more data")
+
+	keys, err := RecoverAuthKeys(m1, m2, m3)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	// forged ciphertext must be accepted by GCM
+	forged, err := Encrypt(m1, []byte("transfer 100 USD to alice"), []byte("transfer 999 USD to mallo"))
+	require.NoError(t, err)
+	sealed := append(forged, keys[0].Tag([]byte("header"), forged)...)
+
+	plaintext, err := aead.Open(nil, nonce, sealed, []byte("header"))
+	require.NoError(t, err)
+	assert.Equal(t, "transfer 999 USD to mallo", string(plaintext))
+
+	// two messages are enough as well, the right key is among candidates
+	keys2, err := RecoverAuthKeys(m1, m2)
+	require.NoError(t, err)
+	assert.Contains(t, keys2, keys[0])
+}
+
+func TestRecoverAuthKeys_Errors(t *testing.T) {
+	block, _ := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	aead, _ := cipher.NewGCM(block)
+
+	m1 := seal(t, aead, []byte("first nonce!"), "one", "")
+	m2 := seal(t, aead, []byte("other nonce!"), "two", "")
+	m3 := seal(t, aead, []byte("first nonce!"), "three", "")
+
+	_, err := RecoverAuthKeys(m1)
+	assert.Error(t, err)
+
+	_, err = RecoverAuthKeys(m1, Message{Tag: []byte("short")})
+	assert.Error(t, err)
+
+	_, err = RecoverAuthKeys(m1, m3, m2)
+	assert.Equal(t, ErrNoAuthKey, err)
+}
+
+func TestEncrypt_TooLong(t *testing.T) {
+	_, err := Encrypt(Message{Ciphertext: []byte("abc")}, []byte("abc"), []byte("abcd"))
+	assert.Error(t, err)
+}
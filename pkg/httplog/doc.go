@@ -0,0 +1,3 @@
+// Package httplog records HTTP exchanges of padre client into a file, for later evidence,
+// debugging or WAF analysis. Supported formats are HAR 1.2 (viewable in browser dev tools) and raw HTTP.
+package httplog
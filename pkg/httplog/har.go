@@ -0,0 +1,181 @@
+package httplog
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/glebarez/padre/pkg/client"
+)
+
+// HAR 1.2 format (http://www.softwareishard.com/blog/har-12-spec/).
+// entries are streamed one by one, so the log is a valid JSON only after Close
+type harFormat struct{}
+
+// name of the tool in HAR log
+const harCreator = "padre"
+
+func (harFormat) header() string {
+	return `{"log":{"version":"1.2","creator":{"name":"` + harCreator + `","version":""},"entries":[` + "\n"
+}
+
+func (harFormat) footer() string {
+	return "\n]}}\n"
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+func (harFormat) entry(e *client.Exchange, n int) (string, error) {
+	req, resp := e.Request, e.Response
+	elapsed := float64(e.Elapsed) / float64(time.Millisecond)
+
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	entry := &harEntry{
+		StartedDateTime: e.Started.Format(time.RFC3339Nano),
+		Time:            elapsed,
+		Request: harRequest{
+			Method:      method,
+			URL:         req.URL.String(),
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     harCookies(req.Cookies()),
+			Headers:     harHeaders(req.Header),
+			QueryString: []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(e.RequestBody),
+		},
+		Response: harResponse{
+			Status:      resp.StatusCode,
+			StatusText:  http.StatusText(resp.StatusCode),
+			HTTPVersion: resp.Proto,
+			Cookies:     harCookies(resp.Cookies()),
+			Headers:     harHeaders(resp.Header),
+			Content:     harBody(e.ResponseBody, resp.Header.Get("Content-Type")),
+			RedirectURL: resp.Header.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    len(e.ResponseBody),
+		},
+		Timings: harTimings{Wait: elapsed},
+	}
+
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{name, value})
+		}
+	}
+	sortNameValues(entry.Request.QueryString)
+
+	if len(e.RequestBody) > 0 {
+		entry.Request.PostData = &harPostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     string(e.RequestBody),
+		}
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+
+	// entries are separated with comma
+	if n > 0 {
+		return ",\n" + string(data), nil
+	}
+	return string(data), nil
+}
+
+func harHeaders(header http.Header) []harNameValue {
+	list := []harNameValue{}
+	for name, values := range header {
+		for _, value := range values {
+			list = append(list, harNameValue{name, value})
+		}
+	}
+	sortNameValues(list)
+	return list
+}
+
+func harCookies(cookies []*http.Cookie) []harNameValue {
+	list := []harNameValue{}
+	for _, c := range cookies {
+		list = append(list, harNameValue{c.Name, c.Value})
+	}
+	return list
+}
+
+// binary bodies are base64-encoded, as HAR requires text
+func harBody(body []byte, mimeType string) harContent {
+	content := harContent{Size: len(body), MimeType: mimeType}
+	if utf8.Valid(body) {
+		content.Text = string(body)
+	} else {
+		content.Text = base64.StdEncoding.EncodeToString(body)
+		content.Encoding = "base64"
+	}
+	return content
+}
+
+// keeps output stable, map iteration order is random
+func sortNameValues(list []harNameValue) {
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+}
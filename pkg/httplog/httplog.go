@@ -0,0 +1,133 @@
+package httplog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/probe"
+)
+
+// supported formats
+const (
+	FormatHAR = "har"
+	FormatRaw = "raw"
+)
+
+// Logger writes exchanges into a file. It implements client.Recorder
+type Logger struct {
+	mx     sync.Mutex
+	w      io.WriteCloser
+	format format
+	count  int   // count of exchanges written
+	err    error // first error occurred while writing, reported on Close
+
+	// if not nil, only exchanges that pass the filter are written
+	Filter func(*client.Exchange) bool
+}
+
+// format encodes exchanges into a stream
+type format interface {
+	header() string
+	entry(e *client.Exchange, n int) (string, error) // n is the sequence number of entry, starting from 0
+	footer() string
+}
+
+// DetectFormat chooses format by extension of file: .har is HAR, everything else is raw
+func DetectFormat(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".har") {
+		return FormatHAR
+	}
+	return FormatRaw
+}
+
+// Create creates log file (truncated if exists) in given format
+func Create(path, formatName string) (*Logger, error) {
+	var f format
+	switch strings.ToLower(formatName) {
+	case FormatHAR:
+		f = harFormat{}
+	case FormatRaw:
+		f = rawFormat{}
+	default:
+		return nil, fmt.Errorf("unsupported HTTP log format: %q (choose one of: %s, %s)", formatName, FormatHAR, FormatRaw)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return newLogger(file, f)
+}
+
+func newLogger(w io.WriteCloser, f format) (*Logger, error) {
+	if _, err := io.WriteString(w, f.header()); err != nil {
+		w.Close()
+		return nil, err
+	}
+	return &Logger{w: w, format: f}, nil
+}
+
+// Record writes exchange into log. write errors are reported on Close
+func (l *Logger) Record(e *client.Exchange) {
+	if l.Filter != nil && !l.Filter(e) {
+		return
+	}
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	if l.err != nil || l.w == nil {
+		return
+	}
+
+	entry, err := l.format.entry(e, l.count)
+	if err == nil {
+		_, err = io.WriteString(l.w, entry)
+	}
+	if err != nil {
+		l.err = err
+		return
+	}
+	l.count++
+}
+
+// Close completes the log, the first error occurred during logging is returned
+func (l *Logger) Close() error {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	if l.w == nil {
+		return l.err
+	}
+
+	_, err := io.WriteString(l.w, l.format.footer())
+	if closeErr := l.w.Close(); err == nil {
+		err = closeErr
+	}
+	l.w = nil
+
+	if l.err == nil {
+		l.err = err
+	}
+	return l.err
+}
+
+// NotMatching creates filter that passes only exchanges not recognized by matcher,
+// e.g. responses that are not padding errors
+func NotMatching(matcher probe.PaddingErrorMatcher) func(*client.Exchange) bool {
+	return func(e *client.Exchange) bool {
+		isErr, err := matcher.IsPaddingError(&client.Response{
+			StatusCode: e.Response.StatusCode,
+			Header:     e.Response.Header,
+			Body:       e.ResponseBody,
+			Length:     len(e.ResponseBody),
+			Elapsed:    e.Elapsed,
+		})
+		return err == nil && !isErr
+	}
+}
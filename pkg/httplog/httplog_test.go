@@ -0,0 +1,99 @@
+package httplog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sends ciphers through client with logger attached, returns contents of log
+func logExchanges(t *testing.T, format string, filter func(*client.Exchange) bool, ciphers ...[]byte) []byte {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("c") == "00" {
+			w.WriteHeader(500)
+			w.Write([]byte{0xff, 0xfe})
+			return
+		}
+		w.Write([]byte("welcome"))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "padre")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "traffic")
+
+	logger, err := Create(path, format)
+	require.NoError(t, err)
+	logger.Filter = filter
+
+	c := &client.Client{
+		HTTPclient:        server.Client(),
+		URL:               server.URL + "/?c=$",
+		POSTdata:          "token=$",
+		ContentType:       "application/x-www-form-urlencoded",
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewLHEXencoder(""),
+		Recorder:          logger,
+	}
+	for _, cipher := range ciphers {
+		_, err := c.DoRequest(nil, cipher)
+		require.NoError(t, err)
+	}
+	require.NoError(t, logger.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	return data
+}
+
+func TestHAR(t *testing.T) {
+	data := logExchanges(t, FormatHAR, nil, []byte{0}, []byte{1})
+
+	har := struct {
+		Log struct {
+			Entries []harEntry `json:"entries"`
+		} `json:"log"`
+	}{}
+	require.NoError(t, json.Unmarshal(data, &har))
+	require.Len(t, har.Log.Entries, 2)
+
+	first := har.Log.Entries[0]
+	assert.Equal(t, "POST", first.Request.Method)
+	assert.Equal(t, []harNameValue{{"c", "00"}}, first.Request.QueryString)
+	assert.Equal(t, &harPostData{"application/x-www-form-urlencoded", "token=00"}, first.Request.PostData)
+	assert.Equal(t, 500, first.Response.Status)
+	assert.Equal(t, harContent{Size: 2, MimeType: "text/plain; charset=utf-8", Text: "//4=", Encoding: "base64"}, first.Response.Content)
+
+	assert.Equal(t, "welcome", har.Log.Entries[1].Response.Content.Text)
+}
+
+func TestRaw(t *testing.T) {
+	// only successful responses
+	data := logExchanges(t, FormatRaw, func(e *client.Exchange) bool {
+		return e.Response.StatusCode == 200
+	}, []byte{0}, []byte{1})
+
+	assert.Equal(t, 1, bytes.Count(data, []byte("### ")))
+	assert.Contains(t, string(data), "POST /?c=01 HTTP/1.1\r\n")
+	assert.Contains(t, string(data), "\r\n\r\ntoken=01\n\nHTTP/1.1 200 OK\r\n")
+	assert.Contains(t, string(data), "\r\n\r\nwelcome\n\n")
+}
+
+func TestDetectFormat(t *testing.T) {
+	assert.Equal(t, FormatHAR, DetectFormat("traffic.HAR"))
+	assert.Equal(t, FormatRaw, DetectFormat("traffic.log"))
+
+	_, err := Create("traffic.txt", "pcap")
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+package httplog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http/httputil"
+	"time"
+
+	"github.com/glebarez/padre/pkg/client"
+)
+
+// raw format: request and response as they appear on the wire (HTTP/1.x), separated by comment line
+type rawFormat struct{}
+
+func (rawFormat) header() string { return "" }
+func (rawFormat) footer() string { return "" }
+
+func (rawFormat) entry(e *client.Exchange, n int) (string, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "### %d | %s | %s\n", n+1, e.Started.Format(time.RFC3339Nano), e.Elapsed)
+
+	// client requests do not carry protocol version
+	r := e.Request
+	if r.ProtoMajor == 0 {
+		r = r.WithContext(context.Background())
+		r.ProtoMajor, r.ProtoMinor = 1, 1
+	}
+
+	req, err := httputil.DumpRequest(r, false)
+	if err != nil {
+		return "", err
+	}
+	b.Write(req)
+	if len(e.RequestBody) > 0 {
+		b.Write(e.RequestBody)
+		b.WriteString("\n\n")
+	}
+
+	resp, err := httputil.DumpResponse(e.Response, false)
+	if err != nil {
+		return "", err
+	}
+	b.Write(resp)
+	b.Write(e.ResponseBody)
+	b.WriteString("\n\n")
+
+	return b.String(), nil
+}
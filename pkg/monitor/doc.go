@@ -0,0 +1,3 @@
+// Package monitor exposes progress of a running padre instance on local unix socket,
+// and queries it from another process. Metrics for Prometheus are served over HTTP as well.
+package monitor
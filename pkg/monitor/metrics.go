@@ -0,0 +1,128 @@
+package monitor
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// upper bounds of histogram of time spent on single block, in seconds
+var blockBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800}
+
+// Metrics exposes progress of the attack in Prometheus text format
+type Metrics struct {
+	// progress of current input
+	Snapshot func() *Snapshot
+
+	// HTTP requests made and failed so far, with their total latency
+	Requests func() (total, failed int, latency time.Duration)
+
+	mx           sync.Mutex
+	blockCounts  []int // per bucket of blockBuckets, not cumulative
+	blockCount   int
+	blockSeconds float64
+	inputsDone   int
+	inputsFailed int
+}
+
+// ObserveBlock records time spent on breaking single block
+func (m *Metrics) ObserveBlock(elapsed time.Duration) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if m.blockCounts == nil {
+		m.blockCounts = make([]int, len(blockBuckets))
+	}
+
+	seconds := elapsed.Seconds()
+	for i, bound := range blockBuckets {
+		if seconds <= bound {
+			m.blockCounts[i]++
+			break
+		}
+	}
+	m.blockCount++
+	m.blockSeconds += seconds
+}
+
+// ObserveInput records completion of input
+func (m *Metrics) ObserveInput(err error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if err != nil {
+		m.inputsFailed++
+	} else {
+		m.inputsDone++
+	}
+}
+
+// ServeHTTP writes metrics in Prometheus text format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.Write(w)
+}
+
+// Write writes metrics in Prometheus text format
+func (m *Metrics) Write(w io.Writer) {
+	metric := func(name, kind, help string, value interface{}) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+	}
+
+	if m.Requests != nil {
+		total, failed, latency := m.Requests()
+		metric("padre_requests_total", "counter", "HTTP requests sent to oracle.", total)
+		metric("padre_request_errors_total", "counter", "HTTP requests that failed (network errors, timeouts).", failed)
+		metric("padre_request_latency_seconds_total", "counter", "Total latency of HTTP requests.", latency.Seconds())
+
+		ratio := 0.0
+		if total > 0 {
+			ratio = float64(failed) / float64(total)
+		}
+		metric("padre_request_error_ratio", "gauge", "Share of failed HTTP requests.", ratio)
+	}
+
+	if m.Snapshot != nil {
+		s := m.Snapshot()
+		metric("padre_requests_per_second", "gauge", "Current rate of HTTP requests.", s.RPS)
+		metric("padre_input", "gauge", "Number of input being processed (starting from 1).", s.Input)
+		metric("padre_inputs", "gauge", "Total number of inputs.", s.Inputs)
+		metric("padre_bytes_recovered", "gauge", "Bytes recovered of current input.", s.Done)
+		metric("padre_bytes_total", "gauge", "Bytes to recover of current input.", s.Total)
+		metric("padre_eta_seconds", "gauge", "Estimated time remaining for current input, 0 if unknown.", s.ETA)
+	}
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	metric("padre_inputs_done_total", "counter", "Inputs processed successfully.", m.inputsDone)
+	metric("padre_inputs_failed_total", "counter", "Inputs that failed.", m.inputsFailed)
+
+	const block = "padre_block_duration_seconds"
+	fmt.Fprintf(w, "# HELP %s Time spent on breaking single block.\n# TYPE %s histogram\n", block, block)
+	cumulative := 0
+	for i, bound := range blockBuckets {
+		if m.blockCounts != nil {
+			cumulative += m.blockCounts[i]
+		}
+		fmt.Fprintf(w, "%s_bucket{le=\"%v\"} %d\n", block, bound, cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", block, m.blockCount)
+	fmt.Fprintf(w, "%s_sum %v\n%s_count %d\n", block, m.blockSeconds, block, m.blockCount)
+}
+
+// ListenMetrics starts serving metrics over HTTP at given address (e.g. :9090), on any path.
+// Addr of returned server is the address actually listened on
+func ListenMetrics(addr string, m *Metrics) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &http.Server{Addr: listener.Addr().String(), Handler: m}
+	go server.Serve(listener)
+	return server, nil
+}
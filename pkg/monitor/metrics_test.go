@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	m := &Metrics{
+		Snapshot: func() *Snapshot { return &Snapshot{Input: 2, Inputs: 3, Done: 5, Total: 32, RPS: 40} },
+		Requests: func() (int, int, time.Duration) { return 200, 10, 4 * time.Second },
+	}
+	m.ObserveBlock(3 * time.Second)
+	m.ObserveBlock(20 * time.Second)
+	m.ObserveBlock(time.Hour)
+	m.ObserveInput(nil)
+	m.ObserveInput(errors.New("failed"))
+
+	buf := &bytes.Buffer{}
+	m.Write(buf)
+	text := buf.String()
+
+	for _, line := range []string{
+		"padre_requests_total 200",
+		"padre_request_errors_total 10",
+		"padre_request_latency_seconds_total 4",
+		"padre_request_error_ratio 0.05",
+		"padre_requests_per_second 40",
+		"padre_input 2",
+		"padre_bytes_recovered 5",
+		"padre_bytes_total 32",
+		"padre_inputs_done_total 1",
+		"padre_inputs_failed_total 1",
+		"# TYPE padre_block_duration_seconds histogram",
+		`padre_block_duration_seconds_bucket{le="1"} 0`,
+		`padre_block_duration_seconds_bucket{le="5"} 1`,
+		`padre_block_duration_seconds_bucket{le="30"} 2`,
+		`padre_block_duration_seconds_bucket{le="1800"} 2`,
+		`padre_block_duration_seconds_bucket{le="+Inf"} 3`,
+		"padre_block_duration_seconds_sum 3623",
+		"padre_block_duration_seconds_count 3",
+	} {
+		assert.Contains(t, text, line+"\n")
+	}
+}
+
+func TestListenMetrics(t *testing.T) {
+	m := &Metrics{}
+	m.ObserveInput(nil)
+
+	server, err := ListenMetrics("127.0.0.1:0", m)
+	require.NoError(t, err)
+	defer server.Close()
+
+	resp, err := http.Get("http://" + server.Addr + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/plain")
+	assert.Contains(t, string(body), "padre_inputs_done_total 1\n")
+
+	// address is taken
+	_, err = ListenMetrics(server.Addr, m)
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// socket files are named after this pattern, so they can be discovered by status queries
+const socketPattern = "padre-*.sock"
+
+// timeout for connecting to running instance
+const dialTimeout = 3 * time.Second
+
+// Snapshot - state of a running padre instance, reported on status query
+type Snapshot struct {
+	PID      int    `json:"pid"`
+	Mode     string `json:"mode"`
+	BlockLen int    `json:"block_len"`
+	Input    int    `json:"input"`    // number of currently processed input (starting from 1)
+	Inputs   int    `json:"inputs"`   // total count of inputs
+	Done     int    `json:"done"`     // count of bytes recovered so far
+	Total    int    `json:"total"`    // total count of bytes to recover
+	Output   string `json:"output"`   // output recovered so far (encoded)
+	Requests int    `json:"requests"` // total HTTP requests made
+	RPS      int    `json:"rps"`      // requests per second
+	ETA      int    `json:"eta"`      // estimated seconds remaining for current input, 0 if unknown
+}
+
+// Server - serves snapshots of current state over local unix socket
+type Server struct {
+	listener net.Listener
+	snapshot func() *Snapshot
+}
+
+// DefaultSocketPath returns socket path for a process with given PID
+func DefaultSocketPath(pid int) string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("padre-%d.sock", pid))
+}
+
+// FindSockets lists sockets of padre instances in default location
+func FindSockets() ([]string, error) {
+	return filepath.Glob(filepath.Join(os.TempDir(), socketPattern))
+}
+
+// Listen starts serving snapshots on unix socket at given path.
+// every connection receives single JSON-encoded snapshot, produced by snapshot function
+func Listen(path string, snapshot func() *Snapshot) (*Server, error) {
+	// remove stale socket, left by instance that was killed
+	if _, err := os.Stat(path); err == nil {
+		if _, err := Query(path); err == nil {
+			return nil, fmt.Errorf("socket %s is in use by another instance", path)
+		}
+		os.Remove(path)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		listener: listener,
+		snapshot: snapshot,
+	}
+	go s.serve()
+
+	return s, nil
+}
+
+// Close stops the server and removes the socket
+func (s *Server) Close() error {
+	return s.listener.Close()
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			// listener closed
+			return
+		}
+
+		json.NewEncoder(conn).Encode(s.snapshot())
+		conn.Close()
+	}
+}
+
+// Query retrieves snapshot from instance listening on given socket path
+func Query(path string) (*Snapshot, error) {
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+
+	snapshot := &Snapshot{}
+	if err = json.NewDecoder(conn).Decode(snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,39 @@
+package monitor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenAndQuery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "padre")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.sock")
+	want := &Snapshot{PID: 1, Mode: "decrypt", Input: 1, Inputs: 2, Done: 3, Total: 16, Output: "abc", Requests: 100, RPS: 10}
+
+	server, err := Listen(path, func() *Snapshot { return want })
+	require.NoError(t, err)
+
+	// query several times
+	for i := 0; i < 3; i++ {
+		got, err := Query(path)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	// second instance must not hijack active socket
+	_, err = Listen(path, func() *Snapshot { return want })
+	assert.Error(t, err)
+
+	// query after close must fail
+	require.NoError(t, server.Close())
+	_, err = Query(path)
+	assert.Error(t, err)
+}
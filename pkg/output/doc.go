@@ -0,0 +1,3 @@
+// Package output renders padre activity: prefixed printing, the status bar,
+// and routing of results into output sinks.
+package output
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/glebarez/padre/pkg/client"
 	"github.com/glebarez/padre/pkg/color"
 	"github.com/glebarez/padre/pkg/encoder"
 )
@@ -14,6 +15,15 @@ import (
 // output refresh frequency (times/second)
 const updateFreq = 13
 
+// output refresh interval of static bar
+const staticUpdateInterval = time.Second
+
+// DefaultProgressInterval - interval of progress lines in quiet mode, unless set in HackyBar.Interval
+const DefaultProgressInterval = 10 * time.Second
+
+// narrowest space that is worth showing the output in, only stats are shown otherwise
+const minOutputWidth = 5
+
 // HackyBar is the dynamically changing bar in status line.
 // The bar reflects current state of output calculation.
 // Apart from currently calculated part of output, it also shows yet-unknown part as a random mix of ASCII characters.
@@ -22,7 +32,7 @@ const updateFreq = 13
 type HackyBar struct {
 	// output info
 	printer       *Printer        // printer to use
-	outputData    []byte          // container for byte-output
+	outputData    []byte          // container for byte-output (in reverse order, as it is produced)
 	outputByteLen int             // total number of bytes in output (before encoding)
 	encoder       encoder.Encoder // encoder for the byte-output
 	Overflow      bool            // flag: terminal width overflowed, data was too wide
@@ -31,17 +41,45 @@ type HackyBar struct {
 	ChanOutput chan byte      // delivering every byte of output via this channel
 	ChanReq    chan byte      // to deliver indicator of yet-another http request made
 	wg         sync.WaitGroup // used to wait for gracefull exit after stop signal sent
+	mx         sync.Mutex     // guards data which is accessible from outside via Progress()
 
 	// RPS calculation
 	start        time.Time // the time of first request made, needed to properly calculate RPS
 	requestsMade int       // total requests made, needed to calculate RPS
 	rps          int       // RPS
 
+	// ETA calculation
+	recovered int // bytes of output recovered by requests (known bytes, delivered before any request, are not counted)
+
 	// the output properties
 	autoUpdateFreq time.Duration // interval at which the bar must be updated
 	encryptMode    bool          // whether encrypt mode is used
+
+	// if set, paused state of requests is reflected in the bar
+	Gate *client.Gate
+
+	// if set, the bar is not animated and refreshed rarely, this saves CPU on tiny machines
+	Static bool
+
+	// if set, the bar is not drawn at all. instead, progress is periodically printed as a separate line,
+	// so that output is readable in files and CI logs. only the final line shows the output
+	Quiet bool
+
+	// interval of progress lines in quiet mode, DefaultProgressInterval if not set
+	Interval time.Duration
+
+	// if set, progress is counted in blocks of given length, and unknown output is not animated:
+	// with hundreds of blocks, byte-level changes are mere noise
+	BlockLen int
+
+	// if not empty, describes throttling of requests (e.g. delay), shown next to effective RPS
+	Throttle string
+
+	// reason of premature stop, stated in the final line
+	stopReason string
 }
 
+// CreateHackyBar creates bar for output of given length (in bytes)
 func CreateHackyBar(encoder encoder.Encoder, outputByteLen int, encryptMode bool, printer *Printer) *HackyBar {
 	return &HackyBar{
 		outputData:     []byte{},
@@ -62,8 +100,27 @@ func (p *HackyBar) Stop() {
 	p.wg.Wait()
 }
 
+// StopWithReason stops the bar, the final line states the reason of stop (if not empty)
+func (p *HackyBar) StopWithReason(reason string) {
+	p.mx.Lock()
+	p.stopReason = reason
+	p.mx.Unlock()
+
+	p.Stop()
+}
+
 // starts the bar
 func (p *HackyBar) Start() {
+	if p.Static {
+		p.autoUpdateFreq = staticUpdateInterval
+	}
+	if p.Quiet {
+		p.autoUpdateFreq = DefaultProgressInterval
+		if p.Interval > 0 {
+			p.autoUpdateFreq = p.Interval
+		}
+	}
+	p.wg.Add(1)
 	go p.listenAndPrint()
 }
 
@@ -81,16 +138,32 @@ func (p *HackyBar) listenAndPrint() {
 		outputBytesReceived int
 	)
 
-	p.wg.Add(1)
 	defer p.wg.Done()
 
+	// quiet bar reports progress only after the interval
+	if p.Quiet {
+		lastPrint = time.Now()
+	}
+
+	// the bar is refreshed even when no events occur (e.g. when paused)
+	ticker := time.NewTicker(p.autoUpdateFreq)
+	defer ticker.Stop()
+
 	/* listen for incoming events */
 	for {
 		select {
+		/* time to refresh */
+		case <-ticker.C:
+
 		/* yet another output byte produced */
 		case b, ok := <-p.ChanOutput:
 			if ok {
-				p.outputData = append([]byte{b}, p.outputData...) //TODO: optimize this
+				p.mx.Lock()
+				p.outputData = append(p.outputData, b)
+				if p.requestsMade > 0 {
+					p.recovered++
+				}
+				p.mx.Unlock()
 				outputBytesReceived++
 			} else {
 				outputChanClosed = true
@@ -98,16 +171,17 @@ func (p *HackyBar) listenAndPrint() {
 
 		/* yet another HTTP request was made. Update stats */
 		case <-p.ChanReq:
+			p.mx.Lock()
 			if p.requestsMade == 0 {
 				p.start = time.Now()
 			}
 			p.requestsMade++
 
-			secsPassed := int(time.Since(p.start).Seconds())
-			if secsPassed > 0 {
-				p.rps = p.requestsMade / int(secsPassed)
+			secsPassed := time.Since(p.start).Seconds()
+			if secsPassed >= 1 {
+				p.rps = int(float64(p.requestsMade) / secsPassed)
 			}
-
+			p.mx.Unlock()
 		}
 
 		// the final status print
@@ -122,13 +196,68 @@ func (p *HackyBar) listenAndPrint() {
 
 		// usual output (still in progress)
 		if time.Since(lastPrint) > p.autoUpdateFreq {
-			statusString := p.buildStatusString(true)
-			p.printer.Printcr(statusString)
+			if p.Quiet {
+				p.printer.Println("progress " + p.buildStats())
+			} else {
+				p.printer.Printcr(p.buildStatusString(!p.Static))
+			}
 			lastPrint = time.Now()
 		}
 	}
 }
 
+// Progress is a point-in-time copy of bar's state
+type Progress struct {
+	Done     int           // count of output bytes produced
+	Total    int           // total count of output bytes
+	Output   string        // the output produced so far, encoded
+	Data     []byte        // the output produced so far, raw bytes (trailing part of total output)
+	Requests int           // total HTTP requests made
+	RPS      int           // requests per second
+	ETA      time.Duration // estimated time remaining, zero if unknown yet
+}
+
+// Progress returns current progress, safe for calling from other goroutines
+func (p *HackyBar) Progress() *Progress {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	return &Progress{
+		Done:     len(p.outputData),
+		Total:    p.outputByteLen,
+		Output:   p.encoder.EncodeToString(p.data(len(p.outputData))),
+		Data:     p.data(len(p.outputData)),
+		Requests: p.requestsMade,
+		RPS:      p.rps,
+		ETA:      p.eta(),
+	}
+}
+
+// estimates time remaining from bytes recovered per second, zero is returned if unknown.
+// must be called with mx held
+func (p *HackyBar) eta() time.Duration {
+	remaining := p.outputByteLen - len(p.outputData)
+	if p.recovered == 0 || remaining <= 0 {
+		return 0
+	}
+
+	perByte := time.Since(p.start) / time.Duration(p.recovered)
+	return (perByte * time.Duration(remaining)).Round(time.Second)
+}
+
+// returns first n bytes of output produced so far (in natural order)
+func (p *HackyBar) data(n int) []byte {
+	if n > len(p.outputData) {
+		n = len(p.outputData)
+	}
+
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = p.outputData[len(p.outputData)-1-i]
+	}
+	return data
+}
+
 /* constructs full status string to be displayed */
 func (p *HackyBar) buildStatusString(hacky bool) string {
 	/* the hacky-bar string is comprised of following parts |unknownOutput|knownOutput|stats|
@@ -142,22 +271,27 @@ func (p *HackyBar) buildStatusString(hacky bool) string {
 	if p.encryptMode {
 		unprocessedLen = len(p.encoder.EncodeToString(make([]byte, unprocessedLen)))
 	}
-	unknownOutput := unknownString(unprocessedLen, hacky)
-
-	/* generate known output */
-	knownOutput := p.encoder.EncodeToString(p.outputData)
+	unknownOutput := unknownString(unprocessedLen, hacky && p.BlockLen == 0)
 
 	/* generate stats */
-	stats := fmt.Sprintf(
-		"[%d/%d] | reqs: %d (%d/sec)", len(p.outputData), p.outputByteLen, p.requestsMade, p.rps)
+	stats := p.buildStats()
 
 	/* get available space */
-	availableSpace := p.printer.AvailableWidth - len(stats) - 1 // -1 is for the space between output and stats
-	if availableSpace < 5 {
-		// a general fool-check
-		panic("Your terminal is to narrow. Use a real one")
+	width := p.printer.Width()
+	availableSpace := width - color.TrueLen(stats) - 1 // -1 is for the space between output and stats
+	if availableSpace < minOutputWidth {
+		// terminal is too narrow to show the output, stats alone are better than nothing
+		if color.TrueLen(stats) > width {
+			return p.buildCounter()
+		}
+		return stats
 	}
 
+	/* generate known output
+	NOTE: every byte is encoded into at least one character, so there's no need to encode more bytes than available space
+	this keeps the bar cheap with huge outputs */
+	knownOutput := p.encoder.EncodeToString(p.data(availableSpace + 1))
+
 	/* if we have enough space, the logic is simple */
 	if availableSpace >= len(unknownOutput)+len(knownOutput) {
 		output := unknownOutput + color.HiGreenBold(knownOutput)
@@ -193,6 +327,40 @@ func (p *HackyBar) buildStatusString(hacky bool) string {
 	return fmt.Sprintf("%s %s", outputString, stats)
 }
 
+/* constructs stats part of status: progress, requests, rate, ETA and state */
+func (p *HackyBar) buildStats() string {
+	rate := fmt.Sprintf("%d/sec", p.rps)
+	if p.Throttle != "" {
+		rate += ", " + p.Throttle
+	}
+	stats := fmt.Sprintf("%s | reqs: %d (%s)", p.buildCounter(), p.requestsMade, rate)
+
+	p.mx.Lock()
+	stopReason := p.stopReason
+	eta := p.eta()
+	p.mx.Unlock()
+
+	if eta > 0 && stopReason == "" {
+		stats += fmt.Sprintf(" | ETA: %s", eta)
+	}
+
+	if stopReason != "" {
+		stats = color.RedBold("STOPPED: "+stopReason) + " " + stats
+	} else if p.Gate != nil && p.Gate.Paused() {
+		stats = color.YellowBold("PAUSED") + " " + stats
+	}
+	return stats
+}
+
+/* constructs counter of output produced: bytes, or complete blocks (see BlockLen) */
+func (p *HackyBar) buildCounter() string {
+	if p.BlockLen > 0 {
+		total := (p.outputByteLen + p.BlockLen - 1) / p.BlockLen
+		return fmt.Sprintf("[blocks %d/%d]", len(p.outputData)/p.BlockLen, total)
+	}
+	return fmt.Sprintf("[%d/%d]", len(p.outputData), p.outputByteLen)
+}
+
 /* generates string that represents the yet-unknown portion of output
 when in 'hacky' mode, will produce random characters form ASCII printable range*/
 func unknownString(n int, hacky bool) string {
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHackyBar_ETA(t *testing.T) {
+	bar := CreateHackyBar(encoder.NewASCIIencoder(), 16, false, &Printer{AvailableWidth: 80})
+
+	// nothing is recovered yet
+	assert.Zero(t, bar.Progress().ETA)
+
+	// 4 bytes in 8 seconds (2 of them known in advance), 12 bytes remain
+	bar.outputData = []byte("abcd")
+	bar.recovered = 2
+	bar.start = time.Now().Add(-8 * time.Second)
+	assert.Equal(t, 48*time.Second, bar.Progress().ETA)
+
+	// done
+	bar.outputData = make([]byte, 16)
+	assert.Zero(t, bar.Progress().ETA)
+}
+
+func TestHackyBar_Quiet(t *testing.T) {
+	buf := &bytes.Buffer{}
+	bar := CreateHackyBar(encoder.NewASCIIencoder(), 4, false, &Printer{Stream: buf, AvailableWidth: 80, Plain: true})
+	bar.Quiet = true
+
+	bar.Start()
+	for _, b := range []byte("abc") {
+		bar.ChanOutput <- b
+	}
+	bar.Stop()
+
+	// single final line, nothing is overwritten
+	assert.NotContains(t, buf.String(), _CR)
+	assert.Equal(t, 1, strings.Count(buf.String(), _LF))
+	// decrypted bytes come from the end
+	assert.Contains(t, buf.String(), "cba")
+}
+
+func TestHackyBar_Interval(t *testing.T) {
+	buf := &bytes.Buffer{}
+	bar := CreateHackyBar(encoder.NewASCIIencoder(), 4, false, &Printer{Stream: buf, AvailableWidth: 80, Plain: true})
+	bar.Quiet = true
+	bar.Interval = 10 * time.Millisecond
+
+	bar.Start()
+	bar.ChanOutput <- 'a'
+	time.Sleep(50 * time.Millisecond)
+	bar.Stop()
+
+	// progress lines are printed before the final one
+	assert.Contains(t, buf.String(), "progress [1/4]")
+}
+
+func TestHackyBar_Narrow(t *testing.T) {
+	printer := &Printer{AvailableWidth: 80}
+	bar := CreateHackyBar(encoder.NewASCIIencoder(), 16, false, printer)
+	bar.outputData = []byte("abcd")
+
+	assert.Contains(t, bar.buildStatusString(false), "dcba")
+
+	// only stats fit
+	printer.Resize(27)
+	assert.Equal(t, "[4/16] | reqs: 0 (0/sec)", bar.buildStatusString(false))
+
+	// not even stats
+	printer.Resize(10)
+	assert.Equal(t, "[4/16]", bar.buildStatusString(false))
+
+	// prefixes are accounted
+	printer.AddPrefix("[1/1]", false)
+	printer.Resize(80)
+	assert.Equal(t, 74, printer.Width())
+}
+
+func TestHackyBar_Blocks(t *testing.T) {
+	buf := &bytes.Buffer{}
+	bar := CreateHackyBar(encoder.NewASCIIencoder(), 48, false, &Printer{Stream: buf, AvailableWidth: 120, Plain: true})
+	bar.BlockLen = 16
+
+	bar.outputData = []byte("0123456789abcdefghij")
+	status := bar.buildStatusString(true)
+	assert.Contains(t, status, "[blocks 1/3]")
+
+	// unknown part is not animated
+	assert.Contains(t, status, strings.Repeat("_", 28))
+}
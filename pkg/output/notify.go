@@ -0,0 +1,162 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// payload formats of notifications
+const (
+	NotifyJSON    = "json"    // generic JSON document, see Notification
+	NotifySlack   = "slack"   // Slack incoming webhook: {"text": ...}
+	NotifyDiscord = "discord" // Discord webhook: {"content": ...}
+)
+
+// notifications are dropped, if that many are waiting for delivery
+const notifyQueueSize = 64
+
+// Notification - event of the attack, delivered to webhook
+type Notification struct {
+	Event   string `json:"event"` // start, progress, result or finish
+	Message string `json:"message"`
+	Mode    string `json:"mode,omitempty"`
+	Input   int    `json:"input,omitempty"` // number of input (starting from 1)
+	Inputs  int    `json:"inputs,omitempty"`
+	Percent int    `json:"percent,omitempty"` // progress of current input
+	Output  string `json:"output,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Notifier POSTs notifications to webhook in background, so that slow webhook does not delay the attack
+type Notifier struct {
+	url    string
+	format string
+	client *http.Client
+	queue  chan *Notification
+	done   chan struct{}
+
+	// if not nil, called when notification could not be delivered
+	OnError func(error)
+
+	closeOnce sync.Once
+}
+
+// DetectNotifyFormat chooses payload format by webhook URL: Slack and Discord webhooks are recognized by host
+func DetectNotifyFormat(webhook string) string {
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return NotifyJSON
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case host == "hooks.slack.com":
+		return NotifySlack
+	case (host == "discord.com" || host == "discordapp.com") && strings.HasPrefix(u.Path, "/api/webhooks/"):
+		return NotifyDiscord
+	}
+	return NotifyJSON
+}
+
+// NewNotifier creates notifier that delivers to webhook URL in given format (empty means detected by URL)
+func NewNotifier(webhook, format string) (*Notifier, error) {
+	if format == "" {
+		format = DetectNotifyFormat(webhook)
+	}
+	switch format {
+	case NotifyJSON, NotifySlack, NotifyDiscord:
+	default:
+		return nil, fmt.Errorf("unsupported notification format: %q", format)
+	}
+
+	n := &Notifier{
+		url:    webhook,
+		format: format,
+		client: &http.Client{Timeout: sinkTimeout},
+		queue:  make(chan *Notification, notifyQueueSize),
+		done:   make(chan struct{}),
+	}
+	go n.deliver()
+	return n, nil
+}
+
+// Notify queues notification for delivery, it never blocks. nil notifier discards notifications
+func (n *Notifier) Notify(notification *Notification) {
+	if n == nil {
+		return
+	}
+	select {
+	case n.queue <- notification:
+	default:
+		n.fail(fmt.Errorf("notification queue is full, %q is dropped", notification.Message))
+	}
+}
+
+// Close waits for queued notifications to be delivered, but no longer than timeout
+func (n *Notifier) Close(timeout time.Duration) {
+	if n == nil {
+		return
+	}
+	n.closeOnce.Do(func() { close(n.queue) })
+	select {
+	case <-n.done:
+	case <-time.After(timeout):
+	}
+}
+
+func (n *Notifier) deliver() {
+	defer close(n.done)
+	for notification := range n.queue {
+		if err := n.send(notification); err != nil {
+			n.fail(err)
+		}
+	}
+}
+
+func (n *Notifier) fail(err error) {
+	if n.OnError != nil {
+		n.OnError(err)
+	}
+}
+
+func (n *Notifier) send(notification *Notification) error {
+	body, err := json.Marshal(n.payload(notification))
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// chat webhooks take plain text, the output is put into code block
+func (n *Notifier) payload(notification *Notification) interface{} {
+	text := "padre: " + notification.Message
+	if notification.Output != "" {
+		text += "\n```" + notification.Output + "```"
+	}
+	if notification.Error != "" {
+		text += "\nerror: " + notification.Error
+	}
+
+	switch n.format {
+	case NotifySlack:
+		return map[string]string{"text": text}
+	case NotifyDiscord:
+		return map[string]string{"content": text}
+	}
+	return notification
+}
@@ -0,0 +1,70 @@
+package output
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectNotifyFormat(t *testing.T) {
+	assert.Equal(t, NotifySlack, DetectNotifyFormat("https://hooks.slack.com/services/T0/B0/x"))
+	assert.Equal(t, NotifyDiscord, DetectNotifyFormat("https://discord.com/api/webhooks/1/x"))
+	assert.Equal(t, NotifyDiscord, DetectNotifyFormat("https://discordapp.com/api/webhooks/1/x"))
+	assert.Equal(t, NotifyJSON, DetectNotifyFormat("https://discord.com/channels/1"))
+	assert.Equal(t, NotifyJSON, DetectNotifyFormat("http://localhost:8080/hook"))
+}
+
+func TestNotifier(t *testing.T) {
+	received := make(chan map[string]interface{}, 4)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		payload := make(map[string]interface{})
+		assert.NoError(t, json.Unmarshal(body, &payload))
+		received <- payload
+	}))
+	defer ts.Close()
+
+	// generic JSON
+	n, err := NewNotifier(ts.URL, "")
+	require.NoError(t, err)
+	n.Notify(&Notification{Event: "progress", Message: "50% done", Input: 1, Inputs: 2, Percent: 50})
+	n.Close(time.Second)
+	assert.Equal(t, map[string]interface{}{
+		"event": "progress", "message": "50% done", "input": 1.0, "inputs": 2.0, "percent": 50.0,
+	}, <-received)
+
+	// chat
+	n, err = NewNotifier(ts.URL, NotifySlack)
+	require.NoError(t, err)
+	n.Notify(&Notification{Event: "result", Message: "input 1/1 decrypted", Output: "secret"})
+	n.Close(time.Second)
+	assert.Equal(t, map[string]interface{}{"text": "padre: input 1/1 decrypted\n```secret```"}, <-received)
+
+	_, err = NewNotifier(ts.URL, "telegram")
+	assert.Error(t, err)
+}
+
+func TestNotifierError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	var errs []error
+	n, err := NewNotifier(ts.URL, NotifyDiscord)
+	require.NoError(t, err)
+	n.OnError = func(err error) { errs = append(errs, err) }
+	n.Notify(&Notification{Event: "finish", Message: "done"})
+	n.Close(time.Second)
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "403")
+}
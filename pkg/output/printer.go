@@ -1,8 +1,13 @@
 package output
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/glebarez/padre/pkg/color"
 )
@@ -13,26 +18,80 @@ const (
 	_CR = "\x1b\x5b2K\r" // Clear Line + CR Carret return
 )
 
+// verbosity levels, see Printer.Log
+const (
+	LevelVerbose = 1 // decisions and milestones (-v)
+	LevelDebug   = 2 // every response and its classification (-vv)
+)
+
 // Printer is the printing facility
 type Printer struct {
-	Stream         io.Writer // the ultimate stream to print into
-	AvailableWidth int       // available terminal width
-	cr             bool      // flag: caret return requested on next print (= print on same line please)
-	prefix         *prefix   // current  prefix to use
+	Stream         io.Writer     // the ultimate stream to print into
+	AvailableWidth int           // available terminal width (minus prefixes), changed with Resize once printing started
+	Verbosity      int           // messages of higher levels are not printed, see Log
+	Plain          bool          // no line overwriting: everything stays on its own line (for files and CI logs)
+	Timestamps     bool          // every line starts with the time it was printed at (for container logs)
+	Silent         bool          // nothing is printed but errors (when output is consumed by other tools)
+	cr             bool          // flag: caret return requested on next print (= print on same line please)
+	midLine        bool          // flag: something is already printed on current line
+	prefix         *prefix       // current  prefix to use
+	mx             sync.Mutex    // guards held buffer and width
+	held           *bytes.Buffer // output is accumulated here while printer is on hold
 }
 
 // base internal print, everyone else must build upon this
 func (p *Printer) print(s string) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if p.Silent {
+		return
+	}
+	if p.held != nil {
+		p.held.WriteString(s)
+		return
+	}
 	fmt.Fprint(p.Stream, s)
 }
 
+// Hold suspends the output, until Release is called.
+// printed contents is not lost, but accumulated.
+// this is useful when the terminal must be temporarily given to someone else (e.g. to ask user a question)
+func (p *Printer) Hold() {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if p.held == nil {
+		p.held = &bytes.Buffer{}
+	}
+}
+
+// Release resumes the output, contents accumulated during hold is printed out
+func (p *Printer) Release() {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if p.held != nil {
+		p.Stream.Write(p.held.Bytes())
+		p.held = nil
+	}
+}
+
+// Print prints string with current prefix
 func (p *Printer) Print(s string) {
 	// CR debt ?
 	if p.cr {
 		p.print(_CR)
 		p.cr = false
+		p.midLine = false
 	}
 
+	// time goes before everything else on the line
+	if p.Timestamps && !p.midLine {
+		p.print(time.Now().Format(time.RFC3339) + space)
+	}
+	p.midLine = true
+
 	// prefix
 	if p.prefix != nil {
 		p.print(p.prefix.string())
@@ -44,18 +103,47 @@ func (p *Printer) Print(s string) {
 
 // AddPrefix adds one more prefix to current printer
 func (p *Printer) AddPrefix(s string, paragraph bool) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
 	p.prefix = newPrefix(s, p.prefix, paragraph)
 	p.AvailableWidth -= p.prefix.len
 }
 
+// RemovePrefix removes innermost prefix
 func (p *Printer) RemovePrefix() {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
 	p.AvailableWidth += p.prefix.len
 	p.prefix = p.prefix.outterPrefix
 }
 
+// Resize sets new width of terminal, e.g. when terminal window was resized.
+// space taken by current prefixes is accounted
+func (p *Printer) Resize(width int) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	for pr := p.prefix; pr != nil; pr = pr.outterPrefix {
+		width -= pr.len
+	}
+	p.AvailableWidth = width
+}
+
+// Width returns currently available width, safe for calling from other goroutines
+func (p *Printer) Width() int {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	return p.AvailableWidth
+}
+
+// Println prints string and feeds the line
 func (p *Printer) Println(s string) {
 	p.Print(s)
 	p.print(_LF)
+	p.midLine = false
 
 	// set flag that line was feeded
 	if p.prefix != nil {
@@ -63,7 +151,12 @@ func (p *Printer) Println(s string) {
 	}
 }
 
+// Printcr prints string, next print will overwrite the line (unless printer is plain)
 func (p *Printer) Printcr(s string) {
+	if p.Plain {
+		p.Println(s)
+		return
+	}
 	p.Print(s)
 	p.cr = true
 }
@@ -78,7 +171,6 @@ func (p *Printer) Printlnf(format string, a ...interface{}) {
 
 func (p *Printer) Printcrf(format string, a ...interface{}) {
 	p.Printcr(fmt.Sprintf(format, a...))
-	p.cr = true
 }
 
 func (p *Printer) PrintWithPrefix(prefix, message string) {
@@ -88,6 +180,13 @@ func (p *Printer) PrintWithPrefix(prefix, message string) {
 }
 
 func (p *Printer) Error(err error) {
+	// errors get through silence, on a line of their own
+	if p.Silent {
+		p.mx.Lock()
+		defer p.mx.Unlock()
+		fmt.Fprintln(p.Stream, color.RedBold("[-]")+" "+color.Red(err))
+		return
+	}
 	p.PrintWithPrefix(color.RedBold("[-]"), color.Red(err))
 }
 
@@ -114,3 +213,30 @@ func (p *Printer) Info(format string, a ...interface{}) {
 func (p *Printer) Action(s string) {
 	p.Printcr(color.Yellow(s))
 }
+
+// Log prints structured message, if verbosity allows it.
+// fields are key-value pairs, printed as key=value after the message.
+// safe to call while status bar is running
+func (p *Printer) Log(level int, msg string, fields ...interface{}) {
+	if level > p.Verbosity {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i+1 < len(fields); i += 2 {
+		value := fmt.Sprint(fields[i+1])
+		if value == "" || strings.ContainsAny(value, " \t\r\n\"=") {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&b, " %s=%s", color.Cyan(fields[i]), value)
+	}
+
+	tag := "[v]"
+	if level >= LevelDebug {
+		tag = "[vv]"
+	}
+
+	// the tag is not added as prefix, so that the status bar (printed concurrently) is not affected
+	p.Println(color.Bold(tag) + " " + b.String())
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrinter_Timestamps(t *testing.T) {
+	buf := &bytes.Buffer{}
+	p := &Printer{Stream: buf, AvailableWidth: 80, Plain: true, Timestamps: true}
+
+	p.Print("one, ")
+	p.Println("still one")
+	p.AddPrefix("[1/2]", true)
+	p.Printcr("progress")
+	p.Println("two")
+	p.RemovePrefix()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), _LF), _LF)
+	require.Len(t, lines, 3)
+
+	// the time goes first, once per line
+	timestamp := regexp.MustCompile(`^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(Z|[+-]\d\d:\d\d) `)
+	for _, line := range lines {
+		assert.Regexp(t, timestamp, line)
+		assert.Len(t, timestamp.FindAllString(line, -1), 1)
+	}
+	assert.True(t, strings.HasSuffix(lines[0], " one, still one"))
+	assert.True(t, strings.HasSuffix(lines[1], " [1/2] progress"))
+}
+
+func TestPrinter_Silent(t *testing.T) {
+	buf := &bytes.Buffer{}
+	p := &Printer{Stream: buf, AvailableWidth: 80, Silent: true}
+
+	p.Info("padre is on duty")
+	p.Printcr("progress")
+	p.Warning("something odd")
+	p.Errorf("it failed")
+	p.Println("done")
+
+	assert.Equal(t, 1, strings.Count(buf.String(), _LF))
+	assert.Contains(t, buf.String(), "it failed")
+	assert.NotContains(t, buf.String(), "padre")
+}
@@ -0,0 +1,111 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/glebarez/padre/pkg/encoder"
+)
+
+// Result - outcome of processing a single input
+type Result struct {
+	Mode   string       // encrypt, decrypt, forge or rsa
+	Input  string       // input as it was passed to padre
+	Output []byte       // produced output (not encoded)
+	Err    error        // error occurred during processing, if any
+	Binary bool         // output is not a printable text, writing it to terminal as-is may corrupt the terminal
+	Blocks []BlockStats // metrics of blocks, if collected
+
+	// output recovered before the error, unknown parts are marked
+	Partial []byte
+}
+
+// BlockStats - metrics of one block of input, included into structured records
+type BlockStats struct {
+	Mode      string  `json:"mode"`  // decrypt or encrypt (forging does both)
+	Block     int     `json:"block"` // block of ciphertext when decrypting (IV is the 1st), block of plaintext when encrypting
+	Requests  int64   `json:"requests"`
+	Retries   int     `json:"retries"`
+	Seconds   float64 `json:"seconds"`
+	Ambiguous bool    `json:"ambiguous_last_byte,omitempty"` // last byte had more than one valid value
+	Known     bool    `json:"known,omitempty"`               // intermediate was known, no requests were made
+}
+
+// ErrBinaryOutput is returned by sinks that refused to write binary output into terminal.
+// hexdump of output is written instead
+var ErrBinaryOutput = errors.New("binary output was not written to terminal as-is, hexdump is shown instead")
+
+// Sink - destination for results
+type Sink interface {
+	Write(*Result) error
+	Close() error
+}
+
+// SinkOptions - per-sink settings of how result is rendered
+type SinkOptions struct {
+	Encoder  encoder.Encoder // encoder for output bytes, nil means raw bytes
+	Redact   bool            // do not reveal input and output, only their lengths
+	ForceRaw bool            // write binary outputs as-is, even if destination is a terminal
+}
+
+// render output according to options
+func (o *SinkOptions) output(r *Result) string {
+	if o.Redact {
+		return redacted(len(r.Output))
+	}
+	if o.Encoder == nil {
+		return string(r.Output)
+	}
+	return o.Encoder.EncodeToString(r.Output)
+}
+
+// render input according to options
+func (o *SinkOptions) input(r *Result) string {
+	if o.Redact {
+		return redacted(len(r.Input))
+	}
+	return r.Input
+}
+
+func redacted(length int) string {
+	return fmt.Sprintf("<redacted: %d bytes>", length)
+}
+
+// Router delivers results into multiple sinks simultaneously
+type Router struct {
+	sinks []Sink
+}
+
+// Add adds one more sink to router
+func (r *Router) Add(s Sink) {
+	r.sinks = append(r.sinks, s)
+}
+
+// Len returns number of sinks in router
+func (r *Router) Len() int {
+	return len(r.sinks)
+}
+
+// Write writes result into every sink.
+// failure of one sink does not prevent others from being written, the first error is returned.
+// ErrBinaryOutput is returned only if no other error occurred
+func (r *Router) Write(res *Result) error {
+	var firstErr error
+	for _, s := range r.sinks {
+		if err := s.Write(res); err != nil && (firstErr == nil || firstErr == ErrBinaryOutput) {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close closes every sink, the first error is returned
+func (r *Router) Close() error {
+	var firstErr error
+	for _, s := range r.sinks {
+		if err := s.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
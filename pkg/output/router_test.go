@@ -0,0 +1,120 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nopCloser struct {
+	bytes.Buffer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func TestRouter(t *testing.T) {
+	// webhook receiver
+	webhookChan := make(chan *record, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		rec := &record{}
+		assert.NoError(t, json.Unmarshal(body, rec))
+		webhookChan <- rec
+	}))
+	defer ts.Close()
+
+	stream := &bytes.Buffer{}
+	jsonStream := &nopCloser{}
+
+	router := &Router{}
+	router.Add(NewStreamSink(stream, SinkOptions{}))
+	router.Add(NewJSONSink(jsonStream, SinkOptions{Encoder: encoder.NewLHEXencoder("")}))
+	router.Add(NewWebhookSink(ts.URL, SinkOptions{Redact: true}))
+	assert.Equal(t, 3, router.Len())
+
+	require.NoError(t, router.Write(&Result{Mode: "decrypt", Input: "in1", Output: []byte("out1")}))
+	require.NoError(t, router.Write(&Result{Mode: "decrypt", Input: "in2", Err: errors.New("failed")}))
+	require.NoError(t, router.Close())
+
+	// stream sink skips errors, writes raw bytes
+	assert.Equal(t, "out1\n", stream.String())
+
+	// json sink writes everything, encoded
+	assert.Equal(t,
+		`{"mode":"decrypt","input":"in1","output":"6f757431"}`+"\n"+
+			`{"mode":"decrypt","input":"in2","error":"failed"}`+"\n",
+		jsonStream.String())
+
+	// webhook is redacted
+	assert.Equal(t, &record{Mode: "decrypt", Input: "<redacted: 3 bytes>", Output: "<redacted: 4 bytes>"}, <-webhookChan)
+	assert.Equal(t, &record{Mode: "decrypt", Input: "<redacted: 3 bytes>", Error: "failed"}, <-webhookChan)
+}
+
+func TestJSONSink_Binary(t *testing.T) {
+	jsonStream := &nopCloser{}
+	sink := NewJSONSink(jsonStream, SinkOptions{})
+
+	// raw text is kept as is, bytes that are not valid UTF-8 are base64-encoded
+	require.NoError(t, sink.Write(&Result{Mode: "decrypt", Input: "in1", Output: []byte("out1")}))
+	require.NoError(t, sink.Write(&Result{Mode: "decrypt", Input: "in2", Output: []byte{0, 1, 0xff}}))
+	require.NoError(t, sink.Write(&Result{Mode: "decrypt", Input: "in3", Err: errors.New("failed"), Partial: []byte{0xfe, '?'}}))
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t,
+		`{"mode":"decrypt","input":"in1","output":"out1"}`+"\n"+
+			`{"mode":"decrypt","input":"in2","output":"AAH/","encoding":"b64"}`+"\n"+
+			`{"mode":"decrypt","input":"in3","error":"failed","partial":"/j8=","encoding":"b64"}`+"\n",
+		jsonStream.String())
+}
+
+func TestFileSink(t *testing.T) {
+	f, err := ioutil.TempFile("", "padre")
+	require.NoError(t, err)
+	f.Close()
+	defer os.Remove(f.Name())
+
+	sink, err := NewFileSink(f.Name(), SinkOptions{})
+	require.NoError(t, err)
+
+	// binary outputs must be written verbatim
+	require.NoError(t, sink.Write(&Result{Output: []byte{0, 1, 0xff}}))
+	require.NoError(t, sink.Write(&Result{Err: errors.New("skipped")}))
+	require.NoError(t, sink.Write(&Result{Output: []byte{'\n', 2}}))
+	require.NoError(t, sink.Close())
+
+	written, err := ioutil.ReadFile(f.Name())
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 1, 0xff, '\n', 2}, written)
+}
+
+func TestStreamSink_BinaryToTerminal(t *testing.T) {
+	binary := &Result{Output: []byte{0x1b, '[', '2', 'J'}, Binary: true}
+
+	// terminal gets hexdump instead of raw bytes
+	buf := &bytes.Buffer{}
+	sink := &streamSink{w: buf, separator: "\n", terminal: true}
+	assert.Equal(t, ErrBinaryOutput, sink.Write(binary))
+	assert.Equal(t, "00000000  1b 5b 32 4a                                       |.[2J|\n", buf.String())
+
+	// unless raw bytes are forced
+	buf.Reset()
+	sink.opts.ForceRaw = true
+	require.NoError(t, sink.Write(binary))
+	assert.Equal(t, "\x1b[2J\n", buf.String())
+
+	// pipes always get raw bytes
+	buf.Reset()
+	require.NoError(t, NewStreamSink(buf, SinkOptions{}).Write(binary))
+	assert.Equal(t, "\x1b[2J\n", buf.String())
+}
@@ -0,0 +1,213 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// timeout for delivering result to remote sinks
+const sinkTimeout = 10 * time.Second
+
+// record is a JSON representation of result, used by structured sinks
+type record struct {
+	Mode   string `json:"mode"`
+	Input  string `json:"input"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+
+	// recovered before the error, unknown blocks are marked
+	Partial string `json:"partial,omitempty"`
+
+	// set to b64 when raw output (or partial) is not valid UTF-8, and is base64-encoded to fit into JSON string
+	Encoding string `json:"encoding,omitempty"`
+
+	Blocks []BlockStats `json:"blocks,omitempty"`
+}
+
+func newRecord(r *Result, opts *SinkOptions) *record {
+	rec := &record{
+		Mode:   r.Mode,
+		Input:  opts.input(r),
+		Blocks: r.Blocks,
+	}
+
+	if r.Err != nil {
+		rec.Error = r.Err.Error()
+		if r.Partial != nil {
+			// markers are text, so partial output is not encoded
+			rec.Partial = rec.jsonSafe(&Result{Output: r.Partial}, &SinkOptions{Redact: opts.Redact})
+		}
+	} else {
+		rec.Output = rec.jsonSafe(r, opts)
+	}
+	return rec
+}
+
+// renders output, raw bytes that are not valid UTF-8 are base64-encoded (and marked in Encoding)
+func (rec *record) jsonSafe(r *Result, opts *SinkOptions) string {
+	if opts.Encoder == nil && !opts.Redact && !utf8.Valid(r.Output) {
+		rec.Encoding = "b64"
+		return encoder.NewB64encoder("").EncodeToString(r.Output)
+	}
+	return opts.output(r)
+}
+
+/* stream sink: writes successful outputs one after another */
+type streamSink struct {
+	w         io.Writer
+	opts      SinkOptions
+	separator string // written after every output
+	terminal  bool   // w is a terminal
+}
+
+// checks whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && util.IsTerminal(f)
+}
+
+// NewStreamSink creates sink that writes every successful output as a separate line into w.
+// erroneous results are skipped.
+// if w is a terminal, binary outputs are written as hexdump, unless raw bytes are forced with options,
+// this is to prevent corruption of the terminal (similar to git and less)
+func NewStreamSink(w io.Writer, opts SinkOptions) Sink {
+	return &streamSink{w, opts, "\n", isTerminal(w)}
+}
+
+// NewFileSink creates sink that writes successful outputs into a file (truncated if exists) verbatim:
+// no separators are added, so with raw encoding the file holds exact bytes of output
+func NewFileSink(path string, opts SinkOptions) (Sink, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &streamSink{f, opts, "", isTerminal(f)}, nil
+}
+
+func (s *streamSink) Write(r *Result) error {
+	if r.Err != nil {
+		return nil
+	}
+
+	// refuse to write binary into terminal
+	if s.terminal && r.Binary && s.opts.Encoder == nil && !s.opts.Redact && !s.opts.ForceRaw {
+		if _, err := io.WriteString(s.w, encoder.NewHexdumpEncoder().EncodeToString(r.Output)); err != nil {
+			return err
+		}
+		return ErrBinaryOutput
+	}
+
+	_, err := io.WriteString(s.w, s.opts.output(r)+s.separator)
+	return err
+}
+
+func (s *streamSink) Close() error {
+	if c, ok := s.w.(io.Closer); ok && s.w != os.Stdout {
+		return c.Close()
+	}
+	return nil
+}
+
+/* JSON sink: writes every result as JSON line */
+type jsonSink struct {
+	wc   io.WriteCloser
+	enc  *json.Encoder
+	opts SinkOptions
+}
+
+// NewJSONSink creates sink that writes results as JSON lines into wc
+func NewJSONSink(wc io.WriteCloser, opts SinkOptions) Sink {
+	enc := json.NewEncoder(wc)
+	enc.SetEscapeHTML(false)
+	return &jsonSink{wc, enc, opts}
+}
+
+// NewJSONFileSink creates JSON sink that writes into a file (truncated if exists)
+func NewJSONFileSink(path string, opts SinkOptions) (Sink, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONSink(f, opts), nil
+}
+
+// NewSocketSink creates JSON sink that writes into a socket.
+// address is in form of URL: tcp://host:port or unix:///path/to/socket
+func NewSocketSink(address string, opts SinkOptions) (Sink, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+
+	var conn net.Conn
+	switch u.Scheme {
+	case "tcp":
+		conn, err = net.DialTimeout("tcp", u.Host, sinkTimeout)
+	case "unix":
+		conn, err = net.DialTimeout("unix", u.Path, sinkTimeout)
+	default:
+		return nil, fmt.Errorf("unsupported socket type: %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewJSONSink(conn, opts), nil
+}
+
+func (s *jsonSink) Write(r *Result) error {
+	return s.enc.Encode(newRecord(r, &s.opts))
+}
+
+func (s *jsonSink) Close() error {
+	return s.wc.Close()
+}
+
+/* webhook sink: POSTs every result as JSON */
+type webhookSink struct {
+	url    string
+	client *http.Client
+	opts   SinkOptions
+}
+
+// NewWebhookSink creates sink that POSTs every result as JSON document to given URL
+func NewWebhookSink(url string, opts SinkOptions) Sink {
+	return &webhookSink{
+		url:    url,
+		client: &http.Client{Timeout: sinkTimeout},
+		opts:   opts,
+	}
+}
+
+func (s *webhookSink) Write(r *Result) error {
+	body, err := json.Marshal(newRecord(r, &s.opts))
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *webhookSink) Close() error {
+	return nil
+}
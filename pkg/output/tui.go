@@ -0,0 +1,395 @@
+//go:build !notui
+// +build !notui
+
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/color"
+	"github.com/nsf/termbox-go"
+)
+
+// TUIIncluded tells whether TUI is compiled in (excluded with build tag notui)
+const TUIIncluded = true
+
+const (
+	tuiLogLines    = 200 // how many lines of log are kept
+	tuiGraphPoints = 60  // how many points are displayed in graphs
+)
+
+// sparkline levels for graphs
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// TUI is a full-screen alternative to the status bar.
+// It shows per-block progress map, live RPS/latency graphs, recent errors and log,
+// and handles keyboard controls: pause/resume, adjust concurrency, quit.
+// TUI is also a writer: text written into it goes to the log pane
+type TUI struct {
+	mx sync.Mutex
+
+	// data sources
+	gate  *client.Gate
+	stats *client.Stats
+	bar   *HackyBar // status bar of currently processed input
+
+	// what is being processed
+	title    string
+	mode     string
+	input    int
+	inputs   int
+	blockLen int
+
+	// log pane
+	log     []string
+	curLine string
+
+	// graphs
+	rpsPoints     []int
+	latencyPoints []int // in milliseconds
+	lastStats     client.StatsSnapshot
+
+	// called when user requests to quit
+	onQuit func()
+
+	// lifecycle
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewTUI creates TUI. gate and stats are sources of control and metrics for HTTP client.
+// onQuit is called when user requests to quit
+func NewTUI(title, mode string, gate *client.Gate, stats *client.Stats, onQuit func()) *TUI {
+	return &TUI{
+		title:  title,
+		mode:   mode,
+		gate:   gate,
+		stats:  stats,
+		onQuit: onQuit,
+		quit:   make(chan struct{}),
+	}
+}
+
+// Start takes over the terminal. returns error if terminal is not capable
+func (t *TUI) Start() error {
+	if err := termbox.Init(); err != nil {
+		return err
+	}
+
+	t.wg.Add(2)
+	go t.handleKeys()
+	go t.refresh()
+	return nil
+}
+
+// Stop releases the terminal, and writes the accumulated log into w (if not nil)
+func (t *TUI) Stop(w io.Writer) {
+	close(t.quit)
+	termbox.Interrupt()
+	t.wg.Wait()
+	termbox.Close()
+
+	if w != nil {
+		t.mx.Lock()
+		defer t.mx.Unlock()
+
+		for _, line := range t.log {
+			fmt.Fprintln(w, line)
+		}
+		if t.curLine != "" {
+			fmt.Fprintln(w, t.curLine)
+		}
+	}
+}
+
+// Width returns width of terminal
+func (t *TUI) Width() int {
+	w, _ := termbox.Size()
+	return w
+}
+
+// Track sets currently processed input along with its status bar
+func (t *TUI) Track(input, inputs, blockLen int, bar *HackyBar) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	t.input, t.inputs, t.blockLen, t.bar = input, inputs, blockLen, bar
+}
+
+// Write appends text to log pane. Carriage returns overwrite the current line
+func (t *TUI) Write(p []byte) (int, error) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	s := strings.Replace(string(p), _CR, "\r", -1)
+	s = color.StripColor(s)
+
+	for _, r := range s {
+		switch r {
+		case '\n':
+			t.log = append(t.log, t.curLine)
+			if len(t.log) > tuiLogLines {
+				t.log = t.log[1:]
+			}
+			t.curLine = ""
+		case '\r':
+			t.curLine = ""
+		default:
+			t.curLine += string(r)
+		}
+	}
+	return len(p), nil
+}
+
+func (t *TUI) handleKeys() {
+	defer t.wg.Done()
+
+	for {
+		ev := termbox.PollEvent()
+
+		select {
+		case <-t.quit:
+			return
+		default:
+		}
+
+		if ev.Type != termbox.EventKey {
+			continue
+		}
+
+		switch {
+		case ev.Key == termbox.KeySpace:
+			t.gate.Toggle()
+		case ev.Ch == '+':
+			t.gate.SetLimit(t.gate.Limit() + 1)
+		case ev.Ch == '-':
+			t.gate.SetLimit(t.gate.Limit() - 1)
+		case ev.Ch == 'q' || ev.Key == termbox.KeyCtrlC:
+			if t.onQuit != nil {
+				go t.onQuit()
+			}
+		}
+		t.draw()
+	}
+}
+
+func (t *TUI) refresh() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(time.Second / updateFreq)
+	defer ticker.Stop()
+
+	graphTicker := time.NewTicker(time.Second)
+	defer graphTicker.Stop()
+
+	for {
+		select {
+		case <-t.quit:
+			return
+		case <-graphTicker.C:
+			t.sampleGraphs()
+		case <-ticker.C:
+			t.draw()
+		}
+	}
+}
+
+// takes one sample of metrics for graphs
+func (t *TUI) sampleGraphs() {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	s := t.stats.Snapshot()
+	requests := s.Requests - t.lastStats.Requests
+
+	latency := 0
+	if requests > 0 {
+		latency = int((s.Latency - t.lastStats.Latency).Milliseconds()) / requests
+	}
+	t.lastStats = s
+
+	t.rpsPoints = appendPoint(t.rpsPoints, requests)
+	t.latencyPoints = appendPoint(t.latencyPoints, latency)
+}
+
+func appendPoint(points []int, p int) []int {
+	points = append(points, p)
+	if len(points) > tuiGraphPoints {
+		points = points[1:]
+	}
+	return points
+}
+
+/* drawing */
+
+func (t *TUI) draw() {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
+	width, height := termbox.Size()
+
+	// header
+	state := "RUNNING"
+	stateColor := termbox.ColorGreen
+	if t.gate.Paused() {
+		state, stateColor = "PAUSED", termbox.ColorYellow
+	}
+	y := 0
+	x := drawText(0, y, t.title, termbox.ColorCyan|termbox.AttrBold)
+	x = drawText(x+1, y, fmt.Sprintf("| %s | input %d/%d | concurrency: %d |", t.mode, t.input, t.inputs, t.gate.Limit()), termbox.ColorDefault)
+	drawText(x+1, y, state, stateColor|termbox.AttrBold)
+	y += 2
+
+	// block map
+	y = t.drawBlockMap(y, width)
+	y++
+
+	// graphs
+	graphWidth := width - 20
+	if graphWidth > tuiGraphPoints {
+		graphWidth = tuiGraphPoints
+	}
+	if graphWidth < 0 {
+		graphWidth = 0
+	}
+	drawText(0, y, fmt.Sprintf("RPS %6d", lastPoint(t.rpsPoints)), termbox.ColorDefault)
+	drawSparkline(12, y, t.rpsPoints, graphWidth, termbox.ColorGreen)
+	y++
+	drawText(0, y, fmt.Sprintf("LAT %4dms", lastPoint(t.latencyPoints)), termbox.ColorDefault)
+	drawSparkline(12, y, t.latencyPoints, graphWidth, termbox.ColorYellow)
+	y += 2
+
+	// recent errors
+	s := t.stats.Snapshot()
+	drawText(0, y, fmt.Sprintf("requests: %d, errors: %d", s.Requests, s.Errors), termbox.ColorDefault)
+	y++
+	for _, e := range s.RecentErrors {
+		if y >= height-2 {
+			break
+		}
+		drawText(0, y, truncate("! "+e, width), termbox.ColorRed)
+		y++
+	}
+	y++
+
+	// log: as many latest lines as fit
+	lines := append(append([]string{}, t.log...), t.curLine)
+	available := height - 1 - y
+	if available > 0 {
+		if len(lines) > available {
+			lines = lines[len(lines)-available:]
+		}
+		for _, line := range lines {
+			drawText(0, y, truncate(line, width), termbox.ColorDefault)
+			y++
+		}
+	}
+
+	// footer
+	drawText(0, height-1, "[space] pause/resume  [+/-] concurrency  [q] quit", termbox.ColorCyan)
+
+	termbox.Flush()
+}
+
+// draws every block of output as a cell with its bytes, returns next free line
+func (t *TUI) drawBlockMap(y, width int) int {
+	if t.bar == nil || t.blockLen == 0 {
+		return y
+	}
+
+	p := t.bar.Progress()
+
+	// bytes are discovered from the end of output, so known bytes form the tail
+	firstKnown := p.Total - p.Done
+	blockCount := (p.Total + t.blockLen - 1) / t.blockLen
+
+	cellWidth := t.blockLen + 1
+	perLine := width / cellWidth
+	if perLine < 1 {
+		perLine = 1
+	}
+
+	for b := 0; b < blockCount; b++ {
+		cx := (b % perLine) * cellWidth
+		cy := y + b/perLine
+
+		for i := 0; i < t.blockLen; i++ {
+			pos := b*t.blockLen + i
+			if pos >= p.Total {
+				break
+			}
+
+			ch, fg := '_', termbox.ColorDefault
+			switch {
+			case pos >= firstKnown:
+				ch, fg = printable(p.Data[pos-firstKnown]), termbox.ColorGreen|termbox.AttrBold
+			case (firstKnown-1)/t.blockLen == b:
+				// block in progress
+				fg = termbox.ColorYellow
+			}
+			termbox.SetCell(cx+i, cy, ch, fg, termbox.ColorDefault)
+		}
+	}
+
+	return y + (blockCount+perLine-1)/perLine
+}
+
+func drawText(x, y int, s string, fg termbox.Attribute) int {
+	for _, r := range s {
+		termbox.SetCell(x, y, r, fg, termbox.ColorDefault)
+		x++
+	}
+	return x
+}
+
+func drawSparkline(x, y int, points []int, width int, fg termbox.Attribute) {
+	// no room on narrow terminal
+	if width <= 0 {
+		return
+	}
+	if len(points) > width {
+		points = points[len(points)-width:]
+	}
+
+	max := 0
+	for _, p := range points {
+		if p > max {
+			max = p
+		}
+	}
+
+	for i, p := range points {
+		level := 0
+		if max > 0 {
+			level = p * (len(sparks) - 1) / max
+		}
+		termbox.SetCell(x+i, y, sparks[level], fg, termbox.ColorDefault)
+	}
+}
+
+func lastPoint(points []int) int {
+	if len(points) == 0 {
+		return 0
+	}
+	return points[len(points)-1]
+}
+
+func truncate(s string, width int) string {
+	if len(s) > width {
+		return s[:width]
+	}
+	return s
+}
+
+func printable(b byte) rune {
+	if b >= 32 && b < 127 {
+		return rune(b)
+	}
+	return '·'
+}
@@ -0,0 +1,45 @@
+//go:build notui
+// +build notui
+
+package output
+
+import (
+	"errors"
+	"io"
+
+	"github.com/glebarez/padre/pkg/client"
+)
+
+// TUIIncluded tells whether TUI is compiled in (excluded with build tag notui)
+const TUIIncluded = false
+
+var errTUINotIncluded = errors.New("TUI is not included in this build")
+
+// TUI is a stub for builds without TUI, it can not be started
+type TUI struct{}
+
+// NewTUI creates TUI stub
+func NewTUI(title, mode string, gate *client.Gate, stats *client.Stats, onQuit func()) *TUI {
+	return &TUI{}
+}
+
+// Start always fails, TUI is not included in this build
+func (t *TUI) Start() error {
+	return errTUINotIncluded
+}
+
+// Stop does nothing
+func (t *TUI) Stop(w io.Writer) {}
+
+// Width returns zero
+func (t *TUI) Width() int {
+	return 0
+}
+
+// Track does nothing
+func (t *TUI) Track(input, inputs, blockLen int, bar *HackyBar) {}
+
+// Write discards text
+func (t *TUI) Write(p []byte) (int, error) {
+	return len(p), nil
+}
@@ -0,0 +1,91 @@
+package probe
+
+import (
+	"encoding/base64"
+	"math"
+	"net/url"
+	"strings"
+
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// unpadded base64url, as in JWT and JWE. padre's encoders can't produce it
+const EncodingB64URLRaw = "b64url-raw"
+
+// nested encodings are peeled off up to this depth
+const maxLayers = 4
+
+// Analysis - structure of token, see Analyze
+type Analysis struct {
+	Layers     []string // encodings, outermost first: url, b64, b64url, b64url-raw or lhex
+	Data       []byte   // bytes under all encoding layers
+	Entropy    float64  // Shannon entropy, bits per byte
+	MaxEntropy float64  // maximum entropy possible for data of that length
+	BlockLens  []int    // supported block lengths, that length of data is multiple of
+	Printable  bool     // data is a text, not a ciphertext
+	Encrypted  bool     // data looks like CBC ciphertext, see LooksEncrypted
+}
+
+// Analyze peels encoding layers off the token and describes the bytes underneath
+func Analyze(token string) *Analysis {
+	a := &Analysis{}
+	value := strings.TrimSpace(token)
+	data := []byte(value)
+
+	for len(a.Layers) < maxLayers {
+		if strings.Contains(value, "%") {
+			if unescaped, err := url.QueryUnescape(value); err == nil && unescaped != value {
+				a.Layers = append(a.Layers, "url")
+				value = unescaped
+				data = []byte(value)
+				continue
+			}
+		}
+
+		encoding, decoded := decodeBlob(value)
+		if decoded == nil {
+			encoding, decoded = decodeRawBase64URL(value)
+		}
+		if decoded == nil {
+			break
+		}
+		a.Layers = append(a.Layers, encoding)
+		data = decoded
+
+		// another layer may be underneath, only text can be decoded further
+		if !util.IsPrintable(decoded) {
+			break
+		}
+		value = string(decoded)
+	}
+
+	a.Data = data
+	a.Printable = util.IsPrintable(data)
+	a.Encrypted = LooksEncrypted(data)
+	if len(data) > 0 {
+		a.Entropy = entropy(data)
+		a.MaxEntropy = math.Log2(math.Min(float64(len(data)), 256))
+	}
+	for _, bl := range []int{8, 16, 32} {
+		if len(data) > 0 && len(data)%bl == 0 {
+			a.BlockLens = append(a.BlockLens, bl)
+		}
+	}
+	return a
+}
+
+// Blocks returns number of blocks of given length in data
+func (a *Analysis) Blocks(blockLen int) int {
+	return len(a.Data) / blockLen
+}
+
+func decodeRawBase64URL(value string) (string, []byte) {
+	if len(value)%4 == 0 || !b64URLPattern.MatchString(value) || strings.HasSuffix(value, "=") {
+		return "", nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return "", nil
+	}
+	return EncodingB64URLRaw, data
+}
@@ -0,0 +1,38 @@
+package probe
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze(t *testing.T) {
+	blob := append([]byte{0xfb, 0xff, 0xbf}, randomBytes(45)...)
+
+	a := Analyze(url.QueryEscape(base64.StdEncoding.EncodeToString(blob)))
+	assert.Equal(t, []string{"url", EncodingB64}, a.Layers)
+	assert.Equal(t, blob, a.Data)
+	assert.Equal(t, []int{8, 16}, a.BlockLens)
+	assert.Equal(t, 3, a.Blocks(16))
+	assert.True(t, a.Encrypted)
+	assert.False(t, a.Printable)
+	assert.InDelta(t, 5.58, a.MaxEntropy, 0.01)
+
+	// hex inside base64
+	a = Analyze(base64.StdEncoding.EncodeToString([]byte(hex.EncodeToString(blob[:32]))))
+	assert.Equal(t, []string{EncodingB64, EncodingHex}, a.Layers)
+	assert.Equal(t, []int{8, 16, 32}, a.BlockLens)
+
+	a = Analyze(base64.RawURLEncoding.EncodeToString(blob[:40]))
+	assert.Equal(t, []string{EncodingB64URLRaw}, a.Layers)
+	assert.Equal(t, []int{8}, a.BlockLens)
+
+	// plain text
+	a = Analyze("hello, world")
+	assert.Empty(t, a.Layers)
+	assert.True(t, a.Printable)
+	assert.False(t, a.Encrypted)
+}
@@ -0,0 +1,140 @@
+package probe
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// number of identical requests, sent to detect multiple backends
+const backendProbes = 16
+
+// cookies that load balancers use to stick client to a backend (prefixes of names)
+var stickyCookies = []string{
+	"AWSALB", "AWSELB", "SERVERID", "BIGipServer", "ROUTEID", "route", "INGRESSCOOKIE", "ARRAffinity",
+	"ApplicationGatewayAffinity", "GCLB", "__cflb", "NSC_", "sticky",
+}
+
+// response headers that vary regardless of backend
+var volatileHeaders = map[string]bool{
+	"Date": true, "Expires": true, "Last-Modified": true, "Age": true, "Set-Cookie": true, "Content-Length": true,
+}
+
+// Backends - what was learned about backends behind the target
+type Backends struct {
+	// identical requests got responses with different fingerprints
+	Inconsistent bool
+
+	// sticky cookie, set by load balancer (nil if none)
+	Cookie *http.Cookie
+
+	// response header that identifies backend (empty if none), and its distinct values
+	Header string
+	Values []string
+
+	// the value of Header in the first response
+	first string
+}
+
+// Multiple tells whether the target is served by more than one backend
+func (b *Backends) Multiple() bool {
+	return b.Inconsistent || len(b.Values) > 1
+}
+
+// DetectBackends sends the same cipher several times, and looks for signs of load balancing:
+// different responses, sticky cookies, headers that name the backend
+func DetectBackends(ctx context.Context, c *client.Client, blockLen int) (*Backends, error) {
+	cipher := util.RandomSlice(blockLen * 2)
+
+	b := &Backends{}
+	var first http.Header
+	fingerprints := map[ResponseFingerprint]bool{}
+	headers := map[string]map[string]bool{}
+
+	for i := 0; i < backendProbes; i++ {
+		resp, err := c.DoRequest(ctx, cipher)
+		if err != nil {
+			return nil, err
+		}
+
+		fp, err := GetResponseFingerprint(resp)
+		if err != nil {
+			return nil, err
+		}
+		fingerprints[*fp] = true
+
+		if b.Cookie == nil {
+			b.Cookie = findStickyCookie(resp.Header)
+		}
+
+		for name, values := range resp.Header {
+			if volatileHeaders[name] {
+				continue
+			}
+			if headers[name] == nil {
+				headers[name] = map[string]bool{}
+			}
+			headers[name][strings.Join(values, ", ")] = true
+		}
+		if i == 0 {
+			first = resp.Header
+		}
+	}
+
+	b.Inconsistent = len(fingerprints) > 1
+
+	// header takes few repeating values: backend names.
+	// values unique for every response are request IDs, traces, etc.
+	for name, values := range headers {
+		if len(values) < 2 || len(values) > backendProbes/2 {
+			continue
+		}
+		if len(values) > len(b.Values) || (len(values) == len(b.Values) && name < b.Header) {
+			b.Header = name
+			b.Values = b.Values[:0]
+			for v := range values {
+				b.Values = append(b.Values, v)
+			}
+		}
+	}
+	sort.Strings(b.Values)
+	if b.Header != "" {
+		b.first = first.Get(b.Header)
+	}
+
+	return b, nil
+}
+
+// finds sticky cookie among cookies set by response
+func findStickyCookie(header http.Header) *http.Cookie {
+	for _, cookie := range (&http.Response{Header: header}).Cookies() {
+		for _, prefix := range stickyCookies {
+			if strings.HasPrefix(strings.ToLower(cookie.Name), strings.ToLower(prefix)) {
+				return &http.Cookie{Name: cookie.Name, Value: cookie.Value}
+			}
+		}
+	}
+	return nil
+}
+
+// Pin makes all further requests of client go to the same backend:
+// sticky cookie is sent with every request, or else the header that names the backend.
+// returns description of the pin, empty if there is nothing to pin with
+func (b *Backends) Pin(c *client.Client) string {
+	switch {
+	case b.Cookie != nil:
+		c.Cookies = append(c.Cookies, b.Cookie)
+		return "cookie " + b.Cookie.Name + "=" + b.Cookie.Value
+	case b.Header != "" && b.first != "":
+		if c.Headers == nil {
+			c.Headers = http.Header{}
+		}
+		c.Headers.Set(b.Header, b.first)
+		return "header " + b.Header + ": " + b.first
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package probe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectBackends(t *testing.T) {
+	// round-robin across 2 backends, the second one is not vulnerable
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		backend := "web1"
+		if cookie, err := r.Cookie("SERVERID"); err == nil {
+			backend = cookie.Value
+		} else if atomic.AddInt32(&count, 1)%2 == 0 {
+			backend = "web2"
+		}
+
+		http.SetCookie(w, &http.Cookie{Name: "SERVERID", Value: backend})
+		w.Header().Set("X-Served-By", backend)
+		w.Header().Set("X-Request-Id", r.URL.Query().Get("c")+string(rune('a'+atomic.LoadInt32(&count)%26)))
+		if backend == "web2" {
+			w.WriteHeader(400)
+		}
+	}))
+	defer ts.Close()
+
+	c := &client.Client{
+		HTTPclient:        ts.Client(),
+		URL:               ts.URL + "/?c=$",
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewLHEXencoder(""),
+		Concurrency:       1,
+	}
+
+	backends, err := DetectBackends(context.Background(), c, 16)
+	require.NoError(t, err)
+	assert.True(t, backends.Multiple())
+	assert.True(t, backends.Inconsistent)
+	assert.Equal(t, "X-Served-By", backends.Header)
+	assert.Equal(t, []string{"web1", "web2"}, backends.Values)
+	require.NotNil(t, backends.Cookie)
+	assert.Equal(t, "SERVERID", backends.Cookie.Name)
+
+	// pinned client always reaches the same backend
+	assert.Equal(t, "cookie SERVERID=web1", backends.Pin(c))
+	backends, err = DetectBackends(context.Background(), c, 16)
+	require.NoError(t, err)
+	assert.False(t, backends.Multiple())
+
+	// without sticky cookie, backend header is sent back
+	c = &client.Client{}
+	assert.Equal(t, "header X-Served-By: web2", (&Backends{Header: "X-Served-By", first: "web2"}).Pin(c))
+	assert.Equal(t, "web2", c.Headers.Get("X-Served-By"))
+}
@@ -0,0 +1,145 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/util"
+)
+
+var numberRegexp = regexp.MustCompile(`\d+`)
+
+// responses of the same status are clustered together when their bodies are at least that similar
+const clusterSimilarity = 0.8
+
+// ResponseCluster - group of similar responses, see ClusterResponses
+type ResponseCluster struct {
+	StatusCode int
+	MinLength  int
+	MaxLength  int
+	Count      int
+	Sample     *client.Response // the first response of the cluster
+
+	words map[string]bool // of the sample
+}
+
+// add response to cluster, if it's similar enough
+func (c *ResponseCluster) add(resp *client.Response, words map[string]bool) bool {
+	if resp.StatusCode != c.StatusCode || similarity(c.words, words) < clusterSimilarity {
+		return false
+	}
+
+	c.Count++
+	if l := bodyLength(resp); l < c.MinLength {
+		c.MinLength = l
+	} else if l > c.MaxLength {
+		c.MaxLength = l
+	}
+	return true
+}
+
+// ClusterResponses groups responses by status code and similarity of bodies (set of words, numbers ignored).
+// clusters are ordered by count of responses, biggest first
+func ClusterResponses(responses []*client.Response) []*ResponseCluster {
+	clusters := make([]*ResponseCluster, 0)
+
+responseLoop:
+	for _, resp := range responses {
+		words := bodyWords(resp.Body)
+		for _, c := range clusters {
+			if c.add(resp, words) {
+				continue responseLoop
+			}
+		}
+
+		l := bodyLength(resp)
+		clusters = append(clusters, &ResponseCluster{
+			StatusCode: resp.StatusCode,
+			MinLength:  l,
+			MaxLength:  l,
+			Count:      1,
+			Sample:     resp,
+			words:      words,
+		})
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool { return clusters[i].Count > clusters[j].Count })
+	return clusters
+}
+
+// ExploreResponses sends probes with every value of last IV byte of random cipher,
+// so that both padding errors and valid paddings are among the responses
+func ExploreResponses(ctx context.Context, c *client.Client, blockLen int) ([]*client.Response, error) {
+	cipher := util.RandomSlice(blockLen * 2)
+	chanResult := make(chan *client.ProbeResult, 256)
+	go c.SendProbes(ctx, cipher, blockLen-1, nil, chanResult)
+
+	responses := make([]*client.Response, 0, 256)
+	for result := range chanResult {
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		responses = append(responses, result.Response)
+	}
+
+	// probing was stopped from outside, responses are incomplete
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
+
+type matcherByCluster struct {
+	cluster *ResponseCluster
+}
+
+func (m *matcherByCluster) IsPaddingError(resp *client.Response) (bool, error) {
+	return resp.StatusCode == m.cluster.StatusCode && similarity(m.cluster.words, bodyWords(resp.Body)) >= clusterSimilarity, nil
+}
+
+func (m *matcherByCluster) String() string {
+	return fmt.Sprintf("similar to response with status %d, length %d", m.cluster.StatusCode, bodyLength(m.cluster.Sample))
+}
+
+// NewMatcherByCluster creates matcher that recognizes padding error by similarity to responses of the cluster
+func NewMatcherByCluster(cluster *ResponseCluster) PaddingErrorMatcher {
+	return &matcherByCluster{cluster}
+}
+
+// words of body, numbers are masked, so that dynamic values (IDs, timestamps) don't make responses different
+func bodyWords(body []byte) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(string(body), func(r rune) bool {
+		return unicode.IsSpace(r) || r == '<' || r == '>' || r == '"'
+	}) {
+		words[numberRegexp.ReplaceAllString(word, "0")] = true
+	}
+	return words
+}
+
+// Jaccard similarity of sets of words
+func similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	common := 0
+	for w := range a {
+		if b[w] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// full length of body, that may be truncated
+func bodyLength(resp *client.Response) int {
+	if resp.Length < len(resp.Body) {
+		return len(resp.Body)
+	}
+	return resp.Length
+}
@@ -0,0 +1,56 @@
+package probe
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterResponses(t *testing.T) {
+	responses := make([]*client.Response, 0)
+	for i := 0; i < 10; i++ {
+		// request ID differs, but responses are the same
+		responses = append(responses, &client.Response{
+			StatusCode: 200,
+			Body:       []byte(fmt.Sprintf("<html><p>Decryption failed</p><p>request %d</p></html>", 95+i)),
+		})
+	}
+	responses = append(responses,
+		&client.Response{StatusCode: 200, Body: []byte("<html><p>Welcome, guest</p><p>Your cart is empty</p></html>")},
+		&client.Response{StatusCode: 500, Body: []byte("<html><p>Decryption failed</p><p>request 1</p></html>")},
+	)
+
+	clusters := ClusterResponses(responses)
+	require.Len(t, clusters, 3)
+
+	assert.Equal(t, 10, clusters[0].Count)
+	assert.Equal(t, 200, clusters[0].StatusCode)
+	assert.Equal(t, 54, clusters[0].MinLength)
+	assert.Equal(t, 55, clusters[0].MaxLength)
+	assert.Equal(t, responses[0], clusters[0].Sample)
+
+	assert.Equal(t, 1, clusters[1].Count)
+	assert.Equal(t, 1, clusters[2].Count)
+
+	// matcher recognizes responses of chosen cluster only
+	matcher := NewMatcherByCluster(clusters[0])
+	for resp, want := range map[*client.Response]bool{
+		{StatusCode: 200, Body: []byte("<html><p>Decryption failed</p><p>request 99999</p></html>")}: true,
+		responses[10]: false,
+		responses[11]: false,
+	} {
+		got, err := matcher.IsPaddingError(resp)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, string(resp.Body))
+	}
+}
+
+func TestClusterResponses_EmptyBodies(t *testing.T) {
+	clusters := ClusterResponses([]*client.Response{{StatusCode: 500}, {StatusCode: 500}, {StatusCode: 200}})
+	require.Len(t, clusters, 2)
+	assert.Equal(t, 2, clusters[0].Count)
+	assert.Equal(t, 500, clusters[0].StatusCode)
+}
@@ -7,9 +7,9 @@ import (
 	"github.com/glebarez/padre/pkg/util"
 )
 
-// confirms existence of padding oracle
+// ConfirmPaddingOracle confirms existence of padding oracle
 // returns true if confirmed, false otherwise
-func ConfirmPaddingOracle(c *client.Client, matcher PaddingErrorMatcher, blockLen int) (bool, error) {
+func ConfirmPaddingOracle(ctx context.Context, c *client.Client, matcher PaddingErrorMatcher, blockLen int) (bool, error) {
 	// create random block of ciphertext (IV prepended)
 	cipher := util.RandomSlice(blockLen * 2)
 
@@ -20,7 +20,7 @@ func ConfirmPaddingOracle(c *client.Client, matcher PaddingErrorMatcher, blockLe
 	chanResult := make(chan *client.ProbeResult, 256)
 
 	// send probes
-	go c.SendProbes(context.Background(), cipher, pos, chanResult)
+	go c.SendProbes(ctx, cipher, pos, nil, chanResult)
 
 	// count padding errors
 	count := 0
@@ -38,6 +38,11 @@ func ConfirmPaddingOracle(c *client.Client, matcher PaddingErrorMatcher, blockLe
 		}
 	}
 
+	// probing was stopped from outside, count is incomplete
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
 	// padding oracle must produce exactly 254 or 255 errors
 	return count == 254 || count == 255, nil
 }
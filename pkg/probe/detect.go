@@ -7,35 +7,12 @@ import (
 	"github.com/glebarez/padre/pkg/util"
 )
 
-// attempts to auto-detect padding oracle fingerprint
-func DetectPaddingErrorFingerprint(c *client.Client, blockLen int) (PaddingErrorMatcher, error) {
-	// create random block of ciphertext (IV prepended)
-	cipher := util.RandomSlice(blockLen * 2)
-
-	// test last byte of IV
-	pos := blockLen - 1
-
-	// channel to soak results
-	chanResult := make(chan *client.ProbeResult, 256)
-
-	// fingerprint probes
-	go c.SendProbes(context.Background(), cipher, pos, chanResult)
-
-	// collect counts of fingerprints
-	fpMap := map[ResponseFingerprint]int{}
-	for result := range chanResult {
-		if result.Err != nil {
-			// error during probes
-			return nil, result.Err
-		}
-
-		fp, err := GetResponseFingerprint(result.Response)
-		if err != nil {
-			// error during fingerprinting
-			return nil, result.Err
-		}
-
-		fpMap[*fp]++
+// DetectPaddingErrorFingerprint attempts to auto-detect padding oracle fingerprint.
+// returns nil matcher if fingerprint was not detected
+func DetectPaddingErrorFingerprint(ctx context.Context, c *client.Client, blockLen int) (PaddingErrorMatcher, error) {
+	fpMap, err := collectFingerprints(ctx, c, blockLen)
+	if err != nil {
+		return nil, err
 	}
 
 	// padding oracles respond with predictable count of unique fingerprints
@@ -49,6 +26,7 @@ func DetectPaddingErrorFingerprint(c *client.Client, blockLen int) (PaddingError
 		{254, 2},
 		{256 - blockLen, blockLen - 1, 1},
 		{256 - blockLen, blockLen - 2, 2},
+		{256 - blockLen, blockLen}, // only the last byte of padding is verified (e.g. ISO 10126)
 	}
 
 	// check if any of count-patterns matches
@@ -76,6 +54,45 @@ patternLoop:
 	return nil, nil
 }
 
+// sends probes with every value of last IV byte, and counts fingerprints of responses
+func collectFingerprints(ctx context.Context, c *client.Client, blockLen int) (map[ResponseFingerprint]int, error) {
+	// create random block of ciphertext (IV prepended)
+	cipher := util.RandomSlice(blockLen * 2)
+
+	// test last byte of IV
+	pos := blockLen - 1
+
+	// channel to soak results
+	chanResult := make(chan *client.ProbeResult, 256)
+
+	// fingerprint probes
+	go c.SendProbes(ctx, cipher, pos, nil, chanResult)
+
+	// collect counts of fingerprints
+	fpMap := map[ResponseFingerprint]int{}
+	for result := range chanResult {
+		if result.Err != nil {
+			// error during probes
+			return nil, result.Err
+		}
+
+		fp, err := GetResponseFingerprint(result.Response)
+		if err != nil {
+			// error during fingerprinting
+			return nil, result.Err
+		}
+
+		fpMap[*fp]++
+	}
+
+	// probing was stopped from outside, counts are incomplete
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return fpMap, nil
+}
+
 func inSlice(slice []int, value int) bool {
 	for _, i := range slice {
 		if value == i {
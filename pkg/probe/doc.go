@@ -0,0 +1,5 @@
+// Package probe recognizes padding oracles.
+// It provides matchers that tell padding errors apart from other responses,
+// and routines to confirm padding oracle or detect its fingerprint automatically.
+// Tokens are recognized as well: request parameters that look like ciphers are found, and structure of token is analyzed.
+package probe
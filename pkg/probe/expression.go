@@ -0,0 +1,364 @@
+package probe
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/glebarez/padre/pkg/client"
+)
+
+// expression grammar (see NewMatcherByExpression):
+//
+//	expr       = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" expr ")" | condition
+//	condition  = field operator value
+//	field      = status | length | time | body | location | header.NAME
+//	operator   = == | != | < | <= | > | >= (status, length, time), ~ | !~ | == | != (body, location, header)
+//	value      = number (status, length), duration (time, e.g. 2s, 300ms), "quoted string" (regexp for ~ and !~)
+
+// node of parsed expression
+type exprNode interface {
+	eval(resp *client.Response) bool
+}
+
+type exprOr struct{ left, right exprNode }
+
+func (n *exprOr) eval(resp *client.Response) bool { return n.left.eval(resp) || n.right.eval(resp) }
+
+type exprAnd struct{ left, right exprNode }
+
+func (n *exprAnd) eval(resp *client.Response) bool { return n.left.eval(resp) && n.right.eval(resp) }
+
+type exprNot struct{ node exprNode }
+
+func (n *exprNot) eval(resp *client.Response) bool { return !n.node.eval(resp) }
+
+// compares number taken from response
+type exprNumber struct {
+	value func(resp *client.Response) int64
+	op    string
+	arg   int64
+}
+
+func (n *exprNumber) eval(resp *client.Response) bool {
+	v := n.value(resp)
+	switch n.op {
+	case "==":
+		return v == n.arg
+	case "!=":
+		return v != n.arg
+	case "<":
+		return v < n.arg
+	case "<=":
+		return v <= n.arg
+	case ">":
+		return v > n.arg
+	default: // >=
+		return v >= n.arg
+	}
+}
+
+// matches text taken from response (any of values, for headers and redirects)
+type exprText struct {
+	values func(resp *client.Response) []string
+	op     string
+	re     *regexp.Regexp // for ~ and !~
+	arg    string         // for == and !=
+}
+
+func (n *exprText) eval(resp *client.Response) bool {
+	matched := false
+	for _, v := range n.values(resp) {
+		if (n.re != nil && n.re.MatchString(v)) || (n.re == nil && v == n.arg) {
+			matched = true
+			break
+		}
+	}
+	if n.op == "!~" || n.op == "!=" {
+		return !matched
+	}
+	return matched
+}
+
+type matcherByExpression struct {
+	source string
+	root   exprNode
+}
+
+func (m *matcherByExpression) IsPaddingError(resp *client.Response) (bool, error) {
+	return m.root.eval(resp), nil
+}
+
+func (m *matcherByExpression) String() string {
+	return fmt.Sprintf("expression (%s)", m.source)
+}
+
+// NewMatcherByExpression creates matcher that recognizes padding error by boolean expression, that combines
+// conditions on status code, body length, response time, body, Location of redirects and headers, e.g.
+// status==500 && body~"padding" || time>2s. && binds tighter than ||, parentheses and ! are supported
+func NewMatcherByExpression(source string) (PaddingErrorMatcher, error) {
+	tokens, err := tokenizeExpression(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in expression", p.tokens[p.pos].text)
+	}
+	return &matcherByExpression{source: source, root: root}, nil
+}
+
+// token kinds
+const (
+	tokenWord     = iota // field name, number or duration
+	tokenString          // quoted string
+	tokenOperator        // comparison or logical operator, parenthesis
+)
+
+type exprToken struct {
+	kind int
+	text string
+}
+
+// operators, longer ones first
+var exprOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "!~", "<", ">", "~", "!", "(", ")"}
+
+func tokenizeExpression(s string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+
+		case c == '"':
+			// find closing quote, skipping escaped characters
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string in expression")
+			}
+			text, err := strconv.Unquote(s[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s in expression: %s", s[i:j+1], err)
+			}
+			tokens = append(tokens, exprToken{tokenString, text})
+			i = j + 1
+
+		case isWordChar(c):
+			j := i
+			for j < len(s) && isWordChar(rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, exprToken{tokenWord, s[i:j]})
+			i = j
+
+		default:
+			found := false
+			for _, op := range exprOperators {
+				if strings.HasPrefix(s[i:], op) {
+					tokens = append(tokens, exprToken{tokenOperator, op})
+					i += len(op)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unexpected character %q in expression", c)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// words are field names (header.X-Error), numbers and durations (1.5s)
+func isWordChar(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '.' || c == '-' || c == '_'
+}
+
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+// returns next token if it's the operator, advancing the position
+func (p *exprParser) accept(op string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokenOperator && p.tokens[p.pos].text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) next() (exprToken, error) {
+	if p.pos >= len(p.tokens) {
+		return exprToken{}, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &exprOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &exprAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.accept("!") {
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &exprNot{node}, nil
+	}
+
+	if p.accept("(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing closing parenthesis in expression")
+		}
+		return node, nil
+	}
+
+	return p.parseCondition()
+}
+
+func (p *exprParser) parseCondition() (exprNode, error) {
+	field, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if field.kind != tokenWord {
+		return nil, fmt.Errorf("expected field name, got %q", field.text)
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if op.kind != tokenOperator {
+		return nil, fmt.Errorf("expected comparison after %s, got %q", field.text, op.text)
+	}
+
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(field.text)
+	switch {
+	case name == "status", name == "length", name == "time":
+		return newNumberCondition(name, op.text, value)
+	case name == "body":
+		return newTextCondition(name, op.text, value, func(resp *client.Response) []string {
+			return []string{string(resp.Body)}
+		})
+	case name == "location":
+		return newTextCondition(name, op.text, value, func(resp *client.Response) []string {
+			return resp.Redirects
+		})
+	case strings.HasPrefix(name, "header.") && len(name) > len("header."):
+		header := field.text[len("header."):]
+		return newTextCondition(field.text, op.text, value, func(resp *client.Response) []string {
+			return resp.Header.Values(header)
+		})
+	default:
+		return nil, fmt.Errorf("unknown field %q, expected one of: status, length, time, body, location, header.NAME", field.text)
+	}
+}
+
+func newNumberCondition(name, op string, value exprToken) (exprNode, error) {
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+	default:
+		return nil, fmt.Errorf("operator %s is not applicable to %s", op, name)
+	}
+	if value.kind != tokenWord {
+		return nil, fmt.Errorf("%s is compared with %q, not a number", name, value.text)
+	}
+
+	n := &exprNumber{op: op}
+	switch name {
+	case "status":
+		n.value = func(resp *client.Response) int64 { return int64(resp.StatusCode) }
+	case "length":
+		n.value = func(resp *client.Response) int64 { return int64(bodyLength(resp)) }
+	case "time":
+		d, err := time.ParseDuration(value.text)
+		if err != nil {
+			return nil, fmt.Errorf("time is compared with %q, not a duration (e.g. 2s, 300ms)", value.text)
+		}
+		n.value = func(resp *client.Response) int64 { return int64(resp.Elapsed) }
+		n.arg = int64(d)
+		return n, nil
+	}
+
+	arg, err := strconv.ParseInt(value.text, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s is compared with %q, not a number", name, value.text)
+	}
+	n.arg = arg
+	return n, nil
+}
+
+func newTextCondition(name, op string, value exprToken, values func(resp *client.Response) []string) (exprNode, error) {
+	if value.kind != tokenString {
+		return nil, fmt.Errorf("%s is compared with %q, use quoted string", name, value.text)
+	}
+
+	n := &exprText{values: values, op: op, arg: value.text}
+	switch op {
+	case "~", "!~":
+		re, err := regexp.Compile(value.text)
+		if err != nil {
+			return nil, err
+		}
+		n.re = re
+	case "==", "!=":
+	default:
+		return nil, fmt.Errorf("operator %s is not applicable to %s, use ~, !~, == or !=", op, name)
+	}
+	return n, nil
+}
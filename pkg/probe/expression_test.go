@@ -0,0 +1,75 @@
+package probe
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcherByExpression(t *testing.T) {
+	padding := &client.Response{StatusCode: 500, Body: []byte("Padding is invalid"), Elapsed: 100 * time.Millisecond}
+	slow := &client.Response{StatusCode: 200, Body: []byte("ok"), Elapsed: 3 * time.Second}
+	generic := &client.Response{StatusCode: 500, Body: []byte("Internal error"), Elapsed: 100 * time.Millisecond}
+	redirect := &client.Response{StatusCode: 302, Header: http.Header{"X-Error": {"CryptographicException"}}, Redirects: []string{"/error"}}
+
+	tests := []struct {
+		expr string
+		resp *client.Response
+		want bool
+	}{
+		{`status==500 && body~"(?i)padding" || time>2s`, padding, true},
+		{`status==500 && body~"(?i)padding" || time>2s`, slow, true},
+		{`status==500 && body~"(?i)padding" || time>2s`, generic, false},
+		{`status==500 && (body~"(?i)padding" || time>2s)`, slow, false},
+		{`!(status >= 500) && length<=2`, slow, true},
+		{`!status==500`, generic, false},
+		{`body!~"error"`, padding, true},
+		{`body=="ok"`, slow, true},
+		{`body!="ok"`, slow, false},
+		{`time<=100ms`, padding, true},
+		{`location~"^/error" && header.x-error~"Cryptographic"`, redirect, true},
+		{`header.X-Error=="CryptographicException"`, padding, false},
+		{`status!=302 || location~"/home"`, redirect, false},
+		{`body~"\"quoted\""`, &client.Response{Body: []byte(`a "quoted" word`)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			m, err := NewMatcherByExpression(tt.expr)
+			require.NoError(t, err)
+
+			got, err := m.IsPaddingError(tt.resp)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMatcherByExpression_Errors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`status`,
+		`status==`,
+		`status=="500"`,
+		`status~500`,
+		`time>2`,
+		`length>big`,
+		`body>"a"`,
+		`body~padding`,
+		`body~"("`,
+		`size==1`,
+		`header.==""`,
+		`(status==500`,
+		`status==500)`,
+		`status==500 &&`,
+		`status==500 status==502`,
+		`body~"unterminated`,
+		`status==500 @ time>1s`,
+	} {
+		_, err := NewMatcherByExpression(expr)
+		assert.Error(t, err, expr)
+	}
+}
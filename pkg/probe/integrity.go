@@ -0,0 +1,25 @@
+package probe
+
+import (
+	"context"
+
+	"github.com/glebarez/padre/pkg/client"
+)
+
+// DetectUniformResponses tells whether every tampered cipher yields identical response, with every block length given.
+// when this happens, it's likely that integrity of cipher is checked (e.g. with MAC) before padding is checked,
+// so that padding oracle is not exposed.
+// tampering with wrong block length misses the padding of target, so responses are uniform even without MAC:
+// unless block length is known, all candidate lengths must be passed
+func DetectUniformResponses(ctx context.Context, c *client.Client, blockLengths []int) (bool, error) {
+	for _, blockLen := range blockLengths {
+		fpMap, err := collectFingerprints(ctx, c, blockLen)
+		if err != nil {
+			return false, err
+		}
+		if len(fpMap) != 1 {
+			return false, nil
+		}
+	}
+	return len(blockLengths) > 0, nil
+}
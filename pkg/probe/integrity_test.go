@@ -0,0 +1,80 @@
+package probe
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectUniformResponses(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		handler http.HandlerFunc
+		uniform bool
+	}{
+		{"generic error", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(400)
+		}, true},
+		{"responses differ", func(w http.ResponseWriter, r *http.Request) {
+			// last byte of IV is varied by probes
+			if r.URL.Query().Get("c")[30:32] > "80" {
+				w.WriteHeader(500)
+			}
+		}, false},
+	} {
+		ts := httptest.NewServer(tc.handler)
+		defer ts.Close()
+
+		c := &client.Client{
+			HTTPclient:        ts.Client(),
+			URL:               ts.URL + "/?c=$",
+			CipherPlaceholder: "$",
+			Encoder:           encoder.NewLHEXencoder(""),
+			Concurrency:       8,
+		}
+
+		uniform, err := DetectUniformResponses(context.Background(), c, []int{16})
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.uniform, uniform, tc.name)
+	}
+}
+
+func TestDetectUniformResponses_BlockLengths(t *testing.T) {
+	// 16-byte blocks without MAC: padding of the last block depends on the last byte of block before it
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cipher, err := hex.DecodeString(r.URL.Query().Get("c"))
+		if err != nil || len(cipher) < 32 || len(cipher)%16 != 0 {
+			w.WriteHeader(400)
+			return
+		}
+		if cipher[len(cipher)-17] != 0x42 {
+			w.WriteHeader(500)
+		}
+	}))
+	defer ts.Close()
+
+	c := &client.Client{
+		HTTPclient:        ts.Client(),
+		URL:               ts.URL + "/?c=$",
+		CipherPlaceholder: "$",
+		Encoder:           encoder.NewLHEXencoder(""),
+		Concurrency:       8,
+	}
+
+	// with wrong block length, the padding is not reached
+	uniform, err := DetectUniformResponses(context.Background(), c, []int{32})
+	require.NoError(t, err)
+	assert.True(t, uniform)
+
+	// but there is no MAC, as the right length shows
+	uniform, err = DetectUniformResponses(context.Background(), c, []int{8, 16, 32})
+	require.NoError(t, err)
+	assert.False(t, uniform)
+}
@@ -6,3 +6,18 @@ import "github.com/glebarez/padre/pkg/client"
 type PaddingErrorMatcher interface {
 	IsPaddingError(*client.Response) (bool, error)
 }
+
+// CaptureMatcher is implemented by matchers that can show the part of response they matched,
+// e.g. dynamic error message, so that the match can be diagnosed
+type CaptureMatcher interface {
+	// Capture returns the matched part of response, empty if nothing is matched
+	Capture(*client.Response) string
+}
+
+// Capture returns the part of response, matched by matcher (if it's able to tell, see CaptureMatcher)
+func Capture(m PaddingErrorMatcher, resp *client.Response) string {
+	if c, ok := m.(CaptureMatcher); ok {
+		return c.Capture(resp)
+	}
+	return ""
+}
@@ -1,7 +1,10 @@
 package probe
 
 import (
+	"fmt"
+	"net/http"
 	"regexp"
+	"strings"
 
 	"github.com/glebarez/padre/pkg/client"
 )
@@ -26,6 +29,17 @@ func (m *matcherByFingerprint) IsPaddingError(resp *client.Response) (bool, erro
 	return false, nil
 }
 
+func (m *matcherByFingerprint) String() string {
+	fps := make([]string, len(m.fingerprints))
+	for i, fp := range m.fingerprints {
+		fps[i] = fmt.Sprintf("status=%d lines=%d words=%d", fp.StatusCode, fp.Lines, fp.Words)
+	}
+	return "fingerprint (" + strings.Join(fps, "; ") + ")"
+}
+
+// captured part of response is shortened to this length
+const maxCaptureLength = 100
+
 type matcherByRegexp struct {
 	re *regexp.Regexp
 }
@@ -34,6 +48,28 @@ func (m *matcherByRegexp) IsPaddingError(resp *client.Response) (bool, error) {
 	return m.re.Match(resp.Body), nil
 }
 
+func (m *matcherByRegexp) String() string {
+	return fmt.Sprintf("body matches /%s/", m.re)
+}
+
+// Capture returns the first capture group of match (the whole match, if regexp has no groups), shortened to maxCaptureLength
+func (m *matcherByRegexp) Capture(resp *client.Response) string {
+	match := m.re.FindSubmatch(resp.Body)
+	if match == nil {
+		return ""
+	}
+
+	capture := match[0]
+	if len(match) > 1 {
+		capture = match[1]
+	}
+	if len(capture) > maxCaptureLength {
+		return string(capture[:maxCaptureLength]) + "..."
+	}
+	return string(capture)
+}
+
+// NewMatcherByRegexp creates matcher that recognizes padding error by regexp match in response body
 func NewMatcherByRegexp(r string) (PaddingErrorMatcher, error) {
 	re, err := regexp.Compile(r)
 	if err != nil {
@@ -42,3 +78,146 @@ func NewMatcherByRegexp(r string) (PaddingErrorMatcher, error) {
 
 	return &matcherByRegexp{re}, nil
 }
+
+type matcherByLocation struct {
+	re *regexp.Regexp
+}
+
+func (m *matcherByLocation) IsPaddingError(resp *client.Response) (bool, error) {
+	for _, location := range resp.Redirects {
+		if m.re.MatchString(location) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *matcherByLocation) String() string {
+	return fmt.Sprintf("redirect location matches /%s/", m.re)
+}
+
+// NewMatcherByLocation creates matcher that recognizes padding error by regexp match in Location header
+// of any redirect, that led to response (or of response itself, if redirect was not followed)
+func NewMatcherByLocation(r string) (PaddingErrorMatcher, error) {
+	re, err := regexp.Compile(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &matcherByLocation{re}, nil
+}
+
+type matcherByHeader struct {
+	name string
+	re   *regexp.Regexp // nil means any value
+}
+
+func (m *matcherByHeader) IsPaddingError(resp *client.Response) (bool, error) {
+	for _, value := range resp.Header.Values(m.name) {
+		if m.re == nil || m.re.MatchString(value) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *matcherByHeader) String() string {
+	if m.re == nil {
+		return fmt.Sprintf("header %s is present", m.name)
+	}
+	return fmt.Sprintf("header %s matches /%s/", m.name, m.re)
+}
+
+// NewMatcherByHeader creates matcher that recognizes padding error by response header: NAME: REGEXP.
+// if regexp is omitted (NAME only), presence of the header means padding error
+func NewMatcherByHeader(header string) (PaddingErrorMatcher, error) {
+	name, value := header, ""
+	if i := strings.Index(header, ":"); i >= 0 {
+		name, value = header[:i], strings.TrimSpace(header[i+1:])
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("header name is empty, format is NAME: REGEXP")
+	}
+
+	m := &matcherByHeader{name: http.CanonicalHeaderKey(name)}
+	if value != "" {
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, err
+		}
+		m.re = re
+	}
+	return m, nil
+}
+
+type matcherByStatusCode struct {
+	codes []int
+}
+
+func (m *matcherByStatusCode) IsPaddingError(resp *client.Response) (bool, error) {
+	return inSlice(m.codes, resp.StatusCode), nil
+}
+
+func (m *matcherByStatusCode) String() string {
+	return fmt.Sprintf("status code in %v", m.codes)
+}
+
+// NewMatcherByStatusCode creates matcher that recognizes padding error by HTTP status code
+func NewMatcherByStatusCode(codes []int) (PaddingErrorMatcher, error) {
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("no status codes provided")
+	}
+
+	return &matcherByStatusCode{codes}, nil
+}
+
+type matcherByContentLength struct {
+	min, max int
+}
+
+func (m *matcherByContentLength) IsPaddingError(resp *client.Response) (bool, error) {
+	l := bodyLength(resp)
+	return l >= m.min && l <= m.max, nil
+}
+
+func (m *matcherByContentLength) String() string {
+	return fmt.Sprintf("body length in %d-%d", m.min, m.max)
+}
+
+// NewMatcherByContentLength creates matcher that recognizes padding error by length of response body.
+// the length must fall into [min, max] range (inclusive)
+func NewMatcherByContentLength(min, max int) (PaddingErrorMatcher, error) {
+	if min < 0 || min > max {
+		return nil, fmt.Errorf("invalid content length range: %d-%d", min, max)
+	}
+
+	return &matcherByContentLength{min, max}, nil
+}
+
+type matcherInverted struct {
+	matcher PaddingErrorMatcher
+}
+
+func (m *matcherInverted) IsPaddingError(resp *client.Response) (bool, error) {
+	isErr, err := m.matcher.IsPaddingError(resp)
+	if err != nil {
+		return false, err
+	}
+	return !isErr, nil
+}
+
+func (m *matcherInverted) String() string {
+	return fmt.Sprintf("not (%v)", m.matcher)
+}
+
+// Capture returns what the inverted matcher captured (e.g. message of successful response)
+func (m *matcherInverted) Capture(resp *client.Response) string {
+	return Capture(m.matcher, resp)
+}
+
+// NewMatcherInverted inverts the logic of provided matcher.
+// used when matcher identifies successful responses rather than padding errors
+func NewMatcherInverted(m PaddingErrorMatcher) PaddingErrorMatcher {
+	return &matcherInverted{m}
+}
@@ -0,0 +1,122 @@
+package probe
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchers(t *testing.T) {
+	byRegexp, err := NewMatcherByRegexp(`padding \w+`)
+	require.NoError(t, err)
+
+	byStatus, err := NewMatcherByStatusCode([]int{500, 502})
+	require.NoError(t, err)
+
+	byLength, err := NewMatcherByContentLength(10, 20)
+	require.NoError(t, err)
+
+	byLocation, err := NewMatcherByLocation(`/error`)
+	require.NoError(t, err)
+
+	byHeader, err := NewMatcherByHeader(`x-error: Cryptographic\w+`)
+	require.NoError(t, err)
+
+	byHeaderPresence, err := NewMatcherByHeader(`X-Padding-Error`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		matcher PaddingErrorMatcher
+		resp    *client.Response
+		want    bool
+	}{
+		{"regexp-match", byRegexp, &client.Response{Body: []byte("bad padding error")}, true},
+		{"regexp-nomatch", byRegexp, &client.Response{Body: []byte("ok")}, false},
+		{"status-match", byStatus, &client.Response{StatusCode: 502}, true},
+		{"status-nomatch", byStatus, &client.Response{StatusCode: 200}, false},
+		{"length-lower", byLength, &client.Response{Body: make([]byte, 10)}, true},
+		{"length-upper", byLength, &client.Response{Body: make([]byte, 20)}, true},
+		{"length-outside", byLength, &client.Response{Body: make([]byte, 21)}, false},
+		{"location-match", byLocation, &client.Response{Redirects: []string{"/login", "/error?id=1"}}, true},
+		{"location-nomatch", byLocation, &client.Response{Redirects: []string{"/home"}}, false},
+		{"location-noredirect", byLocation, &client.Response{}, false},
+		{"header-match", byHeader, &client.Response{Header: http.Header{"X-Error": {"CryptographicException"}}}, true},
+		{"header-nomatch", byHeader, &client.Response{Header: http.Header{"X-Error": {"NullReferenceException"}}}, false},
+		{"header-command", byHeader, &client.Response{}, false},
+		{"header-present", byHeaderPresence, &client.Response{Header: http.Header{"X-Padding-Error": {""}}}, true},
+		{"header-absent", byHeaderPresence, &client.Response{Header: http.Header{}}, false},
+		{"inverted-match", NewMatcherInverted(byStatus), &client.Response{StatusCode: 200}, true},
+		{"inverted-nomatch", NewMatcherInverted(byStatus), &client.Response{StatusCode: 500}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.matcher.IsPaddingError(tt.resp)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMatcherConstructorErrors(t *testing.T) {
+	_, err := NewMatcherByRegexp(`(`)
+	assert.Error(t, err)
+
+	_, err = NewMatcherByStatusCode(nil)
+	assert.Error(t, err)
+
+	_, err = NewMatcherByContentLength(20, 10)
+	assert.Error(t, err)
+
+	_, err = NewMatcherByLocation(`[`)
+	assert.Error(t, err)
+
+	_, err = NewMatcherByHeader(`: value`)
+	assert.Error(t, err)
+
+	_, err = NewMatcherByHeader(`X-Error: (`)
+	assert.Error(t, err)
+}
+
+func TestMatcherString(t *testing.T) {
+	byStatus, _ := NewMatcherByStatusCode([]int{500, 502})
+	byRegexp, _ := NewMatcherByRegexp(`[Pp]adding`)
+	byLength, _ := NewMatcherByContentLength(10, 20)
+	byFingerprint := &matcherByFingerprint{[]ResponseFingerprint{{500, 1, 3}}}
+
+	assert.Equal(t, "status code in [500 502]", fmt.Sprint(byStatus))
+	assert.Equal(t, "body matches /[Pp]adding/", fmt.Sprint(byRegexp))
+	assert.Equal(t, "body length in 10-20", fmt.Sprint(byLength))
+	byLocation, _ := NewMatcherByLocation(`/error`)
+	assert.Equal(t, "redirect location matches //error/", fmt.Sprint(byLocation))
+	byHeader, _ := NewMatcherByHeader(`x-error: Crypto`)
+	assert.Equal(t, "header X-Error matches /Crypto/", fmt.Sprint(byHeader))
+	byHeader, _ = NewMatcherByHeader(`x-error`)
+	assert.Equal(t, "header X-Error is present", fmt.Sprint(byHeader))
+	assert.Equal(t, "not (status code in [500 502])", fmt.Sprint(NewMatcherInverted(byStatus)))
+	assert.Equal(t, "fingerprint (status=500 lines=1 words=3)", fmt.Sprint(byFingerprint))
+}
+
+func TestMatcherCapture(t *testing.T) {
+	withGroup, _ := NewMatcherByRegexp(`Padding error \(ref (\w+)\)`)
+	withoutGroup, _ := NewMatcherByRegexp(`(?i)padding error`)
+	byStatus, _ := NewMatcherByStatusCode([]int{500})
+
+	resp := &client.Response{Body: []byte("Oops! Padding error (ref 7f3a) at 12:00:01")}
+	assert.Equal(t, "7f3a", Capture(withGroup, resp))
+	assert.Equal(t, "Padding error", Capture(withoutGroup, resp))
+	assert.Equal(t, "7f3a", Capture(NewMatcherInverted(withGroup), resp))
+	assert.Equal(t, "", Capture(byStatus, resp))
+	assert.Equal(t, "", Capture(withGroup, &client.Response{Body: []byte("ok")}))
+
+	long, _ := NewMatcherByRegexp(`x+`)
+	capture := Capture(long, &client.Response{Body: make([]byte, 200)})
+	assert.Equal(t, "", capture)
+	capture = Capture(long, &client.Response{Body: []byte(strings.Repeat("x", 200))})
+	assert.Equal(t, strings.Repeat("x", maxCaptureLength)+"...", capture)
+}
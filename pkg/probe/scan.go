@@ -0,0 +1,188 @@
+package probe
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// where parameter is found in request
+const (
+	LocationQuery  = "query"
+	LocationPOST   = "post"
+	LocationCookie = "cookie"
+)
+
+// encodings of candidates, see Candidate
+const (
+	EncodingB64    = "b64"
+	EncodingB64URL = "b64url"
+	EncodingHex    = "lhex"
+)
+
+// encrypted blob must be at least two blocks (IV and ciphertext) of the shortest cipher
+const minBlobLen = 16
+
+// share of maximum entropy, that random data is expected to reach
+const minEntropyRatio = 0.7
+
+var (
+	hexPattern    = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+	b64Pattern    = regexp.MustCompile(`^[A-Za-z0-9+/]+={0,2}$`)
+	b64URLPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+={0,2}$`)
+)
+
+// Candidate - request parameter, whose value looks like encrypted blob
+type Candidate struct {
+	Location string // query, post or cookie
+	Name     string
+	Value    string // unescaped value
+	Encoding string // b64, b64url or lhex
+	Length   int    // length of decoded value, in bytes
+
+	index int // position among parameters of the location
+}
+
+// FindCandidates finds parameters of request, that look like encrypted blobs:
+// values are decodable, long enough, aligned to block and have high entropy
+func FindCandidates(rawURL, postData string, cookies []*http.Cookie) ([]*Candidate, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*Candidate
+	for _, location := range []struct{ name, params string }{{LocationQuery, u.RawQuery}, {LocationPOST, postData}} {
+		for i, param := range splitParams(location.params) {
+			parts := strings.SplitN(param, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			value, err := url.QueryUnescape(parts[1])
+			if err != nil {
+				continue
+			}
+			if c := newCandidate(location.name, parts[0], value, i); c != nil {
+				candidates = append(candidates, c)
+			}
+		}
+	}
+
+	for i, cookie := range cookies {
+		value, err := url.QueryUnescape(cookie.Value)
+		if err != nil {
+			continue
+		}
+		if c := newCandidate(LocationCookie, cookie.Name, value, i); c != nil {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates, nil
+}
+
+func newCandidate(location, name, value string, index int) *Candidate {
+	encoding, data := decodeBlob(value)
+	if data == nil || !LooksEncrypted(data) {
+		return nil
+	}
+	return &Candidate{Location: location, Name: name, Value: value, Encoding: encoding, Length: len(data), index: index}
+}
+
+// hex is tried first, since hex digits are valid base64 as well
+func decodeBlob(value string) (string, []byte) {
+	if hexPattern.MatchString(value) && len(value)%2 == 0 {
+		data, _ := hex.DecodeString(value)
+		return EncodingHex, data
+	}
+	if len(value)%4 != 0 {
+		return "", nil
+	}
+	if b64Pattern.MatchString(value) {
+		data, _ := base64.StdEncoding.DecodeString(value)
+		return EncodingB64, data
+	}
+	if b64URLPattern.MatchString(value) {
+		data, _ := base64.URLEncoding.DecodeString(value)
+		return EncodingB64URL, data
+	}
+	return "", nil
+}
+
+// LooksEncrypted tells whether data may be CBC ciphertext: aligned to block, not a text, close to random
+func LooksEncrypted(data []byte) bool {
+	if len(data) < minBlobLen || len(data)%8 != 0 || util.IsPrintable(data) {
+		return false
+	}
+
+	// entropy of n random bytes can't exceed log2(n)
+	max := math.Log2(math.Min(float64(len(data)), 256))
+	return entropy(data) >= minEntropyRatio*max
+}
+
+// Shannon entropy, in bits per byte
+func entropy(data []byte) float64 {
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+
+	e := 0.0
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / float64(len(data))
+			e -= p * math.Log2(p)
+		}
+	}
+	return e
+}
+
+func splitParams(params string) []string {
+	if params == "" {
+		return nil
+	}
+	return strings.Split(params, "&")
+}
+
+// Place puts placeholder instead of candidate's value into request data
+func (c *Candidate) Place(rawURL, postData string, cookies []*http.Cookie, placeholder string) (string, string, []*http.Cookie) {
+	switch c.Location {
+	case LocationQuery:
+		if i := strings.IndexByte(rawURL, '?'); i >= 0 {
+			query := rawURL[i+1:]
+			fragment := ""
+			if j := strings.IndexByte(query, '#'); j >= 0 {
+				query, fragment = query[:j], query[j:]
+			}
+			rawURL = rawURL[:i+1] + placeParam(query, c.index, placeholder) + fragment
+		}
+	case LocationPOST:
+		postData = placeParam(postData, c.index, placeholder)
+	case LocationCookie:
+		placed := make([]*http.Cookie, len(cookies))
+		for i, cookie := range cookies {
+			copied := *cookie
+			if i == c.index {
+				copied.Value = placeholder
+			}
+			placed[i] = &copied
+		}
+		cookies = placed
+	}
+	return rawURL, postData, cookies
+}
+
+func placeParam(params string, index int, placeholder string) string {
+	list := splitParams(params)
+	for i, param := range list {
+		if i == index {
+			list[i] = strings.SplitN(param, "=", 2)[0] + "=" + placeholder
+		}
+	}
+	return strings.Join(list, "&")
+}
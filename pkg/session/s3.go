@@ -0,0 +1,180 @@
+package session
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// region used when not specified
+const defaultS3Region = "us-east-1"
+
+// S3Store keeps session as object in S3-compatible storage (AWS S3, MinIO, Ceph, etc.).
+// requests are signed with AWS Signature Version 4, path-style addressing is used
+type S3Store struct {
+	Endpoint     string // e.g. https://minio.local:9000, defaults to AWS S3 endpoint of the region
+	Region       string
+	Bucket       string
+	Key          string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string // temporary credentials only
+
+	// client used to reach the storage, defaults to http.DefaultClient
+	HTTPclient *http.Client
+}
+
+func (s *S3Store) region() string {
+	if s.Region == "" {
+		return defaultS3Region
+	}
+	return s.Region
+}
+
+// URL of the object
+func (s *S3Store) objectURL() string {
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", s.region())
+	}
+	return strings.TrimSuffix(endpoint, "/") + "/" + s.Bucket + "/" + s.Key
+}
+
+// sends signed request to object
+func (s *S3Store) do(method string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, s.objectURL(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	s.sign(req, body, time.Now().UTC())
+
+	client := s.HTTPclient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("S3 %s %s: %s", method, s, resp.Status)
+	}
+	return data, nil
+}
+
+// Save uploads session as object
+func (s *S3Store) Save(session *Session) error {
+	data, err := session.marshal()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.do(http.MethodPut, data)
+	return err
+}
+
+// Load downloads session object
+func (s *S3Store) Load() (*Session, error) {
+	data, err := s.do(http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshal(data)
+}
+
+func (s *S3Store) String() string {
+	return fmt.Sprintf("s3://%s/%s", s.Bucket, s.Key)
+}
+
+// sign adds AWS Signature Version 4 to request, all headers of request are signed
+func (s *S3Store) sign(req *http.Request, body []byte, t time.Time) {
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	if s.SessionToken != "" {
+		req.Header.Set("x-amz-security-token", s.SessionToken)
+	}
+
+	// canonical headers: host and all headers of request, lowercase and sorted
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		escapePath(req.URL.Path),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region() + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	key = hmacSHA256(key, s.region())
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature))
+}
+
+// URI-encodes every segment of path, as required by Signature Version 4
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = strings.Replace(url.QueryEscape(segment), "+", "%20", -1)
+	}
+	return strings.Join(segments, "/")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,122 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+	"unicode/utf8"
+)
+
+// current version of session format
+const formatVersion = 1
+
+// Session is a snapshot of progress, sufficient to resume the work later.
+// Target settings (URL, matcher, encoding, etc.) are not part of the session,
+// they must be passed again upon resume
+type Session struct {
+	Version  int       `json:"version"`
+	SavedAt  time.Time `json:"saved_at"`
+	Mode     string    `json:"mode"`      // encrypt, decrypt or forge
+	BlockLen int       `json:"block_len"` // cipher block length
+	Inputs   []string  `json:"inputs"`    // all inputs
+	Current  int       `json:"current"`   // index of input in progress
+	Output   []byte    `json:"output"`    // trailing part of output for current input, recovered so far
+
+	// inputs with arbitrary bytes (e.g. plaintexts read from file) would be corrupted as JSON strings,
+	// so they are saved here instead of Inputs
+	BinaryInputs [][]byte `json:"binary_inputs,omitempty"`
+
+	// where and why the work stopped, if it was stopped by error
+	Failure *Failure `json:"failure,omitempty"`
+}
+
+// Failure - the point where the work was stopped by error
+type Failure struct {
+	Input  int    `json:"input"`           // index of input
+	Block  int    `json:"block,omitempty"` // number of failed block (IV is the 1st when decrypting), 0 if not known
+	Reason string `json:"reason"`          // class of error: network failure, unexpected oracle behavior, etc.
+	Error  string `json:"error"`
+}
+
+// serializes session, stamping it with version and time
+func (s *Session) marshal() ([]byte, error) {
+	s.Version = formatVersion
+	s.SavedAt = time.Now()
+
+	for _, input := range s.Inputs {
+		if !utf8.ValidString(input) {
+			saved := *s
+			saved.Inputs, saved.BinaryInputs = []string{}, make([][]byte, len(s.Inputs))
+			for i, input := range s.Inputs {
+				saved.BinaryInputs[i] = []byte(input)
+			}
+			return json.MarshalIndent(&saved, "", "  ")
+		}
+	}
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// deserializes and validates session
+func unmarshal(data []byte) (*Session, error) {
+	s := &Session{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid session file: %w", err)
+	}
+
+	if s.BinaryInputs != nil {
+		s.Inputs = make([]string, len(s.BinaryInputs))
+		for i, input := range s.BinaryInputs {
+			s.Inputs[i] = string(input)
+		}
+		s.BinaryInputs = nil
+	}
+
+	if s.Version != formatVersion {
+		return nil, fmt.Errorf("unsupported session version: %d", s.Version)
+	}
+	if s.BlockLen <= 0 {
+		return nil, fmt.Errorf("invalid session file: block length is not set")
+	}
+	if s.Current < 0 || s.Current >= len(s.Inputs) {
+		return nil, fmt.Errorf("invalid session file: no input at position %d", s.Current)
+	}
+	return s, nil
+}
+
+// Save writes session into file. The file is replaced atomically
+func (s *Session) Save(path string) error {
+	data, err := s.marshal()
+	if err != nil {
+		return err
+	}
+
+	// write into temporary file first, so that previous session is not corrupted on failure
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load reads session from file
+func Load(path string) (*Session, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshal(data)
+}
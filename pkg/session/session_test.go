@@ -0,0 +1,64 @@
+package session
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "padre")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "padre.session")
+
+	saved := &Session{
+		Mode:     "decrypt",
+		BlockLen: 16,
+		Inputs:   []string{"in1", "in2"},
+		Current:  1,
+		Output:   []byte{0, 1, 2, 0xff},
+	}
+	require.NoError(t, saved.Save(path))
+
+	// save again, must overwrite
+	saved.Output = append(saved.Output, 3)
+	require.NoError(t, saved.Save(path))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, saved.Mode, loaded.Mode)
+	assert.Equal(t, saved.BlockLen, loaded.BlockLen)
+	assert.Equal(t, saved.Inputs, loaded.Inputs)
+	assert.Equal(t, saved.Current, loaded.Current)
+	assert.Equal(t, saved.Output, loaded.Output)
+
+	// no temporary files left behind
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	// failure point
+	saved.Failure = &Failure{Input: 1, Block: 3, Reason: "network failure", Error: "connection refused"}
+	require.NoError(t, saved.Save(path))
+	loaded, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, saved.Failure, loaded.Failure)
+
+	// inputs with arbitrary bytes
+	saved.Inputs = []string{"in1", "\x00\xfe\xff"}
+	require.NoError(t, saved.Save(path))
+	loaded, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, saved.Inputs, loaded.Inputs)
+
+	// broken session
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"version":1,"inputs":[],"current":0}`), 0644))
+	_, err = Load(path)
+	assert.Error(t, err)
+}
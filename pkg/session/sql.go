@@ -0,0 +1,73 @@
+package session
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// default key of session in SQL table
+const defaultSQLKey = "default"
+
+// SQLStore keeps sessions in SQL table (padre_sessions), under given key.
+// designed for SQLite, but any database/sql driver with ? placeholders will do
+type SQLStore struct {
+	DB  *sql.DB
+	Key string
+}
+
+func (s *SQLStore) key() string {
+	if s.Key == "" {
+		return defaultSQLKey
+	}
+	return s.Key
+}
+
+// creates table if it does not exist
+func (s *SQLStore) init() error {
+	_, err := s.DB.Exec(`CREATE TABLE IF NOT EXISTS padre_sessions (key TEXT PRIMARY KEY, data BLOB NOT NULL)`)
+	return err
+}
+
+// Save replaces session under the key
+func (s *SQLStore) Save(session *Session) error {
+	data, err := session.marshal()
+	if err != nil {
+		return err
+	}
+
+	if err = s.init(); err != nil {
+		return err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM padre_sessions WHERE key = ?`, s.key()); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec(`INSERT INTO padre_sessions (key, data) VALUES (?, ?)`, s.key(), data); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Load reads session stored under the key
+func (s *SQLStore) Load() (*Session, error) {
+	var data []byte
+	err := s.DB.QueryRow(`SELECT data FROM padre_sessions WHERE key = ?`, s.key()).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("no session with key %q", s.key())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unmarshal(data)
+}
+
+func (s *SQLStore) String() string {
+	return fmt.Sprintf("SQL table padre_sessions (key %s)", s.key())
+}
@@ -0,0 +1,84 @@
+package session
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Store persists session, so that work can be resumed after the process (or its container) dies
+type Store interface {
+	// Save replaces the stored session
+	Save(s *Session) error
+
+	// Load reads the stored session
+	Load() (*Session, error)
+
+	// String describes location of session, for humans
+	String() string
+}
+
+// FileStore keeps session in local file
+type FileStore struct {
+	Path string
+}
+
+// Save writes session into file
+func (f *FileStore) Save(s *Session) error {
+	return s.Save(f.Path)
+}
+
+// Load reads session from file
+func (f *FileStore) Load() (*Session, error) {
+	return Load(f.Path)
+}
+
+func (f *FileStore) String() string {
+	return f.Path
+}
+
+// OpenStore opens store by location:
+//
+//	path/to/file or file://path/to/file           - local file
+//	s3://bucket/key[?endpoint=URL&region=REGION]   - S3-compatible object storage, credentials are taken from
+//	                                                 AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN
+//
+// SQLStore is not opened by location, as this package links no SQL driver:
+// programs that do, construct it with their own *sql.DB
+func OpenStore(location string) (Store, error) {
+	if !strings.Contains(location, "://") {
+		return &FileStore{Path: location}, nil
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session location: %w", err)
+	}
+
+	switch u.Scheme {
+	case "file":
+		return &FileStore{Path: u.Host + u.Path}, nil
+
+	case "s3":
+		if u.Host == "" || strings.Trim(u.Path, "/") == "" {
+			return nil, fmt.Errorf("invalid session location: bucket and key are required (s3://bucket/key)")
+		}
+
+		store := &S3Store{
+			Endpoint:     u.Query().Get("endpoint"),
+			Region:       u.Query().Get("region"),
+			Bucket:       u.Host,
+			Key:          strings.TrimPrefix(u.Path, "/"),
+			AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		if store.AccessKey == "" || store.SecretKey == "" {
+			return nil, fmt.Errorf("S3 credentials are not set (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY)")
+		}
+		return store, nil
+	}
+
+	return nil, fmt.Errorf("unsupported session storage: %s", u.Scheme)
+}
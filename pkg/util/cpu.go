@@ -0,0 +1,50 @@
+package util
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// cgroup files that define CPU quota
+const (
+	cgroupV2CPUMax    = "/sys/fs/cgroup/cpu.max"
+	cgroupV1CPUQuota  = "/sys/fs/cgroup/cpu/cpu.cfs_quota_us"
+	cgroupV1CPUPeriod = "/sys/fs/cgroup/cpu/cpu.cfs_period_us"
+)
+
+// CPUQuota returns number of CPUs, available to the process according to cgroup limits (e.g. inside container).
+// returns false if quota is not set, or cannot be determined
+func CPUQuota() (float64, bool) {
+	// cgroup v2: "<quota> <period>" or "max <period>"
+	if data, err := ioutil.ReadFile(cgroupV2CPUMax); err == nil {
+		fields := strings.Fields(string(data))
+		if len(fields) == 2 {
+			return parseCPUQuota(fields[0], fields[1])
+		}
+		return 0, false
+	}
+
+	// cgroup v1: quota is -1 when not set
+	quota, err := ioutil.ReadFile(cgroupV1CPUQuota)
+	if err != nil {
+		return 0, false
+	}
+	period, err := ioutil.ReadFile(cgroupV1CPUPeriod)
+	if err != nil {
+		return 0, false
+	}
+	return parseCPUQuota(strings.TrimSpace(string(quota)), strings.TrimSpace(string(period)))
+}
+
+func parseCPUQuota(quota, period string) (float64, bool) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil || q <= 0 {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0, false
+	}
+	return q / p, true
+}
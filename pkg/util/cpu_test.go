@@ -0,0 +1,26 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCPUQuota(t *testing.T) {
+	tests := []struct {
+		quota, period string
+		want          float64
+		ok            bool
+	}{
+		{"50000", "100000", 0.5, true},
+		{"200000", "100000", 2, true},
+		{"max", "100000", 0, false},
+		{"-1", "100000", 0, false},
+		{"100000", "0", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseCPUQuota(tt.quota, tt.period)
+		assert.Equal(t, tt.ok, ok, tt.quota)
+		assert.Equal(t, tt.want, got, tt.quota)
+	}
+}
@@ -0,0 +1,63 @@
+package util
+
+// DiffOp - kind of line in diff
+type DiffOp byte
+
+// kinds of lines in diff
+const (
+	DiffSame    DiffOp = ' '
+	DiffRemoved DiffOp = '-'
+	DiffAdded   DiffOp = '+'
+)
+
+// DiffLine - line of diff
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// DiffLines compares lines of a and b (longest common subsequence), returns lines of both in order:
+// those only in a are removed, those only in b are added.
+// meant for short texts, as it takes len(a)*len(b) memory
+func DiffLines(a, b []string) []DiffLine {
+	// lcs[i][j] is length of common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff []DiffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, DiffLine{DiffSame, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{DiffRemoved, a[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{DiffAdded, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, DiffLine{DiffRemoved, a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, DiffLine{DiffAdded, b[j]})
+	}
+	return diff
+}
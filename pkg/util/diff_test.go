@@ -0,0 +1,23 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	a := []string{"GET /?token=$ HTTP/1.1", "Host: example.com", "Cookie: s=1"}
+	b := []string{"GET /?token=abc%3D HTTP/1.1", "Host: example.com", "Cookie: s=1", "Referer: x"}
+
+	assert.Equal(t, []DiffLine{
+		{DiffRemoved, "GET /?token=$ HTTP/1.1"},
+		{DiffAdded, "GET /?token=abc%3D HTTP/1.1"},
+		{DiffSame, "Host: example.com"},
+		{DiffSame, "Cookie: s=1"},
+		{DiffAdded, "Referer: x"},
+	}, DiffLines(a, b))
+
+	assert.Empty(t, DiffLines(nil, nil))
+	assert.Equal(t, []DiffLine{{DiffRemoved, "x"}}, DiffLines([]string{"x"}, nil))
+}
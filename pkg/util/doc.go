@@ -0,0 +1,2 @@
+// Package util contains helpers shared by padre packages.
+package util
@@ -2,8 +2,10 @@ package util
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -35,12 +37,22 @@ func ParseCookies(cookies string) (cookSlice []*http.Cookie, err error) {
 	return cookSlice, nil
 }
 
+// namespace of SOAP 1.2 envelope, such messages have their own content type
+const soap12Namespace = "http://www.w3.org/2003/05/soap-envelope"
+
 // DetectContentType detects HTTP content type based on provided POST data
 func DetectContentType(data string) string {
 	var contentType string
 
 	if data[0] == '{' || data[0] == '[' {
 		contentType = "application/json"
+	} else if strings.HasPrefix(strings.TrimSpace(data), "<") {
+		// XML documents, SOAP 1.1 envelopes are sent as text/xml
+		if strings.Contains(data, soap12Namespace) {
+			contentType = "application/soap+xml; charset=utf-8"
+		} else {
+			contentType = "text/xml; charset=utf-8"
+		}
 	} else {
 		match, _ := regexp.MatchString("([^=]+=[^=]+&?)+", data)
 		if match {
@@ -51,3 +63,49 @@ func DetectContentType(data string) string {
 	}
 	return contentType
 }
+
+// media type of content type, without parameters
+func mediaType(contentType string) string {
+	return strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+}
+
+// IsXMLContentType tells whether content type denotes XML document (including SOAP envelopes)
+func IsXMLContentType(contentType string) bool {
+	t := mediaType(contentType)
+	return strings.HasSuffix(t, "/xml") || strings.HasSuffix(t, "+xml")
+}
+
+// IsJSONContentType tells whether content type denotes JSON document (e.g. application/json, application/vnd.api+json)
+func IsJSONContentType(contentType string) bool {
+	t := mediaType(contentType)
+	return strings.HasSuffix(t, "/json") || strings.HasSuffix(t, "+json")
+}
+
+// IsFormContentType tells whether content type denotes URL-encoded form
+func IsFormContentType(contentType string) bool {
+	return mediaType(contentType) == "application/x-www-form-urlencoded"
+}
+
+// IsMultipartContentType tells whether content type denotes multipart body (e.g. multipart/form-data)
+func IsMultipartContentType(contentType string) bool {
+	return strings.HasPrefix(mediaType(contentType), "multipart/")
+}
+
+// ParseStatusCodes parses comma-separated list of HTTP status codes (e.g. "500,502")
+func ParseStatusCodes(list string) ([]int, error) {
+	codes := make([]int, 0)
+
+	for _, s := range strings.Split(list, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code: %q", s)
+		}
+
+		if code < 100 || code > 599 {
+			return nil, fmt.Errorf("status code out of range: %d", code)
+		}
+
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
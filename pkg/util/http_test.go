@@ -46,6 +46,9 @@ func TestDetectContentType(t *testing.T) {
 		{"json-object", args{"{'a':1}"}, "application/json"},
 		{"json-array", args{"[{'a':1}]"}, "application/json"},
 		{"form", args{"a=1&b=2"}, "application/x-www-form-urlencoded"},
+		{"xml", args{"<?xml version=\"1.0\"?><a>$</a>"}, "text/xml; charset=utf-8"},
+		{"soap11", args{`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"/>`}, "text/xml; charset=utf-8"},
+		{"soap12", args{`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"/>`}, "application/soap+xml; charset=utf-8"},
 		{"text", args{"text"}, http.DetectContentType([]byte("text"))},
 	}
 	for _, tt := range tests {
@@ -56,3 +59,74 @@ func TestDetectContentType(t *testing.T) {
 		})
 	}
 }
+
+func TestIsXMLContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"text/xml; charset=utf-8", true},
+		{"application/xml", true},
+		{"Application/SOAP+XML; action=\"urn:x\"", true},
+		{"application/json", false},
+		{"application/x-www-form-urlencoded", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			if got := IsXMLContentType(tt.contentType); got != tt.want {
+				t.Errorf("IsXMLContentType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsJSONContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"application/json", true},
+		{"application/vnd.api+json; charset=utf-8", true},
+		{"text/json", true},
+		{"text/xml", false},
+		{"application/x-www-form-urlencoded", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			if got := IsJSONContentType(tt.contentType); got != tt.want {
+				t.Errorf("IsJSONContentType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStatusCodes(t *testing.T) {
+	type args struct {
+		list string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []int
+		wantErr bool
+	}{
+		{"single", args{"500"}, []int{500}, false},
+		{"multiple", args{"500, 502,403"}, []int{500, 502, 403}, false},
+		{"empty", args{""}, nil, true},
+		{"garbage", args{"50x"}, nil, true},
+		{"out-of-range", args{"1000"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStatusCodes(tt.args.list)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseStatusCodes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStatusCodes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
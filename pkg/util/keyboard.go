@@ -0,0 +1,31 @@
+package util
+
+import "os"
+
+// ListenKeys switches terminal attached to STDIN into non-canonical mode (no line buffering, no echo),
+// and delivers every pressed key into returned channel.
+// restore must be called to return terminal into original mode.
+// signals (e.g. Ctrl+C) are still handled by the terminal as usual
+func ListenKeys() (keys <-chan byte, restore func(), err error) {
+	restore, err = enterCbreakMode(os.Stdin)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan byte, 16)
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(ch)
+				return
+			}
+			if n == 1 {
+				ch <- buf[0]
+			}
+		}
+	}()
+
+	return ch, restore, nil
+}
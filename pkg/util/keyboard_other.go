@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !windows
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!windows
+
+package util
+
+import (
+	"errors"
+	"os"
+)
+
+func enterCbreakMode(f *os.File) (func(), error) {
+	return nil, errors.New("keyboard input is not supported on this platform")
+}
@@ -0,0 +1,25 @@
+package util
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+func enterCbreakMode(f *os.File) (func(), error) {
+	handle := windows.Handle(f.Fd())
+
+	var original uint32
+	if err := windows.GetConsoleMode(handle, &original); err != nil {
+		return nil, err
+	}
+
+	cbreak := original &^ (windows.ENABLE_LINE_INPUT | windows.ENABLE_ECHO_INPUT)
+	if err := windows.SetConsoleMode(handle, cbreak); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		windows.SetConsoleMode(handle, original)
+	}, nil
+}
@@ -4,10 +4,15 @@ import (
 	"bytes"
 	"container/ring"
 	"math/rand"
+	"sync"
 )
 
-// ring buffer for generating random chunks of bytes
-var randomRing *ring.Ring
+// ring buffer for generating random chunks of bytes,
+// shared by all attacks running in the process
+var (
+	randomRing   *ring.Ring
+	randomRingMx sync.Mutex
+)
 
 func init() {
 	mysteriousData := []byte{
@@ -26,6 +31,9 @@ func init() {
 func RandomSlice(len int) []byte {
 	buf := bytes.NewBuffer(make([]byte, 0, len))
 
+	randomRingMx.Lock()
+	defer randomRingMx.Unlock()
+
 	for i := 0; i < len; i++ {
 		buf.WriteByte(randomRing.Value.(byte))
 
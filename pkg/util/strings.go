@@ -1,6 +1,10 @@
 package util
 
-import "strings"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // ReverseString returns reverse of a string (does not support runes)
 func ReverseString(in string) string {
@@ -10,3 +14,37 @@ func ReverseString(in string) string {
 	}
 	return out.String()
 }
+
+// ParseIntRange parses either a single non-negative integer ("100"), or inclusive range ("100-200")
+func ParseIntRange(s string) (min, max int, err error) {
+	bounds := strings.SplitN(s, "-", 2)
+
+	min, err = strconv.Atoi(strings.TrimSpace(bounds[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid number: %q", bounds[0])
+	}
+
+	if len(bounds) == 1 {
+		return min, min, nil
+	}
+
+	max, err = strconv.Atoi(strings.TrimSpace(bounds[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid number: %q", bounds[1])
+	}
+
+	if min > max {
+		return 0, 0, fmt.Errorf("lower bound is greater than upper bound: %q", s)
+	}
+	return min, max, nil
+}
+
+// IsPrintable checks whether data consists of printable ASCII characters and common whitespace only
+func IsPrintable(data []byte) bool {
+	for _, b := range data {
+		if (b < 32 || b > 126) && b != '\t' && b != '\n' && b != '\r' {
+			return false
+		}
+	}
+	return true
+}
@@ -22,3 +22,53 @@ func TestReverseString(t *testing.T) {
 		})
 	}
 }
+
+func TestParseIntRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantMin int
+		wantMax int
+		wantErr bool
+	}{
+		{"single", "100", 100, 100, false},
+		{"range", "100-200", 100, 200, false},
+		{"spaces", " 1 - 2 ", 1, 2, false},
+		{"reversed", "200-100", 0, 0, true},
+		{"garbage", "abc", 0, 0, true},
+		{"negative", "-5", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotMin, gotMax, err := ParseIntRange(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseIntRange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotMin != tt.wantMin || gotMax != tt.wantMax {
+				t.Errorf("ParseIntRange() = %v-%v, want %v-%v", gotMin, gotMax, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestIsPrintable(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want bool
+	}{
+		{"empty", []byte{}, true},
+		{"text", []byte("user=admin;\r\n\tok"), true},
+		{"null", []byte("a\x00b"), false},
+		{"high", []byte{'a', 0x80}, false},
+		{"del", []byte{0x7f}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPrintable(tt.in); got != tt.want {
+				t.Errorf("IsPrintable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
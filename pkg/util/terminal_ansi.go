@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package util
+
+import "os"
+
+// EnableANSI turns on processing of ANSI escape sequences by terminal.
+// terminals of this platform process them natively, nothing to do
+func EnableANSI(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,32 @@
+package util
+
+import "time"
+
+// how often terminal width is polled on platforms without resize signal
+const terminalPollInterval = time.Second
+
+// polls terminal width, calls onChange when it differs from the last seen.
+// returned function stops polling
+func pollTerminalWidth(onChange func(width int)) func() {
+	done := make(chan struct{})
+	last, _ := TerminalWidth()
+
+	go func() {
+		ticker := time.NewTicker(terminalPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if width, err := TerminalWidth(); err == nil && width != last {
+					last = width
+					onChange(width)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
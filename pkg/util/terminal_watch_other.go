@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !windows
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!windows
+
+package util
+
+// WatchTerminalWidth calls onChange with new width of terminal every time it is resized.
+// the width is polled on this platform.
+// returned function stops watching
+func WatchTerminalWidth(onChange func(width int)) func() {
+	return pollTerminalWidth(onChange)
+}
@@ -0,0 +1,11 @@
+//go:build notui && !linux && !darwin && !freebsd && !netbsd && !openbsd && !windows
+// +build notui,!linux,!darwin,!freebsd,!netbsd,!openbsd,!windows
+
+package util
+
+import "errors"
+
+// TerminalWidth is not supported in builds without TUI on this platform
+func TerminalWidth() (int, error) {
+	return 0, errors.New("terminal width is not available in this build")
+}
@@ -0,0 +1,17 @@
+//go:build !notui && !linux && !darwin && !freebsd && !netbsd && !openbsd && !windows
+// +build !notui,!linux,!darwin,!freebsd,!netbsd,!openbsd,!windows
+
+package util
+
+import "github.com/nsf/termbox-go"
+
+// TerminalWidth determines width of current terminal in characters
+func TerminalWidth() (int, error) {
+	if err := termbox.Init(); err != nil {
+		return 0, err
+	}
+	w, _ := termbox.Size()
+	termbox.Close()
+	// decrease length by 1 for safety
+	return w - 1, nil
+}
@@ -0,0 +1,51 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+// +build linux darwin freebsd netbsd openbsd
+
+package util
+
+import (
+	"os"
+	"os/signal"
+
+	"golang.org/x/sys/unix"
+)
+
+// TerminalWidth determines width of current terminal in characters.
+// the terminal is queried directly, so it is cheap enough to be called on every resize
+func TerminalWidth() (int, error) {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		ws, err = unix.IoctlGetWinsize(int(os.Stderr.Fd()), unix.TIOCGWINSZ)
+	}
+	if err != nil {
+		return 0, err
+	}
+	// decrease length by 1 for safety
+	return int(ws.Col) - 1, nil
+}
+
+// WatchTerminalWidth calls onChange with new width of terminal every time it is resized (SIGWINCH).
+// returned function stops watching
+func WatchTerminalWidth(onChange func(width int)) func() {
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigs, unix.SIGWINCH)
+
+	go func() {
+		for {
+			select {
+			case <-sigs:
+				if width, err := TerminalWidth(); err == nil {
+					onChange(width)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
@@ -0,0 +1,41 @@
+package util
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// TerminalWidth determines width of current console window in characters
+func TerminalWidth() (int, error) {
+	var info windows.ConsoleScreenBufferInfo
+	err := windows.GetConsoleScreenBufferInfo(windows.Handle(os.Stdout.Fd()), &info)
+	if err != nil {
+		err = windows.GetConsoleScreenBufferInfo(windows.Handle(os.Stderr.Fd()), &info)
+	}
+	if err != nil {
+		return 0, err
+	}
+	// decrease length by 1 for safety
+	// windows CMD wraps the line when the last column is printed
+	return int(info.Window.Right-info.Window.Left+1) - 1, nil
+}
+
+// WatchTerminalWidth calls onChange with new width of console every time it is resized.
+// windows has no resize signal, so the width is polled.
+// returned function stops watching
+func WatchTerminalWidth(onChange func(width int)) func() {
+	return pollTerminalWidth(onChange)
+}
+
+// EnableANSI turns on processing of ANSI escape sequences by console (Windows 10+),
+// so that colors and line overwriting of status bar work in plain cmd.exe and PowerShell
+func EnableANSI(f *os.File) error {
+	handle := windows.Handle(f.Fd())
+
+	var mode uint32
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		return err
+	}
+	return windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING)
+}
@@ -0,0 +1,3 @@
+// Package viewstate parses ASP.NET ViewState payloads: encoding and MAC are detected and split off,
+// unencrypted (or decrypted) state is parsed from ObjectStateFormatter (LosFormatter) binary format into a tree.
+package viewstate
@@ -0,0 +1,415 @@
+package viewstate
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// ObjectStateFormatter starts serialized data with marker and version
+var header = []byte{0xff, 0x01}
+
+// tokens of ObjectStateFormatter
+const (
+	tokenInt16                  = 0x01
+	tokenInt32                  = 0x02
+	tokenByte                   = 0x03
+	tokenChar                   = 0x04
+	tokenString                 = 0x05
+	tokenDateTime               = 0x06
+	tokenDouble                 = 0x07
+	tokenSingle                 = 0x08
+	tokenColor                  = 0x09
+	tokenKnownColor             = 0x0a
+	tokenIntEnum                = 0x0b
+	tokenEmptyColor             = 0x0c
+	tokenPair                   = 0x0f
+	tokenTriplet                = 0x10
+	tokenArray                  = 0x14
+	tokenStringArray            = 0x15
+	tokenArrayList              = 0x16
+	tokenHashtable              = 0x17
+	tokenHybridDictionary       = 0x18
+	tokenType                   = 0x19
+	tokenUnit                   = 0x1b
+	tokenEmptyUnit              = 0x1c
+	tokenIndexedString          = 0x1e
+	tokenIndexedStringReference = 0x1f
+	tokenStringFormatted        = 0x28
+	tokenTypeRefAdd             = 0x29
+	tokenTypeRefAddLocal        = 0x2a
+	tokenTypeRef                = 0x2b
+	tokenBinarySerialized       = 0x32
+	tokenSparseArray            = 0x3c
+	tokenNull                   = 0x64
+	tokenEmptyString            = 0x65
+	tokenZeroInt32              = 0x66
+	tokenTrue                   = 0x67
+	tokenFalse                  = 0x68
+)
+
+// limit of nesting, deeper state is rejected
+const maxDepth = 64
+
+// Node - value of serialized state
+type Node struct {
+	Kind     string      // pair, string, int32, hashtable, etc.
+	Type     string      // type name, for typed values (enums, arrays, formatted strings)
+	Value    interface{} // scalar value, nil for containers
+	Children []*Node     // items of containers, keys and values interleave in dictionaries
+}
+
+// ErrTruncated is returned when serialized data ends unexpectedly
+var ErrTruncated = errors.New("serialized state is truncated")
+
+// Parse parses ObjectStateFormatter data, returns root node and bytes that follow it (e.g. MAC)
+func Parse(data []byte) (*Node, []byte, error) {
+	if !IsSerialized(data) {
+		return nil, nil, fmt.Errorf("not a serialized state: no %x header", header)
+	}
+	p := &parser{data: data, pos: len(header)}
+	root, err := p.node(0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("at offset %d: %s", p.pos, err)
+	}
+	return root, data[p.pos:], nil
+}
+
+type parser struct {
+	data    []byte
+	pos     int
+	strings []string // table of indexed strings
+	types   []string // table of type references
+}
+
+func (p *parser) bytes(n int) ([]byte, error) {
+	if n < 0 || p.pos+n > len(p.data) {
+		return nil, ErrTruncated
+	}
+	b := p.data[p.pos : p.pos+n]
+	p.pos += n
+	return b, nil
+}
+
+func (p *parser) byte() (byte, error) {
+	b, err := p.bytes(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// 7-bit encoded integer
+func (p *parser) int() (int, error) {
+	var n, shift uint
+	for {
+		b, err := p.byte()
+		if err != nil {
+			return 0, err
+		}
+		n |= uint(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return int(int32(n)), nil
+		}
+		if shift += 7; shift > 28 {
+			return 0, errors.New("invalid 7-bit encoded integer")
+		}
+	}
+}
+
+// number of items, every item takes at least a byte
+func (p *parser) count() (int, error) {
+	n, err := p.int()
+	if err == nil && (n < 0 || n > len(p.data)-p.pos) {
+		err = fmt.Errorf("invalid number of items: %d", n)
+	}
+	return n, err
+}
+
+// length-prefixed UTF-8 string
+func (p *parser) string() (string, error) {
+	n, err := p.int()
+	if err != nil {
+		return "", err
+	}
+	b, err := p.bytes(n)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(b) {
+		return "", errors.New("string is not valid UTF-8")
+	}
+	return string(b), nil
+}
+
+func (p *parser) typeRef() (string, error) {
+	token, err := p.byte()
+	if err != nil {
+		return "", err
+	}
+	switch token {
+	case tokenTypeRefAdd, tokenTypeRefAddLocal:
+		name, err := p.string()
+		if err != nil {
+			return "", err
+		}
+		p.types = append(p.types, name)
+		return name, nil
+	case tokenTypeRef:
+		i, err := p.int()
+		if err != nil {
+			return "", err
+		}
+		if i < 0 || i >= len(p.types) {
+			return "", fmt.Errorf("unknown type reference: %d", i)
+		}
+		return p.types[i], nil
+	}
+	return "", fmt.Errorf("unexpected token of type: 0x%02x", token)
+}
+
+// reads n nodes as children of parent
+func (p *parser) children(parent *Node, n, depth int) (*Node, error) {
+	for i := 0; i < n; i++ {
+		child, err := p.node(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		parent.Children = append(parent.Children, child)
+	}
+	return parent, nil
+}
+
+func (p *parser) node(depth int) (*Node, error) {
+	if depth > maxDepth {
+		return nil, errors.New("state is nested too deep")
+	}
+
+	token, err := p.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case tokenNull:
+		return &Node{Kind: "null"}, nil
+	case tokenEmptyString:
+		return &Node{Kind: "string", Value: ""}, nil
+	case tokenZeroInt32:
+		return &Node{Kind: "int32", Value: 0}, nil
+	case tokenTrue:
+		return &Node{Kind: "bool", Value: true}, nil
+	case tokenFalse:
+		return &Node{Kind: "bool", Value: false}, nil
+	case tokenEmptyColor:
+		return &Node{Kind: "color", Value: "empty"}, nil
+	case tokenEmptyUnit:
+		return &Node{Kind: "unit", Value: "empty"}, nil
+
+	case tokenInt16:
+		b, err := p.bytes(2)
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Kind: "int16", Value: int16(binary.LittleEndian.Uint16(b))}, nil
+	case tokenInt32:
+		n, err := p.int()
+		return &Node{Kind: "int32", Value: n}, err
+	case tokenByte:
+		b, err := p.byte()
+		return &Node{Kind: "byte", Value: b}, err
+	case tokenChar:
+		b, err := p.byte()
+		return &Node{Kind: "char", Value: string(rune(b))}, err
+	case tokenString:
+		s, err := p.string()
+		return &Node{Kind: "string", Value: s}, err
+	case tokenDateTime:
+		b, err := p.bytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Kind: "datetime", Value: int64(binary.LittleEndian.Uint64(b))}, nil
+	case tokenDouble:
+		b, err := p.bytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Kind: "double", Value: math.Float64frombits(binary.LittleEndian.Uint64(b))}, nil
+	case tokenSingle:
+		b, err := p.bytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Kind: "single", Value: math.Float32frombits(binary.LittleEndian.Uint32(b))}, nil
+	case tokenColor:
+		b, err := p.bytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Kind: "color", Value: fmt.Sprintf("#%08x", binary.LittleEndian.Uint32(b))}, nil
+	case tokenKnownColor:
+		n, err := p.int()
+		return &Node{Kind: "color", Value: n}, err
+	case tokenUnit:
+		b, err := p.bytes(12)
+		if err != nil {
+			return nil, err
+		}
+		value := math.Float64frombits(binary.LittleEndian.Uint64(b))
+		return &Node{Kind: "unit", Value: fmt.Sprintf("%g (type %d)", value, binary.LittleEndian.Uint32(b[8:]))}, nil
+
+	case tokenIndexedString:
+		s, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		p.strings = append(p.strings, s)
+		return &Node{Kind: "string", Value: s}, nil
+	case tokenIndexedStringReference:
+		i, err := p.byte()
+		if err != nil {
+			return nil, err
+		}
+		if int(i) >= len(p.strings) {
+			return nil, fmt.Errorf("unknown string reference: %d", i)
+		}
+		return &Node{Kind: "string", Value: p.strings[i]}, nil
+
+	case tokenIntEnum:
+		t, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		n, err := p.int()
+		return &Node{Kind: "enum", Type: t, Value: n}, err
+	case tokenType:
+		t, err := p.typeRef()
+		return &Node{Kind: "type", Value: t}, err
+	case tokenStringFormatted:
+		t, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		s, err := p.string()
+		return &Node{Kind: "formatted", Type: t, Value: s}, err
+	case tokenBinarySerialized:
+		n, err := p.int()
+		if err != nil {
+			return nil, err
+		}
+		b, err := p.bytes(n)
+		return &Node{Kind: "binary", Value: b}, err
+
+	case tokenPair:
+		return p.children(&Node{Kind: "pair"}, 2, depth)
+	case tokenTriplet:
+		return p.children(&Node{Kind: "triplet"}, 3, depth)
+	case tokenArrayList:
+		n, err := p.count()
+		if err != nil {
+			return nil, err
+		}
+		return p.children(&Node{Kind: "arraylist"}, n, depth)
+	case tokenArray:
+		t, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		n, err := p.count()
+		if err != nil {
+			return nil, err
+		}
+		return p.children(&Node{Kind: "array", Type: t}, n, depth)
+	case tokenHashtable, tokenHybridDictionary:
+		kind := "hashtable"
+		if token == tokenHybridDictionary {
+			kind = "dictionary"
+		}
+		n, err := p.count()
+		if err != nil {
+			return nil, err
+		}
+		return p.children(&Node{Kind: kind}, 2*n, depth)
+	case tokenStringArray:
+		n, err := p.count()
+		if err != nil {
+			return nil, err
+		}
+		node := &Node{Kind: "array", Type: "System.String"}
+		for i := 0; i < n; i++ {
+			s, err := p.string()
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, &Node{Kind: "string", Value: s})
+		}
+		return node, nil
+	case tokenSparseArray:
+		t, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		length, err := p.int()
+		if err != nil {
+			return nil, err
+		}
+		n, err := p.count()
+		if err != nil {
+			return nil, err
+		}
+
+		// only set items are serialized, along with their indexes
+		node := &Node{Kind: "sparsearray", Type: t, Value: length}
+		for i := 0; i < n; i++ {
+			index, err := p.int()
+			if err != nil {
+				return nil, err
+			}
+			item, err := p.node(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, &Node{Kind: "index", Value: index}, item)
+		}
+		return node, nil
+	}
+
+	return nil, fmt.Errorf("unknown token: 0x%02x", token)
+}
+
+// Format renders tree with indentation, one node per line
+func (n *Node) Format() string {
+	var b strings.Builder
+	n.format(&b, 0)
+	return b.String()
+}
+
+func (n *Node) format(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(n.Kind)
+	if n.Type != "" {
+		fmt.Fprintf(b, " <%s>", n.Type)
+	}
+
+	switch v := n.Value.(type) {
+	case nil:
+	case string:
+		fmt.Fprintf(b, " %q", v)
+	case []byte:
+		fmt.Fprintf(b, " (%d bytes)", len(v))
+	default:
+		fmt.Fprintf(b, " %v", v)
+	}
+	if n.Children != nil {
+		fmt.Fprintf(b, " [%d]", len(n.Children))
+	}
+	b.WriteString("\n")
+
+	for _, child := range n.Children {
+		child.format(b, depth+1)
+	}
+}
@@ -0,0 +1,88 @@
+package viewstate
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// length of HMAC-SHA1, the only MAC that can be told apart from ciphertext by its length
+const sha1MACLen = 20
+
+// lengths of MACs that may follow unencrypted state (HMAC-SHA1, SHA256, SHA384, SHA512)
+var macLens = []int{0, 20, 32, 48, 64}
+
+// Payload - ViewState, as found in __VIEWSTATE form field
+type Payload struct {
+	Data       []byte // serialized state, or ciphertext if encrypted
+	MAC        []byte // nil if not detected
+	Encrypted  bool
+	URLEncoded bool // base64 was URL-encoded (e.g. copied from raw request body)
+}
+
+// Decode decodes ViewState, detects whether it's encrypted and splits the MAC off.
+// MAC of unencrypted state is whatever follows the serialized data, MAC of encrypted state is detected only
+// when it's HMAC-SHA1 (ciphertext length is not multiple of block length then)
+func Decode(s string) (*Payload, error) {
+	p := &Payload{}
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "%") {
+		unescaped, err := url.PathUnescape(s)
+		if err != nil {
+			return nil, err
+		}
+		s, p.URLEncoded = unescaped, true
+	}
+
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("ViewState is not base64-encoded: %s", err)
+	}
+
+	if IsSerialized(data) {
+		_, rest, err := Parse(data)
+		if err != nil {
+			return nil, err
+		}
+		if !validMACLen(len(rest)) {
+			return nil, fmt.Errorf("%d bytes follow the serialized state, that's not a MAC", len(rest))
+		}
+		p.Data = data[:len(data)-len(rest)]
+		if len(rest) > 0 {
+			p.MAC = rest
+		}
+		return p, nil
+	}
+
+	p.Encrypted = true
+	p.Data = data
+	if len(data)%8 == sha1MACLen%8 && len(data) > sha1MACLen {
+		p.Data, p.MAC = data[:len(data)-sha1MACLen], data[len(data)-sha1MACLen:]
+	}
+	return p, nil
+}
+
+// Encode joins data and MAC back, in the same encoding as decoded from
+func (p *Payload) Encode() string {
+	s := base64.StdEncoding.EncodeToString(append(append([]byte{}, p.Data...), p.MAC...))
+	if p.URLEncoded {
+		s = url.QueryEscape(s)
+	}
+	return s
+}
+
+// IsSerialized tells whether data starts with ObjectStateFormatter header
+func IsSerialized(data []byte) bool {
+	return bytes.HasPrefix(data, header)
+}
+
+func validMACLen(n int) bool {
+	for _, l := range macLens {
+		if n == l {
+			return true
+		}
+	}
+	return false
+}
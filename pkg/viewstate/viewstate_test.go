@@ -0,0 +1,110 @@
+package viewstate
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pair(pair("1675424970", null), null), as produced by WebForms page without controls
+const simpleState = "/wEPDwUKMTY3NTQyNDk3MGRk"
+
+func TestDecode_Unencrypted(t *testing.T) {
+	p, err := Decode(simpleState)
+	require.NoError(t, err)
+	assert.False(t, p.Encrypted)
+	assert.Nil(t, p.MAC)
+	assert.Equal(t, simpleState, p.Encode())
+
+	// MAC follows the state
+	data, _ := base64.StdEncoding.DecodeString(simpleState)
+	mac := bytes.Repeat([]byte{0xaa}, 32)
+	encoded := url.QueryEscape(base64.StdEncoding.EncodeToString(append(data, mac...)))
+
+	p, err = Decode(encoded)
+	require.NoError(t, err)
+	assert.True(t, p.URLEncoded)
+	assert.Equal(t, data, p.Data)
+	assert.Equal(t, mac, p.MAC)
+	assert.Equal(t, encoded, p.Encode())
+}
+
+func TestDecode_Encrypted(t *testing.T) {
+	ciphertext := bytes.Repeat([]byte{0x42}, 32)
+	p, err := Decode(base64.StdEncoding.EncodeToString(ciphertext))
+	require.NoError(t, err)
+	assert.True(t, p.Encrypted)
+	assert.Nil(t, p.MAC)
+
+	// HMAC-SHA1 is told apart by length
+	mac := bytes.Repeat([]byte{0xaa}, 20)
+	p, err = Decode(base64.StdEncoding.EncodeToString(append(ciphertext, mac...)))
+	require.NoError(t, err)
+	assert.Equal(t, ciphertext, p.Data)
+	assert.Equal(t, mac, p.MAC)
+
+	_, err = Decode("not base64!")
+	assert.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	data, _ := base64.StdEncoding.DecodeString(simpleState)
+	root, rest, err := Parse(data)
+	require.NoError(t, err)
+	assert.Empty(t, rest)
+	assert.Equal(t, "pair [2]\n  pair [2]\n    string \"1675424970\"\n    null\n  null\n", root.Format())
+
+	// every kind of token
+	data = []byte{0xff, 0x01,
+		tokenArrayList, 7,
+		tokenIndexedString, 3, 'a', 'b', 'c',
+		tokenIndexedStringReference, 0,
+		tokenIntEnum, tokenTypeRefAdd, 4, 'E', 'n', 'u', 'm', 5,
+		tokenArray, tokenTypeRef, 0, 1, tokenTrue,
+		tokenHashtable, 1, tokenEmptyString, tokenZeroInt32,
+		tokenStringArray, 2, 1, 'x', 0,
+		tokenSparseArray, tokenTypeRef, 0, 10, 1, 3, tokenInt16, 0xff, 0xff,
+	}
+	root, _, err = Parse(data)
+	require.NoError(t, err)
+	assert.Equal(t, `arraylist [7]
+  string "abc"
+  string "abc"
+  enum <Enum> 5
+  array <Enum> [1]
+    bool true
+  hashtable [2]
+    string ""
+    int32 0
+  array <System.String> [2]
+    string "x"
+    string ""
+  sparsearray <Enum> 10 [2]
+    index 3
+    int16 -1
+`, root.Format())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, data := range [][]byte{
+		{0x01},
+		{0xff, 0x01},
+		{0xff, 0x01, tokenPair, tokenNull},
+		{0xff, 0x01, tokenString, 10, 'a'},
+		{0xff, 0x01, tokenIndexedStringReference, 0},
+		{0xff, 0x01, tokenArrayList, 100, tokenNull},
+		{0xff, 0x01, 0xee},
+	} {
+		_, _, err := Parse(data)
+		assert.Error(t, err, "%x", data)
+	}
+
+	// nesting is limited
+	deep := append([]byte{0xff, 0x01}, bytes.Repeat([]byte{tokenPair}, maxDepth+2)...)
+	_, _, err := Parse(deep)
+	assert.Error(t, err)
+}
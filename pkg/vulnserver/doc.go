@@ -0,0 +1,4 @@
+// Package vulnserver implements deliberately vulnerable web application, that decrypts CBC tokens
+// and spills padding errors. It's a target for self-validation of padre, integration tests and training.
+// Never expose it to untrusted network.
+package vulnserver
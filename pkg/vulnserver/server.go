@@ -0,0 +1,176 @@
+package vulnserver
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"fmt"
+	"net/http"
+
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/glebarez/padre/pkg/exploit"
+	"github.com/glebarez/padre/pkg/util"
+)
+
+// styles in which padding error is revealed
+const (
+	StyleStatus   = "status"   // 500 Internal Server Error
+	StyleBody     = "body"     // 200 OK with error message in body
+	StyleRedirect = "redirect" // 302 Found to error page
+)
+
+// DefaultParam is the name of request parameter (query, form or cookie) that carries token
+const DefaultParam = "token"
+
+// DefaultSecret is the plaintext of issued tokens, unless set otherwise
+const DefaultSecret = "user=guest;role=user;secret=you broke it with padre"
+
+// messages of responses
+const (
+	msgPaddingError = "Padding is invalid and cannot be removed."
+	msgWelcome      = "Welcome!"
+	msgWelcomeAdmin = "Welcome, admin!"
+	msgBadToken     = "Malformed token."
+)
+
+// Config describes the vulnerable server
+type Config struct {
+	BlockLen int             // 8 (3DES) or 16 (AES)
+	Key      []byte          // random, if nil
+	Encoder  encoder.Encoder // base64 if nil
+	Style    string          // how padding error is revealed, one of Style* constants
+	Param    string          // DefaultParam if empty
+	Secret   string          // DefaultSecret if empty
+}
+
+// Server is vulnerable web application: it decrypts token from request and reveals whether its padding is valid.
+// tokens are IV followed by CBC-encrypted PKCS#7-padded plaintext. plaintext containing role=admin is greeted differently,
+// so that forged tokens can be checked
+type Server struct {
+	block  cipher.Block
+	config Config
+}
+
+// New creates vulnerable server
+func New(config Config) (*Server, error) {
+	if config.Encoder == nil {
+		config.Encoder = encoder.NewB64encoder("")
+	}
+	if config.Param == "" {
+		config.Param = DefaultParam
+	}
+	if config.Secret == "" {
+		config.Secret = DefaultSecret
+	}
+
+	switch config.Style {
+	case StyleStatus, StyleBody, StyleRedirect:
+	case "":
+		config.Style = StyleStatus
+	default:
+		return nil, fmt.Errorf("unsupported error style: %s", config.Style)
+	}
+
+	var (
+		block cipher.Block
+		err   error
+	)
+	switch config.BlockLen {
+	case 8:
+		if config.Key == nil {
+			config.Key = util.RandomSlice(24)
+		}
+		block, err = des.NewTripleDESCipher(config.Key)
+	case 16:
+		if config.Key == nil {
+			config.Key = util.RandomSlice(16)
+		}
+		block, err = aes.NewCipher(config.Key)
+	default:
+		return nil, fmt.Errorf("unsupported block length: %d (8 and 16 are supported)", config.BlockLen)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{block: block, config: config}, nil
+}
+
+// Token encrypts plaintext under random IV, and encodes it the way server expects
+func (s *Server) Token(plaintext []byte) string {
+	bl := s.config.BlockLen
+	plaintext = exploit.PKCS7.Pad(plaintext, bl)
+
+	ciphertext := util.RandomSlice(bl + len(plaintext))
+	cipher.NewCBCEncrypter(s.block, ciphertext[:bl]).CryptBlocks(ciphertext[bl:], plaintext)
+	return s.config.Encoder.EncodeToString(ciphertext)
+}
+
+// Encoder returns encoder of tokens
+func (s *Server) Encoder() encoder.Encoder {
+	return s.config.Encoder
+}
+
+// decrypts token, ok is false if padding is invalid
+func (s *Server) decrypt(token string) (plaintext []byte, ok bool, err error) {
+	ciphertext, err := s.config.Encoder.DecodeString(token)
+	if err != nil {
+		return nil, false, err
+	}
+
+	bl := s.config.BlockLen
+	if len(ciphertext) < 2*bl || len(ciphertext)%bl != 0 {
+		return nil, false, fmt.Errorf("length of ciphertext is not multiple of block")
+	}
+
+	plaintext = make([]byte, len(ciphertext)-bl)
+	cipher.NewCBCDecrypter(s.block, ciphertext[:bl]).CryptBlocks(plaintext, ciphertext[bl:])
+	plaintext, ok = exploit.PKCS7.Unpad(plaintext, bl)
+	return plaintext, ok, nil
+}
+
+// ServeHTTP implements http.Handler. request without token gets a fresh one
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/error" {
+		http.Error(w, msgPaddingError, http.StatusOK)
+		return
+	}
+
+	token := r.FormValue(s.config.Param)
+	if token == "" {
+		if cookie, err := r.Cookie(s.config.Param); err == nil {
+			token = cookie.Value
+		}
+	}
+
+	// new visitor
+	if token == "" {
+		fmt.Fprintf(w, "Your token: %s\n", s.Token([]byte(s.config.Secret)))
+		return
+	}
+
+	plaintext, ok, err := s.decrypt(token)
+	switch {
+	case err != nil:
+		http.Error(w, msgBadToken, http.StatusBadRequest)
+	case !ok:
+		s.paddingError(w, r)
+	case bytes.Contains(plaintext, []byte("role=admin")):
+		fmt.Fprintln(w, msgWelcomeAdmin)
+	default:
+		fmt.Fprintln(w, msgWelcome)
+	}
+}
+
+// reveals padding error in configured style
+func (s *Server) paddingError(w http.ResponseWriter, r *http.Request) {
+	switch s.config.Style {
+	case StyleBody:
+		fmt.Fprintln(w, msgPaddingError)
+	case StyleRedirect:
+		http.Redirect(w, r, "/error", http.StatusFound)
+	default:
+		http.Error(w, msgPaddingError, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,101 @@
+package vulnserver
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/padre/pkg/client"
+	"github.com/glebarez/padre/pkg/encoder"
+	"github.com/glebarez/padre/pkg/exploit"
+	"github.com/glebarez/padre/pkg/probe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, server *httptest.Server, token string) (int, string) {
+	resp, err := http.Get(server.URL + "/?token=" + url.QueryEscape(token))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestServer_Responses(t *testing.T) {
+	s, err := New(Config{BlockLen: 16})
+	require.NoError(t, err)
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	// new visitor gets a token
+	status, body := get(t, server, "")
+	assert.Equal(t, 200, status)
+	token := strings.TrimSpace(strings.TrimPrefix(body, "Your token: "))
+
+	status, body = get(t, server, token)
+	assert.Equal(t, 200, status)
+	assert.Contains(t, body, msgWelcome)
+
+	status, _ = get(t, server, s.Token([]byte("role=admin")))
+	assert.Equal(t, 200, status)
+
+	// tampered padding is reported (tampered value may happen to be valid padding, so several values are tried)
+	ciphertext, err := encoder.NewB64encoder("").DecodeString(token)
+	require.NoError(t, err)
+	errors := 0
+	for i := 1; i <= 3; i++ {
+		tampered := append([]byte{}, ciphertext...)
+		tampered[len(tampered)-17] ^= byte(i * 0x40)
+		if status, _ = get(t, server, encoder.NewB64encoder("").EncodeToString(tampered)); status == 500 {
+			errors++
+		}
+	}
+	assert.GreaterOrEqual(t, errors, 2)
+
+	status, _ = get(t, server, "not a token")
+	assert.Equal(t, 400, status)
+
+	_, err = New(Config{BlockLen: 32})
+	assert.Error(t, err)
+	_, err = New(Config{BlockLen: 16, Style: "silent"})
+	assert.Error(t, err)
+}
+
+func TestServer_Attack(t *testing.T) {
+	for _, config := range []Config{
+		{BlockLen: 16, Style: StyleStatus},
+		{BlockLen: 8, Style: StyleBody, Encoder: encoder.NewLHEXencoder("")},
+		{BlockLen: 16, Style: StyleRedirect},
+	} {
+		s, err := New(config)
+		require.NoError(t, err)
+		server := httptest.NewServer(s)
+
+		matcher, err := probe.NewMatcherByRegexp("invalid")
+		require.NoError(t, err)
+		p := &exploit.Padre{
+			Client: &client.Client{
+				HTTPclient:        http.DefaultClient,
+				URL:               server.URL + "/?token=$",
+				CipherPlaceholder: "$",
+				Encoder:           s.config.Encoder,
+				Concurrency:       16,
+			},
+			Matcher:  matcher,
+			BlockLen: config.BlockLen,
+		}
+
+		ciphertext, err := s.config.Encoder.DecodeString(s.Token([]byte("padre decrypts this")))
+		require.NoError(t, err)
+		plaintext, err := p.Decrypt(context.Background(), ciphertext, nil)
+		require.NoError(t, err, config.Style)
+		assert.Equal(t, exploit.PKCS7.Pad([]byte("padre decrypts this"), config.BlockLen), plaintext)
+
+		server.Close()
+	}
+}